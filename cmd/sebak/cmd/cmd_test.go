@@ -55,6 +55,9 @@ func TestParseFlagsNode(t *testing.T) {
 	require.Equal(t, "100-M", rateLimitRuleAPI.Default.Formatted)
 	require.Equal(t, "100-S", rateLimitRuleNode.Default.Formatted)
 
+	require.Equal(t, uint64(common.DefaultPeerBanThreshold), peerBanThreshold)
+	require.Equal(t, common.DefaultPeerBanDuration, peerBanDuration)
+
 	require.Equal(t, uint64(1000000), txPoolClientLimit)
 	require.Equal(t, uint64(0) /* unlimited */, txPoolNodeLimit)
 
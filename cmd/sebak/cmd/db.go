@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"boscoin.io/sebak/cmd/sebak/cmd/db"
+)
+
+var (
+	dbCmd *cobra.Command
+)
+
+func init() {
+	dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Storage management",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) < 1 {
+				c.Usage()
+			}
+		},
+	}
+
+	dbCmd.AddCommand(db.ExportCmd)
+	dbCmd.AddCommand(db.ImportCmd)
+	dbCmd.AddCommand(db.IndexCmd)
+	dbCmd.AddCommand(db.VerifyCmd)
+	dbCmd.AddCommand(db.MigrateCmd)
+	rootCmd.AddCommand(dbCmd)
+}
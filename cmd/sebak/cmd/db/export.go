@@ -0,0 +1,67 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/sync"
+)
+
+var (
+	ExportCmd *cobra.Command
+
+	flagExportStorage   string = common.GetENVValue("SEBAK_STORAGE", "")
+	flagExportNetworkID string = common.GetENVValue("SEBAK_NETWORK_ID", "")
+	flagExportFrom      uint64 = common.GenesisBlockHeight
+	flagExportTo        uint64
+	flagExportOutput    string = "-"
+)
+
+func init() {
+	ExportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export blocks to archive",
+		Long:  "Export blocks with their transactions to archive; the ballots are not exported, because the node does not store them.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			runExport(c)
+		},
+	}
+
+	ExportCmd.Flags().StringVar(&flagExportStorage, "storage", flagExportStorage, "storage uri")
+	ExportCmd.Flags().StringVar(&flagExportNetworkID, "network-id", flagExportNetworkID, "network id")
+	ExportCmd.Flags().Uint64Var(&flagExportFrom, "from", flagExportFrom, "first block height")
+	ExportCmd.Flags().Uint64Var(&flagExportTo, "to", flagExportTo, "last block height; 0 is the latest block")
+	ExportCmd.Flags().StringVar(&flagExportOutput, "output", flagExportOutput, "archive file; '-' is stdout")
+}
+
+func runExport(c *cobra.Command) {
+	if len(flagExportNetworkID) < 1 {
+		cmdcommon.PrintFlagsError(c, "--network-id", fmt.Errorf("--network-id must be provided"))
+	}
+
+	st := openStorage(c, flagExportStorage)
+	defer st.Close()
+
+	var w io.Writer = os.Stdout
+	if flagExportOutput != "-" {
+		f, err := os.Create(flagExportOutput)
+		if err != nil {
+			cmdcommon.PrintFlagsError(c, "--output", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	header, err := sync.ExportArchive(st, w, []byte(flagExportNetworkID), flagExportFrom, flagExportTo)
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "exported blocks from %d to %d\n", header.From, header.To)
+}
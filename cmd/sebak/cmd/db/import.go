@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/sync"
+)
+
+var (
+	ImportCmd *cobra.Command
+
+	flagImportStorage   string = common.GetENVValue("SEBAK_STORAGE", "")
+	flagImportNetworkID string = common.GetENVValue("SEBAK_NETWORK_ID", "")
+	flagImportInput     string = "-"
+	flagImportLogLevel  string = "info"
+
+	// the limits must be same with the nodes of the network
+	flagImportOperationsLimit         string = common.GetENVValue("SEBAK_OPERATIONS_LIMIT", strconv.Itoa(common.DefaultOperationsInTransactionLimit))
+	flagImportTransactionsLimit       string = common.GetENVValue("SEBAK_TRANSACTIONS_LIMIT", strconv.Itoa(common.DefaultTransactionsInBallotLimit))
+	flagImportOperationsInBallotLimit string = common.GetENVValue("SEBAK_OPERATIONS_IN_BALLOT_LIMIT", strconv.Itoa(common.DefaultOperationsInBallotLimit))
+)
+
+func init() {
+	ImportCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Validate and import blocks from archive",
+		Long:  "Validate and import blocks from archive without network; the blocks after genesis are validated like the synced blocks.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			runImport(c)
+		},
+	}
+
+	ImportCmd.Flags().StringVar(&flagImportStorage, "storage", flagImportStorage, "storage uri")
+	ImportCmd.Flags().StringVar(&flagImportNetworkID, "network-id", flagImportNetworkID, "network id")
+	ImportCmd.Flags().StringVar(&flagImportInput, "input", flagImportInput, "archive file; '-' is stdin")
+	ImportCmd.Flags().StringVar(&flagImportLogLevel, "log-level", flagImportLogLevel, "log level, {crit, error, warn, info, debug}")
+	ImportCmd.Flags().StringVar(&flagImportOperationsLimit, "operations-limit", flagImportOperationsLimit, "operations limit in a transaction")
+	ImportCmd.Flags().StringVar(&flagImportTransactionsLimit, "transactions-limit", flagImportTransactionsLimit, "transactions limit in a ballot")
+	ImportCmd.Flags().StringVar(&flagImportOperationsInBallotLimit, "operations-in-ballot-limit", flagImportOperationsInBallotLimit, "operations limit in a ballot")
+}
+
+func runImport(c *cobra.Command) {
+	if len(flagImportNetworkID) < 1 {
+		cmdcommon.PrintFlagsError(c, "--network-id", fmt.Errorf("--network-id must be provided"))
+	}
+
+	logLevel, err := logging.LvlFromString(flagImportLogLevel)
+	if err != nil {
+		cmdcommon.PrintFlagsError(c, "--log-level", err)
+	}
+
+	var transactionsLimit, operationsLimit, operationsInBallotLimit uint64
+	if transactionsLimit, err = strconv.ParseUint(flagImportTransactionsLimit, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(c, "--transactions-limit", err)
+	}
+	if operationsLimit, err = strconv.ParseUint(flagImportOperationsLimit, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(c, "--operations-limit", err)
+	}
+	if operationsInBallotLimit, err = strconv.ParseUint(flagImportOperationsInBallotLimit, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(c, "--operations-in-ballot-limit", err)
+	}
+	logger := logging.New("module", "import")
+	logger.SetHandler(logging.LvlFilterHandler(logLevel, logging.StreamHandler(os.Stderr, logging.LogfmtFormat())))
+
+	var r io.Reader = os.Stdin
+	if flagImportInput != "-" {
+		f, err := os.Open(flagImportInput)
+		if err != nil {
+			cmdcommon.PrintFlagsError(c, "--input", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	st := openStorage(c, flagImportStorage)
+	defer st.Close()
+
+	conf := common.Config{
+		NetworkID:        []byte(flagImportNetworkID),
+		TxsLimit:         int(transactionsLimit),
+		OpsLimit:         int(operationsLimit),
+		OpsInBallotLimit: int(operationsInBallotLimit),
+	}
+
+	header, imported, err := sync.ImportArchive(context.Background(), st, r, conf, logger)
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d blocks of archive from %d to %d\n", imported, header.From, header.To)
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/sync"
+)
+
+var (
+	IndexCmd *cobra.Command
+)
+
+func init() {
+	IndexCmd = &cobra.Command{
+		Use:   "index <archive directory>",
+		Short: "Write the index of archives, which is served by static HTTP mirror for '--sync-source'",
+		Args:  cobra.ExactArgs(1),
+		Run: func(c *cobra.Command, args []string) {
+			runIndex(c, args[0])
+		},
+	}
+}
+
+func runIndex(c *cobra.Command, dir string) {
+	idx, err := sync.BuildArchiveIndex(dir)
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+	if len(idx.Segments) < 1 {
+		cmdcommon.PrintError(c, fmt.Errorf("archive not found in %s", dir))
+	}
+
+	b, err := common.JSONMarshalIndent(idx)
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, sync.ArchiveIndexName), b, 0644); err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "indexed %d archives to height %d\n", len(idx.Segments), idx.Highest())
+}
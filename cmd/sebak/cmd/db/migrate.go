@@ -0,0 +1,86 @@
+package db
+
+import (
+	"fmt"
+	"os"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/storage"
+)
+
+var (
+	MigrateCmd *cobra.Command
+
+	flagMigrateStorage   string = common.GetENVValue("SEBAK_STORAGE", "")
+	flagMigrateBatchSize int    = storage.DefaultMigrationBatchSize
+	flagMigrateDryRun    bool
+	flagMigrateLogLevel  string = "info"
+)
+
+func init() {
+	MigrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate storage to the current schema version",
+		Long:  "Migrate storage to the current schema version; the node must be stopped while migrating.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			runMigrate(c)
+		},
+	}
+
+	MigrateCmd.Flags().StringVar(&flagMigrateStorage, "storage", flagMigrateStorage, "storage uri")
+	MigrateCmd.Flags().IntVar(&flagMigrateBatchSize, "batch-size", flagMigrateBatchSize, "number of records written in one batch")
+	MigrateCmd.Flags().BoolVar(&flagMigrateDryRun, "dry-run", flagMigrateDryRun, "print the pending migrations without migrating")
+	MigrateCmd.Flags().StringVar(&flagMigrateLogLevel, "log-level", flagMigrateLogLevel, "log level, {crit, error, warn, info, debug}")
+}
+
+func runMigrate(c *cobra.Command) {
+	if flagMigrateBatchSize < 1 {
+		cmdcommon.PrintFlagsError(c, "--batch-size", fmt.Errorf("--batch-size must be greater than 0"))
+	}
+
+	logLevel, err := logging.LvlFromString(flagMigrateLogLevel)
+	if err != nil {
+		cmdcommon.PrintFlagsError(c, "--log-level", err)
+	}
+	logger := logging.New("module", "migrate")
+	logger.SetHandler(logging.LvlFilterHandler(logLevel, logging.StreamHandler(os.Stderr, logging.LogfmtFormat())))
+
+	st := openStorage(c, flagMigrateStorage)
+	defer st.Close()
+
+	m := storage.NewMigrator(st, storage.Migrations(), logger)
+	m.BatchSize = flagMigrateBatchSize
+
+	version, err := m.Version()
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+	if version > storage.SchemaVersion() {
+		cmdcommon.PrintError(c, fmt.Errorf("schema version of storage, %d is newer than %d", version, storage.SchemaVersion()))
+	}
+
+	pending, err := m.Pending()
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	if flagMigrateDryRun {
+		for _, migration := range pending {
+			fmt.Printf("%d: %s\n", migration.Version, migration.Description)
+		}
+		fmt.Fprintf(os.Stderr, "schema version %d; %d pending migrations to %d\n", version, len(pending), storage.SchemaVersion())
+		return
+	}
+
+	applied, err := m.Run()
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "schema version %d; %d migrations applied\n", storage.SchemaVersion(), len(applied))
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// openStorage opens the storage of `--storage`; if it is empty, the default
+// storage path is used.
+func openStorage(c *cobra.Command, uri string) *storage.LevelDBBackend {
+	if len(uri) < 1 {
+		uri = cmdcommon.GetDefaultStoragePath(c)
+	}
+
+	storageConfig, err := storage.NewConfigFromString(uri)
+	if err != nil {
+		cmdcommon.PrintFlagsError(c, "--storage", err)
+	}
+
+	st, err := storage.NewStorage(storageConfig)
+	if err != nil {
+		cmdcommon.PrintFlagsError(c, "--storage", fmt.Errorf("failed to initialize storage: %v", err))
+	}
+
+	return st
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+)
+
+var (
+	VerifyCmd *cobra.Command
+
+	flagVerifyStorage string = common.GetENVValue("SEBAK_STORAGE", "")
+	flagVerifyRepair  bool
+	flagVerifyJSON    bool
+)
+
+func init() {
+	VerifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the integrity of storage",
+		Long:  "Verify the blocks, the indexes and the accounts of storage; the node must not be running.",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			runVerify(c)
+		},
+	}
+
+	VerifyCmd.Flags().StringVar(&flagVerifyStorage, "storage", flagVerifyStorage, "storage uri")
+	VerifyCmd.Flags().BoolVar(&flagVerifyRepair, "repair", flagVerifyRepair, "store the missing indexes again")
+	VerifyCmd.Flags().BoolVar(&flagVerifyJSON, "json", flagVerifyJSON, "print result in json")
+}
+
+func runVerify(c *cobra.Command) {
+	st := openStorage(c, flagVerifyStorage)
+
+	result, err := block.Verify(st, flagVerifyRepair)
+	st.Close()
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	if flagVerifyJSON {
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			cmdcommon.PrintError(c, err)
+		}
+		fmt.Println(string(b))
+	} else {
+		for _, d := range result.Divergences {
+			fmt.Println(d.String())
+		}
+		fmt.Printf(
+			"verified %d blocks, %d transactions, %d operations and %d accounts; %d divergences\n",
+			result.Blocks, result.Transactions, result.Operations, result.Accounts, len(result.Divergences),
+		)
+	}
+
+	for _, d := range result.Divergences {
+		if !d.Repaired {
+			os.Exit(1)
+		}
+	}
+}
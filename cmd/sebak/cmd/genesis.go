@@ -41,6 +41,12 @@ func init() {
 				cmdcommon.PrintFlagsError(c, flagName, err)
 			}
 
+			if len(flagCheckpoints) > 0 {
+				if flagName, err = saveGenesisCheckpoints(flagCheckpoints, flagStorageConfigString); err != nil {
+					cmdcommon.PrintFlagsError(c, flagName, err)
+				}
+			}
+
 			fmt.Println("successfully created genesis block")
 		},
 	}
@@ -48,6 +54,7 @@ func init() {
 	genesisCmd.Flags().StringVar(&flagBalance, "balance", flagBalance, "initial balance of genesis block")
 	genesisCmd.Flags().StringVar(&flagStorageConfigString, "storage", flagStorageConfigString, "storage uri")
 	genesisCmd.Flags().StringVar(&flagNetworkID, "network-id", flagNetworkID, "network id")
+	genesisCmd.Flags().StringVar(&flagCheckpoints, "checkpoints", flagCheckpoints, "trusted block hashes stored with genesis block; '<height>:<hash>,<height>:<hash>'")
 
 	rootCmd.AddCommand(genesisCmd)
 }
@@ -209,3 +216,32 @@ func checkExistingAccounts(st *storage.LevelDBBackend, networkID, genesisAddress
 
 	return
 }
+
+// saveGenesisCheckpoints stores the checkpoints with genesis block, so the
+// node uses them without `--checkpoints`.
+func saveGenesisCheckpoints(checkpoints, storageUri string) (string, error) {
+	cps, err := common.ParseCheckpoints(checkpoints)
+	if err != nil {
+		return "--checkpoints", err
+	}
+
+	storageConfig, err := storage.NewConfigFromString(storageUri)
+	if err != nil {
+		return "--storage", err
+	}
+
+	st, err := storage.NewStorage(storageConfig)
+	if err != nil {
+		return "--storage", fmt.Errorf("failed to initialize storage: %v", err)
+	}
+	defer st.Close()
+
+	if err = block.CheckCheckpoints(st, cps); err != nil {
+		return "--checkpoints", err
+	}
+	if err = block.SaveCheckpoints(st, cps); err != nil {
+		return "--checkpoints", err
+	}
+
+	return "", nil
+}
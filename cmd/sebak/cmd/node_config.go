@@ -0,0 +1,531 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+)
+
+const nodeConfigHiddenSecret = "<hidden>"
+
+var (
+	nodeConfigCmd     *cobra.Command
+	nodeConfigDumpCmd *cobra.Command
+
+	flagShowSecret bool
+)
+
+func init() {
+	nodeConfigCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Node configuration",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) < 1 {
+				c.Usage()
+			}
+		},
+	}
+
+	nodeConfigDumpCmd = &cobra.Command{
+		Use:   "dump",
+		Short: "Print the effective configuration merged from --config, environment variables and flags",
+		Args:  cobra.NoArgs,
+		Run: func(c *cobra.Command, args []string) {
+			parseFlagConfig(c)
+
+			conf, err := newNodeConfigFromFlags(nodeCmd.PersistentFlags())
+			if err != nil {
+				cmdcommon.PrintError(c, err)
+			}
+			if !flagShowSecret {
+				conf.hideSecrets()
+			}
+
+			if err = cmdcommon.DefaultEncodes["yaml"](conf, os.Stdout); err != nil {
+				cmdcommon.PrintError(c, err)
+			}
+		},
+	}
+	nodeConfigDumpCmd.Flags().BoolVar(&flagShowSecret, "show-secret", flagShowSecret, "print the secret seed and the admin token instead of hiding them")
+
+	nodeConfigCmd.AddCommand(nodeConfigDumpCmd)
+}
+
+// parseFlagConfig loads the `--config` file and fills the flags of `sebak
+// node` with it. The flags given in command line and the flags set by the
+// environment variables are kept, so the file has the lowest priority.
+func parseFlagConfig(c *cobra.Command) {
+	if len(flagConfig) < 1 {
+		return
+	}
+
+	conf, err := loadNodeConfig(flagConfig)
+	if err != nil {
+		cmdcommon.PrintFlagsError(c, "--config", err)
+	}
+	if err = conf.apply(nodeCmd.PersistentFlags()); err != nil {
+		cmdcommon.PrintFlagsError(c, "--config", err)
+	}
+}
+
+// configDuration is the time duration string, like `5s`.
+type configDuration string
+
+var configDurationType = reflect.TypeOf(configDuration(""))
+
+// nodeConfig is the configuration file of `sebak node`. Every key is the
+// counterpart of the flag in the `flag` tag; `env` is the environment variable
+// of the flag and `sep` joins the list for the flag, which is not repeatable.
+type nodeConfig struct {
+	Node       nodeConfigNode      `yaml:"node,omitempty"`
+	Log        nodeConfigLog       `yaml:"log,omitempty"`
+	Storage    nodeConfigStorage   `yaml:"storage,omitempty"`
+	Consensus  nodeConfigConsensus `yaml:"consensus,omitempty"`
+	Limits     nodeConfigLimits    `yaml:"limits,omitempty"`
+	Validators []string            `yaml:"validators,omitempty" flag:"validators" env:"SEBAK_VALIDATORS"`
+	HTTPCache  nodeConfigHTTPCache `yaml:"http-cache,omitempty"`
+	RateLimit  nodeConfigRateLimit `yaml:"rate-limit,omitempty"`
+	PeerBan    nodeConfigPeerBan   `yaml:"peer-ban,omitempty"`
+	Sync       nodeConfigSync      `yaml:"sync,omitempty"`
+	Watcher    nodeConfigWatcher   `yaml:"watcher,omitempty"`
+	Time       nodeConfigTime      `yaml:"time,omitempty"`
+	Debug      nodeConfigDebug     `yaml:"debug,omitempty"`
+	Admin      nodeConfigAdmin     `yaml:"admin,omitempty"`
+}
+
+type nodeConfigNode struct {
+	NetworkID        *string  `yaml:"network-id,omitempty" flag:"network-id" env:"SEBAK_NETWORK_ID"`
+	SecretSeed       *string  `yaml:"secret-seed,omitempty" flag:"secret-seed" env:"SEBAK_SECRET_SEED"`
+	Bind             *string  `yaml:"bind,omitempty" flag:"bind" env:"SEBAK_BIND"`
+	Publish          *string  `yaml:"publish,omitempty" flag:"publish" env:"SEBAK_PUBLISH"`
+	JSONRPCBind      *string  `yaml:"jsonrpc-bind,omitempty" flag:"jsonrpc-bind" env:"SEBAK_JSONRPC_BIND"`
+	TLSCert          *string  `yaml:"tls-cert,omitempty" flag:"tls-cert" env:"SEBAK_TLS_CERT"`
+	TLSKey           *string  `yaml:"tls-key,omitempty" flag:"tls-key" env:"SEBAK_TLS_KEY"`
+	CongressAddress  *string  `yaml:"congress-address,omitempty" flag:"set-congress-address" env:"SEBAK_CONGRESS_ADDR"`
+	UnfreezingPeriod *uint64  `yaml:"unfreezing-period,omitempty" flag:"unfreezing-period" env:"SEBAK_UNFREEZING_PERIOD"`
+	Genesis          *string  `yaml:"genesis,omitempty" flag:"genesis"`
+	Discovery        []string `yaml:"discovery,omitempty" flag:"discovery" env:"SEBAK_DISCOVERY"`
+}
+
+type nodeConfigLog struct {
+	Level    *string `yaml:"level,omitempty" flag:"log-level" env:"SEBAK_LOG_LEVEL"`
+	Format   *string `yaml:"format,omitempty" flag:"log-format" env:"SEBAK_LOG_FORMAT"`
+	File     *string `yaml:"file,omitempty" flag:"log" env:"SEBAK_LOG"`
+	HTTPFile *string `yaml:"http-file,omitempty" flag:"http-log" env:"SEBAK_HTTP_LOG"`
+	Verbose  *bool   `yaml:"verbose,omitempty" flag:"verbose" env:"SEBAK_VERBOSE"`
+}
+
+type nodeConfigStorage struct {
+	URI              *string `yaml:"uri,omitempty" flag:"storage" env:"SEBAK_STORAGE"`
+	SnapshotInterval *uint64 `yaml:"snapshot-interval,omitempty" flag:"snapshot-interval" env:"SEBAK_SNAPSHOT_INTERVAL"`
+	PruneKeepBlocks  *uint64 `yaml:"prune-keep-blocks,omitempty" flag:"prune-keep-blocks" env:"SEBAK_PRUNE_KEEP_BLOCKS"`
+}
+
+type nodeConfigConsensus struct {
+	Threshold      *uint64                     `yaml:"threshold,omitempty" flag:"threshold" env:"SEBAK_THRESHOLD"`
+	BlockTime      *configDuration             `yaml:"block-time,omitempty" flag:"block-time" env:"SEBAK_BLOCK_TIME"`
+	BlockTimeDelta *configDuration             `yaml:"block-time-delta,omitempty" flag:"block-time-delta" env:"SEBAK_BLOCK_TIME_DELTA"`
+	Timeouts       nodeConfigConsensusTimeouts `yaml:"timeouts,omitempty"`
+}
+
+type nodeConfigConsensusTimeouts struct {
+	Init       *configDuration `yaml:"init,omitempty" flag:"timeout-init" env:"SEBAK_TIMEOUT_INIT"`
+	Sign       *configDuration `yaml:"sign,omitempty" flag:"timeout-sign" env:"SEBAK_TIMEOUT_SIGN"`
+	Accept     *configDuration `yaml:"accept,omitempty" flag:"timeout-accept" env:"SEBAK_TIMEOUT_ACCEPT"`
+	AllConfirm *configDuration `yaml:"allconfirm,omitempty" flag:"timeout-allconfirm" env:"SEBAK_TIMEOUT_ALLCONFIRM"`
+	Adaptive   *bool           `yaml:"adaptive,omitempty" flag:"timeout-adaptive" env:"SEBAK_TIMEOUT_ADAPTIVE"`
+	Min        *configDuration `yaml:"min,omitempty" flag:"timeout-min" env:"SEBAK_TIMEOUT_MIN"`
+	Max        *configDuration `yaml:"max,omitempty" flag:"timeout-max" env:"SEBAK_TIMEOUT_MAX"`
+}
+
+type nodeConfigLimits struct {
+	OperationsInTransaction *uint64 `yaml:"operations-in-transaction,omitempty" flag:"operations-limit" env:"SEBAK_OPERATIONS_LIMIT"`
+	TransactionsInBallot    *uint64 `yaml:"transactions-in-ballot,omitempty" flag:"transactions-limit" env:"SEBAK_TRANSACTIONS_LIMIT"`
+	OperationsInBallot      *uint64 `yaml:"operations-in-ballot,omitempty" flag:"operations-in-ballot-limit" env:"SEBAK_OPERATIONS_IN_BALLOT_LIMIT"`
+	TxPool                  *string `yaml:"txpool,omitempty" flag:"txpool-limit" env:"SEBAK_TX_POOL_LIMIT"`
+}
+
+type nodeConfigHTTPCache struct {
+	Adapter    *string `yaml:"adapter,omitempty" flag:"http-cache-adapter" env:"SEBAK_HTTP_CACHE_ADAPTER"`
+	PoolSize   *uint64 `yaml:"pool-size,omitempty" flag:"http-cache-pool-size" env:"SEBAK_HTTP_CACHE_POOL_SIZE"`
+	RedisAddrs *string `yaml:"redis-addrs,omitempty" flag:"http-cache-redis-addrs" env:"SEBAK_HTTP_CACHE_REDIS_ADDRS"`
+}
+
+type nodeConfigRateLimit struct {
+	API  []string `yaml:"api,omitempty" flag:"rate-limit-api" env:"SEBAK_RATE_LIMIT_API"`
+	Node []string `yaml:"node,omitempty" flag:"rate-limit-node" env:"SEBAK_RATE_LIMIT_NODE"`
+}
+
+type nodeConfigPeerBan struct {
+	Threshold *uint64         `yaml:"threshold,omitempty" flag:"peer-ban-threshold" env:"SEBAK_PEER_BAN_THRESHOLD"`
+	Duration  *configDuration `yaml:"duration,omitempty" flag:"peer-ban-duration" env:"SEBAK_PEER_BAN_DURATION"`
+}
+
+type nodeConfigSync struct {
+	PoolSize         *uint64         `yaml:"pool-size,omitempty" flag:"sync-pool-size" env:"SEBAK_SYNC_POOL_SIZE"`
+	BatchSize        *uint64         `yaml:"batch-size,omitempty" flag:"sync-batch-size" env:"SEBAK_SYNC_BATCH_SIZE"`
+	FetchAhead       *uint64         `yaml:"fetch-ahead,omitempty" flag:"sync-fetch-ahead" env:"SEBAK_SYNC_FETCH_AHEAD"`
+	Source           *string         `yaml:"source,omitempty" flag:"sync-source" env:"SEBAK_SYNC_SOURCE"`
+	FetchTimeout     *configDuration `yaml:"fetch-timeout,omitempty" flag:"sync-fetch-timeout" env:"SEBAK_SYNC_FETCH_TIMEOUT"`
+	RetryInterval    *configDuration `yaml:"retry-interval,omitempty" flag:"sync-retry-interval" env:"SEBAK_SYNC_RETRY_INTERVAL"`
+	CheckInterval    *configDuration `yaml:"check-interval,omitempty" flag:"sync-check-interval" env:"SEBAK_SYNC_CHECK_INTERVAL"`
+	CheckPrevBlock   *configDuration `yaml:"check-prevblock,omitempty" flag:"sync-check-prevblock" env:"SEBAK_SYNC_CHECK_PREVBLOCK"`
+	FastSync         *bool           `yaml:"fast-sync,omitempty" flag:"fast-sync" env:"SEBAK_FAST_SYNC"`
+	Checkpoints      []string        `yaml:"checkpoints,omitempty" flag:"checkpoints" env:"SEBAK_CHECKPOINTS" sep:","`
+	TrustCheckpoints *bool           `yaml:"trust-checkpoints,omitempty" flag:"trust-checkpoints" env:"SEBAK_TRUST_CHECKPOINTS"`
+}
+
+type nodeConfigWatcher struct {
+	Enabled  *bool           `yaml:"enabled,omitempty" flag:"watcher-mode" env:"SEBAK_WATCHER_MODE"`
+	Interval *configDuration `yaml:"interval,omitempty" flag:"watch-interval" env:"SEBAK_WATCH_INTERVAL"`
+}
+
+type nodeConfigTime struct {
+	NTP         *string `yaml:"ntp,omitempty" flag:"ntp" env:"SEBAK_NTP_SERVER"`
+	SyncCommand *string `yaml:"sync-command,omitempty" flag:"time-sync-command" env:"SEBAK_TIME_SYNC_COMMAND"`
+}
+
+type nodeConfigDebug struct {
+	PProf *bool `yaml:"pprof,omitempty" flag:"debug-pprof" env:"SEBAK_DEBUG_PPROF"`
+}
+
+type nodeConfigAdmin struct {
+	Bind        *string `yaml:"bind,omitempty" flag:"admin-bind" env:"SEBAK_ADMIN_BIND"`
+	Token       *string `yaml:"token,omitempty" flag:"admin-token" env:"SEBAK_ADMIN_TOKEN"`
+	TLSClientCA *string `yaml:"tls-client-ca,omitempty" flag:"admin-tls-client-ca" env:"SEBAK_ADMIN_TLS_CLIENT_CA"`
+	AuditLog    *string `yaml:"audit-log,omitempty" flag:"admin-audit-log" env:"SEBAK_ADMIN_AUDIT_LOG"`
+}
+
+// nodeConfigField is the key of nodeConfig, like `sync.pool-size`.
+type nodeConfigField struct {
+	key  string
+	flag string
+	env  string
+	sep  string
+	v    reflect.Value
+}
+
+func (c *nodeConfig) fields() []nodeConfigField {
+	return walkNodeConfig(reflect.ValueOf(c).Elem(), "")
+}
+
+func walkNodeConfig(v reflect.Value, prefix string) (fields []nodeConfigField) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := prefix + strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, walkNodeConfig(v.Field(i), key+".")...)
+			continue
+		}
+
+		sep := sf.Tag.Get("sep")
+		if len(sep) < 1 {
+			sep = " "
+		}
+
+		fields = append(fields, nodeConfigField{
+			key:  key,
+			flag: sf.Tag.Get("flag"),
+			env:  sf.Tag.Get("env"),
+			sep:  sep,
+			v:    v.Field(i),
+		})
+	}
+
+	return
+}
+
+// decode sets the value of the parsed configuration file.
+func (f nodeConfigField) decode(raw interface{}) error {
+	if f.v.Kind() == reflect.Slice {
+		l, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("must be a list of strings")
+		}
+		s := make([]string, len(l))
+		for i, e := range l {
+			if s[i], ok = e.(string); !ok {
+				return fmt.Errorf("must be a list of strings")
+			}
+		}
+		f.v.Set(reflect.ValueOf(s))
+		return nil
+	}
+
+	p := reflect.New(f.v.Type().Elem())
+	switch p.Elem().Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		if p.Elem().Type() == configDurationType {
+			if _, err := time.ParseDuration(s); err != nil {
+				return err
+			}
+		}
+		p.Elem().SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("must be a boolean")
+		}
+		p.Elem().SetBool(b)
+	case reflect.Uint64:
+		var n uint64
+		switch i := raw.(type) {
+		case int:
+			if i < 0 {
+				return fmt.Errorf("must not be negative")
+			}
+			n = uint64(i)
+		case int64:
+			if i < 0 {
+				return fmt.Errorf("must not be negative")
+			}
+			n = uint64(i)
+		case uint64:
+			n = i
+		default:
+			return fmt.Errorf("must be an integer")
+		}
+		p.Elem().SetUint(n)
+	}
+	f.v.Set(p)
+
+	return nil
+}
+
+// values returns the flag values of the field; nil when the field is not set.
+func (f nodeConfigField) values(flag *pflag.Flag) []string {
+	if f.v.IsNil() {
+		return nil
+	}
+
+	if f.v.Kind() == reflect.Slice {
+		l := f.v.Interface().([]string)
+		if flag.Value.Type() == "list" {
+			return l
+		}
+		return []string{strings.Join(l, f.sep)}
+	}
+
+	e := f.v.Elem()
+	switch e.Kind() {
+	case reflect.Bool:
+		return []string{strconv.FormatBool(e.Bool())}
+	case reflect.Uint64:
+		return []string{strconv.FormatUint(e.Uint(), 10)}
+	default:
+		return []string{e.String()}
+	}
+}
+
+// encode sets the field from the current value of the flag.
+func (f nodeConfigField) encode(flag *pflag.Flag) error {
+	value := flag.Value.String()
+
+	if f.v.Kind() == reflect.Slice {
+		var l []string
+		if f.sep == " " {
+			l = strings.Fields(value)
+		} else {
+			for _, s := range strings.Split(value, f.sep) {
+				if s = strings.TrimSpace(s); len(s) > 0 {
+					l = append(l, s)
+				}
+			}
+		}
+
+		// the repeatable flags take the environment variable in
+		// `parseFlagsNode()`
+		if len(l) < 1 && flag.Value.Type() == "list" && len(f.env) > 0 {
+			l = strings.Fields(os.Getenv(f.env))
+		}
+		if len(l) > 0 {
+			f.v.Set(reflect.ValueOf(l))
+		}
+		return nil
+	}
+
+	p := reflect.New(f.v.Type().Elem())
+	switch p.Elem().Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		p.Elem().SetBool(b)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		p.Elem().SetUint(n)
+	default:
+		p.Elem().SetString(value)
+	}
+	f.v.Set(p)
+
+	return nil
+}
+
+// newNodeConfig makes nodeConfig from the parsed configuration file; the
+// unknown keys and the values of wrong type are refused.
+func newNodeConfig(m map[string]interface{}) (*nodeConfig, error) {
+	flat := map[string]interface{}{}
+	if err := flattenNodeConfig(m, "", flat); err != nil {
+		return nil, err
+	}
+
+	conf := &nodeConfig{}
+	for _, f := range conf.fields() {
+		raw, found := flat[f.key]
+		if !found {
+			continue
+		}
+		delete(flat, f.key)
+
+		if raw == nil {
+			continue
+		}
+		if err := f.decode(raw); err != nil {
+			return nil, fmt.Errorf("'%s' %v", f.key, err)
+		}
+	}
+
+	if len(flat) > 0 {
+		var unknown []string
+		for k := range flat {
+			unknown = append(unknown, k)
+		}
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+	}
+
+	return conf, nil
+}
+
+func flattenNodeConfig(m interface{}, prefix string, flat map[string]interface{}) error {
+	switch t := m.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			if err := flattenNodeConfig(v, prefix+k+".", flat); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, v := range t {
+			s, ok := k.(string)
+			if !ok {
+				return fmt.Errorf("key must be a string: %v", k)
+			}
+			if err := flattenNodeConfig(v, prefix+s+".", flat); err != nil {
+				return err
+			}
+		}
+	default:
+		flat[strings.TrimSuffix(prefix, ".")] = m
+	}
+
+	return nil
+}
+
+// newNodeConfigFromFlags makes the effective nodeConfig from the flags of
+// `sebak node`.
+func newNodeConfigFromFlags(fs *pflag.FlagSet) (*nodeConfig, error) {
+	conf := &nodeConfig{}
+	for _, f := range conf.fields() {
+		flag := fs.Lookup(f.flag)
+		if flag == nil {
+			return nil, fmt.Errorf("'%s' unknown flag --%s", f.key, f.flag)
+		}
+		if err := f.encode(flag); err != nil {
+			return nil, fmt.Errorf("invalid '--%s'; %v", f.flag, err)
+		}
+	}
+
+	return conf, nil
+}
+
+// hideSecrets replaces the secret seed and the admin token, which are the
+// credentials of the node, with `nodeConfigHiddenSecret`.
+func (c *nodeConfig) hideSecrets() {
+	for _, secret := range []**string{&c.Node.SecretSeed, &c.Admin.Token} {
+		if *secret != nil && len(**secret) > 0 {
+			hidden := nodeConfigHiddenSecret
+			*secret = &hidden
+		}
+	}
+}
+
+// apply sets the flags, which are not given in command line or by the
+// environment variables.
+func (c *nodeConfig) apply(fs *pflag.FlagSet) error {
+	for _, f := range c.fields() {
+		flag := fs.Lookup(f.flag)
+		if flag == nil {
+			return fmt.Errorf("'%s' unknown flag --%s", f.key, f.flag)
+		}
+
+		values := f.values(flag)
+		if values == nil || flag.Changed {
+			continue
+		}
+		if len(f.env) > 0 {
+			if _, found := os.LookupEnv(f.env); found {
+				continue
+			}
+		}
+
+		for _, value := range values {
+			if err := fs.Set(f.flag, value); err != nil {
+				return fmt.Errorf("'%s' %v", f.key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTOML decodes the TOML document to the map of the tables.
+func parseTOML(b []byte) (m map[string]interface{}, err error) {
+	_, err = toml.Decode(string(b), &m)
+	return
+}
+
+func loadNodeConfig(path string) (*nodeConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		m, err = parseTOML(b)
+	case ".yaml", ".yml", ".json":
+		err = yaml.Unmarshal(b, &m)
+	default:
+		err = fmt.Errorf("unknown format, '%s'; .toml, .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newNodeConfig(m)
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+)
+
+func TestParseTOML(t *testing.T) {
+	m, err := parseTOML([]byte(`
+# comment
+validators = [
+  "GDPQ2LBYP3RL3O675H2N5IEYM6PRJNUA5QFMKXIHGTKEB5KS5T3KHFA2", # first
+  'self',
+]
+
+[node]
+network-id = "net # not comment"
+genesis = ''
+
+[consensus.timeouts]
+init = "3s"
+adaptive = true
+
+[sync]
+pool-size = 1_000
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, []interface{}{"GDPQ2LBYP3RL3O675H2N5IEYM6PRJNUA5QFMKXIHGTKEB5KS5T3KHFA2", "self"}, m["validators"])
+	require.Equal(t, "net # not comment", m["node"].(map[string]interface{})["network-id"])
+	require.Equal(t, "", m["node"].(map[string]interface{})["genesis"])
+
+	timeouts := m["consensus"].(map[string]interface{})["timeouts"].(map[string]interface{})
+	require.Equal(t, "3s", timeouts["init"])
+	require.Equal(t, true, timeouts["adaptive"])
+	require.Equal(t, int64(1000), m["sync"].(map[string]interface{})["pool-size"])
+
+	{ // wrong format
+		for _, s := range []string{
+			"a = ",
+			"a = \"b",
+			"a = [1, 2",
+			"a = 1\na = 2",
+			"a = 1\n[a]",
+			"a = 1 2",
+		} {
+			_, err = parseTOML([]byte(s))
+			require.Error(t, err, s)
+		}
+	}
+}
+
+func TestNodeConfigValidate(t *testing.T) {
+	var m map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+node:
+  network-id: test
+consensus:
+  threshold: 70
+  timeouts:
+    init: 3s
+sync:
+  checkpoints:
+  - 1:abc
+`), &m))
+
+	conf, err := newNodeConfig(m)
+	require.NoError(t, err)
+	require.Equal(t, "test", *conf.Node.NetworkID)
+	require.Equal(t, uint64(70), *conf.Consensus.Threshold)
+	require.Equal(t, configDuration("3s"), *conf.Consensus.Timeouts.Init)
+	require.Equal(t, []string{"1:abc"}, conf.Sync.Checkpoints)
+	require.Nil(t, conf.Sync.PoolSize)
+
+	for _, s := range []string{
+		"unknown: 1",
+		"sync:\n  unknown: 1",
+		"sync:\n  pool-size: -1",
+		"sync:\n  pool-size: a",
+		"sync:\n  fetch-timeout: 3",
+		"sync:\n  fetch-timeout: 3k",
+		"sync:\n  fast-sync: 1",
+		"validators: self",
+	} {
+		m = nil
+		require.NoError(t, yaml.Unmarshal([]byte(s), &m))
+		_, err = newNodeConfig(m)
+		require.Error(t, err, s)
+	}
+}
+
+// TestNodeConfigCoversFlags checks that every flag of `sebak node` is in the
+// configuration file.
+func TestNodeConfigCoversFlags(t *testing.T) {
+	keys := map[string]string{}
+	for _, f := range (&nodeConfig{}).fields() {
+		require.NotNil(t, nodeCmd.PersistentFlags().Lookup(f.flag), f.key)
+		keys[f.flag] = f.key
+	}
+
+	nodeCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		_, found := keys[f.Name]
+		require.True(t, found, f.Name)
+	})
+}
+
+func TestNodeConfigApply(t *testing.T) {
+	var threshold, poolSize, timeoutInit, checkpoints string
+	var fastSync bool
+	var rateLimitAPI cmdcommon.ListFlags
+
+	fs := pflag.NewFlagSet("node", pflag.ContinueOnError)
+	fs.StringVar(&threshold, "threshold", "67", "")
+	fs.StringVar(&poolSize, "sync-pool-size", "300", "")
+	fs.StringVar(&timeoutInit, "timeout-init", "2s", "")
+	fs.StringVar(&checkpoints, "checkpoints", "", "")
+	fs.BoolVar(&fastSync, "fast-sync", false, "")
+	fs.Var(&rateLimitAPI, "rate-limit-api", "")
+
+	// the other flags are not used
+	for _, f := range (&nodeConfig{}).fields() {
+		if fs.Lookup(f.flag) != nil {
+			continue
+		}
+		switch f.v.Type() {
+		case reflect.TypeOf((*bool)(nil)):
+			fs.Bool(f.flag, false, "")
+		case reflect.TypeOf((*uint64)(nil)):
+			fs.String(f.flag, "0", "")
+		default:
+			fs.String(f.flag, "", "")
+		}
+	}
+
+	m, err := parseTOML([]byte(`
+[consensus]
+threshold = 70
+[consensus.timeouts]
+init = "3s"
+[rate-limit]
+api = ["10-S", "1.2.3.4=100-M"]
+[sync]
+pool-size = 1000
+fast-sync = true
+checkpoints = ["1:abc", "5:def"]
+`))
+	require.NoError(t, err)
+	conf, err := newNodeConfig(m)
+	require.NoError(t, err)
+
+	// command line and environment variable override the file
+	require.NoError(t, fs.Parse([]string{"--threshold", "80"}))
+	os.Setenv("SEBAK_SYNC_POOL_SIZE", "7")
+	defer os.Unsetenv("SEBAK_SYNC_POOL_SIZE")
+
+	require.NoError(t, conf.apply(fs))
+	require.Equal(t, "80", threshold)
+	require.Equal(t, "300", poolSize)
+	require.Equal(t, "3s", timeoutInit)
+	require.Equal(t, true, fastSync)
+	require.Equal(t, "1:abc,5:def", checkpoints)
+	require.Equal(t, cmdcommon.ListFlags{"10-S", "1.2.3.4=100-M"}, rateLimitAPI)
+
+	effective, err := newNodeConfigFromFlags(fs)
+	require.NoError(t, err)
+	require.Equal(t, uint64(80), *effective.Consensus.Threshold)
+	require.Equal(t, configDuration("3s"), *effective.Consensus.Timeouts.Init)
+	require.Equal(t, []string{"1:abc", "5:def"}, effective.Sync.Checkpoints)
+	require.Equal(t, []string{"10-S", "1.2.3.4=100-M"}, effective.RateLimit.API)
+}
+
+func TestNodeConfigHideSecrets(t *testing.T) {
+	m, err := parseTOML([]byte(`
+[node]
+secret-seed = "SCN4NSV5SVHIZWUDJFT4Z5FFVHO3TFRCJKRQMMO3FBUXDEAVU7O5MQPS"
+[admin]
+bind = "https://localhost:12346"
+token = "admin-secret-token"
+`))
+	require.NoError(t, err)
+	conf, err := newNodeConfig(m)
+	require.NoError(t, err)
+
+	conf.hideSecrets()
+
+	b := new(bytes.Buffer)
+	require.NoError(t, cmdcommon.DefaultEncodes["yaml"](conf, b))
+	require.NotContains(t, b.String(), "SCN4NSV5SVHIZWUDJFT4Z5FFVHO3TFRCJKRQMMO3FBUXDEAVU7O5MQPS")
+	require.NotContains(t, b.String(), "admin-secret-token")
+	require.Equal(t, nodeConfigHiddenSecret, *conf.Node.SecretSeed)
+	require.Equal(t, nodeConfigHiddenSecret, *conf.Admin.Token)
+	require.Equal(t, "https://localhost:12346", *conf.Admin.Bind)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"golang.org/x/net/http2"
 
 	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
 	"boscoin.io/sebak/lib/consensus"
@@ -37,6 +39,8 @@ const (
 )
 
 var (
+	flagConfig                     string = common.GetENVValue("SEBAK_CONFIG", "")
+	flagGenesis                    string
 	flagBindURL                    string = common.GetENVValue("SEBAK_BIND", defaultBindURL)
 	flagBlockTime                  string = common.GetENVValue("SEBAK_BLOCK_TIME", "5s")
 	flagBlockTimeDelta             string = common.GetENVValue("SEBAK_BLOCK_TIME_DELTA", "1s")
@@ -51,6 +55,9 @@ var (
 	flagSyncCheckInterval          string = common.GetENVValue("SEBAK_SYNC_CHECK_INTERVAL", "30s")
 	flagSyncFetchTimeout           string = common.GetENVValue("SEBAK_SYNC_FETCH_TIMEOUT", "1m")
 	flagSyncPoolSize               string = common.GetENVValue("SEBAK_SYNC_POOL_SIZE", "300")
+	flagSyncBatchSize              string = common.GetENVValue("SEBAK_SYNC_BATCH_SIZE", "50")
+	flagSyncFetchAhead             string = common.GetENVValue("SEBAK_SYNC_FETCH_AHEAD", "4")
+	flagSyncSource                 string = common.GetENVValue("SEBAK_SYNC_SOURCE", "")
 	flagSyncRetryInterval          string = common.GetENVValue("SEBAK_SYNC_RETRY_INTERVAL", "10s")
 	flagSyncCheckPrevBlockInterval string = common.GetENVValue("SEBAK_SYNC_CHECK_PREVBLOCK", "30s")
 	flagThreshold                  string = common.GetENVValue("SEBAK_THRESHOLD", "67")
@@ -58,6 +65,9 @@ var (
 	flagTimeoutALLCONFIRM          string = common.GetENVValue("SEBAK_TIMEOUT_ALLCONFIRM", "30s")
 	flagTimeoutINIT                string = common.GetENVValue("SEBAK_TIMEOUT_INIT", "2s")
 	flagTimeoutSIGN                string = common.GetENVValue("SEBAK_TIMEOUT_SIGN", "2s")
+	flagTimeoutAdaptive            bool   = common.GetENVValue("SEBAK_TIMEOUT_ADAPTIVE", "0") == "1"
+	flagTimeoutMin                 string = common.GetENVValue("SEBAK_TIMEOUT_MIN", common.DefaultTimeoutMin.String())
+	flagTimeoutMax                 string = common.GetENVValue("SEBAK_TIMEOUT_MAX", common.DefaultTimeoutMax.String())
 	flagTLSCertFile                string = common.GetENVValue("SEBAK_TLS_CERT", "sebak.crt")
 	flagTLSKeyFile                 string = common.GetENVValue("SEBAK_TLS_KEY", "sebak.key")
 	flagUnfreezingPeriod           string = common.GetENVValue("SEBAK_UNFREEZING_PERIOD", strconv.FormatUint(common.UnfreezingPeriod, 10))
@@ -65,6 +75,13 @@ var (
 	flagVerbose                    bool   = common.GetENVValue("SEBAK_VERBOSE", "0") == "1"
 	flagCongressAddress            string = common.GetENVValue("SEBAK_CONGRESS_ADDR", "")
 	flagJSONRPCBindURL             string = common.GetENVValue("SEBAK_JSONRPC_BIND", common.DefaultJSONRPCBindURL)
+	flagAdminBindURL               string = common.GetENVValue("SEBAK_ADMIN_BIND", "")
+	flagAdminToken                 string = common.GetENVValue("SEBAK_ADMIN_TOKEN", "")
+	flagAdminTLSClientCA           string = common.GetENVValue("SEBAK_ADMIN_TLS_CLIENT_CA", "")
+	flagAdminAuditLog              string = common.GetENVValue("SEBAK_ADMIN_AUDIT_LOG", "")
+
+	flagPeerBanThreshold string = common.GetENVValue("SEBAK_PEER_BAN_THRESHOLD", strconv.Itoa(common.DefaultPeerBanThreshold))
+	flagPeerBanDuration  string = common.GetENVValue("SEBAK_PEER_BAN_DURATION", common.DefaultPeerBanDuration.String())
 
 	flagRateLimitAPI        cmdcommon.ListFlags // "SEBAK_RATE_LIMIT_API"
 	flagRateLimitNode       cmdcommon.ListFlags // "SEBAK_RATE_LIMIT_NODE"
@@ -79,6 +96,12 @@ var (
 	flagOperationsInBallotLimit string = common.GetENVValue("SEBAK_OPERATIONS_IN_BALLOT_LIMIT", strconv.Itoa(common.DefaultOperationsInBallotLimit))
 	flagTxPoolLimit             string = common.GetENVValue("SEBAK_TX_POOL_LIMIT", strconv.Itoa(common.DefaultTxPoolLimit))
 
+	flagFastSync         bool   = common.GetENVValue("SEBAK_FAST_SYNC", "0") == "1"
+	flagSnapshotInterval string = common.GetENVValue("SEBAK_SNAPSHOT_INTERVAL", strconv.FormatUint(common.DefaultSnapshotInterval, 10))
+	flagPruneKeepBlocks  string = common.GetENVValue("SEBAK_PRUNE_KEEP_BLOCKS", "0")
+	flagCheckpoints      string = common.GetENVValue("SEBAK_CHECKPOINTS", "")
+	flagTrustCheckpoints bool   = common.GetENVValue("SEBAK_TRUST_CHECKPOINTS", "0") == "1"
+
 	flagWatcherMode   bool   = common.GetENVValue("SEBAK_WATCHER_MODE", "0") == "1"
 	flagWatchInterval string = common.GetENVValue("SEBAK_WATCH_INTERVAL", "5s")
 
@@ -97,16 +120,25 @@ var (
 	publishEndpoint         *common.Endpoint
 	rateLimitRuleAPI        common.RateLimitRule
 	rateLimitRuleNode       common.RateLimitRule
+	peerBanThreshold        uint64
+	peerBanDuration         time.Duration
+	snapshotInterval        uint64
+	pruneKeepBlocks         uint64
+	checkpoints             common.Checkpoints
 	storageConfig           *storage.Config
 	syncCheckInterval       time.Duration
 	syncFetchTimeout        time.Duration
 	syncPoolSize            uint64
+	syncBatchSize           uint64
+	syncFetchAhead          uint64
 	syncRetryInterval       time.Duration
 	threshold               int
 	timeoutACCEPT           time.Duration
 	timeoutALLCONFIRM       time.Duration
 	timeoutINIT             time.Duration
 	timeoutSIGN             time.Duration
+	timeoutMin              time.Duration
+	timeoutMax              time.Duration
 	validators              []*node.Validator
 	httpCacheAdapter        string
 	httpCachePoolSize       int
@@ -118,6 +150,8 @@ var (
 	txPoolNodeLimit         uint64
 	syncCheckPrevBlock      time.Duration
 	jsonrpcbindEndpoint     *common.Endpoint
+	adminBindEndpoint       *common.Endpoint
+	logLevelHandler         *common.LevelHandler
 	watchInterval           time.Duration
 	discoveryEndpoints      []*common.Endpoint
 
@@ -127,12 +161,13 @@ var (
 
 func init() {
 	var err error
-	var flagGenesis string
 
 	nodeCmd = &cobra.Command{
 		Use:   "node",
 		Short: "Run sebak node",
 		Run: func(c *cobra.Command, args []string) {
+			parseFlagConfig(c)
+
 			// If `--genesis` was provided, perfom `sebak genesis` before starting the node
 			// This allows one-step startup from scratch, quite useful for testing
 			if len(flagGenesis) > 0 {
@@ -169,63 +204,82 @@ func init() {
 
 	flagStorageConfigString = common.GetENVValue("SEBAK_STORAGE", cmdcommon.GetDefaultStoragePath(nodeCmd))
 
-	nodeCmd.Flags().StringVar(&flagGenesis, "genesis", flagGenesis, "performs the 'genesis' command before running node. Syntax: key[,balance]")
-	nodeCmd.Flags().StringVar(&flagKPSecretSeed, "secret-seed", flagKPSecretSeed, "secret seed of this node")
-	nodeCmd.Flags().StringVar(&flagNetworkID, "network-id", flagNetworkID, "network id")
-	nodeCmd.Flags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "log level, {crit, error, warn, info, debug}")
-	nodeCmd.Flags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
-	nodeCmd.Flags().StringVar(&flagLog, "log", flagLog, "set log file")
-	nodeCmd.Flags().StringVar(&flagHTTPLog, "http-log", flagHTTPLog, "set log file for HTTP request")
-	nodeCmd.Flags().BoolVar(&flagVerbose, "verbose", flagVerbose, "verbose")
-	nodeCmd.Flags().StringVar(&flagBindURL, "bind", flagBindURL, "bind to listen on")
-	nodeCmd.Flags().StringVar(&flagJSONRPCBindURL, "jsonrpc-bind", flagJSONRPCBindURL, "bind to listen on for jsonrpc")
-	nodeCmd.Flags().StringVar(&flagPublishURL, "publish", flagPublishURL, "endpoint url for other nodes")
-	nodeCmd.Flags().StringVar(&flagStorageConfigString, "storage", flagStorageConfigString, "storage uri")
-	nodeCmd.Flags().StringVar(&flagTLSCertFile, "tls-cert", flagTLSCertFile, "tls certificate file")
-	nodeCmd.Flags().StringVar(&flagTLSKeyFile, "tls-key", flagTLSKeyFile, "tls key file")
-	nodeCmd.Flags().StringVar(&flagValidators, "validators", flagValidators, "set validator: <endpoint url>?address=<public address>[&alias=<alias>] [ <validator>...]")
-	nodeCmd.Flags().StringVar(&flagThreshold, "threshold", flagThreshold, "threshold")
-	nodeCmd.Flags().StringVar(&flagTimeoutINIT, "timeout-init", flagTimeoutINIT, "timeout of the init state")
-	nodeCmd.Flags().StringVar(&flagTimeoutSIGN, "timeout-sign", flagTimeoutSIGN, "timeout of the sign state")
-	nodeCmd.Flags().StringVar(&flagTimeoutACCEPT, "timeout-accept", flagTimeoutACCEPT, "timeout of the accept state")
-	nodeCmd.Flags().StringVar(&flagTimeoutALLCONFIRM, "timeout-allconfirm", flagTimeoutALLCONFIRM, "timeout of the allconfirm state")
-	nodeCmd.Flags().StringVar(&flagBlockTime, "block-time", flagBlockTime, "block creation time")
-	nodeCmd.Flags().StringVar(&flagBlockTimeDelta, "block-time-delta", flagBlockTimeDelta, "variation period of block time")
-	nodeCmd.Flags().StringVar(&flagUnfreezingPeriod, "unfreezing-period", flagUnfreezingPeriod, "how long freezing must last")
-	nodeCmd.Flags().StringVar(&flagOperationsLimit, "operations-limit", flagOperationsLimit, "operations limit in a transaction")
-	nodeCmd.Flags().StringVar(&flagTransactionsLimit, "transactions-limit", flagTransactionsLimit, "transactions limit in a ballot")
-	nodeCmd.Flags().StringVar(&flagOperationsInBallotLimit, "operations-in-ballot-limit", flagOperationsInBallotLimit, "operations limit in a ballot")
-	nodeCmd.Flags().StringVar(&flagTxPoolLimit, "txpool-limit", flagTxPoolLimit, "transaction pool limit: <client-side>[,<node-side>] (0= no limit)")
-	nodeCmd.Flags().Var(
+	nodeCmd.PersistentFlags().StringVar(&flagConfig, "config", flagConfig, "node configuration file; .toml, .yaml or .json. flags and environment variables override it")
+	nodeCmd.PersistentFlags().StringVar(&flagGenesis, "genesis", flagGenesis, "performs the 'genesis' command before running node. Syntax: key[,balance]")
+	nodeCmd.PersistentFlags().StringVar(&flagKPSecretSeed, "secret-seed", flagKPSecretSeed, "secret seed of this node")
+	nodeCmd.PersistentFlags().StringVar(&flagNetworkID, "network-id", flagNetworkID, "network id")
+	nodeCmd.PersistentFlags().StringVar(&flagLogLevel, "log-level", flagLogLevel, "log level, {crit, error, warn, info, debug}")
+	nodeCmd.PersistentFlags().StringVar(&flagLogFormat, "log-format", flagLogFormat, "log format, {terminal, json}")
+	nodeCmd.PersistentFlags().StringVar(&flagLog, "log", flagLog, "set log file")
+	nodeCmd.PersistentFlags().StringVar(&flagHTTPLog, "http-log", flagHTTPLog, "set log file for HTTP request")
+	nodeCmd.PersistentFlags().BoolVar(&flagVerbose, "verbose", flagVerbose, "verbose")
+	nodeCmd.PersistentFlags().StringVar(&flagBindURL, "bind", flagBindURL, "bind to listen on")
+	nodeCmd.PersistentFlags().StringVar(&flagJSONRPCBindURL, "jsonrpc-bind", flagJSONRPCBindURL, "bind to listen on for jsonrpc")
+	nodeCmd.PersistentFlags().StringVar(&flagAdminBindURL, "admin-bind", flagAdminBindURL, fmt.Sprintf("bind to listen on for admin API, like %q; if empty, admin API is disabled", common.DefaultAdminBindURL))
+	nodeCmd.PersistentFlags().StringVar(&flagAdminToken, "admin-token", flagAdminToken, "token of admin API in 'Authorization: Bearer <token>'")
+	nodeCmd.PersistentFlags().StringVar(&flagAdminTLSClientCA, "admin-tls-client-ca", flagAdminTLSClientCA, "CA certificate file to verify the client certificate of admin API over https")
+	nodeCmd.PersistentFlags().StringVar(&flagAdminAuditLog, "admin-audit-log", flagAdminAuditLog, "set audit log file of admin API; by default, it is in the log")
+	nodeCmd.PersistentFlags().StringVar(&flagPublishURL, "publish", flagPublishURL, "endpoint url for other nodes")
+	nodeCmd.PersistentFlags().StringVar(&flagStorageConfigString, "storage", flagStorageConfigString, "storage uri")
+	nodeCmd.PersistentFlags().StringVar(&flagTLSCertFile, "tls-cert", flagTLSCertFile, "tls certificate file")
+	nodeCmd.PersistentFlags().StringVar(&flagTLSKeyFile, "tls-key", flagTLSKeyFile, "tls key file")
+	nodeCmd.PersistentFlags().StringVar(&flagValidators, "validators", flagValidators, "set validator: <endpoint url>?address=<public address>[&alias=<alias>] [ <validator>...]")
+	nodeCmd.PersistentFlags().StringVar(&flagThreshold, "threshold", flagThreshold, "threshold")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeoutINIT, "timeout-init", flagTimeoutINIT, "timeout of the init state")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeoutSIGN, "timeout-sign", flagTimeoutSIGN, "timeout of the sign state")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeoutACCEPT, "timeout-accept", flagTimeoutACCEPT, "timeout of the accept state")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeoutALLCONFIRM, "timeout-allconfirm", flagTimeoutALLCONFIRM, "timeout of the allconfirm state")
+	nodeCmd.PersistentFlags().BoolVar(&flagTimeoutAdaptive, "timeout-adaptive", flagTimeoutAdaptive, "adapt the timeouts of init, sign and accept state to the network latency")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeoutMin, "timeout-min", flagTimeoutMin, "minimum of the adaptive timeouts")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeoutMax, "timeout-max", flagTimeoutMax, "maximum of the adaptive timeouts")
+	nodeCmd.PersistentFlags().StringVar(&flagBlockTime, "block-time", flagBlockTime, "block creation time")
+	nodeCmd.PersistentFlags().StringVar(&flagBlockTimeDelta, "block-time-delta", flagBlockTimeDelta, "variation period of block time")
+	nodeCmd.PersistentFlags().StringVar(&flagUnfreezingPeriod, "unfreezing-period", flagUnfreezingPeriod, "how long freezing must last")
+	nodeCmd.PersistentFlags().StringVar(&flagOperationsLimit, "operations-limit", flagOperationsLimit, "operations limit in a transaction")
+	nodeCmd.PersistentFlags().StringVar(&flagTransactionsLimit, "transactions-limit", flagTransactionsLimit, "transactions limit in a ballot")
+	nodeCmd.PersistentFlags().StringVar(&flagOperationsInBallotLimit, "operations-in-ballot-limit", flagOperationsInBallotLimit, "operations limit in a ballot")
+	nodeCmd.PersistentFlags().StringVar(&flagTxPoolLimit, "txpool-limit", flagTxPoolLimit, "transaction pool limit: <client-side>[,<node-side>] (0= no limit)")
+	nodeCmd.PersistentFlags().Var(
 		&flagRateLimitAPI,
 		"rate-limit-api",
 		fmt.Sprintf("rate limit for %s: [<ip>=]<limit>-<period>, ex) '10-S' '3.3.3.3=1000-M'", network.UrlPathPrefixAPI),
 	)
-	nodeCmd.Flags().Var(
+	nodeCmd.PersistentFlags().Var(
 		&flagRateLimitNode,
 		"rate-limit-node",
 		fmt.Sprintf("rate limit for %s: [<ip>=]<limit>-<period>, ex) '10-S' '3.3.3.3=1000-M'", network.UrlPathPrefixNode),
 	)
-
-	nodeCmd.Flags().BoolVar(&flagDebugPProf, "debug-pprof", flagDebugPProf, "set debug pprof")
-
-	nodeCmd.Flags().StringVar(&flagSyncPoolSize, "sync-pool-size", flagSyncPoolSize, "sync pool size")
-	nodeCmd.Flags().StringVar(&flagSyncFetchTimeout, "sync-fetch-timeout", flagSyncFetchTimeout, "sync fetch timeout")
-	nodeCmd.Flags().StringVar(&flagSyncRetryInterval, "sync-retry-interval", flagSyncRetryInterval, "sync retry interval")
-	nodeCmd.Flags().StringVar(&flagSyncCheckInterval, "sync-check-interval", flagSyncCheckInterval, "sync check interval")
-	nodeCmd.Flags().StringVar(&flagSyncCheckPrevBlockInterval, "sync-check-prevblock", flagSyncCheckPrevBlockInterval, "sync check interval for previous block")
-
-	nodeCmd.Flags().StringVar(&flagHTTPCacheAdapter, "http-cache-adapter", flagHTTPCacheAdapter, "http cache adapter: ex) 'mem'")
-	nodeCmd.Flags().StringVar(&flagHTTPCachePoolSize, "http-cache-pool-size", flagHTTPCachePoolSize, "http cache pool size")
-	nodeCmd.Flags().StringVar(&flagHTTPCacheRedisAddrs, "http-cache-redis-addrs", flagHTTPCacheRedisAddrs, "http cache redis address")
-
-	nodeCmd.Flags().StringVar(&flagCongressAddress, "set-congress-address", flagCongressAddress, "set congress address")
-	nodeCmd.Flags().BoolVar(&flagWatcherMode, "watcher-mode", flagWatcherMode, "watcher mode")
-	nodeCmd.Flags().StringVar(&flagWatchInterval, "watch-interval", flagWatchInterval, "watch interval")
-	nodeCmd.Flags().Var(&flagDiscovery, "discovery", "initial endpoint for discovery")
-	nodeCmd.Flags().StringVar(&flagNTPServer, "ntp", flagNTPServer, "ntp server for time sync")
-	nodeCmd.Flags().StringVar(&flagTimeSyncCommand, "time-sync-command", flagTimeSyncCommand, "command for syncing local time")
-
+	nodeCmd.PersistentFlags().StringVar(&flagPeerBanThreshold, "peer-ban-threshold", flagPeerBanThreshold, "misbehavior score of peer to be banned (0= no ban)")
+	nodeCmd.PersistentFlags().StringVar(&flagPeerBanDuration, "peer-ban-duration", flagPeerBanDuration, "how long the misbehaving peer is banned")
+
+	nodeCmd.PersistentFlags().BoolVar(&flagDebugPProf, "debug-pprof", flagDebugPProf, "set debug pprof")
+
+	nodeCmd.PersistentFlags().StringVar(&flagSyncPoolSize, "sync-pool-size", flagSyncPoolSize, "sync pool size")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncBatchSize, "sync-batch-size", flagSyncBatchSize, "number of blocks fetched at once in sync; 1 fetches block one by one")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncFetchAhead, "sync-fetch-ahead", flagSyncFetchAhead, "number of batches fetched ahead of validation in sync")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncSource, "sync-source", flagSyncSource, "directory or HTTP mirror of block archives to sync from, instead of the other nodes")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncFetchTimeout, "sync-fetch-timeout", flagSyncFetchTimeout, "sync fetch timeout")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncRetryInterval, "sync-retry-interval", flagSyncRetryInterval, "sync retry interval")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncCheckInterval, "sync-check-interval", flagSyncCheckInterval, "sync check interval")
+	nodeCmd.PersistentFlags().StringVar(&flagSyncCheckPrevBlockInterval, "sync-check-prevblock", flagSyncCheckPrevBlockInterval, "sync check interval for previous block")
+	nodeCmd.PersistentFlags().BoolVar(&flagFastSync, "fast-sync", flagFastSync, "restore the state snapshot attested by validators before syncing blocks")
+	nodeCmd.PersistentFlags().StringVar(&flagSnapshotInterval, "snapshot-interval", flagSnapshotInterval, "number of blocks between the state snapshots for fast sync (0= no snapshot)")
+	nodeCmd.PersistentFlags().StringVar(&flagPruneKeepBlocks, "prune-keep-blocks", flagPruneKeepBlocks, "number of latest blocks, whose transactions and operations are kept (0= no pruning)")
+	nodeCmd.PersistentFlags().StringVar(&flagCheckpoints, "checkpoints", flagCheckpoints, "trusted block hashes; '<height>:<hash>,<height>:<hash>'")
+	nodeCmd.PersistentFlags().BoolVar(&flagTrustCheckpoints, "trust-checkpoints", flagTrustCheckpoints, "do not validate the transactions of the blocks until the last checkpoint in sync")
+
+	nodeCmd.PersistentFlags().StringVar(&flagHTTPCacheAdapter, "http-cache-adapter", flagHTTPCacheAdapter, "http cache adapter: ex) 'mem'")
+	nodeCmd.PersistentFlags().StringVar(&flagHTTPCachePoolSize, "http-cache-pool-size", flagHTTPCachePoolSize, "http cache pool size")
+	nodeCmd.PersistentFlags().StringVar(&flagHTTPCacheRedisAddrs, "http-cache-redis-addrs", flagHTTPCacheRedisAddrs, "http cache redis address")
+
+	nodeCmd.PersistentFlags().StringVar(&flagCongressAddress, "set-congress-address", flagCongressAddress, "set congress address")
+	nodeCmd.PersistentFlags().BoolVar(&flagWatcherMode, "watcher-mode", flagWatcherMode, "watcher mode")
+	nodeCmd.PersistentFlags().StringVar(&flagWatchInterval, "watch-interval", flagWatchInterval, "watch interval")
+	nodeCmd.PersistentFlags().Var(&flagDiscovery, "discovery", "initial endpoint for discovery")
+	nodeCmd.PersistentFlags().StringVar(&flagNTPServer, "ntp", flagNTPServer, "ntp server for time sync")
+	nodeCmd.PersistentFlags().StringVar(&flagTimeSyncCommand, "time-sync-command", flagTimeSyncCommand, "command for syncing local time")
+
+	nodeCmd.AddCommand(nodeConfigCmd)
 	rootCmd.AddCommand(nodeCmd)
 }
 
@@ -412,6 +466,41 @@ func parseFlagsNode() {
 		jsonrpcbindEndpoint.RawQuery = queries.Encode()
 	}
 
+	if len(flagAdminBindURL) > 0 { // admin
+		if p, err := common.ParseEndpoint(flagAdminBindURL); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--admin-bind", err)
+		} else {
+			adminBindEndpoint = p
+			flagAdminBindURL = adminBindEndpoint.String()
+		}
+
+		queries := adminBindEndpoint.Query()
+		if strings.ToLower(adminBindEndpoint.Scheme) == "https" {
+			if _, err = os.Stat(flagTLSCertFile); os.IsNotExist(err) {
+				cmdcommon.PrintFlagsError(nodeCmd, "--tls-cert", err)
+			}
+			if _, err = os.Stat(flagTLSKeyFile); os.IsNotExist(err) {
+				cmdcommon.PrintFlagsError(nodeCmd, "--tls-key", err)
+			}
+			queries.Add("TLSCertFile", flagTLSCertFile)
+			queries.Add("TLSKeyFile", flagTLSKeyFile)
+
+			if len(flagAdminTLSClientCA) > 0 {
+				if _, err = os.Stat(flagAdminTLSClientCA); os.IsNotExist(err) {
+					cmdcommon.PrintFlagsError(nodeCmd, "--admin-tls-client-ca", err)
+				}
+				queries.Add("TLSClientCAFile", flagAdminTLSClientCA)
+			}
+		} else if len(flagAdminTLSClientCA) > 0 {
+			cmdcommon.PrintFlagsError(nodeCmd, "--admin-tls-client-ca", fmt.Errorf("admin API should be over https"))
+		}
+		adminBindEndpoint.RawQuery = queries.Encode()
+
+		if err = runner.CheckAdminEndpoint(adminBindEndpoint, flagAdminToken); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--admin-bind", err)
+		}
+	}
+
 	if validators, err = parseFlagValidators(flagValidators); err != nil {
 		cmdcommon.PrintFlagsError(nodeCmd, "--validators", err)
 	}
@@ -424,6 +513,11 @@ func parseFlagsNode() {
 	timeoutSIGN = getTimeDuration(flagTimeoutSIGN, common.DefaultTimeoutSIGN, "--timeout-sign")
 	timeoutACCEPT = getTimeDuration(flagTimeoutACCEPT, common.DefaultTimeoutACCEPT, "--timeout-accept")
 	timeoutALLCONFIRM = getTimeDuration(flagTimeoutALLCONFIRM, common.DefaultTimeoutALLCONFIRM, "--timeout-allconfirm")
+	timeoutMin = getTimeDuration(flagTimeoutMin, common.DefaultTimeoutMin, "--timeout-min")
+	timeoutMax = getTimeDuration(flagTimeoutMax, common.DefaultTimeoutMax, "--timeout-max")
+	if timeoutMin > timeoutMax {
+		cmdcommon.PrintFlagsError(nodeCmd, "--timeout-min", errors.New("must not be greater than --timeout-max"))
+	}
 	blockTime = getTimeDuration(flagBlockTime, common.DefaultBlockTime, "--block-time")
 	blockTimeDelta = getTimeDuration(flagBlockTimeDelta, common.DefaultBlockTimeDelta, "--block-time-delta")
 
@@ -477,6 +571,22 @@ func parseFlagsNode() {
 		cmdcommon.PrintFlagsError(nodeCmd, "--sync-pool-size", err)
 	}
 
+	if syncBatchSize, err = strconv.ParseUint(flagSyncBatchSize, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--sync-batch-size", err)
+	}
+
+	if syncFetchAhead, err = strconv.ParseUint(flagSyncFetchAhead, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--sync-fetch-ahead", err)
+	} else if syncFetchAhead < 1 {
+		cmdcommon.PrintFlagsError(nodeCmd, "--sync-fetch-ahead", errors.New("must be greater than 0"))
+	}
+
+	if len(flagSyncSource) > 0 {
+		if _, err = sync.NewArchiveSource(flagSyncSource, nil); err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--sync-source", err)
+		}
+	}
+
 	syncRetryInterval = getTimeDuration(flagSyncRetryInterval, sync.RetryInterval, "--sync-retry-interval")
 	syncFetchTimeout = getTimeDuration(flagSyncFetchTimeout, sync.FetchTimeout, "--sync-fetch-timeout")
 	syncCheckInterval = getTimeDuration(flagSyncCheckInterval, sync.CheckBlockHeightInterval, "--sync-check-interval")
@@ -533,14 +643,28 @@ func parseFlagsNode() {
 	}
 
 	logHandler = logging.CallerFileHandler(logHandler)
-	logHandler = logging.LvlFilterHandler(logLevel, logHandler)
+
+	// the audit log of admin API is not filtered by the log level
+	if len(flagAdminAuditLog) < 1 {
+		runner.SetAuditLogging(logHandler)
+	} else {
+		auditLogHandler, err := logging.FileHandler(flagAdminAuditLog, common.JsonFormatEx(false, true))
+		if err != nil {
+			cmdcommon.PrintFlagsError(nodeCmd, "--admin-audit-log", err)
+		}
+		runner.SetAuditLogging(auditLogHandler)
+	}
+
+	// the log level can be changed by admin API
+	logLevelHandler = common.NewLevelHandler(logLevel, logHandler)
+	logHandler = logLevelHandler
 	log.SetHandler(logHandler)
 
-	common.SetLogging(logLevel, logHandler)
-	runner.SetLogging(logLevel, logHandler)
-	consensus.SetLogging(logLevel, logHandler)
-	network.SetLogging(logLevel, logHandler)
-	sync.SetLogging(logLevel, logHandler)
+	common.SetLogging(logging.LvlDebug, logHandler)
+	runner.SetLogging(logging.LvlDebug, logHandler)
+	consensus.SetLogging(logging.LvlDebug, logHandler)
+	network.SetLogging(logging.LvlDebug, logHandler)
+	sync.SetLogging(logging.LvlDebug, logHandler)
 
 	// if without http-log, http log messages will be in `network.log`
 	if len(flagHTTPLog) < 1 {
@@ -604,6 +728,23 @@ func parseFlagsNode() {
 		cmdcommon.PrintFlagsError(nodeCmd, "--rate-limit-node", err)
 	}
 
+	if peerBanThreshold, err = strconv.ParseUint(flagPeerBanThreshold, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--peer-ban-threshold", err)
+	}
+	peerBanDuration = getTimeDuration(flagPeerBanDuration, common.DefaultPeerBanDuration, "--peer-ban-duration")
+
+	if snapshotInterval, err = strconv.ParseUint(flagSnapshotInterval, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--snapshot-interval", err)
+	}
+
+	if pruneKeepBlocks, err = strconv.ParseUint(flagPruneKeepBlocks, 10, 64); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--prune-keep-blocks", err)
+	}
+
+	if checkpoints, err = common.ParseCheckpoints(flagCheckpoints); err != nil {
+		cmdcommon.PrintFlagsError(nodeCmd, "--checkpoints", err)
+	}
+
 	{ // time sync
 		if len(flagNTPServer) < 1 {
 			cmdcommon.PrintFlagsError(nodeCmd, "--ntp", errors.New("must be given"))
@@ -634,6 +775,8 @@ func parseFlagsNode() {
 	parsedFlags = append(parsedFlags, "\n\tnetwork-id", flagNetworkID)
 	parsedFlags = append(parsedFlags, "\n\tbind", flagBindURL)
 	parsedFlags = append(parsedFlags, "\n\tjsonrpc-bind", flagJSONRPCBindURL)
+	parsedFlags = append(parsedFlags, "\n\tadmin-bind", flagAdminBindURL)
+	parsedFlags = append(parsedFlags, "\n\tadmin-audit-log", flagAdminAuditLog)
 	parsedFlags = append(parsedFlags, "\n\tpublish", flagPublishURL)
 	parsedFlags = append(parsedFlags, "\n\tstorage", flagStorageConfigString)
 	parsedFlags = append(parsedFlags, "\n\ttls-cert", flagTLSCertFile)
@@ -646,6 +789,9 @@ func parseFlagsNode() {
 	parsedFlags = append(parsedFlags, "\n\ttimeout-sign", flagTimeoutSIGN)
 	parsedFlags = append(parsedFlags, "\n\ttimeout-accept", flagTimeoutACCEPT)
 	parsedFlags = append(parsedFlags, "\n\ttimeout-allconfirm", flagTimeoutALLCONFIRM)
+	parsedFlags = append(parsedFlags, "\n\ttimeout-adaptive", flagTimeoutAdaptive)
+	parsedFlags = append(parsedFlags, "\n\ttimeout-min", flagTimeoutMin)
+	parsedFlags = append(parsedFlags, "\n\ttimeout-max", flagTimeoutMax)
 	parsedFlags = append(parsedFlags, "\n\tblock-time", flagBlockTime)
 	parsedFlags = append(parsedFlags, "\n\tblock-time-delta", flagBlockTimeDelta)
 	parsedFlags = append(parsedFlags, "\n\ttransactions-limit", flagTransactionsLimit)
@@ -654,9 +800,16 @@ func parseFlagsNode() {
 	parsedFlags = append(parsedFlags, "\n\ttxpool-limit", flagTxPoolLimit)
 	parsedFlags = append(parsedFlags, "\n\trate-limit-api", rateLimitRuleAPI)
 	parsedFlags = append(parsedFlags, "\n\trate-limit-node", rateLimitRuleNode)
+	parsedFlags = append(parsedFlags, "\n\tpeer-ban-threshold", flagPeerBanThreshold)
+	parsedFlags = append(parsedFlags, "\n\tpeer-ban-duration", flagPeerBanDuration)
 	parsedFlags = append(parsedFlags, "\n\thttp-cache-adapter", httpCacheAdapter)
 	parsedFlags = append(parsedFlags, "\n\thttp-cache-pool-size", httpCachePoolSize)
 	parsedFlags = append(parsedFlags, "\n\tdiscovery", discoveryEndpoints)
+	parsedFlags = append(parsedFlags, "\n\tfast-sync", flagFastSync)
+	parsedFlags = append(parsedFlags, "\n\tsnapshot-interval", flagSnapshotInterval)
+	parsedFlags = append(parsedFlags, "\n\tprune-keep-blocks", flagPruneKeepBlocks)
+	parsedFlags = append(parsedFlags, "\n\tcheckpoints", checkpoints)
+	parsedFlags = append(parsedFlags, "\n\ttrust-checkpoints", flagTrustCheckpoints)
 	parsedFlags = append(parsedFlags, "\n\twatcher-mode", flagWatcherMode)
 	parsedFlags = append(parsedFlags, "\n\tntp", flagNTPServer)
 	parsedFlags = append(parsedFlags, "\n\ttime-sync-command", flagTimeSyncCommand)
@@ -762,11 +915,26 @@ func runNode() error {
 	log.Debug("initial balance found", "amount", initialBalance)
 	initialBalance.Invariant()
 
+	// the checkpoints of genesis and `--checkpoints`
+	if storedCheckpoints, err := block.GetCheckpoints(st); err != nil {
+		return err
+	} else if checkpoints, err = storedCheckpoints.Merge(checkpoints); err != nil {
+		log.Crit("checkpoints do not match with genesis", "error", err)
+		return err
+	}
+	if err = block.CheckCheckpoints(st, checkpoints); err != nil {
+		log.Crit("stored blocks do not match with checkpoints", "error", err)
+		return err
+	}
+
 	conf := common.Config{
 		TimeoutINIT:            timeoutINIT,
 		TimeoutSIGN:            timeoutSIGN,
 		TimeoutACCEPT:          timeoutACCEPT,
 		TimeoutALLCONFIRM:      timeoutALLCONFIRM,
+		TimeoutAdaptive:        flagTimeoutAdaptive,
+		TimeoutMin:             timeoutMin,
+		TimeoutMax:             timeoutMax,
 		NetworkID:              []byte(flagNetworkID),
 		InitialBalance:         initialBalance,
 		BlockTime:              blockTime,
@@ -776,6 +944,12 @@ func runNode() error {
 		OpsInBallotLimit:       int(operationsInBallotLimit),
 		RateLimitRuleAPI:       rateLimitRuleAPI,
 		RateLimitRuleNode:      rateLimitRuleNode,
+		PeerBanThreshold:       int(peerBanThreshold),
+		PeerBanDuration:        peerBanDuration,
+		SnapshotInterval:       snapshotInterval,
+		PruneKeepBlocks:        pruneKeepBlocks,
+		Checkpoints:            checkpoints,
+		TrustCheckpoints:       flagTrustCheckpoints,
 		HTTPCacheAdapter:       httpCacheAdapter,
 		HTTPCachePoolSize:      httpCachePoolSize,
 		HTTPCacheRedisAddrs:    httpCacheRedisAddrs,
@@ -783,6 +957,8 @@ func runNode() error {
 		TxPoolClientLimit:      int(txPoolClientLimit),
 		TxPoolNodeLimit:        int(txPoolNodeLimit),
 		JSONRPCEndpoint:        jsonrpcbindEndpoint,
+		AdminEndpoint:          adminBindEndpoint,
+		AdminToken:             flagAdminToken,
 		WatcherMode:            flagWatcherMode,
 		DiscoveryEndpoints:     discoveryEndpoints,
 	}
@@ -796,13 +972,20 @@ func runNode() error {
 	}
 	//Place setting config
 	c.SyncPoolSize = syncPoolSize
+	c.SyncBatchSize = syncBatchSize
+	c.SyncFetchAhead = int(syncFetchAhead)
 	c.FetchTimeout = syncFetchTimeout
 	c.RetryInterval = syncRetryInterval
 	c.CheckBlockHeightInterval = syncCheckInterval
 	c.CheckPrevBlockInterval = syncCheckPrevBlock
 	c.WatchInterval = watchInterval
+	c.Source = flagSyncSource
 
-	syncer := c.NewSyncer()
+	syncer, err := c.NewSyncer()
+	if err != nil {
+		log.Crit("failed to launch syncer", "error", err)
+		return err
+	}
 
 	isaac, err := consensus.NewISAAC(localNode, policy, connectionManager, st, conf, syncer)
 	if err != nil {
@@ -819,6 +1002,11 @@ func runNode() error {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
 			return err
 		}
+		nr.SetSyncStatusFunc(syncer.SyncStatus)
+		c.SetFinishBlockFunc(func(block.Block) {
+			nr.Snapshots().Check()
+		})
+		nr.SetLogLevelHandler(logLevelHandler)
 
 		g.Add(func() error {
 			if err := nr.Start(); err != nil {
@@ -829,6 +1017,27 @@ func runNode() error {
 		}, func(error) {
 			nr.Stop()
 		})
+
+		// the shutdown by admin API stops every actor like the signal
+		shutdown := make(chan struct{}, 1)
+		nr.SetShutdownFunc(func() {
+			select {
+			case shutdown <- struct{}{}:
+			default:
+			}
+		})
+
+		cancel := make(chan struct{})
+		g.Add(func() error {
+			select {
+			case <-shutdown:
+				log.Info("shutting down by admin API")
+			case <-cancel:
+			}
+			return nil
+		}, func(error) {
+			close(cancel)
+		})
 	}
 	{
 		g.Add(func() error {
@@ -905,19 +1114,31 @@ func runNode() error {
 			}
 			localNode.AddValidators(validator)
 		}
-		if len(localNode.GetValidators()) < 1 {
+		// with `--sync-source`, the watcher does not need the remote nodes
+		if len(localNode.GetValidators()) < 1 && len(flagSyncSource) < 1 {
 			err = fmt.Errorf("remote nodes not found")
 			log.Crit(err.Error())
 			return err
 		}
 
-		watcher := c.NewWatcher(syncer)
+		watcher, err := c.NewWatcher(syncer)
+		if err != nil {
+			log.Crit("failed to launch watcher", "error", err)
+			return err
+		}
 		g.Add(func() error {
 			return watcher.Start()
 		}, func(error) {
 			watcher.Stop()
 		})
 	}
+	if flagFastSync {
+		// the blocks after the snapshot will be synced by syncer
+		if _, err = c.NewSnapshotFetcher(threshold).Fetch(context.Background()); err != nil {
+			log.Crit("failed to fast sync", "error", err)
+			return err
+		}
+	}
 	{
 		cancel := make(chan struct{})
 		g.Add(func() error {
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/spf13/cobra"
+
+	cmdcommon "boscoin.io/sebak/cmd/sebak/common"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/node/runner/simulation"
+)
+
+var (
+	simulateCmd *cobra.Command
+
+	simulateConf          = simulation.NewConfig()
+	flagSimulateFaults    []string
+	flagSimulateByzantine []string
+	flagSimulateJSON      bool
+	flagSimulateLogLevel  = "crit"
+)
+
+func init() {
+	simulateCmd = &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate the consensus of nodes in a process with virtual clock",
+		Args:  cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			runSimulate(c)
+		},
+	}
+
+	conf := &simulateConf
+	simulateCmd.Flags().IntVar(&conf.Nodes, "nodes", conf.Nodes, "number of nodes")
+	simulateCmd.Flags().IntVar(&conf.Threshold, "threshold", conf.Threshold, "voting threshold in percent")
+	simulateCmd.Flags().DurationVar(&conf.Duration, "duration", conf.Duration, "simulated time")
+	simulateCmd.Flags().DurationVar(&conf.Latency, "latency", conf.Latency, "latency of messages between nodes")
+	simulateCmd.Flags().DurationVar(&conf.Jitter, "jitter", conf.Jitter, "random additional latency of messages")
+	simulateCmd.Flags().Int64Var(&conf.Seed, "seed", conf.Seed, "seed of keypairs, latencies and workload")
+	simulateCmd.Flags().StringArrayVar(&flagSimulateFaults, "fault", nil, "fault, '<kind>[:<argument>][@<start>[-<end>]]'; kind is {crash, partition, drop, delay}, for example, 'crash:1@10s-40s', 'partition:0,1@1m', 'drop:0.1', 'delay:500ms'")
+	simulateCmd.Flags().StringArrayVar(&flagSimulateByzantine, "byzantine", nil, "byzantine node, '<behavior>:<node>'; behavior is {unknown-transactions, yes-to-invalid, wrong-proposed-time}, for example, 'yes-to-invalid:1'")
+	simulateCmd.Flags().IntVar(&conf.Workload.Accounts, "accounts", conf.Workload.Accounts, "number of accounts created by workload")
+	simulateCmd.Flags().DurationVar(&conf.Workload.Interval, "tx-interval", conf.Workload.Interval, "interval of submitting transactions; 0 disables workload")
+	simulateCmd.Flags().IntVar(&conf.Workload.Size, "tx-size", conf.Workload.Size, "number of transactions submitted in every interval")
+
+	simulateCmd.Flags().DurationVar(&conf.Node.BlockTime, "block-time", conf.Node.BlockTime, "block creation time")
+	simulateCmd.Flags().DurationVar(&conf.Node.TimeoutINIT, "timeout-init", conf.Node.TimeoutINIT, "timeout of the init state")
+	simulateCmd.Flags().DurationVar(&conf.Node.TimeoutSIGN, "timeout-sign", conf.Node.TimeoutSIGN, "timeout of the sign state")
+	simulateCmd.Flags().DurationVar(&conf.Node.TimeoutACCEPT, "timeout-accept", conf.Node.TimeoutACCEPT, "timeout of the accept state")
+	simulateCmd.Flags().DurationVar(&conf.Node.TimeoutALLCONFIRM, "timeout-allconfirm", conf.Node.TimeoutALLCONFIRM, "timeout of the allconfirm state")
+	simulateCmd.Flags().BoolVar(&conf.Node.TimeoutAdaptive, "timeout-adaptive", conf.Node.TimeoutAdaptive, "adapt the timeouts to the observed ballot latency")
+	simulateCmd.Flags().DurationVar(&conf.Node.TimeoutMin, "timeout-min", conf.Node.TimeoutMin, "lower bound of adaptive timeouts")
+	simulateCmd.Flags().DurationVar(&conf.Node.TimeoutMax, "timeout-max", conf.Node.TimeoutMax, "upper bound of adaptive timeouts")
+
+	simulateCmd.Flags().BoolVar(&flagSimulateJSON, "json", flagSimulateJSON, "print report in json")
+	simulateCmd.Flags().StringVar(&flagSimulateLogLevel, "log-level", flagSimulateLogLevel, "log level of nodes, {crit, error, warn, info, debug}")
+
+	rootCmd.AddCommand(simulateCmd)
+}
+
+func parseSimulateFlags(c *cobra.Command) (conf simulation.Config) {
+	conf = simulateConf
+	conf.Faults = nil
+	for _, s := range flagSimulateFaults {
+		f, err := simulation.ParseFault(s)
+		if err != nil {
+			cmdcommon.PrintFlagsError(c, "--fault", err)
+		}
+		conf.Faults = append(conf.Faults, f)
+	}
+
+	conf.Byzantine = nil
+	for _, s := range flagSimulateByzantine {
+		b, err := simulation.ParseByzantine(s)
+		if err != nil {
+			cmdcommon.PrintFlagsError(c, "--byzantine", err)
+		}
+		conf.Byzantine = append(conf.Byzantine, b)
+	}
+
+	if err := conf.Validate(); err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	logLevel, err := logging.LvlFromString(flagSimulateLogLevel)
+	if err != nil {
+		cmdcommon.PrintFlagsError(c, "--log-level", err)
+	}
+
+	logHandler := logging.StreamHandler(os.Stderr, logging.LogfmtFormat())
+	logHandler = logging.LvlFilterHandler(logLevel, logHandler)
+
+	common.SetLogging(logLevel, logHandler)
+	runner.SetLogging(logLevel, logHandler)
+	consensus.SetLogging(logLevel, logHandler)
+	network.SetLogging(logLevel, logHandler)
+	simulation.SetLogging(logLevel, logHandler)
+
+	return
+}
+
+func runSimulate(c *cobra.Command) {
+	conf := parseSimulateFlags(c)
+
+	s, err := simulation.New(conf)
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	report, err := s.Run()
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+
+	if !flagSimulateJSON {
+		fmt.Print(report.String())
+		return
+	}
+
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		cmdcommon.PrintError(c, err)
+	}
+	fmt.Println(string(b))
+}
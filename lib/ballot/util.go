@@ -13,7 +13,7 @@ func CheckHasCorrectTime(timeStr string) error {
 	if t, err = common.ParseISO8601(timeStr); err != nil {
 		return err
 	}
-	now := time.Now()
+	now := common.Now()
 	timeStart := now.Add(time.Duration(-1) * common.BallotConfirmedTimeAllowDuration)
 	timeEnd := now.Add(common.BallotConfirmedTimeAllowDuration)
 	if t.Before(timeStart) || t.After(timeEnd) {
@@ -0,0 +1,137 @@
+package ballot
+
+import (
+	"encoding/json"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// wireBallot is the RLP wire form of `Ballot`. The hashes and signatures,
+// especially the transaction hashes, which are the most of ballot, are sent
+// as raw bytes instead of base58 string.
+type wireBallot struct {
+	Version           string
+	Hash              []byte
+	Signature         []byte
+	ProposerSignature []byte
+
+	Confirmed string
+	Source    string
+	State     State
+	Vote      voting.Hole
+	Reason    []byte // JSON encoded `errors.Error`, empty if no reason
+
+	ProposerConfirmed   string
+	Proposer            string
+	Round               uint64
+	Height              uint64
+	BlockHash           []byte
+	TotalTxs            uint64
+	TotalOps            uint64
+	Transactions        [][]byte
+	ProposerTransaction common.RLPRawValue
+}
+
+// SerializeRLP encodes the ballot in the compact wire format,
+// `common.ContentTypeRLP`.
+func (b Ballot) SerializeRLP() (encoded []byte, err error) {
+	proposed := b.B.Proposed
+	w := wireBallot{
+		Version:           b.H.Version,
+		Confirmed:         b.B.Confirmed,
+		Source:            b.B.Source,
+		State:             b.B.State,
+		Vote:              b.B.Vote,
+		ProposerConfirmed: proposed.Confirmed,
+		Proposer:          proposed.Proposer,
+		Round:             proposed.VotingBasis.Round,
+		Height:            proposed.VotingBasis.Height,
+		TotalTxs:          proposed.VotingBasis.TotalTxs,
+		TotalOps:          proposed.VotingBasis.TotalOps,
+	}
+
+	if w.Hash, err = common.Base58ToBytes(b.H.Hash); err != nil {
+		return
+	}
+	if w.Signature, err = common.Base58ToBytes(b.H.Signature); err != nil {
+		return
+	}
+	if w.ProposerSignature, err = common.Base58ToBytes(b.H.ProposerSignature); err != nil {
+		return
+	}
+	if w.BlockHash, err = common.Base58ToBytes(proposed.VotingBasis.BlockHash); err != nil {
+		return
+	}
+	if w.Transactions, err = common.Base58SliceToBytes(proposed.Transactions); err != nil {
+		return
+	}
+	if w.ProposerTransaction, err = proposed.ProposerTransaction.SerializeRLP(); err != nil {
+		return
+	}
+	if b.B.Reason != nil {
+		if w.Reason, err = json.Marshal(b.B.Reason); err != nil {
+			return
+		}
+	}
+
+	return common.EncodeToBytes(w)
+}
+
+// NewBallotFromRLP decodes the ballot encoded by `SerializeRLP`.
+func NewBallotFromRLP(data []byte) (b Ballot, err error) {
+	var w wireBallot
+	if err = common.DecodeBytes(data, &w); err != nil {
+		err = errors.InvalidWireEncoding.Clone().SetData("error", err.Error())
+		return
+	}
+
+	var tx transaction.Transaction
+	if tx, err = transaction.NewTransactionFromRLP(w.ProposerTransaction); err != nil {
+		return
+	}
+
+	var reason *errors.Error
+	if len(w.Reason) > 0 {
+		reason = &errors.Error{}
+		if err = json.Unmarshal(w.Reason, reason); err != nil {
+			err = errors.InvalidWireEncoding.Clone().SetData("error", err.Error())
+			return
+		}
+	}
+
+	b = Ballot{
+		H: BallotHeader{
+			Version:           w.Version,
+			Hash:              base58.Encode(w.Hash),
+			Signature:         base58.Encode(w.Signature),
+			ProposerSignature: base58.Encode(w.ProposerSignature),
+		},
+		B: BallotBody{
+			Confirmed: w.Confirmed,
+			Proposed: BallotBodyProposed{
+				Confirmed: w.ProposerConfirmed,
+				Proposer:  w.Proposer,
+				VotingBasis: voting.Basis{
+					Round:     w.Round,
+					Height:    w.Height,
+					BlockHash: base58.Encode(w.BlockHash),
+					TotalTxs:  w.TotalTxs,
+					TotalOps:  w.TotalOps,
+				},
+				Transactions:        common.BytesSliceToBase58(w.Transactions),
+				ProposerTransaction: ProposerTransaction{Transaction: tx},
+			},
+			Source: w.Source,
+			State:  w.State,
+			Vote:   w.Vote,
+			Reason: reason,
+		},
+	}
+
+	return
+}
@@ -0,0 +1,178 @@
+package ballot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func makeWireTestBallot(conf common.Config, numberOfTxs int) (kp *keypair.Full, blt *Ballot) {
+	kp = keypair.Random()
+	commonKP := keypair.Random()
+	localNode := node.NewTestLocalNode(kp, common.MustParseEndpoint("https://localhost:1000"))
+
+	basis := voting.Basis{
+		Round:     0,
+		Height:    10,
+		BlockHash: common.MustMakeObjectHashString("block"),
+		TotalTxs:  10,
+		TotalOps:  10,
+	}
+
+	var txs []transaction.Transaction
+	var txHashes []string
+	for i := 0; i < numberOfTxs; i++ {
+		_, tx := transaction.TestMakeTransaction(conf.NetworkID, 1)
+		txs = append(txs, tx)
+		txHashes = append(txHashes, tx.GetHash())
+	}
+
+	blt = NewBallot(localNode.Address(), localNode.Address(), basis, txHashes)
+
+	opc, _ := NewCollectTxFeeFromBallot(*blt, commonKP.Address(), txs...)
+	opi, _ := NewInflationFromBallot(*blt, commonKP.Address(), common.Amount(1))
+	ptx, _ := NewProposerTransactionFromBallot(*blt, opc, opi)
+	blt.SetProposerTransaction(ptx)
+	blt.Sign(localNode.Keypair(), conf.NetworkID)
+
+	return
+}
+
+func TestBallotRLPWire(t *testing.T) {
+	conf := common.NewTestConfig()
+	_, blt := makeWireTestBallot(conf, 10)
+	require.NoError(t, blt.IsWellFormed(conf))
+
+	encoded, err := blt.SerializeRLP()
+	require.NoError(t, err)
+
+	decoded, err := NewBallotFromRLP(encoded)
+	require.NoError(t, err)
+	require.NoError(t, decoded.IsWellFormed(conf))
+	require.Equal(t, blt.GetHash(), decoded.GetHash())
+	require.Equal(t, blt.GetHash(), decoded.B.MakeHashString())
+	require.Equal(t, blt.Transactions(), decoded.Transactions())
+	require.Equal(t, blt.VotingBasis(), decoded.VotingBasis())
+	require.Equal(t, blt.ProposerTransaction().GetHash(), decoded.ProposerTransaction().GetHash())
+	require.Nil(t, decoded.B.Reason)
+
+	// the JSON of decoded ballot is same with the original
+	jsonEncoded, _ := blt.Serialize()
+	fromJSON, err := NewBallotFromJSON(jsonEncoded)
+	require.NoError(t, err)
+	jsonDecoded, _ := decoded.Serialize()
+	fromDecoded, err := NewBallotFromJSON(jsonDecoded)
+	require.NoError(t, err)
+	require.Equal(t, fromJSON, fromDecoded)
+
+	require.True(t, len(encoded) < len(jsonEncoded))
+}
+
+func TestBallotRLPWireWithReason(t *testing.T) {
+	conf := common.NewTestConfig()
+	kp, blt := makeWireTestBallot(conf, 0)
+
+	blt.SetVote(StateSIGN, voting.NO)
+	blt.SetReason(errors.TransactionNotFound.Clone().SetData("hash", "findme"))
+	blt.Sign(kp, conf.NetworkID)
+
+	encoded, err := blt.SerializeRLP()
+	require.NoError(t, err)
+
+	decoded, err := NewBallotFromRLP(encoded)
+	require.NoError(t, err)
+	require.NoError(t, decoded.VerifySource(conf.NetworkID))
+	require.Equal(t, blt.GetHash(), decoded.B.MakeHashString())
+	require.Equal(t, errors.TransactionNotFound.Code, decoded.B.Reason.Code)
+	require.Equal(t, "findme", decoded.B.Reason.GetData("hash"))
+	require.Equal(t, 0, decoded.TransactionsLength())
+}
+
+func TestBallotRLPWireInvalid(t *testing.T) {
+	conf := common.NewTestConfig()
+	_, blt := makeWireTestBallot(conf, 1)
+
+	{ // not base58 hash can not be sent in RLP
+		b := *blt
+		b.H.Hash = "0OIl"
+		_, err := b.SerializeRLP()
+		require.Equal(t, errors.InvalidWireEncoding, err)
+	}
+
+	{ // broken data
+		encoded, err := blt.SerializeRLP()
+		require.NoError(t, err)
+
+		_, err = NewBallotFromRLP(encoded[:len(encoded)-10])
+		require.Error(t, err)
+		require.Equal(t, errors.InvalidWireEncoding.Code, err.(*errors.Error).Code)
+	}
+}
+
+// BenchmarkBallotWireSize reports the size of ballot in JSON and RLP. In one
+// round with n validators, the proposer sends INIT ballot to the other
+// validators and every validator sends SIGN and ACCEPT ballots to the others,
+// so `(1 + 2n) * (n - 1)` ballots are sent; every ballot has the transaction
+// hashes of the proposed ballot.
+func BenchmarkBallotWireSize(b *testing.B) {
+	conf := common.NewTestConfig()
+	validators := 4
+	ballotsInRound := float64((1 + 2*validators) * (validators - 1))
+
+	for _, numberOfTxs := range []int{0, 100, 1000} {
+		_, blt := makeWireTestBallot(conf, numberOfTxs)
+
+		b.Run(fmt.Sprintf("txs=%d/json", numberOfTxs), func(b *testing.B) {
+			var encoded []byte
+			for i := 0; i < b.N; i++ {
+				encoded, _ = blt.Serialize()
+			}
+			b.ReportMetric(float64(len(encoded)), "bytes/ballot")
+			b.ReportMetric(float64(len(encoded))*ballotsInRound, "bytes/round")
+		})
+
+		b.Run(fmt.Sprintf("txs=%d/rlp", numberOfTxs), func(b *testing.B) {
+			var encoded []byte
+			for i := 0; i < b.N; i++ {
+				encoded, _ = blt.SerializeRLP()
+			}
+			jsonEncoded, _ := blt.Serialize()
+			b.ReportMetric(float64(len(encoded)), "bytes/ballot")
+			b.ReportMetric(float64(len(encoded))*ballotsInRound, "bytes/round")
+			b.ReportMetric(float64(len(jsonEncoded)-len(encoded))*ballotsInRound, "saved-bytes/round")
+		})
+	}
+}
+
+func BenchmarkBallotWireDecode(b *testing.B) {
+	conf := common.NewTestConfig()
+	_, blt := makeWireTestBallot(conf, 1000)
+
+	b.Run("json", func(b *testing.B) {
+		encoded, _ := blt.Serialize()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewBallotFromJSON(encoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("rlp", func(b *testing.B) {
+		encoded, _ := blt.SerializeRLP()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewBallotFromRLP(encoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,6 +2,7 @@ package block
 
 import (
 	"fmt"
+	"sort"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/storage"
@@ -26,6 +27,13 @@ type BlockAccount struct {
 	Linked   string      `json:"linked"`
 	CodeHash []byte      `json:"code_hash"`
 	RootHash common.Hash `json:"root_hash"`
+	// Data entries set by `operation.ManageData`, sorted by key
+	Data []DataEntry `json:"data,omitempty"`
+}
+
+type DataEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
 }
 
 func NewBlockAccount(address string, balance common.Amount) *BlockAccount {
@@ -45,6 +53,44 @@ func (b *BlockAccount) IsFrozen() bool {
 	return b.Linked != ""
 }
 
+func (b *BlockAccount) searchData(key string) int {
+	return sort.Search(len(b.Data), func(i int) bool { return b.Data[i].Key >= key })
+}
+
+// GetData returns the value of the data entry of `key`.
+func (b *BlockAccount) GetData(key string) ([]byte, bool) {
+	if i := b.searchData(key); i < len(b.Data) && b.Data[i].Key == key {
+		return b.Data[i].Value, true
+	}
+
+	return nil, false
+}
+
+// ManageData sets the data entry of `key`, or deletes it when `value` is
+// empty.
+func (b *BlockAccount) ManageData(key string, value []byte) {
+	i := b.searchData(key)
+	found := i < len(b.Data) && b.Data[i].Key == key
+
+	switch {
+	case len(value) < 1:
+		if found {
+			b.Data = append(b.Data[:i], b.Data[i+1:]...)
+		}
+	case found:
+		b.Data[i].Value = value
+	default:
+		b.Data = append(b.Data, DataEntry{})
+		copy(b.Data[i+1:], b.Data[i:])
+		b.Data[i] = DataEntry{Key: key, Value: value}
+	}
+}
+
+// DataReserve is the amount of balance reserved for the data entries.
+func (b *BlockAccount) DataReserve() common.Amount {
+	return common.DataEntryReserve.MustMult(len(b.Data))
+}
+
 func (b *BlockAccount) IncreaseSequenceID() {
 	b.SequenceID += 1
 }
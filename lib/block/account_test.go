@@ -129,3 +129,50 @@ func TestBlockAccountSaveBlockAccountSequenceIDs(t *testing.T) {
 		require.Equal(t, b.SequenceID, fetched[i].SequenceID)
 	}
 }
+
+func TestBlockAccountManageData(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	b := TestMakeBlockAccount()
+	require.Equal(t, common.Amount(0), b.DataReserve())
+
+	b.ManageData("home_domain", []byte("boscoin.io"))
+	b.ManageData("kyc", []byte{0x01, 0x02})
+	require.Equal(t, common.DataEntryReserve.MustMult(2), b.DataReserve())
+	b.MustSave(st)
+
+	fetched, err := GetBlockAccount(st, b.Address)
+	require.NoError(t, err)
+	require.Equal(t, b.Data, fetched.Data)
+
+	// delete
+	fetched.ManageData("kyc", nil)
+	fetched.ManageData("unknown", nil)
+	require.Equal(t, []DataEntry{{Key: "home_domain", Value: []byte("boscoin.io")}}, fetched.Data)
+	require.Equal(t, common.DataEntryReserve, fetched.DataReserve())
+
+	// sorted by key
+	fetched.ManageData("kyc", []byte("findme"))
+	fetched.ManageData("congress", []byte("findme"))
+	fetched.ManageData("home_domain", []byte("sebak"))
+	require.Equal(
+		t,
+		[]DataEntry{
+			{Key: "congress", Value: []byte("findme")},
+			{Key: "home_domain", Value: []byte("sebak")},
+			{Key: "kyc", Value: []byte("findme")},
+		},
+		fetched.Data,
+	)
+
+	value, found := fetched.GetData("home_domain")
+	require.True(t, found)
+	require.Equal(t, []byte("sebak"), value)
+	_, found = fetched.GetData("unknown")
+	require.False(t, found)
+
+	// the account with data entries can be hashed
+	_, err = common.MakeObjectHash(fetched)
+	require.NoError(t, err)
+}
@@ -3,6 +3,9 @@ package block
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/btcsuite/btcutil/base58"
 
@@ -229,6 +232,60 @@ func GetBlockHeaderByHeight(st *storage.LevelDBBackend, height uint64) (bt Heade
 	return GetBlockHeader(st, hash)
 }
 
+// NewHeightRangeListOptions limits the iterator of the index, whose keys
+// have the block height after the prefix, to the blocks from `from` to `to`.
+// 0 means unbounded.
+func NewHeightRangeListOptions(options storage.ListOptions, from, to uint64) storage.ListOptions {
+	var start, limit []byte
+	if from > 0 {
+		b := common.EncodeUint64ToByteSlice(from)
+		start = b[:]
+	}
+	if to > 0 && to < math.MaxUint64 {
+		b := common.EncodeUint64ToByteSlice(to + 1)
+		limit = b[:]
+	}
+
+	return storage.NewRangeListOptions(options, start, limit)
+}
+
+// GetBlockHeightByTime returns the height of the last block, which was
+// proposed at or before t; if there is no such block, it returns 0.
+func GetBlockHeightByTime(st *storage.LevelDBBackend, t time.Time) (height uint64, err error) {
+	iterFunc, closeFunc := GetBlockHeadersByConfirmed(st, storage.NewDefaultListOptions(true, nil, 1))
+	latest, hasNext, _ := iterFunc()
+	closeFunc()
+	if !hasNext {
+		return
+	}
+
+	// the blocks are proposed in order of height, so the first block after t
+	// is searched by height.
+	n := sort.Search(int(latest.Height), func(i int) bool {
+		if err != nil {
+			return true
+		}
+
+		var header Header
+		if header, err = GetBlockHeaderByHeight(st, uint64(i)+common.GenesisBlockHeight); err != nil {
+			return true
+		}
+
+		var proposed time.Time
+		if proposed, err = common.ParseISO8601(header.ProposedTime); err != nil {
+			return true
+		}
+
+		return proposed.After(t)
+	})
+	if err != nil {
+		return
+	}
+
+	height = uint64(n)
+	return
+}
+
 func GetLatestBlock(st *storage.LevelDBBackend) Block {
 	// get latest blocks
 	iterFunc, closeFunc := GetBlocksByConfirmed(st, storage.NewDefaultListOptions(true, nil, 1))
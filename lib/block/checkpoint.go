@@ -0,0 +1,61 @@
+package block
+
+import (
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+func getCheckpointsKey() string {
+	return fmt.Sprintf("%s-checkpoints", common.InternalPrefix)
+}
+
+// GetCheckpoints returns the checkpoints stored with genesis block.
+func GetCheckpoints(st *storage.LevelDBBackend) (cps common.Checkpoints, err error) {
+	if err = st.Get(getCheckpointsKey(), &cps); err == errors.StorageRecordDoesNotExist {
+		err = nil
+	}
+
+	return
+}
+
+// SaveCheckpoints merges the checkpoints with the stored ones and saves them.
+func SaveCheckpoints(st *storage.LevelDBBackend, cps common.Checkpoints) error {
+	stored, err := GetCheckpoints(st)
+	if err != nil {
+		return err
+	}
+
+	merged, err := stored.Merge(cps)
+	if err != nil {
+		return errors.Wrap(errors.CheckpointMismatch, err.Error())
+	}
+
+	if found, err := st.Has(getCheckpointsKey()); err != nil {
+		return err
+	} else if found {
+		return st.Set(getCheckpointsKey(), merged)
+	}
+
+	return st.New(getCheckpointsKey(), merged)
+}
+
+// CheckCheckpoints checks the stored blocks match with the checkpoints.
+func CheckCheckpoints(st *storage.LevelDBBackend, cps common.Checkpoints) error {
+	for _, cp := range cps {
+		blk, err := GetBlockByHeight(st, cp.Height)
+		if err == errors.StorageRecordDoesNotExist || err == errors.BlockNotFound {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if blk.Hash != cp.Hash {
+			return errors.Wrapf(errors.CheckpointMismatch, "height %d: expected %s, but %s", cp.Height, cp.Hash, blk.Hash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestCheckpoints(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	genesis := GetGenesis(st)
+
+	cps, err := GetCheckpoints(st)
+	require.NoError(t, err)
+	require.Empty(t, cps)
+
+	require.NoError(t, SaveCheckpoints(st, common.Checkpoints{{Height: 10, Hash: "hash10"}}))
+	require.NoError(t, SaveCheckpoints(st, common.Checkpoints{{Height: genesis.Height, Hash: genesis.Hash}}))
+	require.Equal(t, errors.CheckpointMismatch, errors.Cause(SaveCheckpoints(st, common.Checkpoints{{Height: 10, Hash: "another"}})))
+
+	cps, err = GetCheckpoints(st)
+	require.NoError(t, err)
+	require.Equal(t, common.Checkpoints{{Height: genesis.Height, Hash: genesis.Hash}, {Height: 10, Hash: "hash10"}}, cps)
+
+	// the block of height 10 is not stored yet
+	require.NoError(t, CheckCheckpoints(st, cps))
+
+	err = CheckCheckpoints(st, common.Checkpoints{{Height: genesis.Height, Hash: "another"}})
+	require.Equal(t, errors.CheckpointMismatch, errors.Cause(err))
+}
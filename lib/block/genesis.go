@@ -129,5 +129,16 @@ func MakeGenesisBlock(st *storage.LevelDBBackend, genesisAccount BlockAccount, c
 		return
 	}
 
+	var ledger Ledger
+	if ledger, err = NewGenesisLedger(*blk, tx); err != nil {
+		return
+	}
+	if err = ledger.Save(st); err != nil {
+		return
+	}
+
+	// the new storage starts from genesis, so it has the current schema.
+	err = storage.SetSchemaVersion(st, storage.SchemaVersion())
+
 	return
 }
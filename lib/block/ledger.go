@@ -0,0 +1,257 @@
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// Ledger is the ledger-wide statistics at the block of `Height`. The ledger
+// of each block is made from the ledger of the previous block by the
+// transactions of the block, so the accounts are not walked.
+//
+// The frozen accounts are the accounts linked to the other account; the
+// unfrozen accounts are still counted with zero balance.
+//
+// The amounts are in GON like `common.Amount`, but the sum of the balances can
+// be over `common.MaximumBalance`, so they are `uint64`.
+type Ledger struct {
+	Height         uint64 `json:"height"`
+	Block          string `json:"block"`
+	TotalSupply    uint64 `json:"total_supply,string"`
+	FrozenAmount   uint64 `json:"frozen_amount,string"`
+	Accounts       uint64 `json:"accounts"`
+	FrozenAccounts uint64 `json:"frozen_accounts"`
+
+	// Inflation and FeesCollected are accumulated from the block of `Since`;
+	// it is the genesis block, but the ledger made from the state, like the
+	// restored snapshot, starts from its own block.
+	Inflation     uint64 `json:"inflation,string"`
+	FeesCollected uint64 `json:"fees_collected,string"`
+	Since         uint64 `json:"since"`
+}
+
+func getLedgerKey(height uint64) string {
+	return fmt.Sprintf("%s%020d", common.BlockLedgerPrefixHeight, height)
+}
+
+func (l Ledger) String() string {
+	return string(common.MustMarshalJSON(l))
+}
+
+func (l Ledger) Save(st *storage.LevelDBBackend) error {
+	return st.New(getLedgerKey(l.Height), l)
+}
+
+// save stores the ledger again over the existing one.
+func (l Ledger) save(st *storage.LevelDBBackend) error {
+	if found, err := st.Has(getLedgerKey(l.Height)); err != nil {
+		return err
+	} else if found {
+		return st.Set(getLedgerKey(l.Height), l)
+	}
+
+	return l.Save(st)
+}
+
+func GetLedger(st *storage.LevelDBBackend, height uint64) (l Ledger, err error) {
+	err = st.Get(getLedgerKey(height), &l)
+	return
+}
+
+// GetLatestLedger returns the ledger of the highest block.
+func GetLatestLedger(st *storage.LevelDBBackend) (l Ledger, err error) {
+	iterFunc, closeFunc := st.GetIterator(common.BlockLedgerPrefixHeight, storage.NewDefaultListOptions(true, nil, 1))
+	defer closeFunc()
+
+	item, hasNext := iterFunc()
+	if !hasNext {
+		err = errors.StorageRecordDoesNotExist
+		return
+	}
+
+	err = json.Unmarshal(item.Value, &l)
+	return
+}
+
+// NewGenesisLedger makes the ledger of genesis block from the genesis
+// transaction; the source of genesis transaction is not withdrawn.
+func NewGenesisLedger(blk Block, tx transaction.Transaction) (l Ledger, err error) {
+	l = Ledger{Height: blk.Height, Block: blk.Hash, Since: blk.Height}
+
+	for _, op := range tx.B.Operations {
+		opb, ok := op.B.(operation.CreateAccount)
+		if !ok {
+			continue
+		}
+		if err = l.createAccount(opb); err != nil {
+			return
+		}
+		if err = addAmount(&l.TotalSupply, opb.Amount); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// NewLedgerFromState makes the ledger of the block from the stored accounts;
+// the inflation and fees before the block are unknown.
+func NewLedgerFromState(st *storage.LevelDBBackend, blk Block) (l Ledger, err error) {
+	l = Ledger{Height: blk.Height, Block: blk.Hash, Since: blk.Height}
+
+	iterFunc, closeFunc := st.GetIterator(common.BlockAccountPrefixAddress, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var ba BlockAccount
+		if err = json.Unmarshal(item.Value, &ba); err != nil {
+			return
+		}
+
+		l.Accounts++
+		if err = addAmount(&l.TotalSupply, ba.Balance); err != nil {
+			return
+		}
+		if ba.IsFrozen() {
+			l.FrozenAccounts++
+			if err = addAmount(&l.FrozenAmount, ba.Balance); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// Next makes the ledger of the next block, `blk` from the transactions and
+// the proposer transaction of the block; `ptx` can be nil. The accounts of
+// the transactions should be in `st`, which are only used to know whether
+// they are frozen.
+func (l Ledger) Next(st *storage.LevelDBBackend, blk Block, txs []*transaction.Transaction, ptx *transaction.Transaction) (next Ledger, err error) {
+	if blk.Height != l.Height+1 {
+		err = errors.Newf(errors.LedgerHeightMismatch, "ledger height is %d, but block height is %d", l.Height, blk.Height)
+		return
+	}
+
+	next = l
+	next.Height = blk.Height
+	next.Block = blk.Hash
+
+	for _, tx := range txs {
+		if err = next.applyTransaction(st, *tx); err != nil {
+			return
+		}
+	}
+
+	if ptx != nil {
+		err = next.applyProposerTransaction(*ptx)
+	}
+
+	return
+}
+
+func (l *Ledger) createAccount(opb operation.CreateAccount) (err error) {
+	l.Accounts++
+	if len(opb.Linked) < 1 {
+		return
+	}
+
+	l.FrozenAccounts++
+	err = addAmount(&l.FrozenAmount, opb.Amount)
+
+	return
+}
+
+// applyTransaction follows the changes of `runner.FinishTransactions`.
+func (l *Ledger) applyTransaction(st *storage.LevelDBBackend, tx transaction.Transaction) (err error) {
+	for _, op := range tx.B.Operations {
+		switch opb := op.B.(type) {
+		case operation.CreateAccount:
+			err = l.createAccount(opb)
+		case operation.Payment:
+			var frozen bool
+			if frozen, err = isFrozenAccount(st, opb.Target); err == nil && frozen {
+				err = addAmount(&l.FrozenAmount, opb.Amount)
+			}
+		case operation.InflationPF:
+			err = l.inflate(opb.Amount)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	var frozen bool
+	if frozen, err = isFrozenAccount(st, tx.B.Source); err == nil && frozen {
+		err = subAmount(&l.FrozenAmount, tx.TotalAmount(true))
+	}
+
+	return
+}
+
+// applyProposerTransaction follows the changes of
+// `runner.ProcessProposerTransaction`; the collected fees are already in the
+// total supply.
+func (l *Ledger) applyProposerTransaction(tx transaction.Transaction) (err error) {
+	for _, op := range tx.B.Operations {
+		switch opb := op.B.(type) {
+		case operation.CollectTxFee:
+			err = addAmount(&l.FeesCollected, opb.Amount)
+		case operation.Inflation:
+			err = l.inflate(opb.Amount)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (l *Ledger) inflate(amount common.Amount) (err error) {
+	if err = addAmount(&l.TotalSupply, amount); err != nil {
+		return
+	}
+	err = addAmount(&l.Inflation, amount)
+
+	return
+}
+
+func isFrozenAccount(st *storage.LevelDBBackend, address string) (bool, error) {
+	ba, err := GetBlockAccount(st, address)
+	if err != nil {
+		return false, err
+	}
+
+	return ba.IsFrozen(), nil
+}
+
+func addAmount(total *uint64, amount common.Amount) error {
+	n := *total + uint64(amount)
+	if n < *total {
+		return errors.MaximumBalanceReached
+	}
+	*total = n
+
+	return nil
+}
+
+func subAmount(total *uint64, amount common.Amount) error {
+	if *total < uint64(amount) {
+		return errors.AccountBalanceUnderZero
+	}
+	*total -= uint64(amount)
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package block
+
+import (
+	"testing"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func testMakeLedgerTransaction(t *testing.T, source string, opbs ...operation.Body) *transaction.Transaction {
+	var ops []operation.Operation
+	for _, opb := range opbs {
+		op, err := operation.NewOperation(opb)
+		require.NoError(t, err)
+		ops = append(ops, op)
+	}
+
+	tx, err := transaction.NewTransaction(source, 0, ops...)
+	require.NoError(t, err)
+
+	return &tx
+}
+
+// testFinishLedgerBlock stores the block of the transactions and its ledger
+// like `runner.FinishLedger`.
+func testFinishLedgerBlock(t *testing.T, st *storage.LevelDBBackend, txs []*transaction.Transaction, ptx *transaction.Transaction) Ledger {
+	prev, err := GetLatestLedger(st)
+	require.NoError(t, err)
+
+	var hashes []string
+	for _, tx := range txs {
+		hashes = append(hashes, tx.GetHash())
+		_, err = SaveTransactionPool(st, *tx)
+		require.NoError(t, err)
+	}
+	_, err = SaveTransactionPool(st, *ptx)
+	require.NoError(t, err)
+
+	latest := GetLatestBlock(st)
+	blk := NewBlock(
+		keypair.Random().Address(),
+		voting.Basis{Height: latest.Height + 1, BlockHash: latest.Hash},
+		ptx.GetHash(),
+		hashes,
+		common.NowISO8601(),
+	)
+	blk.MustSave(st)
+
+	ledger, err := prev.Next(st, *blk, txs, ptx)
+	require.NoError(t, err)
+	require.NoError(t, ledger.Save(st))
+
+	return ledger
+}
+
+func TestLedger(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	genesis, err := GetLatestLedger(st)
+	require.NoError(t, err)
+	require.Equal(t, common.GenesisBlockHeight, genesis.Height)
+	require.Equal(t, GetGenesis(st).Hash, genesis.Block)
+	require.Equal(t, uint64(2), genesis.Accounts)
+	require.Equal(t, uint64(common.NewTestConfig().InitialBalance), genesis.TotalSupply)
+	require.Equal(t, common.GenesisBlockHeight, genesis.Since)
+
+	target := keypair.Random()
+	frozen := keypair.Random()
+	frozenAmount := common.BaseReserve.MustMult(10)
+	tx := testMakeLedgerTransaction(
+		t,
+		GenesisKP.Address(),
+		operation.NewCreateAccount(target.Address(), common.BaseReserve, ""),
+		operation.NewCreateAccount(frozen.Address(), frozenAmount, GenesisKP.Address()),
+	)
+	// the accounts are already changed by the transactions
+	NewBlockAccount(target.Address(), common.BaseReserve).MustSave(st)
+	NewBlockAccountLinked(frozen.Address(), frozenAmount, GenesisKP.Address()).MustSave(st)
+
+	ptx := testMakeLedgerTransaction(
+		t,
+		keypair.Random().Address(),
+		operation.NewCollectTxFee(CommonKP.Address(), tx.B.Fee, 1, 2, "", 2),
+		operation.NewOperationBodyInflation(CommonKP.Address(), common.Amount(100), common.NewTestConfig().InitialBalance, 2, "", 2),
+	)
+
+	ledger := testFinishLedgerBlock(t, st, []*transaction.Transaction{tx}, ptx)
+	require.Equal(t, uint64(2), ledger.Height)
+	require.Equal(t, GetLatestBlock(st).Hash, ledger.Block)
+	require.Equal(t, uint64(4), ledger.Accounts)
+	require.Equal(t, uint64(1), ledger.FrozenAccounts)
+	require.Equal(t, uint64(frozenAmount), ledger.FrozenAmount)
+	require.Equal(t, genesis.TotalSupply+100, ledger.TotalSupply)
+	require.Equal(t, uint64(100), ledger.Inflation)
+	require.Equal(t, uint64(tx.B.Fee), ledger.FeesCollected)
+
+	{ // the frozen account sends its balance
+		amount := frozenAmount - common.BaseFee
+		tx := testMakeLedgerTransaction(t, frozen.Address(), operation.NewPayment(GenesisKP.Address(), amount))
+		ptx := testMakeLedgerTransaction(
+			t,
+			keypair.Random().Address(),
+			operation.NewCollectTxFee(CommonKP.Address(), tx.B.Fee, 1, 3, "", 4),
+			operation.NewOperationBodyInflation(CommonKP.Address(), common.Amount(100), common.NewTestConfig().InitialBalance, 3, "", 4),
+		)
+
+		next := testFinishLedgerBlock(t, st, []*transaction.Transaction{tx}, ptx)
+		require.Equal(t, uint64(4), next.Accounts)
+		require.Equal(t, uint64(1), next.FrozenAccounts)
+		require.Equal(t, uint64(0), next.FrozenAmount)
+		require.Equal(t, ledger.FeesCollected+uint64(tx.B.Fee), next.FeesCollected)
+		require.Equal(t, uint64(200), next.Inflation)
+
+		stored, err := GetLedger(st, next.Height)
+		require.NoError(t, err)
+		require.Equal(t, next, stored)
+	}
+
+	// the ledger only follows the previous block
+	_, err = genesis.Next(st, GetLatestBlock(st), nil, nil)
+	require.Equal(t, errors.LedgerHeightMismatch.Code, err.(*errors.Error).Code)
+}
+
+func TestMigrateLedgers(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	target := keypair.Random()
+	NewBlockAccount(target.Address(), common.BaseReserve).MustSave(st)
+
+	var expected []Ledger
+	for i := 0; i < 3; i++ {
+		var opb operation.Body = operation.NewPayment(target.Address(), common.BaseReserve)
+		if i == 0 {
+			opb = operation.NewCreateAccount(target.Address(), common.BaseReserve, "")
+		}
+		tx := testMakeLedgerTransaction(t, GenesisKP.Address(), opb)
+		ptx := testMakeLedgerTransaction(
+			t,
+			keypair.Random().Address(),
+			operation.NewCollectTxFee(CommonKP.Address(), tx.B.Fee, 1, 0, "", 0),
+			operation.NewOperationBodyInflation(CommonKP.Address(), common.Amount(10), 0, 0, "", 0),
+		)
+		expected = append(expected, testFinishLedgerBlock(t, st, []*transaction.Transaction{tx}, ptx))
+	}
+
+	removeLedgers := func() {
+		for height := common.GenesisBlockHeight; height <= GetLatestBlock(st).Height; height++ {
+			require.NoError(t, st.Remove(getLedgerKey(height)))
+		}
+	}
+
+	removeLedgers()
+	require.NoError(t, storage.SetSchemaVersion(st, storage.BaseSchemaVersion+1))
+
+	logger := logging.New()
+	logger.SetHandler(logging.DiscardHandler())
+	m := storage.NewMigrator(st, storage.Migrations(), logger)
+	m.BatchSize = 2
+	applied, err := m.Run()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(applied))
+
+	for _, l := range expected {
+		migrated, err := GetLedger(st, l.Height)
+		require.NoError(t, err)
+		require.Equal(t, l, migrated)
+	}
+
+	{ // the pruned storage has only the ledger of the latest block
+		removeLedgers()
+		require.NoError(t, setPrunedBlockHeight(st, 2))
+		require.NoError(t, migrateLedgers(m))
+
+		latest := expected[len(expected)-1]
+		ledger, err := GetLatestLedger(st)
+		require.NoError(t, err)
+		require.Equal(t, latest.Height, ledger.Height)
+		require.Equal(t, latest.Height, ledger.Since)
+		require.Equal(t, latest.Accounts, ledger.Accounts)
+		require.Equal(t, uint64(0), ledger.Inflation)
+
+		_, err = GetLedger(st, latest.Height-1)
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+}
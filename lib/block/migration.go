@@ -0,0 +1,210 @@
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func init() {
+	storage.RegisterMigration(storage.Migration{
+		Version:     storage.BaseSchemaVersion + 1,
+		Description: "add the target and counterparty indexes of transactions and operations",
+		Migrate:     migrateCounterpartyIndexes,
+	})
+	storage.RegisterMigration(storage.Migration{
+		Version:     storage.BaseSchemaVersion + 2,
+		Description: "add the ledger statistics of blocks",
+		Migrate:     migrateLedgers,
+	})
+}
+
+// migrateCounterpartyIndexes stores the target and counterparty indexes of
+// the stored operations; the existing indexes are skipped, so it can run
+// again over the partially migrated storage.
+func migrateCounterpartyIndexes(m *storage.Migrator) (err error) {
+	_, err = m.Each(common.BlockOperationPrefixHash, func(batch *storage.LevelDBBackend, key, value []byte) (bool, error) {
+		var bo BlockOperation
+		if err := json.Unmarshal(value, &bo); err != nil {
+			return false, err
+		}
+		if !bo.hasTarget() {
+			return false, nil
+		}
+
+		bt, err := GetBlockTransaction(m.Storage(), bo.TxHash)
+		if err == errors.DataPruned || err == errors.StorageRecordDoesNotExist {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		bt.blockHeight = bo.Height
+		bo.seqID = bt.SequenceID
+
+		if err = migrateBlockOperationIndexes(batch, bt, bo); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+
+	return
+}
+
+func migrateBlockOperationIndexes(st *storage.LevelDBBackend, bt BlockTransaction, bo BlockOperation) (err error) {
+	suffix := fmt.Sprintf(
+		"%s%s",
+		common.EncodeUint64ToByteSlice(bo.Height),
+		common.EncodeUint64ToByteSlice(bo.seqID),
+	)
+
+	pairs := [][2]string{{bo.Source, bo.Target}}
+	if bo.Source != bo.Target {
+		pairs = append(pairs, [2]string{bo.Target, bo.Source})
+	}
+	for _, pair := range pairs {
+		var found bool
+		if found, err = hasIndex(st, keyPrefixCounterparty(pair[0], pair[1])+suffix, bo.Hash); err != nil {
+			return
+		} else if found {
+			continue
+		}
+		if err = st.New(bo.NewBlockOperationCounterpartyKey(pair[0], pair[1]), bo.Hash); err != nil {
+			return
+		}
+	}
+
+	var body operation.Body
+	if body, err = operation.UnmarshalBodyJSON(bo.Type, bo.Body); err != nil {
+		return
+	}
+	if pop, ok := body.(operation.Payable); ok {
+		return bt.savePaymentIndexes(st, pop.TargetAddress())
+	}
+
+	return nil
+}
+
+// hasIndex checks the index records under the prefix point the value.
+func hasIndex(st *storage.LevelDBBackend, prefix, value string) (bool, error) {
+	iterFunc, closeFunc := st.GetIterator(prefix, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			return false, nil
+		}
+
+		var s string
+		if err := json.Unmarshal(item.Value, &s); err != nil {
+			return false, err
+		} else if s == value {
+			return true, nil
+		}
+	}
+}
+
+// migrateLedgers stores the ledgers of the stored blocks by replaying the
+// transactions from genesis. If the transactions are pruned or the storage
+// is restored from the state snapshot, only the ledger of the latest block is
+// made from the accounts.
+func migrateLedgers(m *storage.Migrator) (err error) {
+	st := m.Storage()
+
+	var exists bool
+	if exists, err = ExistsBlockByHeight(st, common.GenesisBlockHeight); err != nil {
+		return
+	}
+
+	var pruned uint64
+	if pruned, err = GetPrunedBlockHeight(st); err != nil {
+		return
+	}
+
+	latest := GetLatestBlock(st)
+	if !exists || pruned > 0 {
+		var ledger Ledger
+		if ledger, err = NewLedgerFromState(st, latest); err != nil {
+			return
+		}
+		m.Log().Info("ledger made from accounts", "height", latest.Height)
+
+		return ledger.save(st)
+	}
+
+	var batch *storage.LevelDBBackend
+	if batch, err = st.OpenBatch(); err != nil {
+		return
+	}
+
+	var ledger Ledger
+	for height := common.GenesisBlockHeight; height <= latest.Height; height++ {
+		if ledger, err = replayLedger(st, ledger, height); err != nil {
+			batch.Discard()
+			return
+		}
+		if err = ledger.save(batch); err != nil {
+			batch.Discard()
+			return
+		}
+
+		if int(height)%m.BatchSize == 0 || height == latest.Height {
+			if err = batch.Commit(); err != nil {
+				return
+			}
+			m.Log().Info("ledgers migrated", "height", height)
+		}
+	}
+
+	return
+}
+
+// replayLedger makes the ledger of the block at height from the ledger of
+// the previous block and the stored transactions.
+func replayLedger(st *storage.LevelDBBackend, prev Ledger, height uint64) (ledger Ledger, err error) {
+	var blk Block
+	if blk, err = GetBlockByHeight(st, height); err != nil {
+		return
+	}
+
+	getTransaction := func(hash string) (*transaction.Transaction, error) {
+		tp, err := GetTransactionPool(st, hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "transaction, %s of block %d", hash, height)
+		}
+		tx := tp.Transaction()
+		return &tx, nil
+	}
+
+	var txs []*transaction.Transaction
+	for _, hash := range blk.Transactions {
+		var tx *transaction.Transaction
+		if tx, err = getTransaction(hash); err != nil {
+			return
+		}
+		txs = append(txs, tx)
+	}
+
+	if height == common.GenesisBlockHeight {
+		if len(txs) != 1 {
+			err = errors.Newf(errors.InvalidTransaction, "genesis block has %d transactions", len(txs))
+			return
+		}
+		return NewGenesisLedger(blk, *txs[0])
+	}
+
+	var ptx *transaction.Transaction
+	if len(blk.ProposerTransaction) > 0 {
+		if ptx, err = getTransaction(blk.ProposerTransaction); err != nil {
+			return
+		}
+	}
+
+	return prev.Next(st, blk, txs, ptx)
+}
@@ -0,0 +1,86 @@
+package block
+
+import (
+	"testing"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestMigrateCounterpartyIndexes(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	source := keypair.Random()
+	target := keypair.Random()
+	require.NoError(t, NewBlockAccount(source.Address(), common.BaseReserve*100).Save(st))
+	require.NoError(t, NewBlockAccount(target.Address(), common.BaseReserve).Save(st))
+	for i := 0; i < 3; i++ {
+		tx := transaction.TestMakeTransactionWithKeypair(conf.NetworkID, 2, source, target)
+
+		blk := TestMakeNewBlockWithPrevBlock(GetLatestBlock(st), []string{tx.GetHash()})
+		blk.MustSave(st)
+		bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+		bt.MustSave(st)
+		require.NoError(t, bt.SaveBlockOperations(st))
+		_, err := SaveTransactionPool(st, tx)
+		require.NoError(t, err)
+	}
+
+	prefixes := []string{
+		common.BlockTransactionPrefixTarget,
+		common.BlockTransactionPrefixCounterparty,
+		common.BlockOperationPrefixCounterparty,
+	}
+	collect := func() (keys map[string][]string) {
+		keys = map[string][]string{}
+		for _, prefix := range prefixes {
+			iterFunc, closeFunc := st.GetIterator(prefix, nil)
+			for {
+				item, hasNext := iterFunc()
+				if !hasNext {
+					break
+				}
+				keys[prefix] = append(keys[prefix], string(item.Key))
+			}
+			closeFunc()
+		}
+		return
+	}
+
+	expected := collect()
+	for _, prefix := range prefixes {
+		require.NotEmpty(t, expected[prefix])
+	}
+
+	// the storage before the indexes
+	for _, keys := range expected {
+		for _, key := range keys {
+			require.NoError(t, st.Remove(key))
+		}
+	}
+	require.NoError(t, storage.SetSchemaVersion(st, storage.BaseSchemaVersion))
+
+	logger := logging.New()
+	logger.SetHandler(logging.DiscardHandler())
+	m := storage.NewMigrator(st, storage.Migrations(), logger)
+	m.BatchSize = 5
+	applied, err := m.Run()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(applied))
+
+	migrated := collect()
+	require.Equal(t, expected[common.BlockTransactionPrefixTarget], migrated[common.BlockTransactionPrefixTarget])
+	require.Equal(t, expected[common.BlockTransactionPrefixCounterparty], migrated[common.BlockTransactionPrefixCounterparty])
+	require.Equal(t, len(expected[common.BlockOperationPrefixCounterparty]), len(migrated[common.BlockOperationPrefixCounterparty]))
+
+	// migrate again over the migrated storage
+	require.NoError(t, migrateCounterpartyIndexes(m))
+	require.Equal(t, migrated, collect())
+}
@@ -146,6 +146,14 @@ func (bo *BlockOperation) Save(st *storage.LevelDBBackend) (err error) {
 		if err = st.New(bo.NewBlockOperationPeersAndTypeKey(bo.Target), bo.Hash); err != nil {
 			return
 		}
+		if err = st.New(bo.NewBlockOperationCounterpartyKey(bo.Source, bo.Target), bo.Hash); err != nil {
+			return
+		}
+		if bo.Source != bo.Target {
+			if err = st.New(bo.NewBlockOperationCounterpartyKey(bo.Target, bo.Source), bo.Hash); err != nil {
+				return
+			}
+		}
 	}
 
 	if bo.targetIsLinked() {
@@ -215,6 +223,10 @@ func keyPrefixPeersAndType(addr string, ty operation.OperationType) string {
 	return fmt.Sprintf("%s%s%s-", common.BlockOperationPrefixTypePeers, string(ty), addr)
 }
 
+func keyPrefixCounterparty(addr, counterparty string) string {
+	return fmt.Sprintf("%s%s-%s-", common.BlockOperationPrefixCounterparty, addr, counterparty)
+}
+
 func (bo BlockOperation) NewBlockOperationTxHashKey() string {
 	return fmt.Sprintf(
 		"%s%s%s%s",
@@ -291,6 +303,17 @@ func (bo BlockOperation) NewBlockOperationPeersAndTypeKey(addr string) string {
 		common.GetUniqueIDFromUUID(),
 	)
 }
+
+func (bo BlockOperation) NewBlockOperationCounterpartyKey(addr, counterparty string) string {
+	return fmt.Sprintf(
+		"%s%s%s%s",
+		keyPrefixCounterparty(addr, counterparty),
+		common.EncodeUint64ToByteSlice(bo.Height),
+		common.EncodeUint64ToByteSlice(bo.seqID),
+		common.GetUniqueIDFromUUID(),
+	)
+}
+
 func (bo BlockOperation) NewBlockOperationBlockHeightKey() string {
 	return fmt.Sprintf(
 		"%s%s%s",
@@ -442,6 +465,16 @@ func GetBlockOperationsByPeersAndType(st *storage.LevelDBBackend, addr string, t
 	return LoadBlockOperationsInsideIterator(st, iterFunc, closeFunc)
 }
 
+// GetBlockOperationsByCounterparty finds the operations between addr and
+// counterparty.
+func GetBlockOperationsByCounterparty(st *storage.LevelDBBackend, addr, counterparty string, options storage.ListOptions) (
+	func() (BlockOperation, bool, []byte),
+	func(),
+) {
+	iterFunc, closeFunc := st.GetIterator(keyPrefixCounterparty(addr, counterparty), options)
+	return LoadBlockOperationsInsideIterator(st, iterFunc, closeFunc)
+}
+
 func GetBlockOperationsByBlockHeight(st *storage.LevelDBBackend, height uint64, options storage.ListOptions) (
 	func() (BlockOperation, bool, []byte),
 	func(),
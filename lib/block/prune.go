@@ -0,0 +1,235 @@
+package block
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// The pruned `BlockTransaction` leaves the small record of it's hash and block
+// height, so the pruned one can be distinguished from the unknown one.
+
+func getPrunedBlockHeightKey() string {
+	return fmt.Sprintf("%s-pruned-block", common.InternalPrefix)
+}
+
+func GetBlockTransactionPrunedKey(hash string) string {
+	return fmt.Sprintf("%s%s", common.BlockTransactionPrefixPruned, hash)
+}
+
+// GetPrunedBlockHeight returns the last pruned block height; if nothing is
+// pruned, it is 0.
+func GetPrunedBlockHeight(st *storage.LevelDBBackend) (height uint64, err error) {
+	if err = st.Get(getPrunedBlockHeightKey(), &height); err == errors.StorageRecordDoesNotExist {
+		err = nil
+	}
+
+	return
+}
+
+func setPrunedBlockHeight(st *storage.LevelDBBackend, height uint64) error {
+	if found, err := st.Has(getPrunedBlockHeightKey()); err != nil {
+		return err
+	} else if found {
+		return st.Set(getPrunedBlockHeightKey(), height)
+	}
+
+	return st.New(getPrunedBlockHeightKey(), height)
+}
+
+// IsPrunedBlockTransaction checks whether the `BlockTransaction` was stored
+// and pruned.
+func IsPrunedBlockTransaction(st *storage.LevelDBBackend, hash string) (bool, error) {
+	return st.Has(GetBlockTransactionPrunedKey(hash))
+}
+
+// PruneBlock removes the `BlockTransaction`s, `BlockOperation`s,
+// `TransactionPool`s and their indexes of the block; the block itself and the
+// accounts are kept. The transactions of frozen account are also kept,
+// because the consensus and frozen account API depend on them.
+//
+// PruneBlock should be called with the batch storage, so the block is pruned
+// atomically.
+func PruneBlock(st *storage.LevelDBBackend, blk Block) (pruned int, err error) {
+	if blk.Height <= common.GenesisBlockHeight { // genesis is never pruned
+		return
+	}
+
+	hashes := blk.Transactions
+	if len(blk.ProposerTransaction) > 0 {
+		hashes = append([]string{blk.ProposerTransaction}, hashes...)
+	}
+
+	for _, hash := range hashes {
+		var ok bool
+		if ok, err = pruneBlockTransaction(st, blk, hash); err != nil {
+			return
+		} else if ok {
+			pruned++
+		}
+	}
+
+	err = setPrunedBlockHeight(st, blk.Height)
+
+	return
+}
+
+func pruneBlockTransaction(st *storage.LevelDBBackend, blk Block, hash string) (bool, error) {
+	bt, err := GetBlockTransaction(st, hash)
+	if err == errors.StorageRecordDoesNotExist || err == errors.DataPruned {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	bt.blockHeight = blk.Height
+
+	var bos []BlockOperation
+	iterFunc, closeFunc := GetBlockOperationsByTx(st, hash, nil)
+	for {
+		bo, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		bo.seqID = bt.SequenceID
+		bos = append(bos, bo)
+	}
+	closeFunc()
+
+	if keep, err := isFrozenTransaction(st, bt, bos); err != nil || keep {
+		return false, err
+	}
+
+	prefixHeight := fmt.Sprintf(
+		"%s%s",
+		common.EncodeUint64ToByteSlice(bt.blockHeight),
+		common.EncodeUint64ToByteSlice(bt.SequenceID),
+	)
+
+	for _, bo := range bos {
+		indexes := []string{
+			keyPrefixTxHash(bo.TxHash) + prefixHeight,
+			keyPrefixSource(bo.Source) + prefixHeight,
+			keyPrefixSourceAndType(bo.Source, bo.Type) + prefixHeight,
+			keyPrefixPeers(bo.Source) + prefixHeight,
+			keyPrefixPeersAndType(bo.Source, bo.Type) + prefixHeight,
+			fmt.Sprintf("%s%s", keyPrefixBlockHeight(bo.Height), common.EncodeUint64ToByteSlice(bo.seqID)),
+		}
+		if bo.hasTarget() {
+			indexes = append(
+				indexes,
+				keyPrefixTarget(bo.Target)+prefixHeight,
+				keyPrefixTargetAndType(bo.Target, bo.Type)+prefixHeight,
+				keyPrefixPeers(bo.Target)+prefixHeight,
+				keyPrefixPeersAndType(bo.Target, bo.Type)+prefixHeight,
+				GetBlockTransactionKeyPrefixAccount(bo.Target)+prefixHeight,
+				keyPrefixCounterparty(bo.Source, bo.Target)+prefixHeight,
+				keyPrefixCounterparty(bo.Target, bo.Source)+prefixHeight,
+				GetBlockTransactionKeyPrefixTarget(bo.Target)+prefixHeight,
+				GetBlockTransactionKeyPrefixCounterparty(bo.Source, bo.Target)+prefixHeight,
+				GetBlockTransactionKeyPrefixCounterparty(bo.Target, bo.Source)+prefixHeight,
+			)
+		}
+		for _, prefix := range indexes {
+			if err = removeIndex(st, prefix, bo.Hash, bt.Hash); err != nil {
+				return false, err
+			}
+		}
+		if err = removeIfExists(st, key(bo.Hash)); err != nil {
+			return false, err
+		}
+	}
+
+	indexes := []string{
+		GetBlockTransactionKeyPrefixSource(bt.Source) + prefixHeight,
+		GetBlockTransactionKeyPrefixConfirmed(bt.Confirmed),
+		GetBlockTransactionKeyPrefixAccount(bt.Source) + prefixHeight,
+		GetBlockTransactionKeyPrefixBlock(bt.Block) + prefixHeight,
+	}
+	for _, prefix := range indexes {
+		if err = removeIndex(st, prefix, bt.Hash); err != nil {
+			return false, err
+		}
+	}
+	if err = removeIfExists(st, GetTransactionPoolKey(hash)); err != nil {
+		return false, err
+	}
+	if err = st.Remove(GetBlockTransactionKey(hash)); err != nil {
+		return false, err
+	}
+	if err = st.New(GetBlockTransactionPrunedKey(hash), blk.Height); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// isFrozenTransaction checks the transaction is sent by frozen account or
+// creates frozen account.
+func isFrozenTransaction(st *storage.LevelDBBackend, bt BlockTransaction, bos []BlockOperation) (bool, error) {
+	if source, err := GetBlockAccount(st, bt.Source); err == nil && source.IsFrozen() {
+		return true, nil
+	} else if err != nil && err != errors.StorageRecordDoesNotExist {
+		return false, err
+	}
+
+	for _, bo := range bos {
+		if bo.Type != operation.TypeCreateAccount {
+			continue
+		}
+		body, err := operation.UnmarshalBodyJSON(bo.Type, bo.Body)
+		if err != nil {
+			return false, err
+		}
+		if createAccount, ok := body.(operation.CreateAccount); ok && len(createAccount.Linked) > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// removeIndex removes the index records under the prefix, which point one of
+// the values.
+func removeIndex(st *storage.LevelDBBackend, prefix string, values ...string) error {
+	var keys []string
+
+	iterFunc, closeFunc := st.GetIterator(prefix, nil)
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var value string
+		if err := json.Unmarshal(item.Value, &value); err != nil {
+			continue
+		}
+		for _, v := range values {
+			if value == v {
+				keys = append(keys, string(item.Key))
+				break
+			}
+		}
+	}
+	closeFunc()
+
+	for _, k := range keys {
+		if err := removeIfExists(st, k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeIfExists(st *storage.LevelDBBackend, k string) error {
+	if err := st.Remove(k); err != nil && err != errors.StorageRecordDoesNotExist {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package block
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestPruneBlock(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	saveBlock := func(tx transaction.Transaction) (Block, []string) {
+		blk := TestMakeNewBlockWithPrevBlock(GetLatestBlock(st), []string{tx.GetHash()})
+		blk.MustSave(st)
+
+		bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+		bt.MustSave(st)
+		require.NoError(t, bt.SaveBlockOperations(st))
+		_, err := SaveTransactionPool(st, tx)
+		require.NoError(t, err)
+
+		return blk, bt.Operations
+	}
+
+	prune := func(blk Block) int {
+		bs, err := st.OpenBatch()
+		require.NoError(t, err)
+		pruned, err := PruneBlock(bs, blk)
+		require.NoError(t, err)
+		require.NoError(t, bs.Commit())
+		return pruned
+	}
+
+	// records, which point the hashes
+	findRecords := func(hashes ...string) (keys []string) {
+		iterFunc, closeFunc := st.GetIterator("", nil)
+		defer closeFunc()
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+			var value string
+			json.Unmarshal(item.Value, &value)
+			for _, hash := range hashes {
+				if value == hash {
+					keys = append(keys, string(item.Key))
+				}
+			}
+		}
+		return
+	}
+
+	_, tx := transaction.TestMakeTransaction(conf.NetworkID, 3)
+	blk, opHashes := saveBlock(tx)
+	require.NotEmpty(t, findRecords(append(opHashes, tx.GetHash())...))
+
+	// frozen account is created
+	linked := keypair.Random()
+	frozenTx := transaction.MakeTransactionCreateAccount(conf.NetworkID, linked, keypair.Random().Address(), common.Unit)
+	frozenTx.B.Operations[0].B = operation.NewCreateAccount(keypair.Random().Address(), common.Unit, linked.Address())
+	frozenTx.Sign(linked, conf.NetworkID)
+	frozenBlk, _ := saveBlock(frozenTx)
+
+	{ // genesis is not pruned
+		pruned := prune(GetGenesis(st))
+		require.Equal(t, 0, pruned)
+		height, err := GetPrunedBlockHeight(st)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), height)
+	}
+
+	require.Equal(t, 1, prune(blk))
+
+	height, err := GetPrunedBlockHeight(st)
+	require.NoError(t, err)
+	require.Equal(t, blk.Height, height)
+
+	_, err = GetBlockTransaction(st, tx.GetHash())
+	require.Equal(t, errors.DataPruned, err)
+	exists, err := ExistsTransactionPool(st, tx.GetHash())
+	require.NoError(t, err)
+	require.False(t, exists)
+	for _, opHash := range opHashes {
+		exists, err = ExistsBlockOperation(st, opHash)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+
+	// every index is removed
+	require.Empty(t, findRecords(append(opHashes, tx.GetHash())...))
+
+	// block and accounts are kept
+	_, err = GetBlockByHeight(st, blk.Height)
+	require.NoError(t, err)
+	_, err = GetBlockAccount(st, GenesisKP.Address())
+	require.NoError(t, err)
+
+	{ // the transaction for frozen account is kept
+		require.Equal(t, 0, prune(frozenBlk))
+		_, err = GetBlockTransaction(st, frozenTx.GetHash())
+		require.NoError(t, err)
+		_, err = GetBlockOperation(st, NewBlockOperationKey(common.MustMakeObjectHashString(frozenTx.B.Operations[0]), frozenTx.GetHash()))
+		require.NoError(t, err)
+
+		height, err := GetPrunedBlockHeight(st)
+		require.NoError(t, err)
+		require.Equal(t, frozenBlk.Height, height)
+	}
+
+	{ // unknown transaction
+		_, err = GetBlockTransaction(st, "unknown")
+		require.Equal(t, errors.StorageRecordDoesNotExist, err)
+	}
+
+	{ // pruned storage can not be verified
+		_, err = Verify(st, false)
+		require.Equal(t, errors.DataPruned, errors.Cause(err))
+	}
+}
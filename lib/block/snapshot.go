@@ -0,0 +1,453 @@
+package block
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+const SnapshotVersion = "2"
+
+// SnapshotHeader describes the state snapshot at `Block`; it is signed by the
+// node, which made the snapshot. The headers signed by the validators with
+// same `Block` and `StateRoot` prove that the state is finalized.
+//
+// The snapshot is serialized in JSON lines; the header comes first,
+// `Accounts` accounts follow in created order and `Records` records of the
+// frozen accounts follow the accounts.
+type SnapshotHeader struct {
+	Version     string `json:"version"`
+	Block       Block  `json:"block"`
+	StateRoot   string `json:"state-root"`
+	Accounts    uint64 `json:"accounts"`
+	RecordsRoot string `json:"records-root"`
+	Records     uint64 `json:"records"`
+	Node        string `json:"node"`
+	Signature   string `json:"signature"`
+}
+
+type snapshotHeaderBody struct {
+	Version     string
+	Height      uint64
+	BlockHash   string
+	StateRoot   string
+	Accounts    uint64
+	RecordsRoot string
+	Records     uint64
+	Node        string
+}
+
+func (h SnapshotHeader) hash() string {
+	return common.MustMakeObjectHashString(snapshotHeaderBody{
+		Version:     h.Version,
+		Height:      h.Block.Height,
+		BlockHash:   h.Block.Hash,
+		StateRoot:   h.StateRoot,
+		Accounts:    h.Accounts,
+		RecordsRoot: h.RecordsRoot,
+		Records:     h.Records,
+		Node:        h.Node,
+	})
+}
+
+func (h *SnapshotHeader) Sign(kp keypair.KP, networkID []byte) {
+	h.Node = kp.Address()
+	signature, _ := keypair.MakeSignature(kp, networkID, h.hash())
+	h.Signature = base58.Encode(signature)
+}
+
+// Verify checks the signature of `Node` and the hash of `Block`.
+func (h SnapshotHeader) Verify(networkID []byte) (err error) {
+	if h.Version != SnapshotVersion {
+		return errors.Wrapf(errors.InvalidStateSnapshot, "unknown version, %q", h.Version)
+	}
+
+	b := h.Block
+	b.Hash = ""
+	b.Confirmed = ""
+	if common.MustMakeObjectHashString(b) != h.Block.Hash {
+		return errors.Wrap(errors.InvalidStateSnapshot, "hash of block does not match")
+	}
+
+	var kp keypair.KP
+	if kp, err = keypair.Parse(h.Node); err != nil {
+		return
+	}
+
+	return kp.Verify(append(networkID, []byte(h.hash())...), base58.Decode(h.Signature))
+}
+
+// SameState returns true if both headers have same block, state root and
+// records root.
+func (h SnapshotHeader) SameState(o SnapshotHeader) bool {
+	return h.Block.Hash == o.Block.Hash && h.Block.Height == o.Block.Height &&
+		h.StateRoot == o.StateRoot && h.Accounts == o.Accounts &&
+		h.RecordsRoot == o.RecordsRoot && h.Records == o.Records
+}
+
+// StateRoot makes the hash of accounts; the hash of each account record is
+// ordered by address like `storage.StateDB.MakeHash()`.
+type StateRoot struct {
+	addresses []string
+	hashes    map[string][]byte
+}
+
+func NewStateRoot() *StateRoot {
+	return &StateRoot{hashes: map[string][]byte{}}
+}
+
+// Add adds the stored record of account.
+func (s *StateRoot) Add(address string, record []byte) {
+	if _, found := s.hashes[address]; !found {
+		s.addresses = append(s.addresses, address)
+	}
+	s.hashes[address] = common.MakeHash(record)
+}
+
+func (s *StateRoot) Len() uint64 {
+	return uint64(len(s.addresses))
+}
+
+func (s *StateRoot) Hash() (string, error) {
+	sort.Strings(s.addresses)
+
+	hashes := make([][]byte, 0, len(s.addresses))
+	for _, address := range s.addresses {
+		hashes = append(hashes, s.hashes[address])
+	}
+
+	h, err := common.MakeObjectHash(hashes)
+	if err != nil {
+		return "", err
+	}
+
+	return base58.Encode(h), nil
+}
+
+// GetStateRoot returns the state root and the number of the accounts in
+// storage.
+func GetStateRoot(st *storage.LevelDBBackend) (root string, accounts uint64, err error) {
+	s := NewStateRoot()
+
+	iterFunc, closeFunc := st.GetIterator(common.BlockAccountPrefixAddress, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+		s.Add(string(item.Key[len(common.BlockAccountPrefixAddress):]), item.Value)
+	}
+
+	if root, err = s.Hash(); err != nil {
+		return
+	}
+
+	return root, s.Len(), nil
+}
+
+// SnapshotRecord is the raw record of storage, which is not the account; the
+// records of the frozen accounts are included in the snapshot, because
+// `/frozen-accounts` and the frozen accounts of GraphQL depend on them.
+type SnapshotRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// MarshalBinary makes `storage.LevelDBBackend` store the raw value as it is.
+func (r SnapshotRecord) MarshalBinary() ([]byte, error) {
+	return r.Value, nil
+}
+
+// Save stores the record; the existing record is kept.
+func (r SnapshotRecord) Save(st *storage.LevelDBBackend) error {
+	if exists, err := st.Has(string(r.Key)); err != nil || exists {
+		return err
+	}
+
+	return st.New(string(r.Key), r)
+}
+
+func (r SnapshotRecord) hash() []byte {
+	return common.MakeHash(append(append([]byte{}, r.Key...), r.Value...))
+}
+
+// recordsRoot makes the hash of records in the written order.
+func recordsRoot(hashes [][]byte) (string, error) {
+	h, err := common.MakeObjectHash(hashes)
+	if err != nil {
+		return "", err
+	}
+
+	return base58.Encode(h), nil
+}
+
+// GetFrozenRecords returns the records, which the frozen accounts depend on:
+// the indexes of the operations, which created the frozen accounts, the
+// operations and transactions, which they point, and the operations sent by
+// the frozen accounts.
+func GetFrozenRecords(st *storage.LevelDBBackend) (records []SnapshotRecord, err error) {
+	keys := map[string]bool{}
+	add := func(key string, value []byte) {
+		if keys[key] {
+			return
+		}
+		keys[key] = true
+		records = append(records, SnapshotRecord{Key: []byte(key), Value: value})
+	}
+	addKey := func(key string) error {
+		value, err := st.GetRaw(key)
+		if err != nil {
+			return err
+		}
+		add(key, value)
+		return nil
+	}
+	// addIndex adds the index records under the prefix and the operations,
+	// which they point.
+	addIndex := func(prefix string) (hashes []string, err error) {
+		iterFunc, closeFunc := st.GetIterator(prefix, nil)
+		defer closeFunc()
+
+		for {
+			item, hasNext := iterFunc()
+			if !hasNext {
+				break
+			}
+
+			var hash string
+			if err = json.Unmarshal(item.Value, &hash); err != nil {
+				return
+			}
+			add(string(item.Key), item.Value)
+			if err = addKey(key(hash)); err != nil {
+				return
+			}
+			hashes = append(hashes, hash)
+		}
+		return
+	}
+
+	var hashes []string
+	if hashes, err = addIndex(common.BlockOperationPrefixCreateFrozen); err != nil {
+		return
+	}
+	if _, err = addIndex(common.BlockOperationPrefixFrozenLinked); err != nil {
+		return
+	}
+
+	for _, hash := range hashes {
+		var bo BlockOperation
+		if bo, err = GetBlockOperation(st, hash); err != nil {
+			return
+		}
+		if err = addKey(GetBlockTransactionKey(bo.TxHash)); err != nil {
+			return
+		}
+		if _, err = addIndex(keyPrefixSource(bo.Target)); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// GetRecordsRoot returns the root and the number of the records of
+// `GetFrozenRecords`.
+func GetRecordsRoot(st *storage.LevelDBBackend) (root string, n uint64, err error) {
+	var records []SnapshotRecord
+	if records, err = GetFrozenRecords(st); err != nil {
+		return
+	}
+
+	hashes := make([][]byte, 0, len(records))
+	for _, r := range records {
+		hashes = append(hashes, r.hash())
+	}
+
+	if root, err = recordsRoot(hashes); err != nil {
+		return
+	}
+
+	return root, uint64(len(records)), nil
+}
+
+// WriteSnapshot writes the snapshot of storage; `st` should not be changed
+// while writing, like the snapshot of `storage.LevelDBBackend.OpenSnapshot()`.
+func WriteSnapshot(st *storage.LevelDBBackend, w io.Writer, header SnapshotHeader) (err error) {
+	bw := bufio.NewWriter(w)
+
+	var b []byte
+	if b, err = json.Marshal(header); err != nil {
+		return
+	}
+	if _, err = bw.Write(append(b, '\n')); err != nil {
+		return
+	}
+
+	iterFunc, closeFunc := st.GetIterator(common.BlockAccountPrefixCreated, nil)
+	defer closeFunc()
+
+	var n uint64
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var address string
+		if err = json.Unmarshal(item.Value, &address); err != nil {
+			return
+		}
+
+		if b, err = st.GetRaw(GetBlockAccountKey(address)); err != nil {
+			return
+		}
+		if _, err = bw.Write(append(b, '\n')); err != nil {
+			return
+		}
+		n++
+	}
+
+	if n != header.Accounts {
+		return errors.Wrapf(errors.InvalidStateSnapshot, "%d accounts written, but header has %d", n, header.Accounts)
+	}
+
+	var records []SnapshotRecord
+	if records, err = GetFrozenRecords(st); err != nil {
+		return
+	}
+	if uint64(len(records)) != header.Records {
+		return errors.Wrapf(errors.InvalidStateSnapshot, "%d records written, but header has %d", len(records), header.Records)
+	}
+	for _, r := range records {
+		if b, err = json.Marshal(r); err != nil {
+			return
+		}
+		if _, err = bw.Write(append(b, '\n')); err != nil {
+			return
+		}
+	}
+
+	return bw.Flush()
+}
+
+// SnapshotReader reads the snapshot written by `WriteSnapshot`. After the
+// last account, the state root of the read accounts is checked, and after the
+// last record, the records root is checked.
+type SnapshotReader struct {
+	r       *bufio.Reader
+	header  SnapshotHeader
+	root    *StateRoot
+	records [][]byte
+}
+
+func NewSnapshotReader(r io.Reader) (*SnapshotReader, error) {
+	sr := &SnapshotReader{
+		r:    bufio.NewReader(r),
+		root: NewStateRoot(),
+	}
+
+	line, err := sr.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(line, &sr.header); err != nil {
+		return nil, errors.Wrap(errors.InvalidStateSnapshot, err.Error())
+	}
+	if sr.header.Version != SnapshotVersion {
+		return nil, errors.Wrapf(errors.InvalidStateSnapshot, "unknown version, %q", sr.header.Version)
+	}
+
+	return sr, nil
+}
+
+func (sr *SnapshotReader) Header() SnapshotHeader {
+	return sr.header
+}
+
+func (sr *SnapshotReader) readLine() ([]byte, error) {
+	line, err := sr.r.ReadBytes('\n')
+	if err == io.EOF {
+		return nil, errors.Wrap(errors.InvalidStateSnapshot, "unexpected end of snapshot")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return line[:len(line)-1], nil
+}
+
+// Next returns the next account; after the last account, it returns
+// `io.EOF`.
+func (sr *SnapshotReader) Next() (account BlockAccount, err error) {
+	if sr.root.Len() == sr.header.Accounts {
+		var root string
+		if root, err = sr.root.Hash(); err != nil {
+			return
+		}
+		if root != sr.header.StateRoot {
+			err = errors.Wrapf(errors.StateRootMismatch, "expected=%s read=%s", sr.header.StateRoot, root)
+			return
+		}
+
+		err = io.EOF
+		return
+	}
+
+	var line []byte
+	if line, err = sr.readLine(); err != nil {
+		return
+	}
+	if err = json.Unmarshal(line, &account); err != nil {
+		err = errors.Wrap(errors.InvalidStateSnapshot, err.Error())
+		return
+	}
+
+	n := sr.root.Len()
+	sr.root.Add(account.Address, line)
+	if sr.root.Len() == n {
+		err = errors.Wrapf(errors.InvalidStateSnapshot, "duplicated account, %s", account.Address)
+		return
+	}
+
+	return
+}
+
+// NextRecord returns the next record after all the accounts are read by
+// `Next`; after the last record, it returns `io.EOF`.
+func (sr *SnapshotReader) NextRecord() (record SnapshotRecord, err error) {
+	if uint64(len(sr.records)) == sr.header.Records {
+		var root string
+		if root, err = recordsRoot(sr.records); err != nil {
+			return
+		}
+		if root != sr.header.RecordsRoot {
+			err = errors.Wrapf(errors.StateRootMismatch, "records: expected=%s read=%s", sr.header.RecordsRoot, root)
+			return
+		}
+
+		err = io.EOF
+		return
+	}
+
+	var line []byte
+	if line, err = sr.readLine(); err != nil {
+		return
+	}
+	if err = json.Unmarshal(line, &record); err != nil {
+		err = errors.Wrap(errors.InvalidStateSnapshot, err.Error())
+		return
+	}
+	sr.records = append(sr.records, record.hash())
+
+	return
+}
@@ -0,0 +1,208 @@
+package block
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+func TestSnapshotHeader(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	networkID := common.NewTestConfig().NetworkID
+	kp := keypair.Random()
+
+	root, accounts, err := GetStateRoot(st)
+	require.NoError(t, err)
+
+	header := SnapshotHeader{Version: SnapshotVersion, Block: GetLatestBlock(st), StateRoot: root, Accounts: accounts}
+	header.Sign(kp, networkID)
+	require.Equal(t, kp.Address(), header.Node)
+	require.Equal(t, uint64(2), header.Accounts)
+	require.NoError(t, header.Verify(networkID))
+
+	{ // different network
+		require.Error(t, header.Verify([]byte("another")))
+	}
+
+	{ // modified state root
+		h := header
+		h.StateRoot = "wrong"
+		require.Error(t, h.Verify(networkID))
+	}
+
+	{ // modified block, which does not match with the hash
+		h := header
+		h.Block.Proposer = kp.Address()
+		require.Equal(t, errors.InvalidStateSnapshot, errors.Cause(h.Verify(networkID)))
+	}
+}
+
+func TestSnapshotWriteRead(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	for i := 0; i < 3; i++ {
+		TestMakeBlockAccount().MustSave(st)
+	}
+
+	networkID := common.NewTestConfig().NetworkID
+	root, accounts, err := GetStateRoot(st)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), accounts)
+
+	header := SnapshotHeader{Version: SnapshotVersion, Block: GetLatestBlock(st), StateRoot: root, Accounts: accounts}
+	header.Sign(keypair.Random(), networkID)
+
+	b := new(bytes.Buffer)
+	require.NoError(t, WriteSnapshot(st, b, header))
+
+	readAll := func(s string) (read []BlockAccount, err error) {
+		var sr *SnapshotReader
+		if sr, err = NewSnapshotReader(strings.NewReader(s)); err != nil {
+			return
+		}
+		for {
+			var account BlockAccount
+			if account, err = sr.Next(); err == io.EOF {
+				return read, nil
+			} else if err != nil {
+				return
+			}
+			read = append(read, account)
+		}
+	}
+
+	read, err := readAll(b.String())
+	require.NoError(t, err)
+	require.Equal(t, 5, len(read))
+	require.Equal(t, GenesisKP.Address(), read[0].Address) // created order
+
+	lines := strings.SplitAfter(b.String(), "\n")
+
+	{ // modified account
+		modified := append([]string{}, lines...)
+		modified[2] = strings.Replace(modified[2], `"sequence_id":0`, `"sequence_id":1`, 1)
+		require.NotEqual(t, lines[2], modified[2])
+
+		_, err := readAll(strings.Join(modified, ""))
+		require.Equal(t, errors.StateRootMismatch, errors.Cause(err))
+	}
+
+	{ // truncated
+		_, err := readAll(strings.Join(lines[:3], ""))
+		require.Equal(t, errors.InvalidStateSnapshot, errors.Cause(err))
+	}
+
+	{ // duplicated account
+		duplicated := append([]string{}, lines[:2]...)
+		duplicated = append(duplicated, lines[1:5]...)
+		_, err := readAll(strings.Join(duplicated, ""))
+		require.Equal(t, errors.InvalidStateSnapshot, errors.Cause(err))
+	}
+}
+
+func TestSnapshotFrozenRecords(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	// frozen account is created
+	linked := keypair.Random()
+	frozenTx := transaction.MakeTransactionCreateAccount(conf.NetworkID, linked, keypair.Random().Address(), common.Unit)
+	frozenTx.B.Operations[0].B = operation.NewCreateAccount(keypair.Random().Address(), common.Unit, linked.Address())
+	frozenTx.Sign(linked, conf.NetworkID)
+
+	blk := TestMakeNewBlockWithPrevBlock(GetLatestBlock(st), []string{frozenTx.GetHash()})
+	blk.MustSave(st)
+	bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, frozenTx)
+	bt.MustSave(st)
+	require.NoError(t, bt.SaveBlockOperations(st))
+
+	root, accounts, err := GetStateRoot(st)
+	require.NoError(t, err)
+	recordsRoot, records, err := GetRecordsRoot(st)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), records) // 2 indexes, operation and transaction
+
+	header := SnapshotHeader{
+		Version:     SnapshotVersion,
+		Block:       GetLatestBlock(st),
+		StateRoot:   root,
+		Accounts:    accounts,
+		RecordsRoot: recordsRoot,
+		Records:     records,
+	}
+	header.Sign(keypair.Random(), conf.NetworkID)
+
+	b := new(bytes.Buffer)
+	require.NoError(t, WriteSnapshot(st, b, header))
+
+	readRecords := func(s string) (read []SnapshotRecord, err error) {
+		var sr *SnapshotReader
+		if sr, err = NewSnapshotReader(strings.NewReader(s)); err != nil {
+			return
+		}
+		for {
+			if _, err = sr.Next(); err == io.EOF {
+				break
+			} else if err != nil {
+				return
+			}
+		}
+		for {
+			var record SnapshotRecord
+			if record, err = sr.NextRecord(); err == io.EOF {
+				return read, nil
+			} else if err != nil {
+				return
+			}
+			read = append(read, record)
+		}
+	}
+
+	read, err := readRecords(b.String())
+	require.NoError(t, err)
+	require.Equal(t, 4, len(read))
+
+	{ // modified record
+		lines := strings.SplitAfter(b.String(), "\n")
+		modified := append([]string{}, lines...)
+		modified[len(lines)-2] = `{"key":"a2V5","value":"dmFsdWU="}` + "\n"
+
+		_, err := readRecords(strings.Join(modified, ""))
+		require.Equal(t, errors.StateRootMismatch, errors.Cause(err))
+	}
+
+	// the frozen account is found after restoring the records
+	dst := InitTestBlockchain()
+	defer dst.Close()
+	for _, record := range read {
+		require.NoError(t, record.Save(dst))
+	}
+
+	iterFunc, closeFunc := GetBlockOperationsByFrozen(dst, nil)
+	bo, hasNext, _ := iterFunc()
+	closeFunc()
+	require.True(t, hasNext)
+	require.Equal(t, frozenTx.GetHash(), bo.TxHash)
+
+	iterFunc, closeFunc = GetBlockOperationsByLinked(dst, linked.Address(), nil)
+	bo, hasNext, _ = iterFunc()
+	closeFunc()
+	require.True(t, hasNext)
+	require.Equal(t, frozenTx.GetHash(), bo.TxHash)
+
+	_, err = GetBlockTransaction(dst, frozenTx.GetHash())
+	require.NoError(t, err)
+}
@@ -103,6 +103,32 @@ func (bt BlockTransaction) NewBlockTransactionKeyByBlock(hash string) string {
 	)
 }
 
+// NewBlockTransactionKeyByTarget makes the key of the index, which finds
+// the transactions received by the account; the key does not have unique
+// id, so the transaction, which has multiple payments to the same account is
+// indexed only once.
+func (bt BlockTransaction) NewBlockTransactionKeyByTarget(target string) string {
+	return fmt.Sprintf(
+		"%s%s%s%s",
+		GetBlockTransactionKeyPrefixTarget(target),
+		common.EncodeUint64ToByteSlice(bt.blockHeight),
+		common.EncodeUint64ToByteSlice(bt.SequenceID),
+		bt.Hash,
+	)
+}
+
+// NewBlockTransactionKeyByCounterparty makes the key of the index, which
+// finds the transactions between the account and the counterparty.
+func (bt BlockTransaction) NewBlockTransactionKeyByCounterparty(accountAddress, counterparty string) string {
+	return fmt.Sprintf(
+		"%s%s%s%s",
+		GetBlockTransactionKeyPrefixCounterparty(accountAddress, counterparty),
+		common.EncodeUint64ToByteSlice(bt.blockHeight),
+		common.EncodeUint64ToByteSlice(bt.SequenceID),
+		bt.Hash,
+	)
+}
+
 func (bt *BlockTransaction) Save(st *storage.LevelDBBackend) (err error) {
 	if bt.isSaved {
 		return errors.AlreadySaved
@@ -210,6 +236,33 @@ func (bt *BlockTransaction) SaveBlockOperation(st *storage.LevelDBBackend, op op
 		if err != nil {
 			return
 		}
+		if err = bt.savePaymentIndexes(st, pop.TargetAddress()); err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// savePaymentIndexes stores the target and counterparty indexes of the
+// payment to target; the existing indexes are skipped.
+func (bt *BlockTransaction) savePaymentIndexes(st *storage.LevelDBBackend, target string) (err error) {
+	keys := []string{
+		bt.NewBlockTransactionKeyByTarget(target),
+		bt.NewBlockTransactionKeyByCounterparty(bt.Source, target),
+		bt.NewBlockTransactionKeyByCounterparty(target, bt.Source),
+	}
+
+	var exists bool
+	for _, key := range keys {
+		if exists, err = st.Has(key); err != nil {
+			return
+		} else if exists {
+			continue
+		}
+		if err = st.New(key, bt.Hash); err != nil {
+			return
+		}
 	}
 
 	return nil
@@ -241,6 +294,14 @@ func GetBlockTransactionKeyPrefixAccount(accountAddress string) string {
 	return fmt.Sprintf("%s%s-", common.BlockTransactionPrefixAccount, accountAddress)
 }
 
+func GetBlockTransactionKeyPrefixTarget(target string) string {
+	return fmt.Sprintf("%s%s-", common.BlockTransactionPrefixTarget, target)
+}
+
+func GetBlockTransactionKeyPrefixCounterparty(accountAddress, counterparty string) string {
+	return fmt.Sprintf("%s%s-%s-", common.BlockTransactionPrefixCounterparty, accountAddress, counterparty)
+}
+
 func GetBlockTransactionKeyPrefixBlock(hash string) string {
 	return fmt.Sprintf("%s%s-", common.BlockTransactionPrefixBlock, hash)
 }
@@ -249,8 +310,15 @@ func GetBlockTransactionKey(hash string) string {
 	return fmt.Sprintf("%s%s", common.BlockTransactionPrefixHash, hash)
 }
 
+// GetBlockTransaction returns `errors.DataPruned` if the `BlockTransaction`
+// was pruned.
 func GetBlockTransaction(st *storage.LevelDBBackend, hash string) (bt BlockTransaction, err error) {
-	if err = st.Get(GetBlockTransactionKey(hash), &bt); err != nil {
+	if err = st.Get(GetBlockTransactionKey(hash), &bt); err == errors.StorageRecordDoesNotExist {
+		if pruned, _ := IsPrunedBlockTransaction(st, hash); pruned {
+			err = errors.DataPruned
+		}
+		return
+	} else if err != nil {
 		return
 	}
 
@@ -317,6 +385,22 @@ func GetBlockTransactionsByAccount(st *storage.LevelDBBackend, accountAddress st
 	return LoadBlockTransactionsInsideIterator(st, iterFunc, closeFunc)
 }
 
+func GetBlockTransactionsByTarget(st *storage.LevelDBBackend, target string, options storage.ListOptions) (
+	func() (BlockTransaction, bool, []byte),
+	func(),
+) {
+	iterFunc, closeFunc := st.GetIterator(GetBlockTransactionKeyPrefixTarget(target), options)
+	return LoadBlockTransactionsInsideIterator(st, iterFunc, closeFunc)
+}
+
+func GetBlockTransactionsByCounterparty(st *storage.LevelDBBackend, accountAddress, counterparty string, options storage.ListOptions) (
+	func() (BlockTransaction, bool, []byte),
+	func(),
+) {
+	iterFunc, closeFunc := st.GetIterator(GetBlockTransactionKeyPrefixCounterparty(accountAddress, counterparty), options)
+	return LoadBlockTransactionsInsideIterator(st, iterFunc, closeFunc)
+}
+
 func GetBlockTransactionsByBlock(st *storage.LevelDBBackend, hash string, options storage.ListOptions) (
 	func() (BlockTransaction, bool, []byte),
 	func(),
@@ -2,6 +2,7 @@ package block
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -512,3 +513,91 @@ func makeNewBlockTransaction(networkID []byte, n int) BlockTransaction {
 	block := TestMakeNewBlock([]string{tx.GetHash()})
 	return NewBlockTransactionFromTransaction(block.Hash, block.Height, block.ProposedTime, tx)
 }
+
+func TestBlockTransactionsByTargetAndCounterparty(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	source := keypair.Random()
+	targetA := keypair.Random()
+	targetB := keypair.Random()
+
+	var blocks []Block
+	for i, target := range []*keypair.Full{targetA, targetB, targetA} {
+		// the second payment to the same target does not make new index
+		tx := transaction.TestMakeTransactionWithKeypair(conf.NetworkID, i+1, source, target)
+
+		blk := TestMakeNewBlockWithPrevBlock(GetLatestBlock(st), []string{tx.GetHash()})
+		blk.MustSave(st)
+		bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+		bt.MustSave(st)
+		require.NoError(t, bt.SaveBlockOperations(st))
+
+		blocks = append(blocks, blk)
+	}
+
+	collect := func(iterFunc func() (BlockTransaction, bool, []byte), closeFunc func()) (heights []uint64) {
+		defer closeFunc()
+		for {
+			bt, hasNext, _ := iterFunc()
+			if !hasNext {
+				break
+			}
+			blk, err := GetBlock(st, bt.Block)
+			require.NoError(t, err)
+			heights = append(heights, blk.Height)
+		}
+		return
+	}
+
+	require.Equal(
+		t,
+		[]uint64{blocks[0].Height, blocks[2].Height},
+		collect(GetBlockTransactionsByTarget(st, targetA.Address(), nil)),
+	)
+	require.Empty(t, collect(GetBlockTransactionsByTarget(st, source.Address(), nil)))
+	require.Equal(
+		t,
+		[]uint64{blocks[1].Height},
+		collect(GetBlockTransactionsByCounterparty(st, source.Address(), targetB.Address(), nil)),
+	)
+	require.Equal(
+		t,
+		[]uint64{blocks[0].Height, blocks[2].Height},
+		collect(GetBlockTransactionsByCounterparty(st, targetA.Address(), source.Address(), nil)),
+	)
+
+	{ // height range
+		options := NewHeightRangeListOptions(storage.NewDefaultListOptions(false, nil, 0), blocks[1].Height, blocks[2].Height)
+		require.Equal(
+			t,
+			[]uint64{blocks[1].Height, blocks[2].Height},
+			collect(GetBlockTransactionsBySource(st, source.Address(), options)),
+		)
+
+		options = NewHeightRangeListOptions(storage.NewDefaultListOptions(true, nil, 0), 0, blocks[1].Height)
+		require.Equal(
+			t,
+			[]uint64{blocks[0].Height},
+			collect(GetBlockTransactionsByTarget(st, targetA.Address(), options)),
+		)
+	}
+
+	{ // block height by time
+		for _, blk := range blocks {
+			proposed, err := common.ParseISO8601(blk.ProposedTime)
+			require.NoError(t, err)
+			height, err := GetBlockHeightByTime(st, proposed)
+			require.NoError(t, err)
+			require.Equal(t, blk.Height, height)
+		}
+
+		genesis := GetGenesis(st)
+		proposed, err := common.ParseISO8601(genesis.ProposedTime)
+		require.NoError(t, err)
+		height, err := GetBlockHeightByTime(st, proposed.Add(-time.Second))
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), height)
+	}
+}
@@ -0,0 +1,571 @@
+package block
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// Divergence is the inconsistency of storage found by `Verify`. `Key` is the
+// storage key of the missing or wrong record.
+type Divergence struct {
+	Height   uint64 `json:"height"`
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+	Repaired bool   `json:"repaired"`
+}
+
+func (d Divergence) String() string {
+	var repaired string
+	if d.Repaired {
+		repaired = " (repaired)"
+	}
+
+	return fmt.Sprintf("height=%d key=%q: %s%s", d.Height, d.Key, d.Message, repaired)
+}
+
+type VerifyResult struct {
+	Blocks       uint64       `json:"blocks"`
+	Transactions uint64       `json:"transactions"`
+	Operations   uint64       `json:"operations"`
+	Accounts     uint64       `json:"accounts"`
+	Divergences  []Divergence `json:"divergences"`
+}
+
+// verifier walks the blocks by height and replays the transactions to
+// recompute the accounts.
+type verifier struct {
+	st     *storage.LevelDBBackend
+	repair bool
+	result *VerifyResult
+
+	accounts map[string]*BlockAccount // recomputed accounts
+	height   uint64                   // height of the block in checking
+}
+
+// Verify checks every block from genesis is linked by `PrevBlockHash`, has
+// the right `TransactionsRoot` and hash, and the indexes of blocks,
+// `BlockTransaction`s and `BlockOperation`s exist. The balance and sequence
+// id of every account are recomputed from the operations and compared with
+// the stored accounts.
+//
+// With `repair`, the missing indexes, `BlockTransaction`s and
+// `BlockOperation`s, which can be derived from the blocks and
+// `TransactionPool`, are stored again; the accounts are never modified.
+//
+// NOTE The operations of `ProposerTransaction` are not checked, because the
+// consensus does not store them as `BlockOperation`.
+func Verify(st *storage.LevelDBBackend, repair bool) (result *VerifyResult, err error) {
+	// the accounts can not be recomputed without the pruned transactions
+	var pruned uint64
+	if pruned, err = GetPrunedBlockHeight(st); err != nil {
+		return
+	} else if pruned > 0 {
+		err = errors.Wrapf(errors.DataPruned, "blocks are pruned until %d", pruned)
+		return
+	}
+
+	v := &verifier{
+		st:       st,
+		repair:   repair,
+		result:   &VerifyResult{Divergences: []Divergence{}},
+		accounts: map[string]*BlockAccount{},
+	}
+
+	if err = v.verifyBlocks(); err != nil {
+		return
+	}
+	if err = v.verifyAccounts(); err != nil {
+		return
+	}
+
+	return v.result, nil
+}
+
+func (v *verifier) diverge(key, format string, args ...interface{}) *Divergence {
+	v.result.Divergences = append(v.result.Divergences, Divergence{
+		Height:  v.height,
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+
+	return &v.result.Divergences[len(v.result.Divergences)-1]
+}
+
+// repairWith stores the value with the key, if `repair` is enabled.
+func (v *verifier) repairWith(d *Divergence, key string, value interface{}) error {
+	if !v.repair {
+		return nil
+	}
+	if err := v.st.New(key, value); err != nil {
+		return err
+	}
+	d.Repaired = true
+
+	return nil
+}
+
+// checkIndex checks the index, whose key starts with prefix, has the value;
+// if not found, the new index by `newKey` is stored in repair.
+func (v *verifier) checkIndex(prefix, value string, newKey func() string, name string) error {
+	iterFunc, closeFunc := v.st.GetIterator(prefix, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		var s string
+		if err := json.Unmarshal(item.Value, &s); err == nil && s == value {
+			return nil
+		}
+	}
+
+	key := newKey()
+	d := v.diverge(key, "%s index of %s is missing", name, value)
+
+	return v.repairWith(d, key, value)
+}
+
+// checkRecord checks the record exists; if not, the record is stored in
+// repair.
+func (v *verifier) checkRecord(key string, value interface{}, name string) (exists bool, err error) {
+	if exists, err = v.st.Has(key); err != nil || exists {
+		return
+	}
+
+	d := v.diverge(key, "%s is missing", name)
+	err = v.repairWith(d, key, value)
+
+	return
+}
+
+func (v *verifier) latestHeight() (uint64, error) {
+	if exists, err := ExistsBlockByHeight(v.st, common.GenesisBlockHeight); err != nil {
+		return 0, err
+	} else if !exists {
+		return 0, errors.BlockNotFound
+	}
+
+	return GetLatestBlock(v.st).Height, nil
+}
+
+// findBlockByHeight finds the block through the confirmed index, when the
+// height index is missing.
+func (v *verifier) findBlockByHeight(height uint64) (blk Block, found bool) {
+	iterFunc, closeFunc := GetBlocksByConfirmed(v.st, storage.NewDefaultListOptions(false, nil, 0))
+	defer closeFunc()
+
+	for {
+		b, hasNext, _ := iterFunc()
+		if !hasNext {
+			return
+		}
+		if b.Height == height {
+			return b, true
+		}
+	}
+}
+
+func (v *verifier) verifyBlocks() error {
+	latest, err := v.latestHeight()
+	if err != nil {
+		return err
+	}
+
+	var prev Block
+	for v.height = common.GenesisBlockHeight; v.height <= latest; v.height++ {
+		var blk Block
+
+		key := getBlockKeyPrefixHeight(v.height)
+		var hash string
+		if err = v.st.Get(key, &hash); err == errors.StorageRecordDoesNotExist {
+			var found bool
+			if blk, found = v.findBlockByHeight(v.height); !found {
+				v.diverge(key, "block is missing")
+				return nil
+			}
+			d := v.diverge(key, "height index of %s is missing", blk.Hash)
+			if err = v.repairWith(d, key, blk.Hash); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else if blk, err = GetBlock(v.st, hash); err == errors.StorageRecordDoesNotExist {
+			v.diverge(getBlockKey(hash), "block is missing")
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err = v.verifyBlock(blk, prev); err != nil {
+			return err
+		}
+
+		prev = blk
+		v.result.Blocks++
+	}
+
+	return nil
+}
+
+func (v *verifier) verifyBlock(blk, prev Block) (err error) {
+	key := getBlockKey(blk.Hash)
+
+	if blk.Height != v.height {
+		v.diverge(key, "height is %d", blk.Height)
+	}
+	if blk.PrevBlockHash != prev.Hash {
+		v.diverge(key, "previous block hash is %s, but %s", blk.PrevBlockHash, prev.Hash)
+	}
+	if root := getTransactionRoot(append([]string{blk.ProposerTransaction}, blk.Transactions...)); blk.TransactionsRoot != root {
+		v.diverge(key, "transactions root is %s, but %s", blk.TransactionsRoot, root)
+	}
+
+	b := blk
+	b.Hash = ""
+	b.Confirmed = ""
+	if hash := common.MustMakeObjectHashString(b); blk.Hash != hash {
+		v.diverge(key, "hash does not match with %s", hash)
+	}
+
+	err = v.checkIndex(
+		fmt.Sprintf("%s%s-%s", common.BlockPrefixConfirmed, blk.ProposedTime, common.EncodeUint64ToByteSlice(blk.Height)),
+		blk.Hash,
+		blk.NewBlockKeyConfirmed,
+		"confirmed",
+	)
+	if err != nil {
+		return
+	}
+
+	for _, hash := range blk.Transactions {
+		if err = v.verifyTransaction(blk, hash, false); err != nil {
+			return
+		}
+	}
+	if len(blk.ProposerTransaction) > 0 {
+		if err = v.verifyTransaction(blk, blk.ProposerTransaction, true); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (v *verifier) verifyTransaction(blk Block, hash string, isProposerTransaction bool) (err error) {
+	var tp TransactionPool
+	if tp, err = GetTransactionPool(v.st, hash); err == errors.StorageRecordDoesNotExist {
+		v.diverge(GetTransactionPoolKey(hash), "transaction is missing")
+		return nil
+	} else if err != nil {
+		return
+	}
+	tx := tp.Transaction()
+	v.result.Transactions++
+
+	bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+	if _, err = v.checkRecord(GetBlockTransactionKey(hash), bt, "BlockTransaction"); err != nil {
+		return
+	}
+
+	indexes := []struct {
+		name   string
+		prefix string
+		newKey func() string
+	}{
+		{"source", GetBlockTransactionKeyPrefixSource(bt.Source), bt.NewBlockTransactionKeySource},
+		{"confirmed", GetBlockTransactionKeyPrefixConfirmed(bt.Confirmed), bt.NewBlockTransactionKeyConfirmed},
+		{"account", GetBlockTransactionKeyPrefixAccount(bt.Source), func() string { return bt.NewBlockTransactionKeyByAccount(bt.Source) }},
+		{"block", GetBlockTransactionKeyPrefixBlock(bt.Block), func() string { return bt.NewBlockTransactionKeyByBlock(bt.Block) }},
+	}
+	for _, index := range indexes {
+		prefix := index.prefix
+		if index.name != "confirmed" {
+			prefix += fmt.Sprintf("%s%s", common.EncodeUint64ToByteSlice(bt.blockHeight), common.EncodeUint64ToByteSlice(bt.SequenceID))
+		}
+		if err = v.checkIndex(prefix, bt.Hash, index.newKey, "BlockTransaction "+index.name); err != nil {
+			return
+		}
+	}
+
+	if isProposerTransaction {
+		return v.replayProposerTransaction(tx)
+	}
+
+	for i, op := range tx.B.Operations {
+		if err = v.verifyOperation(bt, tx, op, i); err != nil {
+			return
+		}
+	}
+
+	if blk.Height == common.GenesisBlockHeight {
+		return v.replayGenesisTransaction(tx)
+	}
+
+	return v.replayTransaction(tx)
+}
+
+func (v *verifier) verifyOperation(bt BlockTransaction, tx transaction.Transaction, op operation.Operation, opIndex int) (err error) {
+	var bo BlockOperation
+	if bo, err = NewBlockOperationFromOperation(op, tx, bt.blockHeight, opIndex); err != nil {
+		return
+	}
+	v.result.Operations++
+
+	if _, err = v.checkRecord(key(bo.Hash), bo, "BlockOperation"); err != nil {
+		return
+	}
+
+	suffix := fmt.Sprintf("%s%s", common.EncodeUint64ToByteSlice(bo.Height), common.EncodeUint64ToByteSlice(bo.seqID))
+	type index struct {
+		name   string
+		prefix string
+		newKey func() string
+	}
+	indexes := []index{
+		{"tx", keyPrefixTxHash(bo.TxHash) + suffix, bo.NewBlockOperationTxHashKey},
+		{"source", keyPrefixSource(bo.Source) + suffix, bo.NewBlockOperationSourceKey},
+		{"source and type", keyPrefixSourceAndType(bo.Source, bo.Type) + suffix, bo.NewBlockOperationSourceAndTypeKey},
+		{"peers", keyPrefixPeers(bo.Source) + suffix, func() string { return bo.NewBlockOperationPeersKey(bo.Source) }},
+		{"peers and type", keyPrefixPeersAndType(bo.Source, bo.Type) + suffix, func() string { return bo.NewBlockOperationPeersAndTypeKey(bo.Source) }},
+		{"block height", keyPrefixBlockHeight(bo.Height) + fmt.Sprintf("%s", common.EncodeUint64ToByteSlice(bo.seqID)), bo.NewBlockOperationBlockHeightKey},
+	}
+	if bo.hasTarget() {
+		indexes = append(
+			indexes,
+			index{"target", keyPrefixTarget(bo.Target) + suffix, func() string { return bo.NewBlockOperationTargetKey(bo.Target) }},
+			index{"target and type", keyPrefixTargetAndType(bo.Target, bo.Type) + suffix, func() string { return bo.NewBlockOperationTargetAndTypeKey(bo.Target) }},
+			index{"peers", keyPrefixPeers(bo.Target) + suffix, func() string { return bo.NewBlockOperationPeersKey(bo.Target) }},
+			index{"peers and type", keyPrefixPeersAndType(bo.Target, bo.Type) + suffix, func() string { return bo.NewBlockOperationPeersAndTypeKey(bo.Target) }},
+			index{"counterparty", keyPrefixCounterparty(bo.Source, bo.Target) + suffix, func() string { return bo.NewBlockOperationCounterpartyKey(bo.Source, bo.Target) }},
+		)
+		if bo.Source != bo.Target {
+			indexes = append(
+				indexes,
+				index{"counterparty", keyPrefixCounterparty(bo.Target, bo.Source) + suffix, func() string { return bo.NewBlockOperationCounterpartyKey(bo.Target, bo.Source) }},
+			)
+		}
+	}
+	if pop, ok := op.B.(operation.Payable); ok {
+		target := pop.TargetAddress()
+		indexes = append(indexes, index{
+			"BlockTransaction account",
+			GetBlockTransactionKeyPrefixAccount(target) + fmt.Sprintf("%s%s", common.EncodeUint64ToByteSlice(bt.blockHeight), common.EncodeUint64ToByteSlice(bt.SequenceID)),
+			func() string { return bt.NewBlockTransactionKeyByAccount(target) },
+		})
+	}
+
+	for _, i := range indexes {
+		value := bo.Hash
+		name := "BlockOperation " + i.name
+		if i.name == "BlockTransaction account" {
+			value = bt.Hash
+			name = i.name
+		}
+		if err = v.checkIndex(i.prefix, value, i.newKey, name); err != nil {
+			return
+		}
+	}
+
+	if pop, ok := op.B.(operation.Payable); ok {
+		target := pop.TargetAddress()
+		keys := []string{
+			bt.NewBlockTransactionKeyByTarget(target),
+			bt.NewBlockTransactionKeyByCounterparty(bt.Source, target),
+		}
+		if bt.Source != target {
+			keys = append(keys, bt.NewBlockTransactionKeyByCounterparty(target, bt.Source))
+		}
+		for _, k := range keys {
+			if _, err = v.checkRecord(k, bt.Hash, "BlockTransaction payment index"); err != nil {
+				return
+			}
+		}
+	}
+
+	if bo.targetIsLinked() {
+		frozenKey := GetBlockOperationCreateFrozenKey(bo.Target, bo.Height)
+		if _, err = v.checkRecord(frozenKey, bo.Hash, "frozen account index"); err != nil {
+			return
+		}
+		if _, err = v.checkRecord(bo.NewBlockOperationFrozenLinkedKey(bo.linked), bo.Hash, "frozen linked index"); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (v *verifier) account(address string) (*BlockAccount, bool) {
+	ba, found := v.accounts[address]
+	if !found {
+		v.diverge(GetBlockAccountKey(address), "account does not exist in replay")
+	}
+
+	return ba, found
+}
+
+func (v *verifier) deposit(address string, amount common.Amount) {
+	if ba, found := v.account(address); found {
+		if err := ba.Deposit(amount); err != nil {
+			v.diverge(GetBlockAccountKey(address), "failed to deposit %v in replay: %v", amount, err)
+		}
+	}
+}
+
+// replayGenesisTransaction creates the accounts of genesis transaction;
+// the source of genesis transaction is not withdrawn.
+func (v *verifier) replayGenesisTransaction(tx transaction.Transaction) error {
+	for _, op := range tx.B.Operations {
+		if opb, ok := op.B.(operation.CreateAccount); ok {
+			v.accounts[opb.Target] = NewBlockAccount(opb.Target, opb.Amount)
+		}
+	}
+
+	return nil
+}
+
+// replayTransaction changes the accounts like `runner.FinishTransactions`.
+func (v *verifier) replayTransaction(tx transaction.Transaction) error {
+	for _, op := range tx.B.Operations {
+		switch opb := op.B.(type) {
+		case operation.CreateAccount:
+			if _, found := v.accounts[opb.Target]; found {
+				v.diverge(GetBlockAccountKey(opb.Target), "account is created again in replay")
+				continue
+			}
+			v.accounts[opb.Target] = NewBlockAccountLinked(opb.Target, opb.Amount, opb.Linked)
+		case operation.Payment:
+			v.deposit(opb.Target, opb.Amount)
+		case operation.InflationPF:
+			if opb.Amount > 0 {
+				v.deposit(opb.FundingAddress, opb.Amount)
+			}
+		case operation.ManageData:
+			if ba, found := v.account(tx.B.Source); found {
+				ba.ManageData(opb.Key, opb.Value)
+			}
+		}
+	}
+
+	if ba, found := v.account(tx.B.Source); found {
+		if err := ba.Withdraw(tx.TotalAmount(true)); err != nil {
+			v.diverge(GetBlockAccountKey(tx.B.Source), "failed to withdraw %v in replay: %v", tx.TotalAmount(true), err)
+		}
+		ba.IncreaseSequenceID()
+	}
+
+	return nil
+}
+
+// replayProposerTransaction deposits the collected fee and inflation like
+// `runner.ProcessProposerTransaction`.
+func (v *verifier) replayProposerTransaction(tx transaction.Transaction) error {
+	for _, op := range tx.B.Operations {
+		switch opb := op.B.(type) {
+		case operation.CollectTxFee:
+			if opb.Amount > 0 {
+				v.deposit(opb.Target, opb.Amount)
+			}
+		case operation.Inflation:
+			if opb.Amount > 0 {
+				v.deposit(opb.Target, opb.Amount)
+			}
+		}
+	}
+
+	return nil
+}
+
+func equalData(a, b []DataEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || !bytes.Equal(a[i].Value, b[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verifyAccounts compares the recomputed accounts with the stored accounts.
+func (v *verifier) verifyAccounts() (err error) {
+	v.height = 0
+
+	created := map[string]bool{}
+	iterFunc, closeFunc := GetBlockAccountAddressesByCreated(v.st, storage.NewDefaultListOptions(false, nil, 0))
+	for {
+		address, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		created[address] = true
+	}
+	closeFunc()
+
+	var addresses []string
+	for address := range v.accounts {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		expected := v.accounts[address]
+		key := GetBlockAccountKey(address)
+
+		var ba *BlockAccount
+		if ba, err = GetBlockAccount(v.st, address); err == errors.StorageRecordDoesNotExist {
+			v.diverge(key, "account is missing")
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+		v.result.Accounts++
+
+		if ba.Balance != expected.Balance {
+			v.diverge(key, "balance is %v, but %v", ba.Balance, expected.Balance)
+		}
+		if ba.SequenceID != expected.SequenceID {
+			v.diverge(key, "sequence id is %d, but %d", ba.SequenceID, expected.SequenceID)
+		}
+		if ba.Linked != expected.Linked {
+			v.diverge(key, "linked is %q, but %q", ba.Linked, expected.Linked)
+		}
+		if !equalData(ba.Data, expected.Data) {
+			v.diverge(key, "data entries are %d, but %d", len(ba.Data), len(expected.Data))
+		}
+
+		if !created[address] {
+			createdKey := GetBlockAccountCreatedKey(common.GetUniqueIDFromUUID())
+			d := v.diverge(createdKey, "created index of account %s is missing", address)
+			if err = v.repairWith(d, createdKey, address); err != nil {
+				return
+			}
+		}
+		delete(created, address)
+	}
+
+	var unknown []string
+	for address := range created {
+		unknown = append(unknown, address)
+	}
+	sort.Strings(unknown)
+	for _, address := range unknown {
+		v.diverge(GetBlockAccountKey(address), "account is not created by any operation")
+	}
+
+	return nil
+}
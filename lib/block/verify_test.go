@@ -0,0 +1,153 @@
+package block
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// makeVerifyTestBlock makes the block, which has the transaction creating new
+// account from genesis account, and stores it like the consensus.
+func makeVerifyTestBlock(t *testing.T, st *storage.LevelDBBackend) Block {
+	conf := common.NewTestConfig()
+	prev := GetLatestBlock(st)
+
+	genesisAccount, err := GetBlockAccount(st, GenesisKP.Address())
+	require.NoError(t, err)
+
+	target := keypair.Random().Address()
+	tx := transaction.MakeTransactionCreateAccount(conf.NetworkID, GenesisKP, target, common.BaseReserve)
+	tx.B.SequenceID = genesisAccount.SequenceID
+	tx.Sign(GenesisKP, conf.NetworkID)
+
+	blk := NewBlock(
+		keypair.Random().Address(),
+		voting.Basis{
+			Height:    prev.Height + 1,
+			BlockHash: prev.Hash,
+			TotalTxs:  prev.TotalTxs + 1,
+			TotalOps:  prev.TotalOps + 1,
+		},
+		"",
+		[]string{tx.GetHash()},
+		common.NowISO8601(),
+	)
+	blk.MustSave(st)
+
+	bt := NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+	bt.MustSave(st)
+	require.NoError(t, bt.SaveBlockOperations(st))
+	_, err = SaveTransactionPool(st, tx)
+	require.NoError(t, err)
+
+	NewBlockAccount(target, common.BaseReserve).MustSave(st)
+	require.NoError(t, genesisAccount.Withdraw(tx.TotalAmount(true)))
+	genesisAccount.IncreaseSequenceID()
+	genesisAccount.MustSave(st)
+
+	return *blk
+}
+
+func removeFirstKey(t *testing.T, st *storage.LevelDBBackend, prefix string) string {
+	iterFunc, closeFunc := st.GetIterator(prefix, nil)
+	item, hasNext := iterFunc()
+	closeFunc()
+	require.True(t, hasNext)
+	require.NoError(t, st.Remove(string(item.Key)))
+
+	return string(item.Key)
+}
+
+func TestVerify(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	blk := makeVerifyTestBlock(t, st)
+	makeVerifyTestBlock(t, st)
+
+	result, err := Verify(st, false)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), result.Blocks)
+	require.Equal(t, uint64(3), result.Transactions)
+	require.Equal(t, uint64(4), result.Operations)
+	require.Equal(t, uint64(4), result.Accounts)
+	require.Empty(t, result.Divergences)
+
+	// remove the derived indexes and records
+	bt, err := GetBlockTransaction(st, blk.Transactions[0])
+	require.NoError(t, err)
+	removeFirstKey(t, st, fmt.Sprintf("%s%s", GetBlockTransactionKeyPrefixSource(bt.Source), common.EncodeUint64ToByteSlice(blk.Height)))
+	removeFirstKey(t, st, getBlockKeyPrefixHeight(blk.Height))
+	removeFirstKey(t, st, key(bt.Operations[0]))
+
+	result, err = Verify(st, false)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(result.Divergences))
+	for _, d := range result.Divergences {
+		require.Equal(t, blk.Height, d.Height)
+		require.False(t, d.Repaired)
+	}
+
+	result, err = Verify(st, true)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(result.Divergences))
+	for _, d := range result.Divergences {
+		require.True(t, d.Repaired)
+	}
+
+	result, err = Verify(st, false)
+	require.NoError(t, err)
+	require.Empty(t, result.Divergences)
+
+	// the accounts are not repaired
+	commonAccount, err := GetBlockAccount(st, CommonKP.Address())
+	require.NoError(t, err)
+	require.NoError(t, commonAccount.Deposit(common.Amount(1)))
+	commonAccount.MustSave(st)
+
+	result, err = Verify(st, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Divergences))
+	require.Equal(t, GetBlockAccountKey(CommonKP.Address()), result.Divergences[0].Key)
+	require.False(t, result.Divergences[0].Repaired)
+
+	// the data entries are not set by any operation
+	require.NoError(t, commonAccount.Withdraw(common.Amount(1)))
+	commonAccount.ManageData("kyc", []byte("findme"))
+	commonAccount.MustSave(st)
+
+	result, err = Verify(st, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(result.Divergences))
+	require.Equal(t, GetBlockAccountKey(CommonKP.Address()), result.Divergences[0].Key)
+	require.Equal(t, "data entries are 1, but 0", result.Divergences[0].Message)
+}
+
+func TestVerifyBrokenLink(t *testing.T) {
+	st := InitTestBlockchain()
+	defer st.Close()
+
+	blk := makeVerifyTestBlock(t, st)
+
+	// the block of height 2 is replaced by the block, which does not link
+	// to genesis
+	require.NoError(t, st.Remove(getBlockKeyPrefixHeight(blk.Height)))
+	wrong := blk
+	wrong.PrevBlockHash = "wrong"
+	require.NoError(t, st.New(getBlockKeyPrefixHeight(blk.Height), "wrong-block"))
+	require.NoError(t, st.New(getBlockKey("wrong-block"), wrong))
+
+	result, err := Verify(st, false)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(result.Divergences))
+	require.Equal(t, "previous block hash is wrong, but "+GetGenesis(st).Hash, result.Divergences[0].Message)
+	require.Contains(t, result.Divergences[1].Message, "hash does not match")
+}
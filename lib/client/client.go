@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/observer"
@@ -22,12 +23,16 @@ const (
 	UrlAccount               = "/accounts/{id}"
 	UrlAccountOperations     = "/accounts/{id}/operations"
 	UrlAccountFrozenAccounts = "/accounts/{id}/frozen-accounts"
+	UrlAccountData           = "/accounts/{id}/data/{key}"
 	UrlFrozenAccounts        = "/frozen-accounts"
 	UrlTransactions          = "/transactions"
 	UrlTransactionByHash     = "/transactions/{id}"
 	UrlTransactionStatus     = "/transactions/{id}/status"
+	UrlTransactionsBatch     = "/transactions/batch"
 	UrlTransactionOperations = "/transactions/{id}/operations"
+	UrlLedger                = "/ledger"
 	UrlSubscribe             = "/subscribe"
+	UrlSubscribeWebSocket    = "/subscribe/ws"
 )
 
 type QueryKey string
@@ -41,6 +46,17 @@ const (
 	QueryOrder  QueryKey = "reverse"
 	QueryCursor QueryKey = "cursor"
 	QueryType   QueryKey = "type"
+	QueryHeight QueryKey = "height"
+
+	// The filters of the transactions and operations of account
+	QueryMinHeight    QueryKey = "min_height"
+	QueryMaxHeight    QueryKey = "max_height"
+	QuerySince        QueryKey = "since" // ISO8601 time
+	QueryUntil        QueryKey = "until" // ISO8601 time
+	QueryMinAmount    QueryKey = "min_amount"
+	QueryMaxAmount    QueryKey = "max_amount"
+	QueryCounterparty QueryKey = "counterparty"
+	QueryDirection    QueryKey = "direction" // "sent" or "received"
 )
 
 type Q struct {
@@ -65,7 +81,11 @@ func (qs Queries) toQueryString() string {
 			urlValues.Add(QueryCursor.String(), q.Value)
 		case QueryType:
 			urlValues.Add(QueryType.String(), q.Value)
-
+		case QueryHeight:
+			urlValues.Add(QueryHeight.String(), q.Value)
+		case QueryMinHeight, QueryMaxHeight, QuerySince, QueryUntil,
+			QueryMinAmount, QueryMaxAmount, QueryCounterparty, QueryDirection:
+			urlValues.Add(q.Key.String(), q.Value)
 		}
 	}
 	return "?" + urlValues.Encode()
@@ -187,6 +207,12 @@ func (c *Client) LoadAccount(id string, queries ...Q) (account Account, err erro
 	return
 }
 
+func (c *Client) LoadAccountData(id, key string) (data AccountData, err error) {
+	url := strings.NewReplacer("{id}", id, "{key}", key).Replace(UrlAccountData)
+	err = c.getResponse(url, http.Header{}, &data)
+	return
+}
+
 func (c *Client) LoadFrozenAccountsByLinked(id string, queries ...Q) (fPage FrozenAccountsPage, err error) {
 	url := strings.Replace(UrlAccountFrozenAccounts, "{id}", id, -1)
 	url += Queries(queries).toQueryString()
@@ -215,6 +241,15 @@ func (c *Client) LoadTransactionStatus(id string, queries ...Q) (transactionHist
 	return
 }
 
+// LoadLedger loads the ledger statistics of the latest block; the block can be
+// given by `QueryHeight`.
+func (c *Client) LoadLedger(queries ...Q) (ledger Ledger, err error) {
+	url := UrlLedger
+	url += Queries(queries).toQueryString()
+	err = c.getResponse(url, http.Header{}, &ledger)
+	return
+}
+
 func (c *Client) LoadTransactions(queries ...Q) (tPage TransactionsPage, err error) {
 	url := UrlTransactions
 	url += Queries(queries).toQueryString()
@@ -264,6 +299,43 @@ func (c *Client) SubmitTransaction(tx []byte) (pTransaction TransactionPost, err
 	return
 }
 
+// SubmitTransactions submits the transactions at once (via POST
+// `UrlTransactionsBatch`). The results are in the same order as `txs`; the
+// rejected transaction has `Error`, but the others are still submitted.
+//
+// Params:
+//     txs = JSON serialized Transactions
+//
+// Returns:
+//   []TransactionBatchResult = The result of each transaction
+//   error = An error object when the whole batch failed, or `nil`
+func (c *Client) SubmitTransactions(txs ...[]byte) (results []TransactionBatchResult, err error) {
+	items := make([]json.RawMessage, len(txs))
+	for i, tx := range txs {
+		items[i] = json.RawMessage(tx)
+	}
+
+	var body []byte
+	if body, err = json.Marshal(items); err != nil {
+		return
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+	resp, err := c.Post(UrlTransactionsBatch, body, headers)
+	if err != nil {
+		return
+	}
+
+	var batch TransactionsBatch
+	if err = c.ToResponse(resp, &batch); err != nil {
+		return
+	}
+	results = batch.Embedded.Records
+
+	return
+}
+
 // Submit a transaction to the node (via POST `UrlTransactions`)
 //
 // Params:
@@ -299,9 +371,68 @@ func (c *Client) SubmitTransactionAndWait(hash string, tx []byte) (pTransaction
 	return
 }
 
+// The waiting time before reconnecting the stream; it is doubled until
+// `StreamReconnectMax`.
+const (
+	StreamReconnectMin = 500 * time.Millisecond
+	StreamReconnectMax = 30 * time.Second
+)
+
+// stream reconnects when the connection is lost, and the node replays the
+// events after the last `event_id` received.
 func (c *Client) stream(ctx context.Context, url string, body []byte, handler func(data []byte) error) (err error) {
+	var lastEventID string
+	handlerFunc := func(line []byte) error {
+		var e map[string]json.RawMessage
+		if json.Unmarshal(line, &e) != nil {
+			return handler(line)
+		}
+
+		var id string
+		if json.Unmarshal(e["event_id"], &id) == nil && len(id) > 0 {
+			lastEventID = id
+		}
+
+		// the first message of the stream has only the cursor
+		if len(e) == 1 && len(id) > 0 {
+			return nil
+		}
+		return handler(line)
+	}
+
+	wait := StreamReconnectMin
+	for {
+		var connected bool
+		connected, err = c.streamOnce(ctx, url, body, lastEventID, handlerFunc)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if e, ok := err.(Error); ok && e.Problem.Status < http.StatusInternalServerError {
+			return err
+		}
+		if connected {
+			wait = StreamReconnectMin
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		if wait *= 2; wait > StreamReconnectMax {
+			wait = StreamReconnectMax
+		}
+	}
+}
+
+func (c *Client) streamOnce(ctx context.Context, url string, body []byte, lastEventID string, handler func(data []byte) error) (connected bool, err error) {
 	var headers = http.Header{}
 	headers.Set("Accept", "text/event-stream")
+	if len(lastEventID) > 0 {
+		headers.Set("Last-Event-ID", lastEventID)
+	}
+
 	var resp *http.Response
 	if body != nil {
 		resp, err = c.Post(url, body, headers)
@@ -309,46 +440,51 @@ func (c *Client) stream(ctx context.Context, url string, body []byte, handler fu
 		resp, err = c.Get(url, headers)
 	}
 	if err != nil {
-		return err
+		return
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		err = c.ToResponse(resp, nil)
+		return
+	}
+	connected = true
+
 	reader := bufio.NewReader(resp.Body)
 
 	readChan := make(chan []byte)
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
 
 	go func() {
 		for {
 			line, err := reader.ReadBytes('\n')
 			if err != nil {
 				errChan <- err
-				break
+				return
+			}
+			select {
+			case readChan <- line:
+			case <-done:
+				return
 			}
-			readChan <- line
 		}
 	}()
 
 	for {
-		breakFor := false
 		select {
 		case <-ctx.Done():
-			resp.Body.Close()
-			breakFor = true
+			return
 		case err = <-errChan:
-			breakFor = true
+			return
 		case line := <-readChan:
 			if len(line) == 0 {
 				continue
 			}
 			handler(line)
 		}
-		if breakFor {
-			break
-		}
 	}
-
-	return
 }
 
 //
@@ -415,6 +551,109 @@ func (c *Client) StreamTransactions(ctx context.Context, handler func(Transactio
 	return c.stream(ctx, UrlSubscribe, body, handlerFunc)
 }
 
+//
+// Stream the confirmed blocks from the node
+//
+// Params:
+//     ctx = Context to use. The streaming starts a goroutine and doesn't stop.
+//           A common pattern is to pass `context.WithCancel(context.Background())`.
+//           See go's `context` package for more details.
+//     handler   = The handler function that will be called every time a block is confirmed.
+//     proposers = An (optional) list of proposer addresses to listen to.
+//                 If `nil`, all blocks will be streamed to the handler.
+//
+// Returns: An `error` object, or `nil`
+func (c *Client) StreamBlocks(ctx context.Context, handler func(Block), proposers ...string) error {
+	var conds []observer.Conditions
+	for _, proposer := range proposers {
+		conds = append(conds, observer.Conditions{observer.NewCondition(observer.Block, observer.Proposer, proposer)})
+	}
+	if len(conds) == 0 {
+		conds = []observer.Conditions{{observer.NewCondition(observer.Block, observer.All)}}
+	}
+	body, err := json.Marshal(conds)
+	if err != nil {
+		return err
+	}
+	handlerFunc := func(b []byte) error {
+		var v Block
+		err := json.Unmarshal(b, &v)
+		if err != nil {
+			return err
+		}
+		handler(v)
+		return nil
+	}
+	return c.stream(ctx, UrlSubscribe, body, handlerFunc)
+}
+
+//
+// Stream the changes of the frozen accounts from the node
+//
+// Params:
+//     ctx = Context to use. The streaming starts a goroutine and doesn't stop.
+//           A common pattern is to pass `context.WithCancel(context.Background())`.
+//           See go's `context` package for more details.
+//     handler = The handler function that will be called every time a frozen account is
+//               created, requests unfreezing, is unfrozen or returns the amount.
+//     linked  = An (optional) list of linked addresses to listen to.
+//               If `nil`, all frozen accounts will be streamed to the handler.
+//
+// Returns: An `error` object, or `nil`
+func (c *Client) StreamFrozenAccounts(ctx context.Context, handler func(FrozenAccount), linked ...string) error {
+	var conds []observer.Conditions
+	for _, address := range linked {
+		conds = append(conds, observer.Conditions{observer.NewCondition(observer.FrozenAccount, observer.Linked, address)})
+	}
+	if len(conds) == 0 {
+		conds = []observer.Conditions{{observer.NewCondition(observer.FrozenAccount, observer.All)}}
+	}
+	body, err := json.Marshal(conds)
+	if err != nil {
+		return err
+	}
+	handlerFunc := func(b []byte) error {
+		var v FrozenAccount
+		err := json.Unmarshal(b, &v)
+		if err != nil {
+			return err
+		}
+		handler(v)
+		return nil
+	}
+	return c.stream(ctx, UrlSubscribe, body, handlerFunc)
+}
+
+//
+// Stream the consensus state of the node
+//
+// Params:
+//     ctx = Context to use. The streaming starts a goroutine and doesn't stop.
+//           A common pattern is to pass `context.WithCancel(context.Background())`.
+//           See go's `context` package for more details.
+//     handler = The handler function that will be called every time the node moves to
+//               the new height, round or ballot state. The states are not replayed after
+//               reconnecting.
+//
+// Returns: An `error` object, or `nil`
+func (c *Client) StreamConsensusState(ctx context.Context, handler func(ConsensusState)) error {
+	conds := []observer.Conditions{{observer.NewCondition(observer.ConsensusState, observer.All)}}
+	body, err := json.Marshal(conds)
+	if err != nil {
+		return err
+	}
+	handlerFunc := func(b []byte) error {
+		var v ConsensusState
+		err := json.Unmarshal(b, &v)
+		if err != nil {
+			return err
+		}
+		handler(v)
+		return nil
+	}
+	return c.stream(ctx, UrlSubscribe, body, handlerFunc)
+}
+
 func (c *Client) StreamTransactionsByAccount(ctx context.Context, id string, handler func(Transaction)) (err error) {
 	s := []observer.Conditions{{observer.NewCondition(observer.Tx, observer.Source, id), observer.NewCondition(observer.Tx, observer.Target, id)}}
 	b, err := json.Marshal(s)
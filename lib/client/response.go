@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
 )
 
@@ -21,12 +22,27 @@ type Account struct {
 		Self         Link `json:"self"`
 		Transactions Link `json:"transactions"`
 		Operations   Link `json:"operations"`
+		Data         Link `json:"data"`
 	} `json:"_links"`
 
-	Address    string `json:"address"`
-	SequenceID uint64 `json:"sequence_id"`
-	Balance    string `json:"balance"`
-	Linked     string `json:"linked"`
+	Address    string            `json:"address"`
+	SequenceID uint64            `json:"sequence_id"`
+	Balance    string            `json:"balance"`
+	Linked     string            `json:"linked"`
+	Data       map[string][]byte `json:"data"`
+	// Set only in stream; see `Client.StreamAccount`
+	EventID string `json:"event_id,omitempty"`
+}
+
+type AccountData struct {
+	Links struct {
+		Self    Link `json:"self"`
+		Account Link `json:"account"`
+	} `json:"_links"`
+
+	Address string `json:"address"`
+	Key     string `json:"key"`
+	Value   []byte `json:"value"`
 }
 
 type FrozenAccount struct {
@@ -45,6 +61,8 @@ type FrozenAccount struct {
 	UnfreezingOpHash           string                      `json:"unfreezing_op_hash"`
 	UnfreezingRemainingBlockes uint64                      `json:"unfreezing_remaining_blockheight"`
 	PaymentOpHash              string                      `json:"payment_op_hash"`
+	// Set only in stream; see `Client.StreamFrozenAccounts`
+	EventID string `json:"event_id,omitempty"`
 }
 
 type FrozenAccountsPage struct {
@@ -73,6 +91,48 @@ type Transaction struct {
 	SequenceID     uint64 `json:"sequence_id"`
 	Created        string `json:"created"`
 	OperationCount uint64 `json:"operation_count"`
+	// Set only in stream; see `Client.StreamTransactions`
+	EventID string `json:"event_id,omitempty"`
+}
+
+type Block struct {
+	Links struct {
+		Self Link `json:"self"`
+	} `json:"_links"`
+	Version             uint32   `json:"version"`
+	Hash                string   `json:"hash"`
+	Height              uint64   `json:"height"`
+	PrevBlockHash       string   `json:"prev_block_hash"`
+	TransactionsRoot    string   `json:"transactions_root"`
+	Confirmed           string   `json:"confirmed"`
+	Proposer            string   `json:"proposer"`
+	ProposedTime        string   `json:"proposed_time"`
+	ProposerTransaction string   `json:"proposer_transaction"`
+	Round               uint64   `json:"round"`
+	Transactions        []string `json:"transactions"`
+	// Set only in stream; see `Client.StreamBlocks`
+	EventID string `json:"event_id,omitempty"`
+}
+
+type Ledger struct {
+	Links struct {
+		Self Link `json:"self"`
+	} `json:"_links"`
+	Height         uint64 `json:"height"`
+	Block          string `json:"block"`
+	TotalSupply    uint64 `json:"total_supply,string"`
+	FrozenAmount   uint64 `json:"frozen_amount,string"`
+	Accounts       uint64 `json:"accounts"`
+	FrozenAccounts uint64 `json:"frozen_accounts"`
+	Inflation      uint64 `json:"inflation,string"`
+	FeesCollected  uint64 `json:"fees_collected,string"`
+	Since          uint64 `json:"since"`
+}
+
+type ConsensusState struct {
+	Height      uint64 `json:"height"`
+	Round       uint64 `json:"round"`
+	BallotState string `json:"ballot_state"`
 }
 
 type TransactionPost struct {
@@ -85,6 +145,25 @@ type TransactionPost struct {
 	Message interface{} `json:"message"`
 }
 
+// TransactionBatchResult is the result of the transaction submitted by
+// `Client.SubmitTransactions`; `Status` is "submitted" or "rejected" with
+// `Error`.
+type TransactionBatchResult struct {
+	Links struct {
+		Self Link `json:"self"`
+	} `json:"_links"`
+	Hash    string        `json:"hash"`
+	Status  string        `json:"status"`
+	Message interface{}   `json:"message,omitempty"`
+	Error   *errors.Error `json:"error,omitempty"`
+}
+
+type TransactionsBatch struct {
+	Embedded struct {
+		Records []TransactionBatchResult `json:"records"`
+	} `json:"_embedded"`
+}
+
 type TransactionStatus struct {
 	Links struct {
 		Self        Link `json:"self"`
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	neturl "net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"boscoin.io/sebak/lib/common/observer"
+)
+
+const (
+	// Connection is regarded as lost, when nothing is received during this
+	// period; the node sends heartbeat every 30 seconds.
+	WebSocketHeartbeatTimeout = 90 * time.Second
+	// The first waiting time before reconnecting; it is doubled until
+	// `WebSocketReconnectMax`.
+	WebSocketReconnectMin = 500 * time.Millisecond
+	WebSocketReconnectMax = 30 * time.Second
+)
+
+// WebSocketStream streams the events of the node through websocket.
+// Subscriptions can be changed without reconnecting and they are subscribed
+// again after reconnecting.
+type WebSocketStream struct {
+	sync.Mutex
+
+	client  *Client
+	handler func(observer.Message)
+	subs    map[string]observer.Conditions
+	ws      *websocket.Conn
+
+	// What the node does with the slow client, `drop` or `close`; empty
+	// uses the node default.
+	Overflow         string
+	HeartbeatTimeout time.Duration
+	ReconnectMin     time.Duration
+	ReconnectMax     time.Duration
+}
+
+// Create a new WebSocketStream
+//
+// Params:
+//
+//	handler = The handler function that will be called with every message
+//	          but heartbeat; `observer.MessageEvent` has the resource in
+//	          `Data`, like `Transaction` or `Account`.
+//
+// Returns: A `WebSocketStream`, which starts streaming by `Run`
+func (c *Client) NewWebSocketStream(handler func(observer.Message)) *WebSocketStream {
+	return &WebSocketStream{
+		client:           c,
+		handler:          handler,
+		subs:             map[string]observer.Conditions{},
+		HeartbeatTimeout: WebSocketHeartbeatTimeout,
+		ReconnectMin:     WebSocketReconnectMin,
+		ReconnectMax:     WebSocketReconnectMax,
+	}
+}
+
+// Subscribe the events of the conditions
+//
+// Params:
+//
+//	id         = The identifier of the subscription; the events have it.
+//	conditions = The conditions to listen to; any of them triggers the event.
+//
+// Returns: An `error` object, or `nil`. Even if it fails to send, the
+//
+//	subscription is kept and subscribed after reconnecting.
+func (s *WebSocketStream) Subscribe(id string, conditions observer.Conditions) error {
+	s.Lock()
+	s.subs[id] = conditions
+	ws := s.ws
+	s.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+	return websocket.JSON.Send(ws, observer.Message{Type: observer.MessageSubscribe, ID: id, Conditions: conditions})
+}
+
+// Stop the subscription of id
+func (s *WebSocketStream) Unsubscribe(id string) error {
+	s.Lock()
+	delete(s.subs, id)
+	ws := s.ws
+	s.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+	return websocket.JSON.Send(ws, observer.Message{Type: observer.MessageUnsubscribe, ID: id})
+}
+
+// Connect to the node and stream the messages to the handler
+//
+// Params:
+//
+//	ctx = Context to use. `Run` reconnects whenever the connection is lost,
+//	      until `ctx` is done.
+//
+// Returns: The `error` of `ctx`
+func (s *WebSocketStream) Run(ctx context.Context) error {
+	wait := s.ReconnectMin
+	for {
+		if connected, _ := s.connect(ctx); connected {
+			wait = s.ReconnectMin
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		if wait *= 2; wait > s.ReconnectMax {
+			wait = s.ReconnectMax
+		}
+	}
+}
+
+func (s *WebSocketStream) url() (string, error) {
+	u, err := neturl.Parse(s.client.URL + UrlPrefixForAPIV1 + UrlSubscribeWebSocket)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	}
+	if len(s.Overflow) > 0 {
+		u.RawQuery = neturl.Values{"overflow": []string{s.Overflow}}.Encode()
+	}
+
+	return u.String(), nil
+}
+
+func (s *WebSocketStream) connect(ctx context.Context) (connected bool, err error) {
+	var location string
+	if location, err = s.url(); err != nil {
+		return
+	}
+
+	var config *websocket.Config
+	if config, err = websocket.NewConfig(location, s.client.URL); err != nil {
+		return
+	}
+	config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	config.Dialer = &net.Dialer{Timeout: 3 * time.Second}
+
+	var ws *websocket.Conn
+	if ws, err = websocket.DialConfig(config); err != nil {
+		return
+	}
+	connected = true
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		ws.Close()
+	}()
+
+	s.Lock()
+	s.ws = ws
+	for id, conditions := range s.subs {
+		m := observer.Message{Type: observer.MessageSubscribe, ID: id, Conditions: conditions}
+		if err = websocket.JSON.Send(ws, m); err != nil {
+			break
+		}
+	}
+	s.Unlock()
+
+	defer func() {
+		s.Lock()
+		s.ws = nil
+		s.Unlock()
+	}()
+
+	if err != nil {
+		return
+	}
+
+	for {
+		ws.SetReadDeadline(time.Now().Add(s.HeartbeatTimeout))
+
+		var m observer.Message
+		if err = websocket.JSON.Receive(ws, &m); err != nil {
+			return
+		}
+		if m.Type == observer.MessageHeartbeat {
+			continue
+		}
+		s.handler(m)
+	}
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Checkpoint is the hash of block at the height, which is trusted by the
+// operator.
+type Checkpoint struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// Checkpoints is the list of `Checkpoint` ordered by height.
+type Checkpoints []Checkpoint
+
+// ParseCheckpoints parses the comma separated checkpoints like
+// "<height>:<hash>,<height>:<hash>".
+func ParseCheckpoints(s string) (Checkpoints, error) {
+	var cps Checkpoints
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 1 {
+			continue
+		}
+
+		parsed := strings.SplitN(item, ":", 2)
+		if len(parsed) != 2 || len(parsed[1]) < 1 {
+			return nil, fmt.Errorf("invalid checkpoint: %q", item)
+		}
+
+		height, err := strconv.ParseUint(parsed[0], 10, 64)
+		if err != nil || height < GenesisBlockHeight {
+			return nil, fmt.Errorf("invalid checkpoint height: %q", item)
+		}
+
+		cps = append(cps, Checkpoint{Height: height, Hash: parsed[1]})
+	}
+
+	return cps.Merge(nil)
+}
+
+// Get returns the hash of checkpoint at the height.
+func (cps Checkpoints) Get(height uint64) (string, bool) {
+	i := sort.Search(len(cps), func(i int) bool { return cps[i].Height >= height })
+	if i < len(cps) && cps[i].Height == height {
+		return cps[i].Hash, true
+	}
+
+	return "", false
+}
+
+// Last returns the highest checkpoint.
+func (cps Checkpoints) Last() (Checkpoint, bool) {
+	if len(cps) < 1 {
+		return Checkpoint{}, false
+	}
+
+	return cps[len(cps)-1], true
+}
+
+// Merge returns the checkpoints of both ordered by height; if they have the
+// different hashes at the same height, Merge fails.
+func (cps Checkpoints) Merge(others Checkpoints) (Checkpoints, error) {
+	hashes := map[uint64]string{}
+
+	var merged Checkpoints
+	for _, cp := range append(append(Checkpoints{}, cps...), others...) {
+		if hash, found := hashes[cp.Height]; found {
+			if hash != cp.Hash {
+				return nil, fmt.Errorf("conflicting checkpoints at height %d", cp.Height)
+			}
+			continue
+		}
+		hashes[cp.Height] = cp.Hash
+		merged = append(merged, cp)
+	}
+
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Height < merged[j].Height })
+
+	return merged, nil
+}
+
+func (cps Checkpoints) String() string {
+	var s []string
+	for _, cp := range cps {
+		s = append(s, fmt.Sprintf("%d:%s", cp.Height, cp.Hash))
+	}
+
+	return strings.Join(s, ",")
+}
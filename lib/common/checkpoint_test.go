@@ -0,0 +1,35 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCheckpoints(t *testing.T) {
+	cps, err := ParseCheckpoints("10:hash10, 3:hash3,,10:hash10")
+	require.NoError(t, err)
+	require.Equal(t, Checkpoints{{Height: 3, Hash: "hash3"}, {Height: 10, Hash: "hash10"}}, cps)
+	require.Equal(t, "3:hash3,10:hash10", cps.String())
+
+	hash, found := cps.Get(10)
+	require.True(t, found)
+	require.Equal(t, "hash10", hash)
+	_, found = cps.Get(5)
+	require.False(t, found)
+
+	last, found := cps.Last()
+	require.True(t, found)
+	require.Equal(t, uint64(10), last.Height)
+
+	cps, err = ParseCheckpoints("")
+	require.NoError(t, err)
+	require.Empty(t, cps)
+	_, found = cps.Last()
+	require.False(t, found)
+
+	for _, s := range []string{"10", "10:", "a:hash", "0:hash", "10:hash,10:another"} {
+		_, err = ParseCheckpoints(s)
+		require.Error(t, err, s)
+	}
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of consensus; the timestamps of ballots and
+// the timeouts of ISAAC states. By default it is the wall clock, but it can be
+// replaced by the virtual clock to simulate the network, see `SetClock`.
+type Clock interface {
+	Now() time.Time
+	NewTimer(time.Duration) Timer
+}
+
+// Timer is the `time.Timer` of `Clock`.
+type Timer interface {
+	C() <-chan time.Time
+	Reset(time.Duration) bool
+	Stop() bool
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) NewTimer(d time.Duration) Timer {
+	return wallTimer{Timer: time.NewTimer(d)}
+}
+
+type wallTimer struct {
+	*time.Timer
+}
+
+func (t wallTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+var WallClock Clock = wallClock{}
+
+var (
+	clockLock sync.RWMutex
+	clock     Clock = WallClock
+)
+
+// SetClock replaces the clock of consensus; it must be set before the nodes
+// start.
+func SetClock(c Clock) {
+	clockLock.Lock()
+	defer clockLock.Unlock()
+
+	clock = c
+}
+
+func GetClock() Clock {
+	clockLock.RLock()
+	defer clockLock.RUnlock()
+
+	return clock
+}
+
+// Now returns the current time of `Clock`.
+func Now() time.Time {
+	return GetClock().Now()
+}
+
+// NewTimer creates new `Timer` of `Clock`.
+func NewTimer(d time.Duration) Timer {
+	return GetClock().NewTimer(d)
+}
+
+// Sleep pauses for the duration of `Clock`.
+func Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	<-NewTimer(d).C()
+}
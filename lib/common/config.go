@@ -14,6 +14,12 @@ type Config struct {
 	BlockTime         time.Duration
 	BlockTimeDelta    time.Duration
 
+	// TimeoutAdaptive adapts the timeouts of INIT, SIGN and ACCEPT state to
+	// the observed ballot latencies, between TimeoutMin and TimeoutMax.
+	TimeoutAdaptive bool
+	TimeoutMin      time.Duration
+	TimeoutMax      time.Duration
+
 	TxsLimit          int
 	OpsLimit          int
 	OpsInBallotLimit  int
@@ -27,6 +33,27 @@ type Config struct {
 	RateLimitRuleAPI  RateLimitRule
 	RateLimitRuleNode RateLimitRule
 
+	// PeerBanThreshold is the misbehavior score to ban the peer; if 0, the
+	// peer will not be banned.
+	PeerBanThreshold int
+	PeerBanDuration  time.Duration
+
+	// SnapshotInterval is the number of blocks between the state snapshots
+	// served for fast sync; if 0, the snapshot is not taken.
+	SnapshotInterval uint64
+
+	// PruneKeepBlocks is the number of latest blocks, whose transactions and
+	// operations are kept; if 0, nothing is pruned.
+	PruneKeepBlocks uint64
+
+	// Checkpoints are the trusted block hashes; the synced block, which does
+	// not match with them, is refused. If TrustCheckpoints is set, the
+	// transactions of the blocks until the last checkpoint are not validated
+	// again in sync, once the blocks are linked to the checkpoint by their
+	// previous block hashes.
+	Checkpoints      Checkpoints
+	TrustCheckpoints bool
+
 	HTTPCacheAdapter    string
 	HTTPCachePoolSize   int
 	HTTPCacheRedisAddrs map[string]string
@@ -36,6 +63,13 @@ type Config struct {
 
 	JSONRPCEndpoint *Endpoint
 
+	// AdminEndpoint is where the admin API listens; if nil, the admin API is
+	// disabled. The admin API, which is not bound to localhost, should be
+	// protected by AdminToken or by the client certificate of
+	// `TLSClientCAFile` query of the endpoint.
+	AdminEndpoint *Endpoint
+	AdminToken    string
+
 	WatcherMode bool
 
 	DiscoveryEndpoints []*Endpoint
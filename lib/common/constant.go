@@ -15,6 +15,19 @@ const (
 	// is `0.1` BOS.
 	BaseReserve Amount = 1000000
 
+	// DataEntryReserve is the amount of balance reserved for each data entry
+	// of account. By default, it is `0.1` BOS.
+	DataEntryReserve Amount = 1000000
+
+	// MaxDataEntries is the maximum number of the data entries of account.
+	MaxDataEntries int = 32
+
+	// MaxDataKeyLength is the maximum length of the key of data entry.
+	MaxDataKeyLength int = 64
+
+	// MaxDataValueLength is the maximum length of the value of data entry.
+	MaxDataValueLength int = 128
+
 	// FrozenFee is a special transaction fee about freezing, and unfreezing.
 	FrozenFee Amount = 0
 
@@ -64,6 +77,22 @@ const (
 	DefaultBlockTime         = 5 * time.Second
 	DefaultBlockTimeDelta    = 1 * time.Second
 
+	// DefaultTimeoutMin and DefaultTimeoutMax are the default bounds of the
+	// adaptive timeouts.
+	DefaultTimeoutMin = 500 * time.Millisecond
+	DefaultTimeoutMax = 10 * time.Second
+
+	// DefaultPeerBanThreshold is the default misbehavior score of peer to be
+	// banned.
+	DefaultPeerBanThreshold int = 100
+
+	// DefaultPeerBanDuration is the default duration of peer ban.
+	DefaultPeerBanDuration = 10 * time.Minute
+
+	// DefaultSnapshotInterval is the default number of blocks between the
+	// state snapshots.
+	DefaultSnapshotInterval uint64 = 1000
+
 	// DiscoveryMessageCreatedAllowDuration limit the `DiscoveryMessage.Created`
 	// is allowed or not.
 	DiscoveryMessageCreatedAllowDuration time.Duration = time.Second * 10
@@ -99,6 +128,7 @@ var (
 		"":                         true, // default value is nop cache
 	}
 	DefaultJSONRPCBindURL string = "http://127.0.0.1:54321/jsonrpc" // JSONRPC only can be accessed from localhost
+	DefaultAdminBindURL   string = "http://127.0.0.1:54322/admin"
 
 	// MaxTimeDiffAllow is the allowed difference of node time. The default
 	// value, 4 seconds is from BlockTime.
@@ -23,3 +23,7 @@ var RandomCanFail = stellar.Random
 func MakeSignature(kp KP, networkID []byte, hash string) ([]byte, error) {
 	return kp.Sign(append(networkID, []byte(hash)...))
 }
+
+// ErrInvalidSignature is returned when the signature is not valid for the
+// given keypair.
+var ErrInvalidSignature = stellar.ErrInvalidSignature
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sync/atomic"
 	"time"
 
 	logging "github.com/inconshreveable/log15"
@@ -86,6 +87,34 @@ func JsonFormatEx(pretty, lineSeparated bool) logging.Format {
 	})
 }
 
+// LevelHandler filters the records by the level like
+// `logging.LvlFilterHandler`, but the level can be changed while running.
+type LevelHandler struct {
+	level   int32
+	handler logging.Handler
+}
+
+func NewLevelHandler(level logging.Lvl, handler logging.Handler) *LevelHandler {
+	return &LevelHandler{level: int32(level), handler: handler}
+}
+
+func (h *LevelHandler) Log(r *logging.Record) error {
+	if r.Lvl > h.Level() {
+		return nil
+	}
+
+	return h.handler.Log(r)
+}
+
+func (h *LevelHandler) Level() logging.Lvl {
+	return logging.Lvl(atomic.LoadInt32(&h.level))
+}
+
+// SetLevel changes the level and returns the previous level.
+func (h *LevelHandler) SetLevel(level logging.Lvl) logging.Lvl {
+	return logging.Lvl(atomic.SwapInt32(&h.level, int32(level)))
+}
+
 // NopLogger returns a Logger with a no-op (nil) Logger
 func NopLogger() logging.Logger {
 	return &nopLogger{}
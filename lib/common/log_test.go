@@ -0,0 +1,30 @@
+package common
+
+import (
+	"testing"
+
+	logging "github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelHandler(t *testing.T) {
+	var records []*logging.Record
+	h := NewLevelHandler(logging.LvlInfo, logging.FuncHandler(func(r *logging.Record) error {
+		records = append(records, r)
+		return nil
+	}))
+
+	logger := logging.New()
+	logger.SetHandler(h)
+
+	logger.Debug("debug")
+	logger.Info("info")
+	require.Equal(t, 1, len(records))
+
+	require.Equal(t, logging.LvlInfo, h.SetLevel(logging.LvlDebug))
+	require.Equal(t, logging.LvlDebug, h.Level())
+
+	logger.Debug("debug")
+	require.Equal(t, 2, len(records))
+	require.Equal(t, "debug", records[1].Msg)
+}
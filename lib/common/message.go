@@ -38,6 +38,10 @@ type Message interface {
 type NetworkMessage struct {
 	Type MessageType
 	Data []byte
+
+	// Peer is the remote peer which sent this message; it is empty when the
+	// message is made by the local node.
+	Peer string `json:"-"`
 }
 
 func (t NetworkMessage) Serialize() ([]byte, error) {
@@ -53,6 +57,7 @@ func (t NetworkMessage) Head(n int) NetworkMessage {
 	return NetworkMessage{
 		Type: t.Type,
 		Data: []byte(s[:int(i)]),
+		Peer: t.Peer,
 	}
 }
 
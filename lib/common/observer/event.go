@@ -0,0 +1,52 @@
+package observer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EventID orders the events of `ResourceObserver`; it is triggered with the
+// resource as the last argument.
+//
+// `Index` is the index of the first operation of the transaction in the
+// block. The account events follow the operations of the block, the frozen
+// account events follow the account events and the block event is the last.
+type EventID struct {
+	Height uint64
+	Index  uint64
+}
+
+// Implement `fmt.Stringer`, `<height>-<index>`
+func (e EventID) String() string {
+	return fmt.Sprintf("%d-%d", e.Height, e.Index)
+}
+
+func (e EventID) IsEmpty() bool {
+	return e.Height == 0 && e.Index == 0
+}
+
+// After returns true if `e` comes after `o`.
+func (e EventID) After(o EventID) bool {
+	if e.Height != o.Height {
+		return e.Height > o.Height
+	}
+	return e.Index > o.Index
+}
+
+func ParseEventID(s string) (e EventID, err error) {
+	sl := strings.SplitN(s, "-", 2)
+	if len(sl) != 2 {
+		err = fmt.Errorf("invalid event id, '%s'", s)
+		return
+	}
+
+	if e.Height, err = strconv.ParseUint(sl[0], 10, 64); err != nil {
+		return
+	}
+	if e.Index, err = strconv.ParseUint(sl[1], 10, 64); err != nil {
+		return
+	}
+
+	return
+}
@@ -22,20 +22,51 @@ const (
 	TxPool = "txpool"
 	// An event relative to accounts (creation, update)
 	Acc = "acc"
+	// An event relative to the confirmed blocks
+	Block = "block"
+	// An event relative to frozen accounts (freezing, unfreezing request,
+	// unfreezing, returning)
+	FrozenAccount = "frozen-account"
+	// An event relative to the consensus state of the node (height, round,
+	// ballot state)
+	ConsensusState = "consensus-state"
 )
 
 const (
 	// All events related to the `ResourceType`
 	All KeyType = "*"
 	// "Identifier" of the item
-	// Hash for a Transaction, address for an Account.
+	// Hash for a Transaction and a Block, address for an Account and a
+	// FrozenAccount.
 	Identifier = "identifier"
 	// Tx/TxPool only: Transactions with a specified source
 	Source = "source"
 	// Tx/TxPool only: Transactions with a specified target
 	Target = "target"
+	// Block only: Blocks with a specified proposer
+	Proposer = "proposer"
+	// FrozenAccount only: Frozen accounts with a specified linked address
+	Linked = "linked"
 )
 
+// Keys returns the keys which can be used with the resource, but `All`.
+func Keys(resource ResourceType) []KeyType {
+	switch resource {
+	case Tx, TxPool:
+		return []KeyType{Identifier, Source, Target}
+	case Acc:
+		return []KeyType{Identifier}
+	case Block:
+		return []KeyType{Identifier, Proposer}
+	case FrozenAccount:
+		return []KeyType{Identifier, Linked}
+	case ConsensusState:
+		return []KeyType{}
+	}
+
+	return nil
+}
+
 // A Condition can be sent as the body when calling subscribe
 type Condition struct {
 	// Affected ressource
@@ -0,0 +1,77 @@
+package observer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The type of message exchanged through the websocket subscription
+type MessageType = string
+
+const (
+	// client: subscribe the events of `Conditions` by `ID`
+	MessageSubscribe MessageType = "subscribe"
+	// client: stop the subscription of `ID`
+	MessageUnsubscribe = "unsubscribe"
+	// server: `ID` is subscribed
+	MessageSubscribed = "subscribed"
+	// server: `ID` is unsubscribed
+	MessageUnsubscribed = "unsubscribed"
+	// server: the `Data` of `Event` for the subscription, `ID`
+	MessageEvent = "event"
+	// server: sent periodically to tell the connection is alive
+	MessageHeartbeat = "heartbeat"
+	// server: the request of `ID` was refused
+	MessageError = "error"
+)
+
+// Message is the JSON message of the websocket subscription.
+type Message struct {
+	Type MessageType `json:"type"`
+	// Subscription identifier, chosen by the client
+	ID         string     `json:"id,omitempty"`
+	Conditions Conditions `json:"conditions,omitempty"`
+	// The triggered event, like `tx-source=GABC...`
+	Event   string          `json:"event,omitempty"`
+	EventID string          `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	// Number of the events dropped before this message, because the client
+	// was too slow to receive them
+	Dropped uint64 `json:"dropped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Validate checks the resource, key and value of the conditions.
+func (cs Conditions) Validate() error {
+	if len(cs) < 1 {
+		return fmt.Errorf("conditions must be given")
+	}
+
+	for _, c := range cs {
+		keys := Keys(c.Resource)
+		if keys == nil {
+			return fmt.Errorf("unknown resource, '%s'", c.Resource)
+		}
+
+		if c.Key == All {
+			continue
+		}
+
+		var found bool
+		for _, k := range keys {
+			if c.Key == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown key of '%s', '%s'", c.Resource, c.Key)
+		}
+
+		if len(c.Value) < 1 {
+			return fmt.Errorf("value of '%s' must be given", c.Key)
+		}
+	}
+
+	return nil
+}
@@ -1,29 +1,34 @@
 package common
 
 const (
-	BlockPrefixHash                       = string(0x00)
-	BlockPrefixConfirmed                  = string(0x01)
-	BlockPrefixHeight                     = string(0x02)
-	BlockTransactionPrefixHash            = string(0x10)
-	BlockTransactionPrefixSource          = string(0x11)
-	BlockTransactionPrefixConfirmed       = string(0x12)
-	BlockTransactionPrefixAccount         = string(0x13)
-	BlockTransactionPrefixBlock           = string(0x14)
-	BlockOperationPrefixHash              = string(0x20)
-	BlockOperationPrefixTxHash            = string(0x21)
-	BlockOperationPrefixSource            = string(0x22)
-	BlockOperationPrefixTarget            = string(0x23)
-	BlockOperationPrefixPeers             = string(0x24)
-	BlockOperationPrefixTypeSource        = string(0x25)
-	BlockOperationPrefixTypeTarget        = string(0x26)
-	BlockOperationPrefixTypePeers         = string(0x27)
-	BlockOperationPrefixCreateFrozen      = string(0x28)
-	BlockOperationPrefixFrozenLinked      = string(0x29)
-	BlockOperationPrefixBlockHeight       = string(0x2A)
-	BlockAccountPrefixAddress             = string(0x30)
-	BlockAccountPrefixCreated             = string(0x31)
-	BlockAccountSequenceIDPrefix          = string(0x32)
-	BlockAccountSequenceIDByAddressPrefix = string(0x33)
-	TransactionPoolPrefix                 = string(0x40)
-	InternalPrefix                        = string(0x50) // internal data
+	BlockPrefixHash                       = "\x00"
+	BlockPrefixConfirmed                  = "\x01"
+	BlockPrefixHeight                     = "\x02"
+	BlockLedgerPrefixHeight               = "\x03"
+	BlockTransactionPrefixHash            = "\x10"
+	BlockTransactionPrefixSource          = "\x11"
+	BlockTransactionPrefixConfirmed       = "\x12"
+	BlockTransactionPrefixAccount         = "\x13"
+	BlockTransactionPrefixBlock           = "\x14"
+	BlockTransactionPrefixPruned          = "\x15"
+	BlockTransactionPrefixTarget          = "\x16"
+	BlockTransactionPrefixCounterparty    = "\x17"
+	BlockOperationPrefixHash              = "\x20"
+	BlockOperationPrefixTxHash            = "\x21"
+	BlockOperationPrefixSource            = "\x22"
+	BlockOperationPrefixTarget            = "\x23"
+	BlockOperationPrefixPeers             = "\x24"
+	BlockOperationPrefixTypeSource        = "\x25"
+	BlockOperationPrefixTypeTarget        = "\x26"
+	BlockOperationPrefixTypePeers         = "\x27"
+	BlockOperationPrefixCreateFrozen      = "\x28"
+	BlockOperationPrefixFrozenLinked      = "\x29"
+	BlockOperationPrefixBlockHeight       = "\x2A"
+	BlockOperationPrefixCounterparty      = "\x2B"
+	BlockAccountPrefixAddress             = "\x30"
+	BlockAccountPrefixCreated             = "\x31"
+	BlockAccountSequenceIDPrefix          = "\x32"
+	BlockAccountSequenceIDByAddressPrefix = "\x33"
+	TransactionPoolPrefix                 = "\x40"
+	InternalPrefix                        = "\x50" // internal data
 )
@@ -17,6 +17,8 @@ func NewTestConfig() Config {
 	p.TimeoutSIGN = DefaultTimeoutSIGN
 	p.TimeoutACCEPT = DefaultTimeoutACCEPT
 	p.TimeoutALLCONFIRM = DefaultTimeoutALLCONFIRM
+	p.TimeoutMin = DefaultTimeoutMin
+	p.TimeoutMax = DefaultTimeoutMax
 	p.BlockTime = 0
 	p.BlockTimeDelta = DefaultBlockTimeDelta
 
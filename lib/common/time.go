@@ -16,7 +16,7 @@ func FormatISO8601(t time.Time) string {
 }
 
 func NowISO8601() string {
-	return FormatISO8601(time.Now())
+	return FormatISO8601(Now())
 }
 
 func ParseISO8601(s string) (time.Time, error) {
@@ -0,0 +1,120 @@
+// Wire encoding of the messages between nodes
+//
+// By default nodes send messages as JSON. A node which also understands the
+// compact RLP encoding announces it with the `Accept-Post` header in the
+// responses of node endpoints; peers who see the header can switch to RLP,
+// while the older nodes keep on receiving JSON.
+package common
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/gorilla/mux"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeRLP  = "application/x-sebak-rlp"
+
+	// HeaderAcceptPost lists the content types which can be posted to the
+	// node endpoints.
+	HeaderAcceptPost = "Accept-Post"
+)
+
+// Raw, already encoded RLP value
+type RLPRawValue = rlp.RawValue
+
+// Decode the RLP encoded binary data into the value
+var DecodeBytes = rlp.DecodeBytes
+
+// RLPWireMessage is implemented by the messages which can be sent to the
+// other nodes in RLP.
+type RLPWireMessage interface {
+	SerializeRLP() ([]byte, error)
+}
+
+// MediaType returns the media type of `Content-Type` header value without
+// parameters.
+func MediaType(ct string) string {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(ct))
+	}
+
+	return mt
+}
+
+// AcceptsContentType checks the `Accept-Post` header of response has the
+// given content type.
+func AcceptsContentType(header http.Header, ct string) bool {
+	for _, value := range header[HeaderAcceptPost] {
+		for _, s := range strings.Split(value, ",") {
+			if MediaType(s) == ct {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ContentTypeMatcher matches the `POST` requests having one of the given
+// content types.
+func ContentTypeMatcher(cts ...string) mux.MatcherFunc {
+	return func(r *http.Request, rm *mux.RouteMatch) bool {
+		mt := MediaType(r.Header.Get("Content-Type"))
+		for _, ct := range cts {
+			if mt == ct {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Base58ToBytes decodes the base58 string. Unlike `base58.Decode`, the
+// string, which can not be encoded back to the same string returns
+// `errors.InvalidWireEncoding`, so the decoded bytes always give the original
+// string.
+func Base58ToBytes(s string) (b []byte, err error) {
+	b = base58.Decode(s)
+	if base58.Encode(b) != s {
+		err = errors.InvalidWireEncoding
+		return
+	}
+
+	return
+}
+
+// Base58SliceToBytes decodes every base58 string in the slice.
+func Base58SliceToBytes(l []string) (b [][]byte, err error) {
+	b = make([][]byte, len(l))
+	for i, s := range l {
+		if b[i], err = Base58ToBytes(s); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// BytesSliceToBase58 is the reverse of `Base58SliceToBytes`.
+func BytesSliceToBase58(b [][]byte) (l []string) {
+	if len(b) < 1 {
+		return
+	}
+
+	l = make([]string, len(b))
+	for i, s := range b {
+		l[i] = base58.Encode(s)
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestMediaType(t *testing.T) {
+	require.Equal(t, ContentTypeJSON, MediaType("application/json"))
+	require.Equal(t, ContentTypeJSON, MediaType("Application/JSON; charset=utf-8"))
+	require.Equal(t, ContentTypeRLP, MediaType(" application/x-sebak-rlp "))
+	require.Equal(t, "", MediaType(""))
+}
+
+func TestAcceptsContentType(t *testing.T) {
+	header := http.Header{}
+	require.False(t, AcceptsContentType(header, ContentTypeRLP))
+
+	header.Set(HeaderAcceptPost, "application/json, application/x-sebak-rlp")
+	require.True(t, AcceptsContentType(header, ContentTypeJSON))
+	require.True(t, AcceptsContentType(header, ContentTypeRLP))
+	require.False(t, AcceptsContentType(header, "text/plain"))
+}
+
+func TestContentTypeMatcher(t *testing.T) {
+	matcher := ContentTypeMatcher(ContentTypeJSON, ContentTypeRLP)
+
+	for ct, expected := range map[string]bool{
+		"application/json":                true,
+		"application/json; charset=UTF-8": true,
+		"application/x-sebak-rlp":         true,
+		"text/plain":                      false,
+		"":                                false,
+	} {
+		r := httptest.NewRequest("POST", "/", nil)
+		r.Header.Set("Content-Type", ct)
+		require.Equal(t, expected, matcher(r, &mux.RouteMatch{}), ct)
+	}
+}
+
+func TestBase58ToBytes(t *testing.T) {
+	hash := MustMakeObjectHashString("showme")
+
+	b, err := Base58ToBytes(hash)
+	require.NoError(t, err)
+	require.Equal(t, 32, len(b))
+	require.Equal(t, hash, base58.Encode(b))
+
+	b, err = Base58ToBytes("")
+	require.NoError(t, err)
+	require.Equal(t, 0, len(b))
+
+	// '0', 'O', 'I' and 'l' are not in base58 alphabet
+	_, err = Base58ToBytes("0OIl")
+	require.Equal(t, errors.InvalidWireEncoding, err)
+
+	l := []string{hash, MustMakeObjectHashString("findme")}
+	bl, err := Base58SliceToBytes(l)
+	require.NoError(t, err)
+	require.Equal(t, l, BytesSliceToBase58(bl))
+}
@@ -0,0 +1,166 @@
+package consensus
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+)
+
+// AdaptiveTimeouts estimates the timeouts of INIT, SIGN and ACCEPT state from
+// the observed ballot latencies, like the retransmission timeout of TCP(RFC
+// 6298); the timeout is `smoothed latency + 4 * latency variation + round trip
+// time` and it is bounded by `common.Config.TimeoutMin` and
+// `common.Config.TimeoutMax`.
+//
+// Until the latency of state is observed, or if `common.Config.TimeoutAdaptive`
+// is not set, the configured timeout is used. The timeout of ALLCONFIRM state is
+// not adapted.
+type AdaptiveTimeouts struct {
+	sync.RWMutex
+
+	enabled       bool
+	min           time.Duration
+	max           time.Duration
+	configured    map[ballot.State]time.Duration
+	smoothed      map[ballot.State]time.Duration
+	variation     map[ballot.State]time.Duration
+	roundTripTime time.Duration
+}
+
+func NewAdaptiveTimeouts(conf common.Config) *AdaptiveTimeouts {
+	return &AdaptiveTimeouts{
+		enabled: conf.TimeoutAdaptive,
+		min:     conf.TimeoutMin,
+		max:     conf.TimeoutMax,
+		configured: map[ballot.State]time.Duration{
+			ballot.StateINIT:       conf.TimeoutINIT,
+			ballot.StateSIGN:       conf.TimeoutSIGN,
+			ballot.StateACCEPT:     conf.TimeoutACCEPT,
+			ballot.StateALLCONFIRM: conf.TimeoutALLCONFIRM,
+		},
+		smoothed:  map[ballot.State]time.Duration{},
+		variation: map[ballot.State]time.Duration{},
+	}
+}
+
+func isAdaptiveState(state ballot.State) bool {
+	switch state {
+	case ballot.StateINIT, ballot.StateSIGN, ballot.StateACCEPT:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *AdaptiveTimeouts) Enabled() bool {
+	return a.enabled
+}
+
+// Observe adds the latency of the ballot state; the time from the beginning of
+// the state, or sending the ballot, until the state is finished by the ballots
+// from the other validators.
+func (a *AdaptiveTimeouts) Observe(state ballot.State, latency time.Duration) {
+	if !isAdaptiveState(state) || latency < 0 {
+		return
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	smoothed, found := a.smoothed[state]
+	if !found {
+		a.smoothed[state] = latency
+		a.variation[state] = latency / 2
+		return
+	}
+
+	diff := smoothed - latency
+	if diff < 0 {
+		diff = -diff
+	}
+	a.variation[state] = (3*a.variation[state] + diff) / 4
+	a.smoothed[state] = (7*smoothed + latency) / 8
+}
+
+// Expired backs off the timeout of the ballot state, which was expired without
+// enough ballots.
+func (a *AdaptiveTimeouts) Expired(state ballot.State) {
+	if !isAdaptiveState(state) {
+		return
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	if smoothed, found := a.smoothed[state]; found {
+		a.smoothed[state] = a.bound(smoothed * 2)
+	}
+}
+
+// SetRoundTripTime sets the round trip time of the connections to the
+// validators; it is added to the timeouts.
+func (a *AdaptiveTimeouts) SetRoundTripTime(d time.Duration) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.roundTripTime = d
+}
+
+func (a *AdaptiveTimeouts) RoundTripTime() time.Duration {
+	a.RLock()
+	defer a.RUnlock()
+
+	return a.roundTripTime
+}
+
+// Timeout returns the effective timeout of the ballot state.
+func (a *AdaptiveTimeouts) Timeout(state ballot.State) time.Duration {
+	a.RLock()
+	defer a.RUnlock()
+
+	if !a.enabled || !isAdaptiveState(state) {
+		return a.configured[state]
+	}
+
+	smoothed, found := a.smoothed[state]
+	if !found {
+		return a.configured[state]
+	}
+
+	return a.bound(smoothed + 4*a.variation[state] + a.roundTripTime)
+}
+
+func (a *AdaptiveTimeouts) bound(d time.Duration) time.Duration {
+	if d < a.min {
+		return a.min
+	} else if d > a.max {
+		return a.max
+	}
+
+	return d
+}
+
+// QuorumRoundTripTime returns the round trip time within which `threshold`
+// validators, including the local node, can be reached; the slow validators
+// over the threshold do not delay the consensus.
+func QuorumRoundTripTime(rtts map[string]time.Duration, threshold int) time.Duration {
+	needed := threshold - 1 // local node
+	if needed < 1 || len(rtts) < 1 {
+		return 0
+	}
+
+	var sorted []time.Duration
+	for _, rtt := range rtts {
+		sorted = append(sorted, rtt)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	if needed > len(sorted) {
+		needed = len(sorted)
+	}
+
+	return sorted[needed-1]
+}
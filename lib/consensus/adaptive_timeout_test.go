@@ -0,0 +1,109 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+)
+
+func newTestAdaptiveTimeouts() *AdaptiveTimeouts {
+	conf := common.NewTestConfig()
+	conf.TimeoutAdaptive = true
+	conf.TimeoutMin = 100 * time.Millisecond
+	conf.TimeoutMax = 5 * time.Second
+
+	return NewAdaptiveTimeouts(conf)
+}
+
+func TestAdaptiveTimeoutsDisabled(t *testing.T) {
+	conf := common.NewTestConfig()
+	a := NewAdaptiveTimeouts(conf)
+	require.False(t, a.Enabled())
+
+	a.Observe(ballot.StateSIGN, 10*time.Millisecond)
+	require.Equal(t, conf.TimeoutINIT, a.Timeout(ballot.StateINIT))
+	require.Equal(t, conf.TimeoutSIGN, a.Timeout(ballot.StateSIGN))
+	require.Equal(t, conf.TimeoutACCEPT, a.Timeout(ballot.StateACCEPT))
+	require.Equal(t, conf.TimeoutALLCONFIRM, a.Timeout(ballot.StateALLCONFIRM))
+}
+
+func TestAdaptiveTimeoutsObserve(t *testing.T) {
+	conf := common.NewTestConfig()
+	a := newTestAdaptiveTimeouts()
+	require.True(t, a.Enabled())
+
+	// without observed latency, configured timeout is used
+	require.Equal(t, conf.TimeoutSIGN, a.Timeout(ballot.StateSIGN))
+
+	// first latency; 200ms + 4 * 100ms
+	a.Observe(ballot.StateSIGN, 200*time.Millisecond)
+	require.Equal(t, 600*time.Millisecond, a.Timeout(ballot.StateSIGN))
+	require.Equal(t, conf.TimeoutACCEPT, a.Timeout(ballot.StateACCEPT))
+
+	// stable latency decreases the variation
+	for i := 0; i < 30; i++ {
+		a.Observe(ballot.StateSIGN, 200*time.Millisecond)
+	}
+	timeout := a.Timeout(ballot.StateSIGN)
+	require.True(t, timeout < 250*time.Millisecond, timeout)
+	require.True(t, timeout >= 200*time.Millisecond, timeout)
+
+	// round trip time is added
+	a.SetRoundTripTime(50 * time.Millisecond)
+	require.Equal(t, timeout+50*time.Millisecond, a.Timeout(ballot.StateSIGN))
+
+	// ALLCONFIRM is not adapted
+	a.Observe(ballot.StateALLCONFIRM, 200*time.Millisecond)
+	require.Equal(t, conf.TimeoutALLCONFIRM, a.Timeout(ballot.StateALLCONFIRM))
+}
+
+func TestAdaptiveTimeoutsBounds(t *testing.T) {
+	a := newTestAdaptiveTimeouts()
+
+	a.Observe(ballot.StateINIT, time.Millisecond)
+	require.Equal(t, 100*time.Millisecond, a.Timeout(ballot.StateINIT))
+
+	a.Observe(ballot.StateACCEPT, time.Minute)
+	require.Equal(t, 5*time.Second, a.Timeout(ballot.StateACCEPT))
+}
+
+func TestAdaptiveTimeoutsExpired(t *testing.T) {
+	a := newTestAdaptiveTimeouts()
+
+	// without observed latency, nothing happens
+	a.Expired(ballot.StateSIGN)
+	require.Equal(t, common.DefaultTimeoutSIGN, a.Timeout(ballot.StateSIGN))
+
+	for i := 0; i < 30; i++ {
+		a.Observe(ballot.StateSIGN, 200*time.Millisecond)
+	}
+	before := a.Timeout(ballot.StateSIGN)
+
+	a.Expired(ballot.StateSIGN)
+	after := a.Timeout(ballot.StateSIGN)
+	require.True(t, after > before+150*time.Millisecond, after)
+
+	for i := 0; i < 10; i++ {
+		a.Expired(ballot.StateSIGN)
+	}
+	require.Equal(t, 5*time.Second, a.Timeout(ballot.StateSIGN))
+}
+
+func TestQuorumRoundTripTime(t *testing.T) {
+	rtts := map[string]time.Duration{
+		"v1": 10 * time.Millisecond,
+		"v2": 300 * time.Millisecond,
+		"v3": 20 * time.Millisecond,
+	}
+
+	// 4 validators, threshold 3; local node and 2 fastest validators
+	require.Equal(t, 20*time.Millisecond, QuorumRoundTripTime(rtts, 3))
+	require.Equal(t, 300*time.Millisecond, QuorumRoundTripTime(rtts, 4))
+	require.Equal(t, 300*time.Millisecond, QuorumRoundTripTime(rtts, 10))
+	require.Equal(t, time.Duration(0), QuorumRoundTripTime(rtts, 1))
+	require.Equal(t, time.Duration(0), QuorumRoundTripTime(nil, 3))
+}
@@ -2,8 +2,11 @@ package consensus
 
 import (
 	"sync"
+	"time"
 
 	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/common"
 )
 
 // Record the ballot sent by ISAACstate
@@ -11,14 +14,16 @@ import (
 type BallotSendRecord struct {
 	sync.RWMutex
 
-	record map[ISAACState]bool
-	log    logging.Logger
+	record   map[ISAACState]bool
+	sentTime map[ISAACState]time.Time // when the ballot was sent; used to measure the ballot latency
+	log      logging.Logger
 }
 
 func NewBallotSendRecord(nodeAlias string) *BallotSendRecord {
 	p := &BallotSendRecord{
-		record: make(map[ISAACState]bool),
-		log:    log.New(logging.Ctx{"node": nodeAlias}),
+		record:   make(map[ISAACState]bool),
+		sentTime: make(map[ISAACState]time.Time),
+		log:      log.New(logging.Ctx{"node": nodeAlias}),
 	}
 
 	return p
@@ -31,6 +36,7 @@ func (r *BallotSendRecord) SetSent(state ISAACState) {
 	defer r.Unlock()
 	log.Debug("BallotSendRecord.SetSent()", "state", state)
 	r.record[state] = true
+	r.sentTime[state] = common.Now()
 
 	return
 }
@@ -42,6 +48,7 @@ func (r *BallotSendRecord) InitSent(state ISAACState) {
 	defer r.Unlock()
 	log.Debug("BallotSendRecord.InitSent()", "state", state)
 	r.record[state] = false
+	delete(r.sentTime, state)
 
 	return
 }
@@ -54,6 +61,15 @@ func (r *BallotSendRecord) Sent(state ISAACState) bool {
 	return r.record[state]
 }
 
+// SentTime returns the time when the ballot of this ISAACState was sent.
+func (r *BallotSendRecord) SentTime(state ISAACState) (t time.Time, found bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	t, found = r.sentTime[state]
+	return
+}
+
 func (r *BallotSendRecord) RemoveLowerThanOrEqualHeight(height uint64) {
 	r.Lock()
 	defer r.Unlock()
@@ -62,6 +78,7 @@ func (r *BallotSendRecord) RemoveLowerThanOrEqualHeight(height uint64) {
 	for state := range r.record {
 		if state.Height <= height {
 			delete(r.record, state)
+			delete(r.sentTime, state)
 		}
 	}
 
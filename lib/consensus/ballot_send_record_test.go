@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"testing"
+	"time"
 
 	"boscoin.io/sebak/lib/ballot"
 	"github.com/stretchr/testify/require"
@@ -72,3 +73,27 @@ func TestBallotSendRecord(t *testing.T) {
 	}))
 
 }
+
+func TestBallotSendRecordSentTime(t *testing.T) {
+	r := NewBallotSendRecord("n1")
+
+	state := ISAACState{Height: 1, Round: 0, BallotState: ballot.StateSIGN}
+
+	_, found := r.SentTime(state)
+	require.False(t, found)
+
+	before := time.Now()
+	r.SetSent(state)
+	sent, found := r.SentTime(state)
+	require.True(t, found)
+	require.False(t, sent.Before(before))
+
+	r.InitSent(state)
+	_, found = r.SentTime(state)
+	require.False(t, found)
+
+	r.SetSent(state)
+	r.RemoveLowerThanOrEqualHeight(1)
+	_, found = r.SentTime(state)
+	require.False(t, found)
+}
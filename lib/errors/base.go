@@ -81,6 +81,24 @@ func (o *Error) EncodeRLP(w io.Writer) (err error) {
 	})
 }
 
+// defined is the errors made by `NewError`; `NewError` should be used only to
+// define the errors as the package variables.
+var defined []*Error
+
 func NewError(code uint, message string) *Error {
-	return &Error{Code: code, Message: message, Data: map[string]interface{}{}}
+	e := &Error{Code: code, Message: message, Data: map[string]interface{}{}}
+	defined = append(defined, e.Clone())
+
+	return e
+}
+
+// Errors returns the copies of the defined errors ordered by code.
+func Errors() []*Error {
+	errs := make([]*Error, len(defined))
+	for i, e := range defined {
+		errs[i] = e.Clone()
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Code < errs[j].Code })
+
+	return errs
 }
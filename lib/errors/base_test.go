@@ -43,3 +43,19 @@ func TestErrorsRLP(t *testing.T) {
 		require.NotEqual(t, encoded, encoded0)
 	}
 }
+
+func TestErrorsDefined(t *testing.T) {
+	errs := Errors()
+	require.NotEmpty(t, errs)
+
+	codes := map[uint]string{}
+	for _, e := range errs {
+		_, found := codes[e.Code]
+		require.False(t, found, "code %d is defined again by %q", e.Code, e.Message)
+		codes[e.Code] = e.Message
+	}
+
+	// `Newf` does not define the new error
+	Newf(BlockNotFound, "block %d", 1)
+	require.Equal(t, len(errs), len(Errors()))
+}
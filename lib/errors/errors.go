@@ -10,9 +10,10 @@ import (
 var New = errors.New
 var Wrap = pkgerrors.Wrap
 var Wrapf = pkgerrors.Wrapf
+var Cause = pkgerrors.Cause
 
 func Newf(err *Error, format string, args ...interface{}) error {
-	return NewError(err.Code, fmt.Sprintf(format, args...))
+	return &Error{Code: err.Code, Message: fmt.Sprintf(format, args...), Data: map[string]interface{}{}}
 }
 
 var (
@@ -115,4 +116,30 @@ var (
 	SnapshotNotFound                          = NewError(197, "snapshot not found")
 	SnapshotLimitReached                      = NewError(198, "snapshots over limit")
 	BallotsNotFound                           = NewError(199, "ballots not found")
+	PeerBanned                                = NewError(200, "peer is banned")
+	UnsupportedContentType                    = NewError(201, "`Content-Type` is not supported")
+	InvalidWireEncoding                       = NewError(202, "invalid wire encoding")
+	InvalidArchive                            = NewError(203, "invalid archive")
+	ArchiveChecksumMismatch                   = NewError(204, "checksum of archive does not match")
+	StorageSchemaOutdated                     = NewError(205, "storage schema is outdated; run `sebak db migrate`")
+	StorageSchemaNotSupported                 = NewError(206, "storage schema is newer than supported")
+	InvalidStateSnapshot                      = NewError(207, "invalid state snapshot")
+	StateRootMismatch                         = NewError(208, "state root of snapshot does not match")
+	StateSnapshotNotFound                     = NewError(209, "state snapshot not found")
+	StateSnapshotNotFinalized                 = NewError(210, "state snapshot is not attested by enough validators")
+	DataPruned                                = NewError(211, "data is pruned")
+	CheckpointMismatch                        = NewError(212, "block does not match with checkpoint")
+	StreamCursorTooOld                        = NewError(213, "cursor is too old to resume the event stream")
+	LedgerHeightMismatch                      = NewError(214, "ledger does not follow the previous block")
+	LedgerNotFound                            = NewError(215, "ledger not found")
+	AdminUnauthorized                         = NewError(216, "admin request is not authorized")
+	AdminNotProtected                         = NewError(217, "admin API should be bound to localhost or protected by token or client certificate")
+	LogLevelNotChangeable                     = NewError(218, "log level can not be changed")
+	TransactionsInBatchLimitExceeded          = NewError(219, "too many transactions in batch")
+	DataEntryKeyInvalid                       = NewError(220, "invalid key of data entry")
+	DataEntryValueTooLong                     = NewError(221, "value of data entry is too long")
+	DataEntryNotFound                         = NewError(222, "data entry not found")
+	DataEntriesLimitExceeded                  = NewError(223, "too many data entries in account")
+	DataEntryReserveInsufficient              = NewError(224, "balance is not enough for the reserve of data entries")
+	FrozenAccountNoManageData                 = NewError(225, "frozen account can not manage data entries")
 )
@@ -20,6 +20,10 @@ type ConsensusMetrics struct {
 
 	Validators        metrics.Gauge
 	MissingValidators metrics.Gauge
+
+	TimeoutSeconds       metrics.Gauge
+	BallotLatencySeconds metrics.Histogram
+	RoundTripTimeSeconds metrics.Gauge
 }
 
 func (c *ConsensusMetrics) SetBlockIntervalSeconds(t time.Time) time.Time {
@@ -46,6 +50,15 @@ func (c *ConsensusMetrics) SetValidators(num int) {
 func (c *ConsensusMetrics) SetMissingValidators(num int) {
 	c.MissingValidators.Set(float64(num))
 }
+func (c *ConsensusMetrics) SetTimeout(state string, timeout time.Duration) {
+	c.TimeoutSeconds.With("state", state).Set(timeout.Seconds())
+}
+func (c *ConsensusMetrics) ObserveBallotLatency(state string, latency time.Duration) {
+	c.BallotLatencySeconds.With("state", state).Observe(latency.Seconds())
+}
+func (c *ConsensusMetrics) SetRoundTripTime(rtt time.Duration) {
+	c.RoundTripTimeSeconds.Set(rtt.Seconds())
+}
 
 func PromConsensusMetrics() *ConsensusMetrics {
 	return &ConsensusMetrics{
@@ -91,6 +104,24 @@ func PromConsensusMetrics() *ConsensusMetrics {
 			Name:      "missing_validators",
 			Help:      "Number of missing validators.",
 		}, []string{}),
+		TimeoutSeconds: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: ConsensusSubsystem,
+			Name:      "timeout_seconds",
+			Help:      "Effective timeout of ballot state.",
+		}, []string{"state"}),
+		BallotLatencySeconds: prometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: Namespace,
+			Subsystem: ConsensusSubsystem,
+			Name:      "ballot_latency_seconds",
+			Help:      "Time until enough ballots of the state are received.",
+		}, []string{"state"}),
+		RoundTripTimeSeconds: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: ConsensusSubsystem,
+			Name:      "round_trip_time_seconds",
+			Help:      "Round trip time of the connections to validators.",
+		}, []string{}),
 	}
 }
 
@@ -106,5 +137,9 @@ func NopConsensusMetrics() *ConsensusMetrics {
 
 		Validators:        discard.NewGauge(),
 		MissingValidators: discard.NewGauge(),
+
+		TimeoutSeconds:       discard.NewGauge(),
+		BallotLatencySeconds: discard.NewHistogram(),
+		RoundTripTimeSeconds: discard.NewGauge(),
 	}
 }
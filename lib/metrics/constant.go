@@ -6,6 +6,7 @@ const (
 	SyncSubsystem      = "sync"
 	TxPoolSubsystem    = "txpool"
 	APISubsystem       = "api"
+	PeerSubsystem      = "peer"
 )
 
 const (
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+type PeerMetrics struct {
+	Penalties   metrics.Counter
+	Bans        metrics.Counter
+	BannedPeers metrics.Gauge
+}
+
+func PromPeerMetrics() *PeerMetrics {
+	return &PeerMetrics{
+		Penalties: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: PeerSubsystem,
+			Name:      "penalties_total",
+			Help:      "Total number of penalties given to peers.",
+		}, []string{"reason"}),
+		Bans: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: PeerSubsystem,
+			Name:      "bans_total",
+			Help:      "Total number of peer bans.",
+		}, []string{}),
+		BannedPeers: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: PeerSubsystem,
+			Name:      "banned",
+			Help:      "Number of currently banned peers.",
+		}, []string{}),
+	}
+}
+
+func NopPeerMetrics() *PeerMetrics {
+	return &PeerMetrics{
+		Penalties:   discard.NewCounter(),
+		Bans:        discard.NewCounter(),
+		BannedPeers: discard.NewGauge(),
+	}
+}
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"sync"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -9,14 +10,107 @@ import (
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
+// SyncRateWindow is the period to calculate the rate of synced blocks.
+var SyncRateWindow = time.Minute
+
+// SyncRecentErrors is the number of recent errors kept by `SyncMetrics`.
+const SyncRecentErrors = 10
+
 type SyncMetrics struct {
 	Height          metrics.Gauge
 	ErrorTotal      metrics.Counter
 	DurationSeconds metrics.Histogram
+
+	recent *syncRecent
+}
+
+// SyncError is the recent error of sync.
+type SyncError struct {
+	Component string
+	Error     string
+	Time      time.Time
+}
+
+type syncHeight struct {
+	height uint64
+	time   time.Time
+}
+
+// syncRecent keeps the recent heights and errors, which can not be read from
+// the metrics.
+type syncRecent struct {
+	sync.Mutex
+	heights []syncHeight
+	errors  []SyncError
+}
+
+func newSyncRecent() *syncRecent {
+	return &syncRecent{}
 }
 
 func (s *SyncMetrics) SetHeight(height uint64) {
 	s.Height.Set(float64(height))
+
+	s.recent.Lock()
+	defer s.recent.Unlock()
+
+	now := time.Now()
+	s.recent.heights = append(s.recent.heights, syncHeight{height: height, time: now})
+
+	var i int
+	for i < len(s.recent.heights)-1 && now.Sub(s.recent.heights[i].time) > SyncRateWindow {
+		i++
+	}
+	s.recent.heights = s.recent.heights[i:]
+}
+
+// BlocksPerSecond returns the rate of synced blocks in `SyncRateWindow`.
+func (s *SyncMetrics) BlocksPerSecond() float64 {
+	s.recent.Lock()
+	defer s.recent.Unlock()
+
+	if len(s.recent.heights) < 1 {
+		return 0
+	}
+
+	first := s.recent.heights[0]
+	last := s.recent.heights[len(s.recent.heights)-1]
+	if time.Since(last.time) > SyncRateWindow || last.height <= first.height {
+		return 0
+	}
+
+	elapsed := time.Since(first.time).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(last.height-first.height) / elapsed
+}
+
+// RecentErrors returns the recent errors of sync; the latest is the last.
+func (s *SyncMetrics) RecentErrors() []SyncError {
+	s.recent.Lock()
+	defer s.recent.Unlock()
+
+	errs := make([]SyncError, len(s.recent.errors))
+	copy(errs, s.recent.errors)
+
+	return errs
+}
+
+func (s *SyncMetrics) addError(component string, err error) {
+	s.ErrorTotal.With(SyncComponent, component).Add(1)
+	if err == nil {
+		return
+	}
+
+	s.recent.Lock()
+	defer s.recent.Unlock()
+
+	s.recent.errors = append(s.recent.errors, SyncError{Component: component, Error: err.Error(), Time: time.Now()})
+	if len(s.recent.errors) > SyncRecentErrors {
+		s.recent.errors = s.recent.errors[len(s.recent.errors)-SyncRecentErrors:]
+	}
 }
 
 func (s *SyncMetrics) ObserveDurationSeconds(begin time.Time, component string) {
@@ -26,11 +120,11 @@ func (s *SyncMetrics) ObserveDurationSeconds(begin time.Time, component string)
 	s.DurationSeconds.With(SyncComponent, component).Observe(time.Since(begin).Seconds())
 }
 
-func (s *SyncMetrics) AddFetchError() {
-	s.ErrorTotal.With(SyncComponent, SyncFetcher).Add(1)
+func (s *SyncMetrics) AddFetchError(err error) {
+	s.addError(SyncFetcher, err)
 }
-func (s *SyncMetrics) AddValidateError() {
-	s.ErrorTotal.With(SyncComponent, SyncValidator).Add(1)
+func (s *SyncMetrics) AddValidateError(err error) {
+	s.addError(SyncValidator, err)
 }
 
 func PromSyncMetrics() *SyncMetrics {
@@ -53,6 +147,7 @@ func PromSyncMetrics() *SyncMetrics {
 			Name:      "duration_seconds",
 			Help:      "Time processing one block.",
 		}, []string{SyncComponent}),
+		recent: newSyncRecent(),
 	}
 }
 
@@ -61,5 +156,6 @@ func NopSyncMetrics() *SyncMetrics {
 		Height:          discard.NewGauge(),
 		ErrorTotal:      discard.NewCounter(),
 		DurationSeconds: discard.NewHistogram(),
+		recent:          newSyncRecent(),
 	}
 }
@@ -10,4 +10,5 @@ var (
 	Sync      = NopSyncMetrics()
 	TxPool    = NopTxPoolMetrics()
 	API       = NopAPIMetrics()
+	Peer      = NopPeerMetrics()
 )
@@ -30,6 +30,15 @@ type Network interface {
 	ReceiveMessage() <-chan common.NetworkMessage
 }
 
+// MessageCounter is the network, which counts the received messages until
+// they are handled, like `MemoryNetwork`.
+type MessageCounter interface {
+	// MessageHandled is called after the received message is handled.
+	MessageHandled()
+	// Pending returns the number of the messages, which are not handled yet.
+	Pending() int
+}
+
 type NetworkClient interface {
 	Endpoint() *common.Endpoint
 
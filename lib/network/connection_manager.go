@@ -1,6 +1,8 @@
 package network
 
 import (
+	"time"
+
 	"boscoin.io/sebak/lib/common"
 )
 
@@ -13,4 +15,7 @@ type ConnectionManager interface {
 	CountConnected() int
 	IsReady() bool
 	Discovery(DiscoveryMessage) error
+	TriggerDiscovery()
+	Reconnect()
+	RoundTripTimes() map[string]time.Duration
 }
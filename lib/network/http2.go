@@ -214,6 +214,12 @@ func (t *HTTP2Network) AddHandler(pattern string, handler http.HandlerFunc) (rou
 	return r.HandleFunc(prefix, handler)
 }
 
+// Walk walks the routes of the handlers including the routes of the
+// subrouters.
+func (t *HTTP2Network) Walk(walkFn mux.WalkFunc) error {
+	return t.router.Walk(walkFn)
+}
+
 func (t *HTTP2Network) SetMessageBroker(mb MessageBroker) {
 	t.messageBroker = mb
 }
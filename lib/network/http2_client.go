@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"boscoin.io/sebak/lib/common"
@@ -17,6 +19,10 @@ type HTTP2NetworkClient struct {
 	endpoint       *common.Endpoint
 	client         *common.HTTP2Client
 	defaultHeaders http.Header
+
+	// acceptRLP is set to 1 when the peer announces it can receive the
+	// messages in `common.ContentTypeRLP`.
+	acceptRLP int32
 }
 
 var (
@@ -78,14 +84,51 @@ func (c *HTTP2NetworkClient) GetNodeInfo() (body []byte, err error) {
 }
 
 func (c *HTTP2NetworkClient) Send(path string, message interface{}) (retBody []byte, err error) {
-	headers := c.DefaultHeaders()
-	headers.Set("Content-Type", "application/json")
-
 	var body []byte
 	if body, err = json.Marshal(message); err != nil {
 		return
 	}
 
+	_, retBody, err = c.post(path, common.ContentTypeJSON, body)
+	return
+}
+
+// AcceptRLP returns true if the peer can receive the messages in
+// `common.ContentTypeRLP`.
+func (c *HTTP2NetworkClient) AcceptRLP() bool {
+	return atomic.LoadInt32(&c.acceptRLP) == 1
+}
+
+// SendWire sends the message in `common.ContentTypeRLP` if the peer accepts
+// it and the message supports it, otherwise it falls back to JSON like
+// `Send`.
+func (c *HTTP2NetworkClient) SendWire(path string, message interface{}) (retBody []byte, err error) {
+	wm, ok := message.(common.RLPWireMessage)
+	if !ok || !c.AcceptRLP() {
+		return c.Send(path, message)
+	}
+
+	var body []byte
+	if body, err = wm.SerializeRLP(); err != nil {
+		return c.Send(path, message)
+	}
+
+	var status int
+	status, retBody, err = c.post(path, common.ContentTypeRLP, body)
+	if status == http.StatusUnsupportedMediaType || status == http.StatusNotFound {
+		// the peer does not accept RLP anymore; the older node does not have
+		// the route for RLP.
+		atomic.StoreInt32(&c.acceptRLP, 0)
+		return c.Send(path, message)
+	}
+
+	return
+}
+
+func (c *HTTP2NetworkClient) post(path, contentType string, body []byte) (status int, retBody []byte, err error) {
+	headers := c.DefaultHeaders()
+	headers.Set("Content-Type", contentType)
+
 	u := c.resolvePath(path)
 
 	var response *http.Response
@@ -94,6 +137,11 @@ func (c *HTTP2NetworkClient) Send(path string, message interface{}) (retBody []b
 		return
 	}
 	defer response.Body.Close()
+	if strings.HasPrefix(path, UrlPathPrefixNode) {
+		c.checkAcceptPost(response)
+	}
+
+	status = response.StatusCode
 	retBody, err = ioutil.ReadAll(response.Body)
 
 	if response.StatusCode != http.StatusOK {
@@ -103,12 +151,22 @@ func (c *HTTP2NetworkClient) Send(path string, message interface{}) (retBody []b
 	return
 }
 
+// checkAcceptPost updates `acceptRLP` by the `Accept-Post` header of the
+// response from node endpoints.
+func (c *HTTP2NetworkClient) checkAcceptPost(response *http.Response) {
+	if common.AcceptsContentType(response.Header, common.ContentTypeRLP) {
+		atomic.StoreInt32(&c.acceptRLP, 1)
+	} else {
+		atomic.StoreInt32(&c.acceptRLP, 0)
+	}
+}
+
 func (c *HTTP2NetworkClient) Connect(n node.Node) (body []byte, err error) {
 	return c.Send(UrlPathPrefixNode+"/connect", n)
 }
 
 func (c *HTTP2NetworkClient) SendMessage(message interface{}) (retBody []byte, err error) {
-	return c.Send(UrlPathPrefixNode+"/message", message)
+	return c.SendWire(UrlPathPrefixNode+"/message", message)
 }
 
 func (c *HTTP2NetworkClient) SendTransaction(message interface{}) (retBody []byte, err error) {
@@ -120,7 +178,7 @@ func (c *HTTP2NetworkClient) SendDiscovery(message interface{}) (retBody []byte,
 }
 
 func (c *HTTP2NetworkClient) SendBallot(message interface{}) (retBody []byte, err error) {
-	return c.Send(UrlPathPrefixNode+"/ballot", message)
+	return c.SendWire(UrlPathPrefixNode+"/ballot", message)
 }
 
 func (c *HTTP2NetworkClient) GetTransactions(txs []string) (retBody []byte, err error) {
@@ -0,0 +1,89 @@
+package network
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+)
+
+type wireTestMessage struct {
+	Data string
+}
+
+func (m wireTestMessage) SerializeRLP() ([]byte, error) {
+	return common.EncodeToBytes(m)
+}
+
+func TestHTTP2NetworkClientSendWire(t *testing.T) {
+	var supportRLP bool
+	var received []string // content types of received requests
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		ioutil.ReadAll(r.Body)
+
+		ct := common.MediaType(r.Header.Get("Content-Type"))
+		if !supportRLP && ct != common.ContentTypeJSON {
+			// the older node does not have the route for RLP
+			http.NotFound(w, r)
+			return
+		}
+
+		received = append(received, ct)
+		if supportRLP {
+			w.Header().Set(common.HeaderAcceptPost, "application/json, application/x-sebak-rlp")
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	httpClient, err := common.NewHTTP2Client(defaultTimeout, defaultIdleTimeout, false)
+	require.NoError(t, err)
+	client := NewHTTP2NetworkClient(common.MustParseEndpoint(server.URL), httpClient)
+
+	message := wireTestMessage{Data: "showme"}
+
+	{ // until the peer announces RLP, JSON is sent
+		_, err = client.SendBallot(message)
+		require.NoError(t, err)
+		require.Equal(t, []string{common.ContentTypeJSON}, received)
+		require.False(t, client.AcceptRLP())
+	}
+
+	supportRLP = true
+	received = nil
+
+	{ // after the response with `Accept-Post`, RLP is sent
+		_, err = client.SendBallot(message)
+		require.NoError(t, err)
+		require.True(t, client.AcceptRLP())
+
+		_, err = client.SendBallot(message)
+		require.NoError(t, err)
+		require.Equal(t, []string{common.ContentTypeJSON, common.ContentTypeRLP}, received)
+	}
+
+	received = nil
+
+	{ // the message which does not support RLP is sent in JSON
+		_, err = client.SendBallot(map[string]string{"data": "showme"})
+		require.NoError(t, err)
+		require.Equal(t, []string{common.ContentTypeJSON}, received)
+	}
+
+	supportRLP = false
+	received = nil
+
+	{ // the peer is downgraded, falls back to JSON
+		_, err = client.SendBallot(message)
+		require.NoError(t, err)
+		require.Equal(t, []string{common.ContentTypeJSON}, received)
+		require.False(t, client.AcceptRLP())
+	}
+}
@@ -1,6 +1,9 @@
 package network
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
@@ -61,6 +64,17 @@ func (l *HTTP2ResponseLog15Writer) Flush() {
 	}
 }
 
+// Hijack lets the handler take over the connection, like websocket.
+func (l *HTTP2ResponseLog15Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := l.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("http: response can not be hijacked")
+	}
+
+	l.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
 type HTTP2Log15Handler struct {
 	log     logging.Logger
 	handler http.Handler
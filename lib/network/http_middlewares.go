@@ -1,6 +1,7 @@
 package network
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	logging "github.com/inconshreveable/log15"
 	"github.com/ulule/limiter"
 	"github.com/ulule/limiter/drivers/middleware/stdlib"
+	limitercommon "github.com/ulule/limiter/drivers/store/common"
 	"github.com/ulule/limiter/drivers/store/memory"
 
 	"boscoin.io/sebak/lib/common"
@@ -61,11 +63,7 @@ func RateLimitMiddleware(logger logging.Logger, rule common.RateLimitRule) mux.M
 		logger = log
 	}
 
-	store := memory.NewStoreWithOptions(
-		limiter.StoreOptions{
-			CleanUpInterval: time.Duration(2) * time.Minute,
-		},
-	)
+	store := newRateLimitStore(time.Duration(2) * time.Minute)
 
 	var defaultMiddleware *stdlib.Middleware
 	if rule.Default.Limit > 0 {
@@ -174,6 +172,90 @@ func RateLimitMiddleware(logger logging.Logger, rule common.RateLimitRule) mux.M
 				return
 			}
 
+			next.ServeHTTP(w, withRateLimitCharge(r, store, middleware.Limiter.Rate, ip))
+		})
+	}
+}
+
+type rateLimitChargeKey struct{}
+
+type rateLimitChargeFunc func(n int) (bool, error)
+
+func withRateLimitCharge(r *http.Request, store *rateLimitStore, rate limiter.Rate, ip string) *http.Request {
+	var charge rateLimitChargeFunc = func(n int) (bool, error) {
+		if n < 1 {
+			return true, nil
+		}
+		return !store.charge(ip, rate, int64(n)).Reached, nil
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), rateLimitChargeKey{}, charge))
+}
+
+// rateLimitStore is the in-memory `limiter.Store`, which also charges several
+// requests at once.
+type rateLimitStore struct {
+	cache *memory.CacheWrapper
+}
+
+func newRateLimitStore(cleanUpInterval time.Duration) *rateLimitStore {
+	return &rateLimitStore{cache: memory.NewCache(cleanUpInterval)}
+}
+
+func (s *rateLimitStore) Get(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	return s.charge(key, rate, 1), nil
+}
+
+func (s *rateLimitStore) Peek(ctx context.Context, key string, rate limiter.Rate) (limiter.Context, error) {
+	count, expiration := s.cache.Get(key, rate.Period)
+	return limitercommon.GetContextFromState(time.Now(), rate, expiration, count), nil
+}
+
+// charge increments the count of the key by `n` at once.
+func (s *rateLimitStore) charge(key string, rate limiter.Rate, n int64) limiter.Context {
+	count, expiration := s.cache.Increment(key, n, rate.Period)
+	return limitercommon.GetContextFromState(time.Now(), rate, expiration, count)
+}
+
+// ChargeRateLimit charges the `n` more requests to the rate limit of the
+// request, like the request, which has several items; if the limit is
+// reached, it returns false. Without the rate limit, it returns true.
+func ChargeRateLimit(r *http.Request, n int) (bool, error) {
+	charge, ok := r.Context().Value(rateLimitChargeKey{}).(rateLimitChargeFunc)
+	if !ok {
+		return true, nil
+	}
+
+	return charge(n)
+}
+
+// PeerBanMiddleware rejects the requests from the banned peers.
+func PeerBanMiddleware(logger logging.Logger, scorer *PeerScorer) mux.MiddlewareFunc {
+	if logger == nil {
+		logger = log
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if peer := PeerFromRequest(r); scorer.IsBanned(peer) {
+				logger.Debug("request from banned peer", "peer", peer, "url", r.URL.String())
+				httputils.WriteJSONError(w, errors.PeerBanned)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AcceptPostMiddleware announces the content types, which the node endpoints
+// can receive, thru the `Accept-Post` header.
+func AcceptPostMiddleware(contentTypes ...string) mux.MiddlewareFunc {
+	value := strings.Join(contentTypes, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(common.HeaderAcceptPost, value)
 			next.ServeHTTP(w, r)
 		})
 	}
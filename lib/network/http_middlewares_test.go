@@ -321,3 +321,63 @@ func TestRateLimitMiddleWareUnlimit(t *testing.T) {
 		require.Equal(t, []byte("1"), body)
 	}
 }
+
+func TestRateLimitMiddleWareCharge(t *testing.T) {
+	handlerURL := UrlPathPrefixAPI + "/test"
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if ok, err := ChargeRateLimit(r, 3); err != nil || !ok {
+			httputils.WriteJSONError(w, errors.TooManyRequests)
+			return
+		}
+		w.Write([]byte("1"))
+	}
+
+	// 5 requests per minute
+	rate := limiter.Rate{
+		Period: 1 * time.Minute,
+		Limit:  5,
+	}
+	router := mux.NewRouter()
+	router.Use(RateLimitMiddleware(nil, common.NewRateLimitRule(rate)))
+	router.HandleFunc(handlerURL, http.HandlerFunc(handler)).Methods("GET")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// the request and 3 more are charged
+	resp, err := testRequestForRateLimit(ts, handlerURL, "3.3.3.3")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// only 1 is remaining
+	resp, err = testRequestForRateLimit(ts, handlerURL, "3.3.3.3")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	{ // without rate limit, nothing is charged
+		r := httptest.NewRequest("GET", handlerURL, nil)
+		ok, err := ChargeRateLimit(r, 1000)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
+
+func TestAcceptPostMiddleware(t *testing.T) {
+	handlerURL := UrlPathPrefixNode + "/test"
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("1"))
+	}
+
+	router := mux.NewRouter()
+	router.Use(AcceptPostMiddleware(common.ContentTypeJSON, common.ContentTypeRLP))
+	router.HandleFunc(handlerURL, http.HandlerFunc(handler)).Methods("POST")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	resp, err := ts.Client().Post(ts.URL+handlerURL, common.ContentTypeJSON, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "application/json, application/x-sebak-rlp", resp.Header.Get(common.HeaderAcceptPost))
+	require.True(t, common.AcceptsContentType(resp.Header, common.ContentTypeRLP))
+}
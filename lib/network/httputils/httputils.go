@@ -25,6 +25,13 @@ var (
 		errors.BlockAccountDoesNotExists.Code:     http.StatusNotFound,
 		errors.TransactionPoolFull.Code:           http.StatusLocked,
 		errors.BadRequestParameter.Code:           http.StatusBadRequest,
+		errors.PeerBanned.Code:                    http.StatusForbidden,
+		errors.UnsupportedContentType.Code:        http.StatusUnsupportedMediaType,
+		errors.StateSnapshotNotFound.Code:         http.StatusNotFound,
+		errors.DataPruned.Code:                    http.StatusGone,
+		errors.LedgerNotFound.Code:                http.StatusNotFound,
+		errors.DataEntryNotFound.Code:             http.StatusNotFound,
+		errors.NotImplemented.Code:                http.StatusNotImplemented,
 	}
 )
 
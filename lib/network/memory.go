@@ -3,6 +3,7 @@ package network
 import (
 	"io"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -22,6 +23,7 @@ type MemoryNetwork struct {
 	peers map[ /* endpoint */ string]*MemoryNetwork
 
 	messageBroker MessageBroker
+	pending       *int64 // the messages, which are not handled yet
 }
 
 func (t *MemoryNetwork) GetClient(endpoint *common.Endpoint) NetworkClient {
@@ -71,6 +73,7 @@ func (p *MemoryNetwork) GetNodeInfo() []byte {
 }
 
 func (p *MemoryNetwork) Send(mt common.MessageType, b []byte) (err error) {
+	atomic.AddInt64(p.pending, 1)
 	p.connWriter <- common.NewNetworkMessage(mt, b)
 
 	return
@@ -95,6 +98,17 @@ func (p *MemoryNetwork) receiveMessage() {
 	}
 }
 
+// MessageHandled decreases the pending messages.
+func (p *MemoryNetwork) MessageHandled() {
+	atomic.AddInt64(p.pending, -1)
+}
+
+// Pending returns the number of the messages, which are sent or received by
+// `MessageBroker`, but not handled yet.
+func (p *MemoryNetwork) Pending() int {
+	return int(atomic.LoadInt64(p.pending))
+}
+
 func (p *MemoryNetwork) SetLocalNode(localNode *node.LocalNode) {
 	p.localNode = localNode
 }
@@ -117,6 +131,7 @@ func (prev *MemoryNetwork) NewMemoryNetwork() *MemoryNetwork {
 		receiveChannel: make(chan common.NetworkMessage),
 		close:          make(chan bool),
 		peers:          peers,
+		pending:        new(int64),
 	}
 
 	n.peers[n.endpoint.String()] = n
@@ -144,5 +159,6 @@ func (r *MemoryMessageBroker) Response(w io.Writer, o []byte) error {
 }
 
 func (r *MemoryMessageBroker) Receive(m common.NetworkMessage) {
+	atomic.AddInt64(r.network.pending, 1)
 	r.network.ReceiveChannel() <- m
 }
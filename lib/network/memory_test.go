@@ -120,3 +120,30 @@ func TestMemoryNetworkConnect(t *testing.T) {
 		return
 	}
 }
+
+func TestMemoryNetworkPending(t *testing.T) {
+	s0, _ := CreateMemoryNetwork(nil)
+	go s0.Start()
+
+	c0 := s0.GetClient(s0.Endpoint())
+	go c0.SendMessage(NewDummyMessage("findme"))
+	go s0.MessageBroker().Receive(common.NewNetworkMessage(common.BallotMessage, []byte("ballot")))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-s0.ReceiveMessage():
+		case <-time.After(1 * time.Second):
+			t.Fatal("failed to get message")
+		}
+	}
+	// the received messages are pending until they are handled
+	if s0.Pending() != 2 {
+		t.Errorf("pending messages; %d != 2", s0.Pending())
+	}
+
+	s0.MessageHandled()
+	s0.MessageHandled()
+	if s0.Pending() != 0 {
+		t.Errorf("pending messages; %d != 0", s0.Pending())
+	}
+}
@@ -0,0 +1,258 @@
+package network
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/metrics"
+)
+
+const (
+	PeerFailureUnknownValidator = "unknown-validator"
+	PeerFailureBadSignature     = "bad-signature"
+	PeerFailureMalformed        = "malformed"
+)
+
+// PeerPenalties is the score added to a peer for each kind of failure. The
+// invalid voting basis is not scored, because the honest validators send it
+// whenever they are ahead of or behind this node.
+var PeerPenalties = map[string]int{
+	PeerFailureUnknownValidator: 20,
+	PeerFailureBadSignature:     50,
+	PeerFailureMalformed:        10,
+}
+
+// PeerFailureFromError finds the failure reason of the checker error. If the
+// error is not caused by the misbehavior of peer, it returns empty string.
+func PeerFailureFromError(err error) string {
+	switch err {
+	case nil:
+		return ""
+	case keypair.ErrInvalidSignature:
+		return PeerFailureBadSignature
+	}
+
+	switch e := err.(type) {
+	case *errors.Error:
+		switch e.Code {
+		case errors.BallotFromUnknownValidator.Code, errors.DiscoveryFromUnknownValidator.Code:
+			return PeerFailureUnknownValidator
+		case errors.SignatureVerificationFailed.Code:
+			return PeerFailureBadSignature
+		case errors.HashDoesNotMatch.Code, errors.InvalidMessage.Code, errors.InvalidState.Code,
+			errors.InvalidWireEncoding.Code:
+			return PeerFailureMalformed
+		}
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return PeerFailureMalformed
+	}
+
+	return ""
+}
+
+// PeerFromRequest returns the peer id of the request, it is the ip address of
+// remote peer.
+func PeerFromRequest(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// PeerState is the state of one peer in `PeerScorer`.
+type PeerState struct {
+	Peer        string            `json:"peer"`
+	Score       int               `json:"score"`
+	Failures    map[string]uint64 `json:"failures"`
+	LastFailure time.Time         `json:"last_failure"`
+	Bans        uint64            `json:"bans"`
+	BannedUntil time.Time         `json:"banned_until,omitempty"`
+	Banned      bool              `json:"banned"`
+}
+
+// PeerScorer keeps the misbehavior score of the remote peers. If the score
+// of peer reaches the `threshold`, the peer is banned for `banDuration`. The
+// score is forgotten after `banDuration` without any failure.
+//
+// If `threshold` is 0, the peer will not be banned.
+type PeerScorer struct {
+	sync.Mutex
+
+	threshold   int
+	banDuration time.Duration
+	peers       map[string]*PeerState
+	now         func() time.Time
+	log         logging.Logger
+}
+
+func NewPeerScorer(threshold int, banDuration time.Duration) *PeerScorer {
+	return &PeerScorer{
+		threshold:   threshold,
+		banDuration: banDuration,
+		peers:       map[string]*PeerState{},
+		now:         time.Now,
+		log:         log,
+	}
+}
+
+func (p *PeerScorer) Threshold() int {
+	return p.threshold
+}
+
+func (p *PeerScorer) BanDuration() time.Duration {
+	return p.banDuration
+}
+
+// Punish adds the penalty to the peer by the given error. It returns true if
+// the peer is banned by this failure.
+func (p *PeerScorer) Punish(peer string, err error) bool {
+	reason := PeerFailureFromError(err)
+	if len(reason) < 1 {
+		return false
+	}
+
+	return p.Add(peer, reason)
+}
+
+// Add adds the penalty of `reason` to the peer.
+func (p *PeerScorer) Add(peer, reason string) (banned bool) {
+	if p == nil || len(peer) < 1 {
+		return false
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.now()
+
+	state, found := p.peers[peer]
+	if !found {
+		state = &PeerState{Peer: peer, Failures: map[string]uint64{}}
+		p.peers[peer] = state
+	}
+
+	p.expire(state, now)
+	if state.Banned {
+		return false
+	}
+
+	if !state.LastFailure.IsZero() && now.Sub(state.LastFailure) > p.banDuration {
+		state.Score = 0
+	}
+
+	state.Score += PeerPenalties[reason]
+	state.Failures[reason]++
+	state.LastFailure = now
+
+	metrics.Peer.Penalties.With("reason", reason).Add(1)
+
+	if p.threshold < 1 || state.Score < p.threshold {
+		return false
+	}
+
+	state.Banned = true
+	state.Bans++
+	state.BannedUntil = now.Add(p.banDuration)
+
+	metrics.Peer.Bans.Add(1)
+	metrics.Peer.BannedPeers.Add(1)
+
+	p.log.Warn(
+		"peer banned",
+		"peer", peer,
+		"score", state.Score,
+		"failures", state.Failures,
+		"until", state.BannedUntil,
+	)
+
+	return true
+}
+
+// expire releases the expired ban.
+func (p *PeerScorer) expire(state *PeerState, now time.Time) {
+	if !state.Banned || now.Before(state.BannedUntil) {
+		return
+	}
+
+	state.Banned = false
+	state.BannedUntil = time.Time{}
+	state.Score = 0
+
+	metrics.Peer.BannedPeers.Add(-1)
+}
+
+func (p *PeerScorer) IsBanned(peer string) bool {
+	if p == nil {
+		return false
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	state, found := p.peers[peer]
+	if !found {
+		return false
+	}
+	p.expire(state, p.now())
+
+	return state.Banned
+}
+
+// Unban releases the ban of peer and resets the score.
+func (p *PeerScorer) Unban(peer string) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	state, found := p.peers[peer]
+	if !found {
+		return false
+	}
+
+	if state.Banned {
+		metrics.Peer.BannedPeers.Add(-1)
+	}
+	state.Banned = false
+	state.BannedUntil = time.Time{}
+	state.Score = 0
+
+	return true
+}
+
+// Peers returns the states of the known peers, sorted by score.
+func (p *PeerScorer) Peers() []PeerState {
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.now()
+
+	var states []PeerState
+	for _, state := range p.peers {
+		p.expire(state, now)
+
+		s := *state
+		s.Failures = map[string]uint64{}
+		for k, v := range state.Failures {
+			s.Failures[k] = v
+		}
+		states = append(states, s)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Score == states[j].Score {
+			return states[i].Peer < states[j].Peer
+		}
+		return states[i].Score > states[j].Score
+	})
+
+	return states
+}
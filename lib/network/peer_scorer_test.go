@@ -0,0 +1,131 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestPeerFailureFromError(t *testing.T) {
+	require.Equal(t, PeerFailureUnknownValidator, PeerFailureFromError(errors.BallotFromUnknownValidator))
+	require.Equal(t, PeerFailureUnknownValidator, PeerFailureFromError(errors.DiscoveryFromUnknownValidator))
+	require.Equal(t, PeerFailureBadSignature, PeerFailureFromError(keypair.ErrInvalidSignature))
+	require.Equal(t, PeerFailureMalformed, PeerFailureFromError(errors.InvalidWireEncoding.Clone()))
+
+	var v map[string]interface{}
+	err := json.Unmarshal([]byte("{showme"), &v)
+	require.Equal(t, PeerFailureMalformed, PeerFailureFromError(err))
+
+	// not misbehavior
+	require.Equal(t, "", PeerFailureFromError(nil))
+	require.Equal(t, "", PeerFailureFromError(errors.NewButKnownMessage))
+	require.Equal(t, "", PeerFailureFromError(errors.InvalidVotingBasis))
+	require.Equal(t, "", PeerFailureFromError(errors.TransactionAlreadyExistsInPool))
+}
+
+func TestPeerScorerBan(t *testing.T) {
+	now := time.Now()
+	scorer := NewPeerScorer(100, time.Minute)
+	scorer.now = func() time.Time { return now }
+
+	peer := "1.2.3.4"
+
+	// not misbehavior, not counted
+	require.False(t, scorer.Punish(peer, errors.NewButKnownMessage))
+	require.False(t, scorer.Punish(peer, errors.InvalidVotingBasis))
+	require.Equal(t, 0, len(scorer.Peers()))
+
+	require.False(t, scorer.Punish(peer, keypair.ErrInvalidSignature))
+	require.False(t, scorer.IsBanned(peer))
+	require.True(t, scorer.Punish(peer, keypair.ErrInvalidSignature))
+	require.True(t, scorer.IsBanned(peer))
+	require.False(t, scorer.IsBanned("5.6.7.8"))
+
+	peers := scorer.Peers()
+	require.Equal(t, 1, len(peers))
+	require.Equal(t, peer, peers[0].Peer)
+	require.Equal(t, 100, peers[0].Score)
+	require.Equal(t, uint64(2), peers[0].Failures[PeerFailureBadSignature])
+	require.Equal(t, uint64(1), peers[0].Bans)
+	require.True(t, peers[0].Banned)
+
+	// ban is expired
+	now = now.Add(time.Minute)
+	require.False(t, scorer.IsBanned(peer))
+	peers = scorer.Peers()
+	require.Equal(t, 0, peers[0].Score)
+	require.False(t, peers[0].Banned)
+}
+
+func TestPeerScorerForgetScore(t *testing.T) {
+	now := time.Now()
+	scorer := NewPeerScorer(100, time.Minute)
+	scorer.now = func() time.Time { return now }
+
+	peer := "1.2.3.4"
+	require.False(t, scorer.Punish(peer, keypair.ErrInvalidSignature))
+
+	// without failure in `banDuration`, score is reset
+	now = now.Add(time.Minute + time.Second)
+	require.False(t, scorer.Punish(peer, keypair.ErrInvalidSignature))
+	require.False(t, scorer.IsBanned(peer))
+	require.Equal(t, 50, scorer.Peers()[0].Score)
+}
+
+func TestPeerScorerWithoutThreshold(t *testing.T) {
+	scorer := NewPeerScorer(0, time.Minute)
+
+	peer := "1.2.3.4"
+	for i := 0; i < 10; i++ {
+		require.False(t, scorer.Punish(peer, keypair.ErrInvalidSignature))
+	}
+	require.False(t, scorer.IsBanned(peer))
+	require.Equal(t, 500, scorer.Peers()[0].Score)
+}
+
+func TestPeerScorerUnban(t *testing.T) {
+	scorer := NewPeerScorer(10, time.Minute)
+
+	peer := "1.2.3.4"
+	require.True(t, scorer.Add(peer, PeerFailureMalformed))
+	require.True(t, scorer.IsBanned(peer))
+
+	require.True(t, scorer.Unban(peer))
+	require.False(t, scorer.IsBanned(peer))
+	require.False(t, scorer.Unban("5.6.7.8"))
+}
+
+func TestPeerBanMiddleware(t *testing.T) {
+	handlerURL := UrlPathPrefixNode + "/test"
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("1"))
+	}
+
+	scorer := NewPeerScorer(10, time.Minute)
+
+	router := mux.NewRouter()
+	router.Use(PeerBanMiddleware(nil, scorer))
+	router.HandleFunc(handlerURL, http.HandlerFunc(handler)).Methods("GET")
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL + handlerURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	scorer.Add("127.0.0.1", PeerFailureMalformed)
+
+	resp, err = ts.Client().Get(ts.URL + handlerURL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
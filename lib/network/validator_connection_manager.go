@@ -26,6 +26,7 @@ type ValidatorConnectionManager struct {
 
 	clients                       map[ /* hash of node.Endpoint() */ string]NetworkClient
 	connected                     map[ /* node.Address() */ string]bool
+	roundTripTimes                map[ /* node.Address() */ string]time.Duration
 	config                        common.Config
 	discoveryChannel              chan DiscoveryMessage
 	connectedEqualOrOverThreshold bool
@@ -49,7 +50,9 @@ func NewValidatorConnectionManager(
 		config:    config,
 		clients:   map[string]NetworkClient{},
 		connected: map[string]bool{},
-		log:       log.New(logging.Ctx{"node": localNode.Alias()}),
+
+		roundTripTimes: map[string]time.Duration{},
+		log:            log.New(logging.Ctx{"node": localNode.Alias()}),
 	}
 	cm.connected[localNode.Address()] = true
 	cm.discoveryChannel = make(chan DiscoveryMessage, 100)
@@ -105,6 +108,23 @@ func (c *ValidatorConnectionManager) Start() {
 	go c.watchForMetrics()
 }
 
+// Reconnect forgets the clients and the connection states of the validators,
+// so `connectingValidator` connects to them again with the new clients.
+func (c *ValidatorConnectionManager) Reconnect() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.clients = map[string]NetworkClient{}
+	for address := range c.connected {
+		if address == c.localNode.Address() {
+			continue
+		}
+		delete(c.connected, address)
+	}
+	c.connectedEqualOrOverThreshold = false
+	c.policy.SetConnected(c.countConnectedUnlocked())
+}
+
 // setConnected returns `true` when the validator is newly connected or
 // disconnected at first
 func (c *ValidatorConnectionManager) setConnected(v *node.Validator, connected bool) bool {
@@ -169,7 +189,11 @@ func (c *ValidatorConnectionManager) connectingValidator(v *node.Validator) {
 			continue
 		}
 
+		started := time.Now()
 		err := c.connectValidator(v)
+		if err == nil {
+			c.setRoundTripTime(v, time.Since(started))
+		}
 
 		if c.setConnected(v, err == nil) {
 			if err == nil {
@@ -195,6 +219,35 @@ func (c *ValidatorConnectionManager) connectingValidator(v *node.Validator) {
 	return
 }
 
+// setRoundTripTime updates the round trip time of the connection to the
+// validator; like TCP, it is smoothed by the previous ones.
+func (c *ValidatorConnectionManager) setRoundTripTime(v *node.Validator, rtt time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	if old, found := c.roundTripTimes[v.Address()]; found {
+		rtt = (7*old + rtt) / 8
+	}
+	c.roundTripTimes[v.Address()] = rtt
+}
+
+// RoundTripTimes returns the round trip times of the connected validators,
+// which are measured by the periodic connect requests.
+func (c *ValidatorConnectionManager) RoundTripTimes() map[string]time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+
+	rtts := map[string]time.Duration{}
+	for address, rtt := range c.roundTripTimes {
+		if !c.connected[address] {
+			continue
+		}
+		rtts[address] = rtt
+	}
+
+	return rtts
+}
+
 func (c *ValidatorConnectionManager) updateBallots() {
 	ballots := c.getBallots()
 	if len(ballots) == 0 {
@@ -417,6 +470,20 @@ func (c *ValidatorConnectionManager) Discovery(dm DiscoveryMessage) error {
 	return nil
 }
 
+// TriggerDiscovery broadcasts DiscoveryMessage to the discovery endpoints and
+// to the discovered validators, whether they are connected or not.
+func (c *ValidatorConnectionManager) TriggerDiscovery() {
+	endpoints := append([]*common.Endpoint{}, c.config.DiscoveryEndpoints...)
+	for _, v := range c.discovered() {
+		if v.Address() == c.localNode.Address() {
+			continue
+		}
+		endpoints = append(endpoints, v.Endpoint())
+	}
+
+	c.broadcastDiscovery(endpoints...)
+}
+
 func (c *ValidatorConnectionManager) discovered() (vs []*node.Validator) {
 	for _, v := range c.localNode.GetValidators() {
 		if v.Endpoint() == nil {
@@ -8,9 +8,11 @@ import (
 )
 
 type NodeInfo struct {
-	Node   NodeInfoNode  `json:"node"`
-	Policy NodePolicy    `json:"policy"`
-	Block  NodeBlockInfo `json:"block"`
+	Node     NodeInfoNode  `json:"node"`
+	Policy   NodePolicy    `json:"policy"`
+	Block    NodeBlockInfo `json:"block"`
+	Timeouts NodeTimeouts  `json:"timeouts"`
+	Sync     *NodeSyncInfo `json:"sync,omitempty"`
 }
 
 type NodeInfoNode struct {
@@ -24,11 +26,12 @@ type NodeInfoNode struct {
 }
 
 type NodePolicy struct {
-	NetworkID                 string        `json:"network-id"`      // network id
-	InitialBalance            common.Amount `json:"initial-balance"` // initial balance of genesis account
-	BaseReserve               common.Amount `json:"base-reserve"`    // base reserve for one account
-	BaseFee                   common.Amount `json:"base-fee"`        // base fee of operation
-	BlockTime                 time.Duration `json:"block-time"`      // block creation time
+	NetworkID                 string        `json:"network-id"`         // network id
+	InitialBalance            common.Amount `json:"initial-balance"`    // initial balance of genesis account
+	BaseReserve               common.Amount `json:"base-reserve"`       // base reserve for one account
+	DataEntryReserve          common.Amount `json:"data-entry-reserve"` // reserve for one data entry of account
+	BaseFee                   common.Amount `json:"base-fee"`           // base fee of operation
+	BlockTime                 time.Duration `json:"block-time"`         // block creation time
 	BlockTimeDelta            time.Duration `json:"block-time-delta"`
 	TimeoutINIT               time.Duration `json:"timeout-init"`
 	TimeoutSIGN               time.Duration `json:"timeout-sign"`
@@ -54,6 +57,46 @@ type NodeBlockInfo struct {
 	Confirmed string `json:"confirmed"`
 }
 
+// NodeTimeouts is the effective timeouts of ballot states; if `Adaptive` is
+// set, they are adapted to the network latency, see
+// `consensus.AdaptiveTimeouts`.
+type NodeTimeouts struct {
+	Adaptive      bool          `json:"adaptive"`
+	INIT          time.Duration `json:"init"`
+	SIGN          time.Duration `json:"sign"`
+	ACCEPT        time.Duration `json:"accept"`
+	ALLCONFIRM    time.Duration `json:"allconfirm"`
+	RoundTripTime time.Duration `json:"round-trip-time"` // round trip time of the connections to validators
+}
+
+// NodeSyncInfo is the progress of sync; `ETA` is 0 if the rate of sync is
+// unknown.
+type NodeSyncInfo struct {
+	Syncing         bool            `json:"syncing"`
+	StartHeight     uint64          `json:"start-height"`
+	TargetHeight    uint64          `json:"target-height"`
+	CurrentHeight   uint64          `json:"current-height"`
+	BlocksPerSecond float64         `json:"blocks-per-second"`
+	ETA             time.Duration   `json:"eta"`
+	Peers           []NodeSyncPeer  `json:"peers"`
+	Errors          []NodeSyncError `json:"errors"` // recent errors of fetch and validate
+}
+
+type NodeSyncPeer struct {
+	Address    string  `json:"address"`
+	Blocks     uint64  `json:"blocks"`
+	Failures   uint64  `json:"failures"`
+	InFlight   int     `json:"in-flight"`
+	Throughput float64 `json:"throughput"` // blocks per second
+	LastError  string  `json:"last-error,omitempty"`
+}
+
+type NodeSyncError struct {
+	Component string    `json:"component"` // "fetcher" or "validator"
+	Error     string    `json:"error"`
+	Time      time.Time `json:"time"`
+}
+
 type NodeVersion struct {
 	Version   string `json:"version"`
 	GitCommit string `json:"git-commit"`
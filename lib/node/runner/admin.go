@@ -0,0 +1,393 @@
+package runner
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/rpc"
+	jsonrpc "github.com/gorilla/rpc/json"
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// MaxLimitAdminPoolTransactions is the maximum number of the transactions
+// listed by `Admin.PoolTransactions`.
+const MaxLimitAdminPoolTransactions uint64 = 1000
+
+type AdminArgs struct{}
+
+// AdminResult is `false` when the action has nothing to do, like pausing the
+// paused proposing.
+type AdminResult bool
+
+type AdminSetLogLevelArgs struct {
+	Level string `json:"level"`
+}
+
+type AdminSetLogLevelResult struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous"`
+}
+
+type AdminRunningRound struct {
+	VotingBasis  voting.Basis                    `json:"voting_basis"`
+	Proposer     string                          `json:"proposer"`
+	Transactions map[string][]string             `json:"transactions"`
+	Voted        map[string]*consensus.RoundVote `json:"voted"`
+	Ballots      []ballot.Ballot                 `json:"ballots"`
+}
+
+// AdminRunningRoundsResult is `ISAAC.RunningRounds` by the index of round.
+type AdminRunningRoundsResult map[string]AdminRunningRound
+
+type AdminPoolTransactionsArgs struct {
+	Limit uint64 `json:"limit"`
+}
+
+type AdminPoolTransactionsResult struct {
+	Total        int                       `json:"total"`
+	Transactions []transaction.Transaction `json:"transactions"`
+}
+
+// AdminPeersResult is the misbehavior scores and the ban states of the remote
+// peers.
+type AdminPeersResult struct {
+	Threshold   int                 `json:"threshold"`
+	BanDuration string              `json:"ban_duration"`
+	Peers       []network.PeerState `json:"peers"`
+}
+
+type AdminEvictTransactionArgs struct {
+	Hash string `json:"hash"`
+}
+
+// adminApp is the "Admin" service of the admin API. Every action is logged to
+// the audit log with the client, whether it succeeds or not.
+type adminApp struct {
+	nr *NodeRunner
+}
+
+func (a *adminApp) audit(r *http.Request, action string, args interface{}, err error) {
+	ctx := logging.Ctx{
+		"action": action,
+		"remote": r.RemoteAddr,
+		"args":   args,
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		ctx["client"] = r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	if err != nil {
+		ctx["error"] = err
+		auditLog.Warn("admin action failed", ctx)
+		return
+	}
+	auditLog.Info("admin action", ctx)
+}
+
+func (a *adminApp) SetLogLevel(r *http.Request, args *AdminSetLogLevelArgs, result *AdminSetLogLevelResult) (err error) {
+	defer func() { a.audit(r, "SetLogLevel", args, err) }()
+
+	if a.nr.logLevelHandler == nil {
+		return errors.LogLevelNotChangeable
+	}
+
+	var level logging.Lvl
+	if level, err = logging.LvlFromString(args.Level); err != nil {
+		return
+	}
+
+	previous := a.nr.logLevelHandler.SetLevel(level)
+	*result = AdminSetLogLevelResult{Level: level.String(), Previous: previous.String()}
+
+	return
+}
+
+func (a *adminApp) RunningRounds(r *http.Request, args *AdminArgs, result *AdminRunningRoundsResult) (err error) {
+	defer func() { a.audit(r, "RunningRounds", args, err) }()
+
+	is := a.nr.Consensus()
+
+	// the rounds are copied, because they are changed while being encoded
+	rounds := AdminRunningRoundsResult{}
+	is.RLock()
+	for index, rr := range is.RunningRounds {
+		rr.RLock()
+		transactions := map[string][]string{}
+		for proposer, hashes := range rr.Transactions {
+			transactions[proposer] = append([]string{}, hashes...)
+		}
+		voted := map[string]*consensus.RoundVote{}
+		for proposer, rv := range rr.Voted {
+			voted[proposer] = copyRoundVote(rv)
+		}
+
+		rounds[index] = AdminRunningRound{
+			VotingBasis:  rr.VotingBasis,
+			Proposer:     rr.Proposer,
+			Transactions: transactions,
+			Voted:        voted,
+			Ballots:      append([]ballot.Ballot{}, rr.Ballots...),
+		}
+		rr.RUnlock()
+	}
+	is.RUnlock()
+	*result = rounds
+
+	return
+}
+
+func copyRoundVote(rv *consensus.RoundVote) *consensus.RoundVote {
+	copied := &consensus.RoundVote{
+		SIGN:   consensus.RoundVoteResult{},
+		ACCEPT: consensus.RoundVoteResult{},
+	}
+	for source, b := range rv.SIGN {
+		copied.SIGN[source] = b
+	}
+	for source, b := range rv.ACCEPT {
+		copied.ACCEPT[source] = b
+	}
+
+	return copied
+}
+
+func (a *adminApp) PoolTransactions(r *http.Request, args *AdminPoolTransactionsArgs, result *AdminPoolTransactionsResult) (err error) {
+	defer func() { a.audit(r, "PoolTransactions", args, err) }()
+
+	limit := args.Limit
+	if limit < 1 || limit > MaxLimitAdminPoolTransactions {
+		limit = MaxLimitAdminPoolTransactions
+	}
+
+	pool := a.nr.TransactionPool
+	txs := []transaction.Transaction{}
+	for _, hash := range pool.AvailableTransactions(int(limit)) {
+		if tx, found := pool.Get(hash); found {
+			txs = append(txs, tx)
+		}
+	}
+	*result = AdminPoolTransactionsResult{Total: pool.Len(), Transactions: txs}
+
+	return
+}
+
+func (a *adminApp) EvictTransaction(r *http.Request, args *AdminEvictTransactionArgs, result *AdminResult) (err error) {
+	defer func() { a.audit(r, "EvictTransaction", args, err) }()
+
+	if !a.nr.TransactionPool.Has(args.Hash) {
+		return errors.TransactionNotFound
+	}
+	a.nr.TransactionPool.Remove(args.Hash)
+	*result = true
+
+	return
+}
+
+func (a *adminApp) ReconnectValidators(r *http.Request, args *AdminArgs, result *AdminResult) (err error) {
+	defer func() { a.audit(r, "ReconnectValidators", args, err) }()
+
+	a.nr.ConnectionManager().Reconnect()
+	*result = true
+
+	return
+}
+
+func (a *adminApp) Discovery(r *http.Request, args *AdminArgs, result *AdminResult) (err error) {
+	defer func() { a.audit(r, "Discovery", args, err) }()
+
+	a.nr.ConnectionManager().TriggerDiscovery()
+	*result = true
+
+	return
+}
+
+func (a *adminApp) Peers(r *http.Request, args *AdminArgs, result *AdminPeersResult) (err error) {
+	defer func() { a.audit(r, "Peers", args, err) }()
+
+	scorer := a.nr.PeerScorer()
+	peers := scorer.Peers()
+	if peers == nil {
+		peers = []network.PeerState{}
+	}
+	*result = AdminPeersResult{
+		Threshold:   scorer.Threshold(),
+		BanDuration: scorer.BanDuration().String(),
+		Peers:       peers,
+	}
+
+	return
+}
+
+func (a *adminApp) PauseProposing(r *http.Request, args *AdminArgs, result *AdminResult) (err error) {
+	defer func() { a.audit(r, "PauseProposing", args, err) }()
+
+	*result = AdminResult(a.nr.PauseProposing())
+
+	return
+}
+
+func (a *adminApp) ResumeProposing(r *http.Request, args *AdminArgs, result *AdminResult) (err error) {
+	defer func() { a.audit(r, "ResumeProposing", args, err) }()
+
+	*result = AdminResult(a.nr.ResumeProposing())
+
+	return
+}
+
+// Shutdown starts the graceful shutdown after the response is sent.
+func (a *adminApp) Shutdown(r *http.Request, args *AdminArgs, result *AdminResult) (err error) {
+	defer func() { a.audit(r, "Shutdown", args, err) }()
+
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		a.nr.shutdown()
+	}()
+	*result = true
+
+	return
+}
+
+// adminServer serves the admin API in JSON-RPC like `jsonrpcServer`. The
+// server, which is not bound to localhost, should be protected by the token
+// or by the client certificate.
+type adminServer struct {
+	endpoint *common.Endpoint
+	token    string
+	nr       *NodeRunner
+	server   *http.Server
+	rpc      *rpc.Server
+}
+
+func newAdminServer(nr *NodeRunner, endpoint *common.Endpoint, token string) (*adminServer, error) {
+	if err := CheckAdminEndpoint(endpoint, token); err != nil {
+		return nil, err
+	}
+
+	return &adminServer{
+		endpoint: endpoint,
+		token:    token,
+		nr:       nr,
+		server:   &http.Server{Addr: endpoint.Host},
+	}, nil
+}
+
+// CheckAdminEndpoint checks the admin API is bound to localhost or protected
+// by the token or by the client certificate of `TLSClientCAFile`.
+func CheckAdminEndpoint(endpoint *common.Endpoint, token string) error {
+	if len(token) > 0 {
+		return nil
+	}
+	if strings.ToLower(endpoint.Scheme) == "https" && len(endpoint.Query().Get("TLSClientCAFile")) > 0 {
+		return nil
+	}
+
+	host := (*url.URL)(endpoint).Hostname()
+	if common.IsLocalhost(host) {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+
+	return errors.AdminNotProtected
+}
+
+// authorize checks the token of the request in `Authorization: Bearer
+// <token>`; the client certificate is already verified by TLS.
+func (s *adminServer) authorize(r *http.Request) bool {
+	if len(s.token) < 1 {
+		return true
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *adminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorize(r) {
+		auditLog.Warn("admin request refused", "remote", r.RemoteAddr, "error", errors.AdminUnauthorized)
+		http.Error(w, errors.AdminUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	s.rpc.ServeHTTP(w, r)
+}
+
+func (s *adminServer) Ready() *mux.Router {
+	s.rpc = rpc.NewServer()
+	s.rpc.RegisterCodec(jsonrpc.NewCodec(), "application/json")
+	s.rpc.RegisterCodec(jsonrpc.NewCodec(), "application/json;charset=UTF-8")
+	s.rpc.RegisterService(&adminApp{nr: s.nr}, "Admin")
+
+	router := mux.NewRouter()
+
+	path := s.endpoint.Path
+	if len(path) < 1 {
+		path = "/"
+	}
+	router.Handle(path, s).Methods("POST")
+
+	return router
+}
+
+func (s *adminServer) Start() error {
+	s.server.Handler = s.Ready()
+
+	err := func() error {
+		if strings.ToLower(s.endpoint.Scheme) == "http" {
+			return s.server.ListenAndServe()
+		}
+
+		if caFile := s.endpoint.Query().Get("TLSClientCAFile"); len(caFile) > 0 {
+			b, err := ioutil.ReadFile(caFile)
+			if err != nil {
+				return err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(b) {
+				return errors.Newf(errors.AdminNotProtected, "no certificate found in %q", caFile)
+			}
+			s.server.TLSConfig = &tls.Config{
+				ClientCAs:  pool,
+				ClientAuth: tls.RequireAndVerifyClientCert,
+			}
+		}
+
+		tlsCertFile := s.endpoint.Query().Get("TLSCertFile")
+		tlsKeyFile := s.endpoint.Query().Get("TLSKeyFile")
+
+		return s.server.ListenAndServeTLS(tlsCertFile, tlsKeyFile)
+	}()
+
+	if err == http.ErrServerClosed {
+		return nil
+	}
+
+	return err
+}
+
+// Stop waits for the running requests, like the response of
+// `Admin.Shutdown`.
+func (s *adminServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	s.server.Shutdown(ctx)
+}
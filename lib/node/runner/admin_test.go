@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsonrpc "github.com/gorilla/rpc/json"
+	logging "github.com/inconshreveable/log15"
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+type adminServerTestHelper struct {
+	t      *testing.T
+	nr     *NodeRunner
+	server *httptest.Server
+	token  string
+
+	audits []*logging.Record
+}
+
+func (h *adminServerTestHelper) prepare() {
+	nodeRunners, _ := createTestNodeRunnersHTTP2Network(1)
+	h.nr = nodeRunners[0]
+
+	as, err := newAdminServer(h.nr, common.MustParseEndpoint("http://localhost/admin"), h.token)
+	require.NoError(h.t, err)
+	h.server = httptest.NewServer(as.Ready())
+
+	SetAuditLogging(logging.FuncHandler(func(r *logging.Record) error {
+		h.audits = append(h.audits, r)
+		return nil
+	}))
+}
+
+func (h *adminServerTestHelper) done() {
+	SetAuditLogging(common.DefaultLogHandler)
+	h.server.Close()
+	h.nr.Storage().Close()
+}
+
+func (h *adminServerTestHelper) request(method string, args, result interface{}) (*http.Response, error) {
+	message, err := jsonrpc.EncodeClientRequest(method, args)
+	require.NoError(h.t, err)
+
+	req, err := http.NewRequest("POST", h.server.URL+"/admin", bytes.NewBuffer(message))
+	require.NoError(h.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if len(h.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	return resp, jsonrpc.DecodeClientResponse(resp.Body, result)
+}
+
+func TestCheckAdminEndpoint(t *testing.T) {
+	for _, bind := range []string{
+		"http://localhost:54322/admin",
+		"http://127.0.0.1:54322/admin",
+		"http://[::1]:54322/admin",
+		"https://0.0.0.0:54322/admin?TLSClientCAFile=ca.crt",
+	} {
+		require.NoError(t, CheckAdminEndpoint(common.MustParseEndpoint(bind), ""), bind)
+	}
+
+	require.Equal(t, errors.AdminNotProtected, CheckAdminEndpoint(common.MustParseEndpoint("http://0.0.0.0:54322/admin"), ""))
+	require.Equal(t, errors.AdminNotProtected, CheckAdminEndpoint(common.MustParseEndpoint("https://10.0.0.1:54322/admin"), ""))
+	require.NoError(t, CheckAdminEndpoint(common.MustParseEndpoint("http://0.0.0.0:54322/admin"), "token"))
+}
+
+func TestAdminServerToken(t *testing.T) {
+	h := &adminServerTestHelper{t: t, token: "showmethemoney"}
+	h.prepare()
+	defer h.done()
+
+	var result AdminResult
+	resp, err := h.request("Admin.PauseProposing", &AdminArgs{}, &result)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.True(t, bool(result))
+
+	h.token = "wrong"
+	resp, err = h.request("Admin.ResumeProposing", &AdminArgs{}, &result)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.True(t, h.nr.ProposingPaused())
+
+	require.Equal(t, 2, len(h.audits))
+	require.Equal(t, "admin request refused", h.audits[1].Msg)
+}
+
+func TestAdminServerActions(t *testing.T) {
+	h := &adminServerTestHelper{t: t}
+	h.prepare()
+	defer h.done()
+
+	{ // log level
+		var result AdminSetLogLevelResult
+		_, err := h.request("Admin.SetLogLevel", &AdminSetLogLevelArgs{Level: "debug"}, &result)
+		require.Error(t, err)
+
+		levelHandler := common.NewLevelHandler(logging.LvlInfo, logging.DiscardHandler())
+		h.nr.SetLogLevelHandler(levelHandler)
+
+		_, err = h.request("Admin.SetLogLevel", &AdminSetLogLevelArgs{Level: "debug"}, &result)
+		require.NoError(t, err)
+		require.Equal(t, AdminSetLogLevelResult{Level: "dbug", Previous: "info"}, result)
+		require.Equal(t, logging.LvlDebug, levelHandler.Level())
+
+		_, err = h.request("Admin.SetLogLevel", &AdminSetLogLevelArgs{Level: "unknown"}, &result)
+		require.Error(t, err)
+	}
+
+	{ // running rounds
+		var result AdminRunningRoundsResult
+		_, err := h.request("Admin.RunningRounds", &AdminArgs{}, &result)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(result))
+	}
+
+	{ // pool
+		_, tx := transaction.TestMakeTransaction(h.nr.NetworkID(), 1)
+		require.NoError(t, h.nr.TransactionPool.Add(tx))
+
+		var result AdminPoolTransactionsResult
+		_, err := h.request("Admin.PoolTransactions", &AdminPoolTransactionsArgs{}, &result)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Total)
+		require.Equal(t, tx.GetHash(), result.Transactions[0].GetHash())
+
+		var evicted AdminResult
+		_, err = h.request("Admin.EvictTransaction", &AdminEvictTransactionArgs{Hash: tx.GetHash()}, &evicted)
+		require.NoError(t, err)
+		require.True(t, bool(evicted))
+		require.False(t, h.nr.TransactionPool.Has(tx.GetHash()))
+
+		_, err = h.request("Admin.EvictTransaction", &AdminEvictTransactionArgs{Hash: tx.GetHash()}, &evicted)
+		require.Error(t, err)
+	}
+
+	{ // peers
+		h.nr.PeerScorer().Add("1.2.3.4", network.PeerFailureMalformed)
+
+		var result AdminPeersResult
+		_, err := h.request("Admin.Peers", &AdminArgs{}, &result)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(result.Peers))
+		require.Equal(t, "1.2.3.4", result.Peers[0].Peer)
+		require.Equal(t, network.PeerPenalties[network.PeerFailureMalformed], result.Peers[0].Score)
+		require.False(t, result.Peers[0].Banned)
+	}
+
+	{ // proposing
+		var result AdminResult
+		_, err := h.request("Admin.PauseProposing", &AdminArgs{}, &result)
+		require.NoError(t, err)
+		require.True(t, bool(result))
+		require.True(t, h.nr.ProposingPaused())
+
+		_, err = h.request("Admin.PauseProposing", &AdminArgs{}, &result)
+		require.NoError(t, err)
+		require.False(t, bool(result))
+
+		_, err = h.request("Admin.ResumeProposing", &AdminArgs{}, &result)
+		require.NoError(t, err)
+		require.True(t, bool(result))
+		require.False(t, h.nr.ProposingPaused())
+	}
+
+	{ // shutdown
+		shutdown := make(chan struct{})
+		h.nr.SetShutdownFunc(func() { close(shutdown) })
+
+		var result AdminResult
+		_, err := h.request("Admin.Shutdown", &AdminArgs{}, &result)
+		require.NoError(t, err)
+		<-shutdown
+	}
+
+	// every action is audited
+	var failed int
+	for _, r := range h.audits {
+		if r.Lvl == logging.LvlWarn {
+			failed++
+		}
+	}
+	require.Equal(t, 12, len(h.audits))
+	require.Equal(t, 3, failed)
+}
@@ -44,6 +44,41 @@ func (api NetworkHandlerAPI) GetAccountHandler(w http.ResponseWriter, r *http.Re
 	httputils.MustWriteJSON(w, 200, payload)
 }
 
+// GetAccountDataHandler returns the data entry of account by the key.
+func (api NetworkHandlerAPI) GetAccountDataHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	address := vars["id"]
+	key := vars["key"]
+
+	readFunc := func() (payload interface{}, err error) {
+		found, err := block.ExistsBlockAccount(api.storage, address)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errors.BlockAccountDoesNotExists
+		}
+		ba, err := block.GetBlockAccount(api.storage, address)
+		if err != nil {
+			return nil, err
+		}
+		value, found := ba.GetData(key)
+		if !found {
+			return nil, errors.DataEntryNotFound
+		}
+		payload = resource.NewAccountData(address, key, value)
+		return payload, nil
+	}
+
+	payload, err := readFunc()
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	httputils.MustWriteJSON(w, 200, payload)
+}
+
 func (api NetworkHandlerAPI) GetAccountsHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -105,18 +140,6 @@ func (api NetworkHandlerAPI) GetFrozenAccountsByAccountHandler(w http.ResponseWr
 		var txs []resource.Resource
 		iterFunc, closeFunc := block.GetBlockOperationsByLinked(api.storage, address, options)
 		for {
-			var (
-				createdBlockHeight        uint64
-				createdOpHash             string
-				sequenceid                uint64
-				amount                    common.Amount
-				state                     resource.FrozenAccountState
-				unfreezingBlockHeight     uint64
-				unfreezingOpHash          string
-				unfreezingRemainingBlocks uint64
-				paymentOpHash             string
-			)
-
 			bo, hasNext, c := iterFunc()
 			if !hasNext {
 				break
@@ -125,68 +148,11 @@ func (api NetworkHandlerAPI) GetFrozenAccountsByAccountHandler(w http.ResponseWr
 			if len(firstCursor) == 0 {
 				firstCursor = append(firstCursor, c...)
 			}
-			var (
-				casted operation.CreateAccount
-				ok     bool
-				body   operation.Body
-			)
-			if body, err = operation.UnmarshalBodyJSON(bo.Type, bo.Body); err != nil {
-				break
-			}
-			if casted, ok = body.(operation.CreateAccount); !ok {
-				break
-			}
-			createdBlockHeight = bo.Height
-			createdOpHash = bo.OpHash
-			var tx block.BlockTransaction
-			if tx, err = block.GetBlockTransaction(api.storage, bo.TxHash); err != nil {
-				break
-			}
-			sequenceid = tx.SequenceID
-			amount = casted.Amount
-
-			opIterFunc, opCloseFunc := block.GetBlockOperationsBySource(api.storage, casted.Target, nil)
-			state = resource.FrozenState
-			for {
-				bo, hasNext, _ := opIterFunc()
-				switch bo.Type {
-				case operation.TypeUnfreezingRequest:
-					lastblock := block.GetLatestBlock(api.storage)
-					if lastblock.Height-bo.Height >= common.UnfreezingPeriod {
-						state = resource.UnfrozenState
-					} else {
-						unfreezingRemainingBlocks = bo.Height + common.UnfreezingPeriod - lastblock.Height
-						state = resource.MeltingState
-					}
-					unfreezingOpHash = bo.OpHash
-					unfreezingBlockHeight = bo.Height
-				case operation.TypePayment:
-					state = resource.ReturnedState
-					paymentOpHash = bo.OpHash
-				}
-				if !hasNext {
-					break
-				}
-			}
-			opCloseFunc()
-
-			info := resource.FrozenAccountInfo{
-				CreatedBlockHeight:           createdBlockHeight,
-				CreatedOpHash:                createdOpHash,
-				CreatedSequenceId:            sequenceid,
-				InitialAmount:                amount,
-				FreezingState:                state,
-				UnfreezingRequestBlockHeight: unfreezingBlockHeight,
-				UnfreezingRequestOpHash:      unfreezingOpHash,
-				UnfreezingRemainingBlocks:    unfreezingRemainingBlocks,
-				PaymentOpHash:                paymentOpHash,
-			}
-			var ba *block.BlockAccount
-			if ba, err = block.GetBlockAccount(api.storage, casted.Target); err != nil {
+
+			var frozenAccountResource *resource.FrozenAccount
+			if frozenAccountResource, err = api.newFrozenAccount(bo); err != nil {
 				break
 			}
-
-			frozenAccountResource := resource.NewFrozenAccount(ba, info)
 			txs = append(txs, frozenAccountResource)
 		}
 		closeFunc()
@@ -214,18 +180,6 @@ func (api NetworkHandlerAPI) GetFrozenAccountsHandler(w http.ResponseWriter, r *
 		var txs []resource.Resource
 		iterFunc, closeFunc := block.GetBlockOperationsByFrozen(api.storage, options)
 		for {
-			var (
-				createdBlockHeight        uint64
-				createdOpHash             string
-				sequenceid                uint64
-				amount                    common.Amount
-				state                     resource.FrozenAccountState
-				unfreezingBlockHeight     uint64
-				unfreezingOpHash          string
-				unfreezingRemainingBlocks uint64
-				paymentOpHash             string
-			)
-
 			bo, hasNext, c := iterFunc()
 			if !hasNext {
 				break
@@ -234,68 +188,11 @@ func (api NetworkHandlerAPI) GetFrozenAccountsHandler(w http.ResponseWriter, r *
 			if len(firstCursor) == 0 {
 				firstCursor = append(firstCursor, c...)
 			}
-			var (
-				casted operation.CreateAccount
-				ok     bool
-				body   operation.Body
-			)
-			if body, err = operation.UnmarshalBodyJSON(bo.Type, bo.Body); err != nil {
-				break
-			}
-			if casted, ok = body.(operation.CreateAccount); !ok {
-				break
-			}
-			createdBlockHeight = bo.Height
-			createdOpHash = bo.OpHash
-			var tx block.BlockTransaction
-			if tx, err = block.GetBlockTransaction(api.storage, bo.TxHash); err != nil {
-				break
-			}
-			sequenceid = tx.SequenceID
-			amount = casted.Amount
-
-			opIterFunc, opCloseFunc := block.GetBlockOperationsBySource(api.storage, casted.Target, nil)
-			state = resource.FrozenState
-			for {
-				bo, hasNext, _ := opIterFunc()
-				switch bo.Type {
-				case operation.TypePayment:
-					state = resource.ReturnedState
-					paymentOpHash = bo.OpHash
-				case operation.TypeUnfreezingRequest:
-					lastblock := block.GetLatestBlock(api.storage)
-					if lastblock.Height-bo.Height >= common.UnfreezingPeriod {
-						state = resource.UnfrozenState
-					} else {
-						unfreezingRemainingBlocks = bo.Height + common.UnfreezingPeriod - lastblock.Height
-						state = resource.MeltingState
-					}
-					unfreezingOpHash = bo.OpHash
-					unfreezingBlockHeight = bo.Height
-				}
-				if !hasNext {
-					break
-				}
-			}
-			opCloseFunc()
-
-			info := resource.FrozenAccountInfo{
-				CreatedBlockHeight:           createdBlockHeight,
-				CreatedOpHash:                createdOpHash,
-				CreatedSequenceId:            sequenceid,
-				InitialAmount:                amount,
-				FreezingState:                state,
-				UnfreezingRequestBlockHeight: unfreezingBlockHeight,
-				UnfreezingRequestOpHash:      unfreezingOpHash,
-				UnfreezingRemainingBlocks:    unfreezingRemainingBlocks,
-				PaymentOpHash:                paymentOpHash,
-			}
-			var ba *block.BlockAccount
-			if ba, err = block.GetBlockAccount(api.storage, casted.Target); err != nil {
+
+			var frozenAccountResource *resource.FrozenAccount
+			if frozenAccountResource, err = api.newFrozenAccount(bo); err != nil {
 				break
 			}
-
-			frozenAccountResource := resource.NewFrozenAccount(ba, info)
 			txs = append(txs, frozenAccountResource)
 		}
 		closeFunc()
@@ -306,3 +203,73 @@ func (api NetworkHandlerAPI) GetFrozenAccountsHandler(w http.ResponseWriter, r *
 	list := p.ResourceList(txs, firstCursor, cursor)
 	httputils.MustWriteJSON(w, 200, list)
 }
+
+// newFrozenAccount makes `resource.FrozenAccount` from the operation, which
+// created the frozen account.
+func (api NetworkHandlerAPI) newFrozenAccount(bo block.BlockOperation) (*resource.FrozenAccount, error) {
+	var (
+		state                     resource.FrozenAccountState
+		unfreezingBlockHeight     uint64
+		unfreezingOpHash          string
+		unfreezingRemainingBlocks uint64
+		paymentOpHash             string
+	)
+
+	body, err := operation.UnmarshalBodyJSON(bo.Type, bo.Body)
+	if err != nil {
+		return nil, err
+	}
+	casted, ok := body.(operation.CreateAccount)
+	if !ok {
+		return nil, errors.TypeOperationBodyNotMatched
+	}
+
+	tx, err := block.GetBlockTransaction(api.storage, bo.TxHash)
+	if err != nil {
+		return nil, err
+	}
+
+	opIterFunc, opCloseFunc := block.GetBlockOperationsBySource(api.storage, casted.Target, nil)
+	state = resource.FrozenState
+	for {
+		bo, hasNext, _ := opIterFunc()
+		switch bo.Type {
+		case operation.TypeUnfreezingRequest:
+			lastblock := block.GetLatestBlock(api.storage)
+			if lastblock.Height-bo.Height >= common.UnfreezingPeriod {
+				state = resource.UnfrozenState
+			} else {
+				unfreezingRemainingBlocks = bo.Height + common.UnfreezingPeriod - lastblock.Height
+				state = resource.MeltingState
+			}
+			unfreezingOpHash = bo.OpHash
+			unfreezingBlockHeight = bo.Height
+		case operation.TypePayment:
+			state = resource.ReturnedState
+			paymentOpHash = bo.OpHash
+		}
+		if !hasNext {
+			break
+		}
+	}
+	opCloseFunc()
+
+	info := resource.FrozenAccountInfo{
+		CreatedBlockHeight:           bo.Height,
+		CreatedOpHash:                bo.OpHash,
+		CreatedSequenceId:            tx.SequenceID,
+		InitialAmount:                casted.Amount,
+		FreezingState:                state,
+		UnfreezingRequestBlockHeight: unfreezingBlockHeight,
+		UnfreezingRequestOpHash:      unfreezingOpHash,
+		UnfreezingRemainingBlocks:    unfreezingRemainingBlocks,
+		PaymentOpHash:                paymentOpHash,
+	}
+
+	ba, err := block.GetBlockAccount(api.storage, casted.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource.NewFrozenAccount(ba, info), nil
+}
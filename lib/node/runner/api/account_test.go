@@ -51,6 +51,51 @@ func TestGetAccountHandler(t *testing.T) {
 	}
 }
 
+func TestGetAccountDataHandler(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	ba := block.TestMakeBlockAccount()
+	ba.ManageData("home_domain", []byte("boscoin.io"))
+	ba.MustSave(storage)
+
+	{
+		url := strings.NewReplacer("{id}", ba.Address, "{key}", "home_domain").Replace(GetAccountDataHandlerPattern)
+		respBody := request(ts, url, false)
+		defer respBody.Close()
+
+		readByte, err := ioutil.ReadAll(respBody)
+		require.NoError(t, err)
+		recv := make(map[string]interface{})
+		common.MustUnmarshalJSON(readByte, &recv)
+
+		require.Equal(t, ba.Address, recv["address"])
+		require.Equal(t, "home_domain", recv["key"])
+		require.Equal(t, "Ym9zY29pbi5pbw==", recv["value"])
+	}
+
+	{ // unknown key
+		url := strings.NewReplacer("{id}", ba.Address, "{key}", "kyc").Replace(GetAccountDataHandlerPattern)
+		req, _ := http.NewRequest("GET", ts.URL+url, nil)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	{ // unknown address
+		url := strings.NewReplacer("{id}", keypair.Random().Address(), "{key}", "home_domain").Replace(GetAccountDataHandlerPattern)
+		req, _ := http.NewRequest("GET", ts.URL+url, nil)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
+
 // Test that getting an inexisting account returns an error
 func TestGetNonExistentAccountHandler(t *testing.T) {
 
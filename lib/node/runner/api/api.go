@@ -1,14 +1,18 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
 	obs "boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/consensus"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
 	"boscoin.io/sebak/lib/storage"
-	"boscoin.io/sebak/lib/transaction"
 	"boscoin.io/sebak/lib/transaction/operation"
 )
 
@@ -21,6 +25,7 @@ const (
 	GetAccountsHandlerPattern              = "/accounts"
 	GetAccountOperationsHandlerPattern     = "/accounts/{id}/operations"
 	GetAccountFrozenAccountHandlerPattern  = "/accounts/{id}/frozen-accounts"
+	GetAccountDataHandlerPattern           = "/accounts/{id}/data/{key}"
 	GetFrozenAccountHandlerPattern         = "/frozen-accounts"
 	GetTransactionsHandlerPattern          = "/transactions"
 	GetTransactionByHashHandlerPattern     = "/transactions/{id}"
@@ -28,10 +33,16 @@ const (
 	GetTransactionOperationHandlerPattern  = "/transactions/{id}/operations/{opindex}"
 	GetTransactionStatusHandlerPattern     = "/transactions/{id}/status"
 	PostTransactionPattern                 = "/transactions"
+	PostTransactionsBatchPattern           = "/transactions/batch"
 	GetBlocksHandlerPattern                = "/blocks"
 	GetBlockHandlerPattern                 = "/blocks/{hashOrHeight}"
+	GetLedgerHandlerPattern                = "/ledger"
 	GetNodeInfoPattern                     = "/"
+	GetSyncHandlerPattern                  = "/sync"
 	PostSubscribePattern                   = "/subscribe"
+	GetSubscribeWebSocketPattern           = "/subscribe/ws"
+	GraphQLPattern                         = "/graphql"
+	GetOpenAPIHandlerPattern               = "/openapi.json"
 )
 
 type NetworkHandlerAPI struct {
@@ -42,6 +53,8 @@ type NetworkHandlerAPI struct {
 	version        string
 	nodeInfo       node.NodeInfo
 	GetLatestBlock func() block.Block
+	GetTimeouts    func() node.NodeTimeouts
+	GetSyncStatus  func(context.Context) (*node.NodeSyncInfo, error)
 }
 
 func NewNetworkHandlerAPI(localNode *node.LocalNode, network network.Network, storage *storage.LevelDBBackend, urlPrefix string, nodeInfo node.NodeInfo) *NetworkHandlerAPI {
@@ -59,46 +72,166 @@ func (api NetworkHandlerAPI) HandlerURLPattern(pattern string) string {
 	return fmt.Sprintf("%s/%s%s", api.urlPrefix, api.version, pattern)
 }
 
-func TriggerEvent(st *storage.LevelDBBackend, transactions []*transaction.Transaction) {
-	var (
-		t    = obs.ResourceObserver.Trigger
-		cond = obs.NewCondition
+// TriggerEvent triggers the events of the transactions, the accounts, the
+// frozen accounts and the block itself to `observer.ResourceObserver`.
+func TriggerEvent(st *storage.LevelDBBackend, blk block.Block) {
+	blockEvents(st, blk, func(event string, v interface{}, id obs.EventID) {
+		obs.ResourceObserver.Trigger(event, v, id)
+	})
+}
+
+// TriggerConsensusState triggers the event of the new consensus state to
+// `observer.ResourceObserver`. It is not stored, so it is not replayed.
+func TriggerConsensusState(state consensus.ISAACState) {
+	obs.ResourceObserver.Trigger(
+		obs.NewCondition(obs.ConsensusState, obs.All).String(),
+		resource.NewConsensusState(state.Height, state.Round, state.BallotState),
 	)
+}
+
+type frozenAccountEvent struct {
+	address string
+	info    resource.FrozenAccountInfo
+}
 
+// blockEvents calls fn with the events of the block in order.
+func blockEvents(st *storage.LevelDBBackend, blk block.Block, fn func(string, interface{}, obs.EventID)) error {
+	cond := obs.NewCondition
+
+	var index uint64
 	accountMap := make(map[string]struct{})
+	var frozens []frozenAccountEvent
 
-	for _, tx := range transactions {
-		source := tx.Source()
-		accountMap[source] = struct{}{}
-		txHash := tx.H.Hash
-		bt, err := block.GetBlockTransaction(st, tx.H.Hash)
+	for _, hash := range blk.Transactions {
+		bt, err := block.GetBlockTransaction(st, hash)
 		if err != nil {
-			return
+			return err
 		}
+		tp, err := block.GetTransactionPool(st, hash)
+		if err != nil {
+			return err
+		}
+		tx := tp.Transaction()
 
-		t(cond(obs.Tx, obs.All).String(), &bt)
-		t(cond(obs.Tx, obs.Source, source).String(), &bt)
-		t(cond(obs.Tx, obs.Identifier, txHash).String(), &bt)
+		id := obs.EventID{Height: blk.Height, Index: index}
+		source := tx.Source()
+		accountMap[source] = struct{}{}
 
-		for _, op := range tx.B.Operations {
-			if err != nil {
-				return
-			}
+		fn(cond(obs.Tx, obs.All).String(), &bt, id)
+		fn(cond(obs.Tx, obs.Source, source).String(), &bt, id)
+		fn(cond(obs.Tx, obs.Identifier, hash).String(), &bt, id)
 
+		for _, op := range tx.B.Operations {
 			if pop, ok := op.B.(operation.Targetable); ok {
 				target := pop.TargetAddress()
 				accountMap[target] = struct{}{}
-				t(cond(obs.Tx, obs.Target, target).String(), &bt)
+				fn(cond(obs.Tx, obs.Target, target).String(), &bt, id)
+			}
+
+			opHash := common.MustMakeObjectHashString(op)
+			switch pop := op.B.(type) {
+			case operation.CreateAccount:
+				if len(pop.Linked) < 1 {
+					break
+				}
+				frozens = append(frozens, frozenAccountEvent{
+					address: pop.Target,
+					info: resource.FrozenAccountInfo{
+						CreatedBlockHeight: blk.Height,
+						CreatedOpHash:      opHash,
+						CreatedSequenceId:  tx.B.SequenceID,
+						InitialAmount:      pop.Amount,
+						FreezingState:      resource.FrozenState,
+					},
+				})
+			case operation.UnfreezeRequest:
+				frozens = append(frozens, frozenAccountEvent{
+					address: source,
+					info: resource.FrozenAccountInfo{
+						FreezingState:                resource.MeltingState,
+						UnfreezingRequestBlockHeight: blk.Height,
+						UnfreezingRequestOpHash:      opHash,
+						UnfreezingRemainingBlocks:    common.UnfreezingPeriod,
+					},
+				})
+			case operation.Payment:
+				// only the payment of the frozen account, which is checked
+				// below, returns the frozen amount
+				frozens = append(frozens, frozenAccountEvent{
+					address: source,
+					info: resource.FrozenAccountInfo{
+						FreezingState: resource.ReturnedState,
+						PaymentOpHash: opHash,
+					},
+				})
 			}
 		}
+		index += uint64(len(tx.B.Operations))
 	}
-	for account, _ := range accountMap {
+
+	var accounts []string
+	for account := range accountMap {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	for _, account := range accounts {
 		ba, err := block.GetBlockAccount(st, account)
 		if err != nil {
-			return
+			return err
+		}
+
+		id := obs.EventID{Height: blk.Height, Index: index}
+		fn(cond(obs.Acc, obs.All).String(), ba, id)
+		fn(cond(obs.Acc, obs.Identifier, account).String(), ba, id)
+		index++
+	}
+
+	// the unfreezing requests expire in this block
+	if blk.Height > common.UnfreezingPeriod {
+		height := blk.Height - common.UnfreezingPeriod
+		iterFunc, closeFunc := block.GetBlockOperationsByBlockHeight(st, height, nil)
+		for {
+			bo, hasNext, _ := iterFunc()
+			if !hasNext {
+				break
+			}
+			if bo.Type != operation.TypeUnfreezingRequest {
+				continue
+			}
+			frozens = append(frozens, frozenAccountEvent{
+				address: bo.Source,
+				info: resource.FrozenAccountInfo{
+					FreezingState:                resource.UnfrozenState,
+					UnfreezingRequestBlockHeight: bo.Height,
+					UnfreezingRequestOpHash:      bo.OpHash,
+				},
+			})
+		}
+		closeFunc()
+	}
+
+	for _, f := range frozens {
+		ba, err := block.GetBlockAccount(st, f.address)
+		if err != nil {
+			return err
+		}
+		if !ba.IsFrozen() {
+			continue
 		}
-		t(cond(obs.Acc, obs.All).String(), ba)
-		t(cond(obs.Acc, obs.Identifier, account).String(), ba)
+
+		id := obs.EventID{Height: blk.Height, Index: index}
+		fa := resource.NewFrozenAccount(ba, f.info)
+		fn(cond(obs.FrozenAccount, obs.All).String(), fa, id)
+		fn(cond(obs.FrozenAccount, obs.Identifier, ba.Address).String(), fa, id)
+		fn(cond(obs.FrozenAccount, obs.Linked, ba.Linked).String(), fa, id)
+		index++
 	}
 
+	id := obs.EventID{Height: blk.Height, Index: index}
+	fn(cond(obs.Block, obs.All).String(), &blk, id)
+	fn(cond(obs.Block, obs.Identifier, blk.Hash).String(), &blk, id)
+	fn(cond(obs.Block, obs.Proposer, blk.Proposer).String(), &blk, id)
+
+	return nil
 }
@@ -26,6 +26,7 @@ func prepareAPIServer() (*httptest.Server, *storage.LevelDBBackend) {
 
 	router := mux.NewRouter()
 	router.HandleFunc(GetAccountHandlerPattern, apiHandler.GetAccountHandler).Methods("GET")
+	router.HandleFunc(GetAccountDataHandlerPattern, apiHandler.GetAccountDataHandler).Methods("GET")
 	router.HandleFunc(GetAccountsHandlerPattern, apiHandler.GetAccountsHandler).Methods("POST")
 	router.HandleFunc(GetAccountTransactionsHandlerPattern, apiHandler.GetTransactionsByAccountHandler).Methods("GET")
 	router.HandleFunc(GetAccountOperationsHandlerPattern, apiHandler.GetOperationsByAccountHandler).Methods("GET")
@@ -36,7 +37,10 @@ func prepareAPIServer() (*httptest.Server, *storage.LevelDBBackend) {
 	router.HandleFunc(GetTransactionOperationsHandlerPattern, apiHandler.GetOperationsByTxHandler).Methods("GET")
 	router.HandleFunc(GetBlocksHandlerPattern, apiHandler.GetBlocksHandler).Methods("GET")
 	router.HandleFunc(GetBlockHandlerPattern, apiHandler.GetBlockHandler).Methods("GET")
+	router.HandleFunc(GetLedgerHandlerPattern, apiHandler.GetLedgerHandler).Methods("GET")
 	router.HandleFunc(PostSubscribePattern, apiHandler.PostSubscribeHandler).Methods("POST")
+	router.HandleFunc(GraphQLPattern, apiHandler.GraphQLHandler).Methods("GET", "POST")
+	router.HandleFunc(GetOpenAPIHandlerPattern, apiHandler.GetOpenAPIHandler).Methods("GET")
 	ts := httptest.NewServer(router)
 	return ts, storage
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"sync"
+)
+
+// EventQueue triggers the pushed events one by one in the pushed order, so the
+// subscribers receive the events of the blocks in height order and the
+// consensus states in transition order. Push does not wait for the
+// subscribers.
+type EventQueue struct {
+	sync.Mutex
+
+	triggers []func()
+	signal   chan struct{}
+	stop     chan struct{}
+}
+
+func NewEventQueue() *EventQueue {
+	return &EventQueue{
+		signal: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Push appends the trigger to the queue.
+func (q *EventQueue) Push(trigger func()) {
+	q.Lock()
+	q.triggers = append(q.triggers, trigger)
+	q.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the pushed triggers until Stop is called.
+func (q *EventQueue) Start() {
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.signal:
+		}
+
+		for {
+			trigger := q.pop()
+			if trigger == nil {
+				break
+			}
+			trigger()
+		}
+	}
+}
+
+func (q *EventQueue) Stop() {
+	select {
+	case <-q.stop:
+	default:
+		close(q.stop)
+	}
+}
+
+func (q *EventQueue) pop() func() {
+	q.Lock()
+	defer q.Unlock()
+
+	if len(q.triggers) < 1 {
+		return nil
+	}
+
+	trigger := q.triggers[0]
+	q.triggers[0] = nil
+	q.triggers = q.triggers[1:]
+
+	return trigger
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// MaxFilterScan is the maximum number of the records scanned by one request,
+// including the filtered out ones. If it is reached, the list has less
+// records than the limit and the next link continues the scan.
+var MaxFilterScan = 1000
+
+// FilterQuery is the filters of the transactions and operations of account.
+// The block height and time ranges are inclusive.
+type FilterQuery struct {
+	MinHeight uint64
+	MaxHeight uint64
+	Since     time.Time
+	Until     time.Time
+	MinAmount common.Amount
+	MaxAmount common.Amount
+
+	// Counterparty is the address of the other side of payments
+	Counterparty string
+	// Direction is `DirectionSent` or `DirectionReceived`
+	Direction string
+
+	hasMinAmount bool
+	hasMaxAmount bool
+}
+
+func NewFilterQuery(r *http.Request) (*FilterQuery, error) {
+	f := &FilterQuery{}
+	if err := f.parseRequest(r); err != nil {
+		return nil, errors.InvalidQueryString.Clone().SetData("error", err.Error())
+	}
+
+	return f, nil
+}
+
+func (f *FilterQuery) parseRequest(r *http.Request) (err error) {
+	q := r.URL.Query()
+
+	if s := q.Get("min_height"); len(s) > 0 {
+		if f.MinHeight, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return
+		}
+	}
+	if s := q.Get("max_height"); len(s) > 0 {
+		if f.MaxHeight, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return
+		}
+	}
+	if s := q.Get("since"); len(s) > 0 {
+		if f.Since, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return
+		}
+	}
+	if s := q.Get("until"); len(s) > 0 {
+		if f.Until, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return
+		}
+	}
+	if s := q.Get("min_amount"); len(s) > 0 {
+		if f.MinAmount, err = common.AmountFromString(s); err != nil {
+			return
+		}
+		f.hasMinAmount = true
+	}
+	if s := q.Get("max_amount"); len(s) > 0 {
+		if f.MaxAmount, err = common.AmountFromString(s); err != nil {
+			return
+		}
+		f.hasMaxAmount = true
+	}
+
+	f.Counterparty = q.Get("counterparty")
+
+	switch f.Direction = q.Get("direction"); f.Direction {
+	case "", DirectionSent, DirectionReceived:
+	default:
+		return fmt.Errorf("unknown direction, '%s'", f.Direction)
+	}
+
+	return nil
+}
+
+// HasAmount returns true if the amount range is given.
+func (f *FilterQuery) HasAmount() bool {
+	return f.hasMinAmount || f.hasMaxAmount
+}
+
+// MatchAmount checks the amount is in the amount range.
+func (f *FilterQuery) MatchAmount(amount common.Amount) bool {
+	if f.hasMinAmount && amount < f.MinAmount {
+		return false
+	}
+	if f.hasMaxAmount && amount > f.MaxAmount {
+		return false
+	}
+
+	return true
+}
+
+// MatchDirection checks the direction of the transaction or operation from
+// source, which is found by the account, address.
+func (f *FilterQuery) MatchDirection(address, source string) bool {
+	switch f.Direction {
+	case DirectionSent:
+		return source == address
+	case DirectionReceived:
+		return source != address
+	}
+
+	return true
+}
+
+// HeightRange returns the block height range from the height and time
+// filters; 0 is unbounded. If no block can be in the range, `empty` is true.
+func (f *FilterQuery) HeightRange(st *storage.LevelDBBackend) (from, to uint64, empty bool, err error) {
+	from, to = f.MinHeight, f.MaxHeight
+
+	if !f.Since.IsZero() {
+		var height uint64
+		if height, err = block.GetBlockHeightByTime(st, f.Since.Add(-time.Nanosecond)); err != nil {
+			return
+		}
+		if height+1 > from {
+			from = height + 1
+		}
+	}
+	if !f.Until.IsZero() {
+		var height uint64
+		if height, err = block.GetBlockHeightByTime(st, f.Until); err != nil {
+			return
+		} else if height < 1 {
+			empty = true
+			return
+		}
+		if to < 1 || height < to {
+			to = height
+		}
+	}
+
+	if to > 0 && from > to {
+		empty = true
+	}
+
+	return
+}
+
+// ListOptions limits the options of `PageQuery` to the height range; the
+// limit is removed, because the filtered records are skipped and the page is
+// filled by the caller.
+func (f *FilterQuery) ListOptions(st *storage.LevelDBBackend, p *PageQuery) (options storage.ListOptions, empty bool, err error) {
+	var from, to uint64
+	if from, to, empty, err = f.HeightRange(st); err != nil || empty {
+		return
+	}
+
+	options = block.NewHeightRangeListOptions(
+		storage.NewDefaultListOptions(p.Reverse(), p.Cursor(), 0),
+		from,
+		to,
+	)
+
+	return
+}
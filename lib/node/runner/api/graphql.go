@@ -0,0 +1,654 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/node/runner/api/graphql"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+	"boscoin.io/sebak/lib/storage"
+)
+
+const (
+	// GraphQLMaxCost is the maximum cost of a query; every field costs 1 and
+	// the fields under the list are multiplied by it's `first`.
+	GraphQLMaxCost uint64 = 10000
+	// GraphQLMaxDepth is the maximum depth of the selections of a query
+	GraphQLMaxDepth = 10
+	// GraphQLMaxBodySize is the maximum size of the request body
+	GraphQLMaxBodySize = 64 * 1024
+)
+
+// GraphQLHandler serves the read-only GraphQL query over the blocks,
+// transactions, operations, accounts and frozen accounts. The query is
+// `query` of GET or the JSON body of POST, `{"query": "...", "variables":
+// {...}}`.
+//
+// The fields of the objects are the same with the REST resources and the
+// lists are paginated with `first`, `after` and `reverse` arguments like,
+//
+//	{
+//	  blocks(first: 10, reverse: true) {
+//	    edges { cursor node { hash height transactions { nodes { hash } } } }
+//	    page_info { has_next_page end_cursor }
+//	  }
+//	}
+func (api NetworkHandlerAPI) GraphQLHandler(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); len(v) > 0 {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				httputils.WriteJSONError(w, errors.BadRequestParameter)
+				return
+			}
+		}
+	} else {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, GraphQLMaxBodySize))
+		if err != nil {
+			httputils.WriteJSONError(w, errors.BadRequestParameter)
+			return
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			httputils.WriteJSONError(w, errors.BadRequestParameter)
+			return
+		}
+	}
+
+	resp, err := api.graphQLSchema().Execute(req)
+	if err != nil {
+		httputils.MustWriteJSON(w, http.StatusBadRequest, graphql.Response{
+			Errors: []graphql.Error{{Message: err.Error()}},
+		})
+		return
+	}
+
+	httputils.MustWriteJSON(w, http.StatusOK, resp)
+}
+
+type graphQLConnection struct {
+	edges   []graphQLEdge
+	hasNext bool
+}
+
+type graphQLEdge struct {
+	cursor string
+	node   interface{}
+}
+
+func (api NetworkHandlerAPI) graphQLSchema() *graphql.Schema {
+	var (
+		blockType         = graphql.NewObject("Block")
+		transactionType   = graphql.NewObject("Transaction")
+		operationType     = graphql.NewObject("Operation")
+		accountType       = graphql.NewObject("Account")
+		frozenAccountType = graphql.NewObject("FrozenAccount")
+		query             = graphql.NewObject("Query")
+	)
+
+	graphQLMapFields(blockType, map[string]graphql.Type{
+		"version":              graphql.Int,
+		"hash":                 graphql.String,
+		"height":               graphql.Int,
+		"prev_block_hash":      graphql.String,
+		"transactions_root":    graphql.String,
+		"confirmed":            graphql.String,
+		"proposer":             graphql.String,
+		"proposed_time":        graphql.String,
+		"proposer_transaction": graphql.String,
+		"round":                graphql.Int,
+	})
+	blockType.Fields["transactions"] = graphQLConnectionField(
+		transactionType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			hash := graphQLMapValue(p.Source, "hash").(string)
+			iterFunc, closeFunc := block.GetBlockTransactionsByBlock(api.storage, hash, options)
+			defer closeFunc()
+			return api.graphQLTransactions(iterFunc, options)
+		},
+	)
+
+	graphQLMapFields(transactionType, map[string]graphql.Type{
+		"hash":            graphql.String,
+		"source":          graphql.String,
+		"fee":             graphql.String,
+		"sequence_id":     graphql.Int,
+		"created":         graphql.String,
+		"operation_count": graphql.Int,
+	})
+	transactionType.Fields["block"] = &graphql.Field{
+		Type: blockType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLBlock(graphQLMapValue(p.Source, "block").(string))
+		},
+	}
+	transactionType.Fields["source_account"] = &graphql.Field{
+		Type: accountType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLAccount(graphQLMapValue(p.Source, "source").(string))
+		},
+	}
+	transactionType.Fields["operations"] = graphQLConnectionField(
+		operationType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			hash := graphQLMapValue(p.Source, "hash").(string)
+			iterFunc, closeFunc := block.GetBlockOperationsByTx(api.storage, hash, options)
+			defer closeFunc()
+			return api.graphQLOperations(iterFunc, options)
+		},
+	)
+
+	graphQLMapFields(operationType, map[string]graphql.Type{
+		"hash":         graphql.String,
+		"source":       graphql.String,
+		"target":       graphql.String,
+		"type":         graphql.String,
+		"tx_hash":      graphql.String,
+		"index":        graphql.Int,
+		"body":         graphql.JSON,
+		"block_height": graphql.Int,
+	})
+	operationType.Fields["transaction"] = &graphql.Field{
+		Type: transactionType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLTransaction(graphQLMapValue(p.Source, "tx_hash").(string))
+		},
+	}
+	operationType.Fields["source_account"] = &graphql.Field{
+		Type: accountType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLAccount(graphQLMapValue(p.Source, "source").(string))
+		},
+	}
+	operationType.Fields["target_account"] = &graphql.Field{
+		Type: accountType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLAccount(graphQLMapValue(p.Source, "target").(string))
+		},
+	}
+
+	graphQLMapFields(accountType, map[string]graphql.Type{
+		"address":     graphql.String,
+		"sequence_id": graphql.Int,
+		"balance":     graphql.String,
+		"linked":      graphql.String,
+	})
+	accountType.Fields["linked_account"] = &graphql.Field{
+		Type: accountType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLAccount(graphQLMapValue(p.Source, "linked").(string))
+		},
+	}
+	accountType.Fields["transactions"] = graphQLConnectionField(
+		transactionType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			address := graphQLMapValue(p.Source, "address").(string)
+			iterFunc, closeFunc := block.GetBlockTransactionsByAccount(api.storage, address, options)
+			defer closeFunc()
+			return api.graphQLTransactions(iterFunc, options)
+		},
+	)
+	accountType.Fields["operations"] = graphQLConnectionField(
+		operationType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			address := graphQLMapValue(p.Source, "address").(string)
+			iterFunc, closeFunc := block.GetBlockOperationsByPeers(api.storage, address, options)
+			defer closeFunc()
+			return api.graphQLOperations(iterFunc, options)
+		},
+	)
+	accountType.Fields["frozen_accounts"] = graphQLConnectionField(
+		frozenAccountType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			address := graphQLMapValue(p.Source, "address").(string)
+			iterFunc, closeFunc := block.GetBlockOperationsByLinked(api.storage, address, options)
+			defer closeFunc()
+			return api.graphQLFrozenAccounts(iterFunc, options)
+		},
+	)
+
+	graphQLMapFields(frozenAccountType, map[string]graphql.Type{
+		"address":                     graphql.String,
+		"linked":                      graphql.String,
+		"create_block_height":         graphql.Int,
+		"create_op_hash":              graphql.String,
+		"sequence_id":                 graphql.Int,
+		"amount":                      graphql.String,
+		"state":                       graphql.String,
+		"unfreezing_block_height":     graphql.Int,
+		"unfreezing_op_hash":          graphql.String,
+		"unfreezing_remaining_blocks": graphql.Int,
+		"payment_op_hash":             graphql.String,
+	})
+	frozenAccountType.Fields["account"] = &graphql.Field{
+		Type: accountType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLAccount(graphQLMapValue(p.Source, "address").(string))
+		},
+	}
+	frozenAccountType.Fields["linked_account"] = &graphql.Field{
+		Type: accountType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return api.graphQLAccount(graphQLMapValue(p.Source, "linked").(string))
+		},
+	}
+
+	query.Fields["block"] = &graphql.Field{
+		Type: blockType,
+		Args: []string{"hash", "height"},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			if height, found, err := graphQLIntArg(p.Args, "height"); err != nil {
+				return nil, err
+			} else if found {
+				if exists, err := block.ExistsBlockByHeight(api.storage, uint64(height)); err != nil || !exists {
+					return nil, err
+				}
+				blk, err := block.GetBlockByHeight(api.storage, uint64(height))
+				if err != nil {
+					return nil, err
+				}
+				return resource.NewBlock(&blk), nil
+			}
+
+			hash, err := graphQLStringArg(p.Args, "hash")
+			if err != nil {
+				return nil, err
+			}
+			return api.graphQLBlock(hash)
+		},
+	}
+	query.Fields["latest_block"] = &graphql.Field{
+		Type: blockType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			blk := block.GetLatestBlock(api.storage)
+			return resource.NewBlock(&blk), nil
+		},
+	}
+	query.Fields["blocks"] = graphQLConnectionField(
+		blockType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			iterFunc, closeFunc := block.GetBlocksByConfirmed(api.storage, options)
+			defer closeFunc()
+			return graphQLPage(options, func() (interface{}, bool, []byte, error) {
+				blk, hasNext, cursor := iterFunc()
+				return resource.NewBlock(&blk), hasNext, cursor, nil
+			})
+		},
+	)
+	query.Fields["transaction"] = &graphql.Field{
+		Type: transactionType,
+		Args: []string{"hash"},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			hash, err := graphQLStringArg(p.Args, "hash")
+			if err != nil {
+				return nil, err
+			}
+			return api.graphQLTransaction(hash)
+		},
+	}
+	query.Fields["transactions"] = graphQLConnectionField(
+		transactionType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			iterFunc, closeFunc := block.GetBlockTransactions(api.storage, options)
+			defer closeFunc()
+			return api.graphQLTransactions(iterFunc, options)
+		},
+	)
+	query.Fields["operation"] = &graphql.Field{
+		Type: operationType,
+		Args: []string{"tx_hash", "index"},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			hash, err := graphQLStringArg(p.Args, "tx_hash")
+			if err != nil {
+				return nil, err
+			}
+			index, found, err := graphQLIntArg(p.Args, "index")
+			if err != nil {
+				return nil, err
+			} else if !found {
+				return nil, fmt.Errorf("'index' must be given")
+			}
+
+			if exists, err := block.ExistsBlockTransaction(api.storage, hash); err != nil || !exists {
+				return nil, err
+			}
+			bo, err := block.GetBlockOperationWithIndex(api.storage, hash, int(index))
+			if err == errors.OperationNotFound {
+				return nil, nil
+			} else if err != nil {
+				return nil, err
+			}
+			return resource.NewOperation(&bo, int(index)), nil
+		},
+	}
+	query.Fields["account"] = &graphql.Field{
+		Type: accountType,
+		Args: []string{"address"},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			address, err := graphQLStringArg(p.Args, "address")
+			if err != nil {
+				return nil, err
+			}
+			return api.graphQLAccount(address)
+		},
+	}
+	query.Fields["frozen_accounts"] = graphQLConnectionField(
+		frozenAccountType,
+		func(p graphql.ResolveParams, options storage.ListOptions) (*graphQLConnection, error) {
+			iterFunc, closeFunc := block.GetBlockOperationsByFrozen(api.storage, options)
+			defer closeFunc()
+			return api.graphQLFrozenAccounts(iterFunc, options)
+		},
+	)
+
+	return &graphql.Schema{
+		Query:    query,
+		MaxCost:  GraphQLMaxCost,
+		MaxDepth: GraphQLMaxDepth,
+	}
+}
+
+// graphQLMapFields adds the fields, which are resolved from
+// `resource.Resource.GetMap()`.
+func graphQLMapFields(o *graphql.Object, fields map[string]graphql.Type) {
+	for key, t := range fields {
+		key := key
+		o.Fields[key] = &graphql.Field{
+			Type: t,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return graphQLMapValue(p.Source, key), nil
+			},
+		}
+	}
+}
+
+func graphQLMapValue(source interface{}, key string) interface{} {
+	return source.(resource.Resource).GetMap()[key]
+}
+
+// graphQLConnectionField makes the paginated list field of nodeType, which
+// has `edges`, `nodes` and `page_info`.
+func graphQLConnectionField(
+	nodeType *graphql.Object,
+	resolve func(graphql.ResolveParams, storage.ListOptions) (*graphQLConnection, error),
+) *graphql.Field {
+	edgeType := graphql.NewObject(nodeType.Name + "Edge")
+	edgeType.Fields["cursor"] = &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(graphQLEdge).cursor, nil
+		},
+	}
+	edgeType.Fields["node"] = &graphql.Field{
+		Type: nodeType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(graphQLEdge).node, nil
+		},
+	}
+
+	pageInfoType := graphql.NewObject("PageInfo")
+	pageInfoType.Fields["has_next_page"] = &graphql.Field{
+		Type: graphql.Boolean,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*graphQLConnection).hasNext, nil
+		},
+	}
+	pageInfoType.Fields["end_cursor"] = &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			c := p.Source.(*graphQLConnection)
+			if len(c.edges) < 1 {
+				return nil, nil
+			}
+			return c.edges[len(c.edges)-1].cursor, nil
+		},
+	}
+
+	connectionType := graphql.NewObject(nodeType.Name + "Connection")
+	connectionType.Fields["edges"] = &graphql.Field{
+		Type: graphql.NewList(edgeType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			var l []interface{}
+			for _, e := range p.Source.(*graphQLConnection).edges {
+				l = append(l, e)
+			}
+			return l, nil
+		},
+	}
+	connectionType.Fields["nodes"] = &graphql.Field{
+		Type: graphql.NewList(nodeType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			var l []interface{}
+			for _, e := range p.Source.(*graphQLConnection).edges {
+				l = append(l, e.node)
+			}
+			return l, nil
+		},
+	}
+	connectionType.Fields["page_info"] = &graphql.Field{
+		Type: pageInfoType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source, nil
+		},
+	}
+
+	return &graphql.Field{
+		Type: connectionType,
+		Args: []string{"first", "after", "reverse"},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			options, err := graphQLListOptions(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			return resolve(p, options)
+		},
+		Multiplier: graphQLFirst,
+	}
+}
+
+// graphQLFirst returns the page size, `first` argument.
+func graphQLFirst(args map[string]interface{}) (uint64, error) {
+	first, found, err := graphQLIntArg(args, "first")
+	if err != nil {
+		return 0, err
+	} else if !found {
+		return DefaultLimit, nil
+	}
+
+	if first < 1 {
+		return 0, fmt.Errorf("'first' must be positive")
+	} else if uint64(first) > MaxLimit {
+		return 0, errors.PageQueryLimitMaxExceed
+	}
+
+	return uint64(first), nil
+}
+
+// graphQLListOptions makes `storage.ListOptions` from the pagination
+// arguments; the limit is `first` + 1 to know whether the next page exists.
+func graphQLListOptions(args map[string]interface{}) (storage.ListOptions, error) {
+	first, err := graphQLFirst(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor []byte
+	if v, found := args["after"]; found && v != nil {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("'after' must be String")
+		}
+		if cursor, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("invalid cursor, '%s'", s)
+		}
+	}
+
+	var reverse bool
+	if v, found := args["reverse"]; found && v != nil {
+		var ok bool
+		if reverse, ok = v.(bool); !ok {
+			return nil, fmt.Errorf("'reverse' must be Boolean")
+		}
+	}
+
+	return storage.NewDefaultListOptions(reverse, cursor, first+1), nil
+}
+
+// graphQLPage reads the nodes from the iterator until `first`.
+func graphQLPage(options storage.ListOptions, next func() (interface{}, bool, []byte, error)) (*graphQLConnection, error) {
+	c := &graphQLConnection{}
+	first := options.Limit() - 1
+	for {
+		node, hasNext, cursor, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if !hasNext {
+			break
+		}
+		if uint64(len(c.edges)) == first {
+			c.hasNext = true
+			break
+		}
+		c.edges = append(c.edges, graphQLEdge{
+			cursor: base64.StdEncoding.EncodeToString(cursor),
+			node:   node,
+		})
+	}
+
+	return c, nil
+}
+
+func (api NetworkHandlerAPI) graphQLTransactions(iterFunc func() (block.BlockTransaction, bool, []byte), options storage.ListOptions) (*graphQLConnection, error) {
+	return graphQLPage(options, func() (interface{}, bool, []byte, error) {
+		bt, hasNext, cursor := iterFunc()
+		if !hasNext {
+			return nil, false, cursor, nil
+		}
+		tp, err := block.GetTransactionPool(api.storage, bt.Hash)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		return resource.NewTransaction(&bt, tp.Transaction()), true, cursor, nil
+	})
+}
+
+func (api NetworkHandlerAPI) graphQLOperations(iterFunc func() (block.BlockOperation, bool, []byte), options storage.ListOptions) (*graphQLConnection, error) {
+	return graphQLPage(options, func() (interface{}, bool, []byte, error) {
+		bo, hasNext, cursor := iterFunc()
+		if !hasNext {
+			return nil, false, cursor, nil
+		}
+		bt, err := block.GetBlockTransaction(api.storage, bo.TxHash)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		opIndex, err := bt.GetOperationIndex(bo.Hash)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		return resource.NewOperation(&bo, opIndex), true, cursor, nil
+	})
+}
+
+func (api NetworkHandlerAPI) graphQLFrozenAccounts(iterFunc func() (block.BlockOperation, bool, []byte), options storage.ListOptions) (*graphQLConnection, error) {
+	return graphQLPage(options, func() (interface{}, bool, []byte, error) {
+		bo, hasNext, cursor := iterFunc()
+		if !hasNext {
+			return nil, false, cursor, nil
+		}
+		fa, err := api.newFrozenAccount(bo)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		return fa, true, cursor, nil
+	})
+}
+
+// graphQLBlock returns `nil` if the block does not exist.
+func (api NetworkHandlerAPI) graphQLBlock(hash string) (interface{}, error) {
+	if exists, err := block.ExistsBlock(api.storage, hash); err != nil || !exists {
+		return nil, err
+	}
+	blk, err := block.GetBlock(api.storage, hash)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewBlock(&blk), nil
+}
+
+// graphQLTransaction returns `nil` if the transaction does not exist.
+func (api NetworkHandlerAPI) graphQLTransaction(hash string) (interface{}, error) {
+	if exists, err := block.ExistsBlockTransaction(api.storage, hash); err != nil {
+		return nil, err
+	} else if !exists {
+		if err = api.blockTransactionNotFound(hash); err == errors.DataPruned {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	bt, err := block.GetBlockTransaction(api.storage, hash)
+	if err != nil {
+		return nil, err
+	}
+	tp, err := block.GetTransactionPool(api.storage, hash)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewTransaction(&bt, tp.Transaction()), nil
+}
+
+// graphQLAccount returns `nil` if the account does not exist.
+func (api NetworkHandlerAPI) graphQLAccount(address string) (interface{}, error) {
+	if len(address) < 1 {
+		return nil, nil
+	}
+	if exists, err := block.ExistsBlockAccount(api.storage, address); err != nil || !exists {
+		return nil, err
+	}
+	ba, err := block.GetBlockAccount(api.storage, address)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewAccount(ba), nil
+}
+
+func graphQLStringArg(args map[string]interface{}, name string) (string, error) {
+	v, found := args[name]
+	if !found || v == nil {
+		return "", fmt.Errorf("'%s' must be given", name)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("'%s' must be String", name)
+	}
+	return s, nil
+}
+
+// graphQLIntArg returns the integer argument; the variables decoded from JSON
+// are `float64`.
+func graphQLIntArg(args map[string]interface{}, name string) (int64, bool, error) {
+	v, found := args[name]
+	if !found || v == nil {
+		return 0, false, nil
+	}
+
+	switch i := v.(type) {
+	case int64:
+		return i, true, nil
+	case float64:
+		if i == float64(int64(i)) {
+			return int64(i), true, nil
+		}
+	}
+	return 0, false, fmt.Errorf("'%s' must be Int", name)
+}
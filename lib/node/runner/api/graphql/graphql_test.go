@@ -0,0 +1,177 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# comment
+		query Q($id: String!, $n: Int = 3) {
+			a: item(id: $id, tags: ["x", "y"], opt: {k: true}) { name }
+			items(first: $n, f: -1.5e2, s: "q\"A") { name, value }
+		}
+	`, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(doc.Operations))
+
+	op := doc.Operations[0]
+	require.Equal(t, "Q", op.Name)
+	require.Equal(t, []VariableDefinition{
+		{Name: "id", Type: "String", NonNull: true},
+		{Name: "n", Type: "Int", Default: int64(3)},
+	}, op.Variables)
+
+	require.Equal(t, 2, len(op.SelectionSet))
+	a := op.SelectionSet[0]
+	require.Equal(t, "a", a.Key())
+	require.Equal(t, "item", a.Name)
+	require.Equal(t, map[string]interface{}{
+		"id":   Variable("id"),
+		"tags": []interface{}{"x", "y"},
+		"opt":  map[string]interface{}{"k": true},
+	}, a.Arguments)
+
+	items := op.SelectionSet[1]
+	require.Equal(t, "items", items.Key())
+	require.Equal(t, -150.0, items.Arguments["f"])
+	require.Equal(t, `q"A`, items.Arguments["s"])
+	require.Equal(t, 2, len(items.SelectionSet))
+
+	for _, q := range []string{
+		``,
+		`{ a `,
+		`{ }`,
+		`{ a(b: ) }`,
+		`{ a(b: "c) }`,
+		`{ ...f }`,
+		`fragment f on A { a }`,
+		`mutation { a }`,
+		`subscription { a }`,
+		`{ a @include(if: true) }`,
+		`query ($a: Int = $b) { a }`,
+		`{ a % }`,
+	} {
+		_, err := Parse(q, 0)
+		require.Error(t, err, q)
+	}
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	_, err := Parse(`{ a { b { c } } }`, 3)
+	require.NoError(t, err)
+	_, err = Parse(`query ($a: [[Int]]) { a(b: [[1]], c: {d: {e: 1}}) { b } }`, 2)
+	require.NoError(t, err)
+
+	for _, q := range []string{
+		`{ a { b { c { d } } } }`,
+		`query ($a: [[[[Int]]]]) { a }`,
+		`{ a(b: [[[[1]]]]) }`,
+		`{ a(b: {c: {d: {e: {f: 1}}}}) }`,
+		// the rest of the query is not read
+		strings.Repeat("{ a ", 100000),
+		"{ a(b: " + strings.Repeat("[", 100000),
+	} {
+		_, err := Parse(q, 3)
+		require.Error(t, err, q)
+		require.Contains(t, err.Error(), "depth", q)
+	}
+}
+
+func testSchema() *Schema {
+	item := NewObject("Item")
+	item.Fields["name"] = &Field{
+		Type: String,
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			return fmt.Sprintf("item-%d", p.Source.(int)), nil
+		},
+	}
+	item.Fields["fail"] = &Field{
+		Type: String,
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, fmt.Errorf("failed")
+		},
+	}
+	item.Fields["next"] = &Field{
+		Type: item,
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(int) + 1, nil
+		},
+	}
+
+	query := NewObject("Query")
+	query.Fields["item"] = &Field{
+		Type: item,
+		Args: []string{"id"},
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			if id, ok := p.Args["id"].(int64); ok {
+				return int(id), nil
+			}
+			return int(p.Args["id"].(float64)), nil
+		},
+	}
+	query.Fields["items"] = &Field{
+		Type: NewList(item),
+		Args: []string{"first"},
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			var l []interface{}
+			for i := 0; i < int(p.Args["first"].(int64)); i++ {
+				l = append(l, i)
+			}
+			return l, nil
+		},
+		Multiplier: func(args map[string]interface{}) (uint64, error) {
+			return uint64(args["first"].(int64)), nil
+		},
+	}
+
+	return &Schema{Query: query, MaxCost: 20, MaxDepth: 3}
+}
+
+func TestExecute(t *testing.T) {
+	s := testSchema()
+
+	resp, err := s.Execute(Request{
+		Query:     `query ($id: Int!) { z: item(id: $id) { __typename name next { name } fail } items(first: 2) { name } }`,
+		Variables: map[string]interface{}{"id": float64(7)},
+	})
+	require.NoError(t, err)
+
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		`{"data":{"z":{"__typename":"Item","name":"item-7","next":{"name":"item-8"},"fail":null},"items":[{"name":"item-0"},{"name":"item-1"}]},`+
+			`"errors":[{"message":"failed","path":["z","fail"]}]}`,
+		string(b),
+	)
+
+	for q, expected := range map[string]string{
+		`{ unknown }`:                                                       "unknown field",
+		`{ item(id: 1, x: 2) { name } }`:                                    "unknown argument",
+		`{ item(id: 1) }`:                                                   "must have selections",
+		`{ item(id: 1) { name { a } } }`:                                    "must not have selections",
+		`{ items(first: 10) { name next { name } } }`:                       "cost",
+		`{ item(id: 1) { next { next { next { name } } } } }`:               "depth",
+		`query ($id: Int!) { item(id: $id) { name } }`:                      "must be given",
+		`{ item(id: $id) { name } }`:                                        "not defined",
+		`query A { item(id: 1) { name } } query B { item(id: 2) { name } }`: "operationName",
+	} {
+		_, err := s.Execute(Request{Query: q})
+		require.Error(t, err, q)
+		require.Contains(t, err.Error(), expected, q)
+	}
+
+	resp, err = s.Execute(Request{
+		Query:         `query A { item(id: 1) { name } } query B { item(id: 2) { name } }`,
+		OperationName: "B",
+	})
+	require.NoError(t, err)
+	b, _ = json.Marshal(resp)
+	require.Equal(t, `{"data":{"item":{"name":"item-2"}}}`, string(b))
+}
@@ -0,0 +1,594 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is the parsed GraphQL query document. Only the subset of the
+// language for the read-only queries is supported; fragments, directives,
+// mutations and subscriptions are refused.
+type Document struct {
+	Operations []*Operation
+}
+
+type Operation struct {
+	Name         string
+	Variables    []VariableDefinition
+	SelectionSet []*Selection
+}
+
+type VariableDefinition struct {
+	Name    string
+	Type    string
+	NonNull bool
+	Default interface{}
+}
+
+// Selection is the field of the selection set.
+type Selection struct {
+	Alias        string
+	Name         string
+	Arguments    map[string]interface{}
+	SelectionSet []*Selection
+}
+
+// Key is the name of the field in the result.
+func (s *Selection) Key() string {
+	if len(s.Alias) > 0 {
+		return s.Alias
+	}
+	return s.Name
+}
+
+// Variable is the value of argument, which refers the variable.
+type Variable string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type parser struct {
+	src      string
+	pos      int
+	token    token
+	maxDepth int
+	depth    int // nesting of the selection sets
+	valDepth int // nesting of the list types and the list and object values
+}
+
+// Parse parses the query document. If `maxDepth` is over 0, the selection
+// sets can not be nested deeper than `maxDepth` and so do the list types and
+// the list and object values; the parser stops at the limit before reading the
+// rest of the query.
+func Parse(src string, maxDepth int) (doc *Document, err error) {
+	p := &parser{src: src, maxDepth: maxDepth}
+	if err = p.next(); err != nil {
+		return
+	}
+
+	doc = &Document{}
+	for p.token.kind != tokenEOF {
+		var op *Operation
+		if op, err = p.parseOperation(); err != nil {
+			return nil, err
+		}
+		doc.Operations = append(doc.Operations, op)
+	}
+
+	if len(doc.Operations) < 1 {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	return
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("syntax error at %d: %s", p.token.pos, fmt.Sprintf(format, args...))
+}
+
+// enter increases the depth; every call must be paired with `leave`.
+func (p *parser) enter(depth *int) error {
+	*depth++
+	if p.maxDepth > 0 && *depth > p.maxDepth {
+		return p.errorf("query depth is over the limit, %d", p.maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave(depth *int) {
+	*depth--
+}
+
+func (p *parser) next() error {
+	// skip the ignored tokens; white space, line terminator, comma and comment
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+		} else if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '\r' {
+				p.pos++
+			}
+		} else if strings.HasPrefix(p.src[p.pos:], "\ufeff") {
+			p.pos += len("\ufeff")
+		} else {
+			break
+		}
+	}
+
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.token = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case strings.IndexByte("!$():=@[]{}|", c) >= 0:
+		p.pos++
+		p.token = token{kind: tokenPunctuator, value: string(c), pos: start}
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.token = token{kind: tokenPunctuator, value: "...", pos: start}
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || isLetter(p.src[p.pos]) || isDigit(p.src[p.pos])) {
+			p.pos++
+		}
+		p.token = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+	case c == '-' || isDigit(c):
+		return p.readNumber()
+	case c == '"':
+		return p.readString()
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.token.pos = start
+		return p.errorf("unexpected character, %q", r)
+	}
+
+	return nil
+}
+
+func (p *parser) readNumber() error {
+	start := p.pos
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(p.src) && isDigit(p.src[p.pos]) {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits()
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+
+	p.token = token{kind: kind, value: p.src[start:p.pos], pos: start}
+	return nil
+}
+
+func (p *parser) readString() error {
+	start := p.pos
+	p.pos++ // opening quote
+
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r' {
+			p.token.pos = start
+			return p.errorf("unterminated string")
+		}
+
+		c := p.src[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		if p.pos+1 >= len(p.src) {
+			p.token.pos = start
+			return p.errorf("unterminated string")
+		}
+		p.pos += 2
+		switch e := p.src[p.pos-1]; e {
+		case '"', '\\', '/':
+			b.WriteByte(e)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(p.src) {
+				p.token.pos = start
+				return p.errorf("invalid unicode escape")
+			}
+			r, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				p.token.pos = start
+				return p.errorf("invalid unicode escape")
+			}
+			b.WriteRune(rune(r))
+			p.pos += 4
+		default:
+			p.token.pos = start
+			return p.errorf("invalid escape, '\\%c'", e)
+		}
+	}
+
+	p.token = token{kind: tokenString, value: b.String(), pos: start}
+	return nil
+}
+
+func (p *parser) is(kind tokenKind, value string) bool {
+	return p.token.kind == kind && p.token.value == value
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.is(kind, value) {
+		return p.errorf("expected '%s', but '%s'", value, p.token.value)
+	}
+	return p.next()
+}
+
+func (p *parser) expectName() (name string, err error) {
+	if p.token.kind != tokenName {
+		return "", p.errorf("expected name, but '%s'", p.token.value)
+	}
+	name = p.token.value
+	err = p.next()
+	return
+}
+
+func (p *parser) parseOperation() (op *Operation, err error) {
+	op = &Operation{}
+
+	// query shorthand, `{ ... }`
+	if p.is(tokenPunctuator, "{") {
+		op.SelectionSet, err = p.parseSelectionSet()
+		return
+	}
+
+	if p.token.kind != tokenName {
+		return nil, p.errorf("expected operation, but '%s'", p.token.value)
+	}
+	switch p.token.value {
+	case "query":
+	case "mutation", "subscription":
+		return nil, p.errorf("'%s' is not supported", p.token.value)
+	case "fragment":
+		return nil, p.errorf("fragment is not supported")
+	default:
+		return nil, p.errorf("unknown operation, '%s'", p.token.value)
+	}
+	if err = p.next(); err != nil {
+		return
+	}
+
+	if p.token.kind == tokenName {
+		op.Name = p.token.value
+		if err = p.next(); err != nil {
+			return
+		}
+	}
+
+	if p.is(tokenPunctuator, "(") {
+		if op.Variables, err = p.parseVariableDefinitions(); err != nil {
+			return
+		}
+	}
+	if p.is(tokenPunctuator, "@") {
+		return nil, p.errorf("directive is not supported")
+	}
+
+	op.SelectionSet, err = p.parseSelectionSet()
+	return
+}
+
+func (p *parser) parseVariableDefinitions() (defs []VariableDefinition, err error) {
+	if err = p.expect(tokenPunctuator, "("); err != nil {
+		return
+	}
+
+	for !p.is(tokenPunctuator, ")") {
+		var def VariableDefinition
+		if err = p.expect(tokenPunctuator, "$"); err != nil {
+			return
+		}
+		if def.Name, err = p.expectName(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if def.Type, def.NonNull, err = p.parseType(); err != nil {
+			return
+		}
+		if p.is(tokenPunctuator, "=") {
+			if err = p.next(); err != nil {
+				return
+			}
+			if def.Default, err = p.parseValue(true); err != nil {
+				return
+			}
+		}
+		defs = append(defs, def)
+	}
+
+	err = p.next()
+	return
+}
+
+func (p *parser) parseType() (t string, nonNull bool, err error) {
+	if p.is(tokenPunctuator, "[") {
+		if err = p.enter(&p.valDepth); err != nil {
+			return
+		}
+		defer p.leave(&p.valDepth)
+
+		if err = p.next(); err != nil {
+			return
+		}
+		var of string
+		var ofNonNull bool
+		if of, ofNonNull, err = p.parseType(); err != nil {
+			return
+		}
+		if ofNonNull {
+			of += "!"
+		}
+		if err = p.expect(tokenPunctuator, "]"); err != nil {
+			return
+		}
+		t = "[" + of + "]"
+	} else if t, err = p.expectName(); err != nil {
+		return
+	}
+
+	if p.is(tokenPunctuator, "!") {
+		nonNull = true
+		err = p.next()
+	}
+	return
+}
+
+func (p *parser) parseSelectionSet() (selections []*Selection, err error) {
+	if err = p.enter(&p.depth); err != nil {
+		return
+	}
+	defer p.leave(&p.depth)
+
+	if err = p.expect(tokenPunctuator, "{"); err != nil {
+		return
+	}
+
+	for !p.is(tokenPunctuator, "}") {
+		if p.token.kind == tokenEOF {
+			return nil, p.errorf("expected '}'")
+		}
+		if p.is(tokenPunctuator, "...") {
+			return nil, p.errorf("fragment is not supported")
+		}
+
+		var s *Selection
+		if s, err = p.parseField(); err != nil {
+			return
+		}
+		selections = append(selections, s)
+	}
+	if len(selections) < 1 {
+		return nil, p.errorf("selection set is empty")
+	}
+
+	err = p.next()
+	return
+}
+
+func (p *parser) parseField() (s *Selection, err error) {
+	s = &Selection{}
+	if s.Name, err = p.expectName(); err != nil {
+		return
+	}
+	if p.is(tokenPunctuator, ":") {
+		if err = p.next(); err != nil {
+			return
+		}
+		s.Alias = s.Name
+		if s.Name, err = p.expectName(); err != nil {
+			return
+		}
+	}
+
+	if p.is(tokenPunctuator, "(") {
+		if s.Arguments, err = p.parseArguments(); err != nil {
+			return
+		}
+	}
+	if p.is(tokenPunctuator, "@") {
+		return nil, p.errorf("directive is not supported")
+	}
+
+	if p.is(tokenPunctuator, "{") {
+		if s.SelectionSet, err = p.parseSelectionSet(); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *parser) parseArguments() (args map[string]interface{}, err error) {
+	if err = p.expect(tokenPunctuator, "("); err != nil {
+		return
+	}
+
+	args = map[string]interface{}{}
+	for !p.is(tokenPunctuator, ")") {
+		var name string
+		if name, err = p.expectName(); err != nil {
+			return
+		}
+		if _, found := args[name]; found {
+			return nil, p.errorf("duplicated argument, '%s'", name)
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if args[name], err = p.parseValue(false); err != nil {
+			return
+		}
+	}
+
+	err = p.next()
+	return
+}
+
+// parseValue parses the value; `int64`, `float64`, `string`, `bool`, `nil`,
+// `[]interface{}`, `map[string]interface{}` and `Variable`. The enum value is
+// parsed as `string`.
+func (p *parser) parseValue(isConst bool) (v interface{}, err error) {
+	t := p.token
+	switch t.kind {
+	case tokenInt:
+		if v, err = strconv.ParseInt(t.value, 10, 64); err != nil {
+			return nil, p.errorf("invalid int, '%s'", t.value)
+		}
+	case tokenFloat:
+		if v, err = strconv.ParseFloat(t.value, 64); err != nil {
+			return nil, p.errorf("invalid float, '%s'", t.value)
+		}
+	case tokenString:
+		v = t.value
+	case tokenName:
+		switch t.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = t.value
+		}
+	case tokenPunctuator:
+		switch t.value {
+		case "$":
+			if isConst {
+				return nil, p.errorf("variable is not allowed")
+			}
+			if err = p.next(); err != nil {
+				return
+			}
+			var name string
+			if name, err = p.expectName(); err != nil {
+				return
+			}
+			return Variable(name), nil
+		case "[":
+			return p.parseList(isConst)
+		case "{":
+			return p.parseObject(isConst)
+		}
+		return nil, p.errorf("unexpected '%s'", t.value)
+	default:
+		return nil, p.errorf("expected value")
+	}
+
+	err = p.next()
+	return
+}
+
+func (p *parser) parseList(isConst bool) (v interface{}, err error) {
+	if err = p.enter(&p.valDepth); err != nil {
+		return
+	}
+	defer p.leave(&p.valDepth)
+
+	if err = p.next(); err != nil {
+		return
+	}
+
+	l := []interface{}{}
+	for !p.is(tokenPunctuator, "]") {
+		var i interface{}
+		if i, err = p.parseValue(isConst); err != nil {
+			return
+		}
+		l = append(l, i)
+	}
+
+	err = p.next()
+	return l, err
+}
+
+func (p *parser) parseObject(isConst bool) (v interface{}, err error) {
+	if err = p.enter(&p.valDepth); err != nil {
+		return
+	}
+	defer p.leave(&p.valDepth)
+
+	if err = p.next(); err != nil {
+		return
+	}
+
+	o := map[string]interface{}{}
+	for !p.is(tokenPunctuator, "}") {
+		var name string
+		if name, err = p.expectName(); err != nil {
+			return
+		}
+		if err = p.expect(tokenPunctuator, ":"); err != nil {
+			return
+		}
+		if o[name], err = p.parseValue(isConst); err != nil {
+			return
+		}
+	}
+
+	err = p.next()
+	return o, err
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,367 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Type is the type of the field; `Scalar`, `*List` or `*Object`.
+type Type interface {
+	String() string
+}
+
+// Scalar is the leaf value, which is rendered as JSON.
+type Scalar string
+
+const (
+	String  Scalar = "String"
+	Int     Scalar = "Int"
+	Boolean Scalar = "Boolean"
+	// JSON is any value, like the body of operation.
+	JSON Scalar = "JSON"
+)
+
+func (s Scalar) String() string {
+	return string(s)
+}
+
+type List struct {
+	Of Type
+}
+
+func NewList(of Type) *List {
+	return &List{Of: of}
+}
+
+func (l *List) String() string {
+	return "[" + l.Of.String() + "]"
+}
+
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+func NewObject(name string) *Object {
+	return &Object{Name: name, Fields: map[string]*Field{}}
+}
+
+func (o *Object) String() string {
+	return o.Name
+}
+
+// ResolveParams is passed to `ResolveFunc`.
+type ResolveParams struct {
+	// The value resolved by the parent field
+	Source interface{}
+	// The arguments of the field; the variables are already replaced.
+	Args map[string]interface{}
+}
+
+// ResolveFunc returns the value of field. For `*Object`, `nil` is `null`.
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+type Field struct {
+	Type Type
+	// The names of the arguments, which the field accepts
+	Args    []string
+	Resolve ResolveFunc
+	// Multiplier returns how many times the selections of the field are
+	// resolved at most, like the page size of the list. `nil` is 1.
+	Multiplier func(args map[string]interface{}) (uint64, error)
+}
+
+// Schema is the read-only schema, which has only the query root.
+type Schema struct {
+	Query *Object
+	// The maximum cost of a query; every resolved field costs 1 and the
+	// selections of the field are multiplied by `Field.Multiplier`.
+	MaxCost uint64
+	// The maximum depth of the selection sets
+	MaxDepth int
+}
+
+// Request is the body of GraphQL request.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Execute runs the query. If the query is not valid, `error` is returned
+// without executing it. The errors of the resolvers are in
+// `Response.Errors` and the fields are `null`.
+func (s *Schema) Execute(r Request) (*Response, error) {
+	doc, err := Parse(r.Query, s.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := doc.operation(r.OperationName)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := op.variables(r.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	cost, err := s.validate(s.Query, op.SelectionSet, vars, 1)
+	if err != nil {
+		return nil, err
+	}
+	if s.MaxCost > 0 && cost > s.MaxCost {
+		return nil, fmt.Errorf("query cost, %d is over the limit, %d", cost, s.MaxCost)
+	}
+
+	e := &executor{vars: vars}
+	data := e.selections(s.Query, nil, op.SelectionSet, nil)
+
+	return &Response{Data: data, Errors: e.errors}, nil
+}
+
+func (d *Document) operation(name string) (*Operation, error) {
+	if len(name) < 1 {
+		if len(d.Operations) > 1 {
+			return nil, fmt.Errorf("operationName must be given for multiple operations")
+		}
+		return d.Operations[0], nil
+	}
+
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation, '%s'", name)
+}
+
+func (op *Operation) variables(given map[string]interface{}) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	for _, def := range op.Variables {
+		v, found := given[def.Name]
+		if !found {
+			v = def.Default
+		}
+		if v == nil && def.NonNull {
+			return nil, fmt.Errorf("variable, '$%s' of '%s!' must be given", def.Name, def.Type)
+		}
+		vars[def.Name] = v
+	}
+
+	return vars, nil
+}
+
+// validate checks the selections against the schema and returns the cost.
+func (s *Schema) validate(o *Object, selections []*Selection, vars map[string]interface{}, depth int) (cost uint64, err error) {
+	if s.MaxDepth > 0 && depth > s.MaxDepth {
+		return 0, fmt.Errorf("query depth is over the limit, %d", s.MaxDepth)
+	}
+
+	for _, sel := range selections {
+		if sel.Name == "__typename" {
+			cost++
+			continue
+		}
+
+		f, found := o.Fields[sel.Name]
+		if !found {
+			return 0, fmt.Errorf("unknown field, '%s' of '%s'", sel.Name, o.Name)
+		}
+
+		for name := range sel.Arguments {
+			if !hasString(f.Args, name) {
+				return 0, fmt.Errorf("unknown argument, '%s' of '%s.%s'", name, o.Name, sel.Name)
+			}
+		}
+		var args map[string]interface{}
+		if args, err = arguments(sel.Arguments, vars); err != nil {
+			return
+		}
+
+		child := objectOf(f.Type)
+		if child == nil && len(sel.SelectionSet) > 0 {
+			return 0, fmt.Errorf("'%s.%s' of '%s' must not have selections", o.Name, sel.Name, f.Type)
+		} else if child != nil && len(sel.SelectionSet) < 1 {
+			return 0, fmt.Errorf("'%s.%s' of '%s' must have selections", o.Name, sel.Name, f.Type)
+		}
+
+		cost++
+		if child == nil {
+			continue
+		}
+
+		var n uint64 = 1
+		if f.Multiplier != nil {
+			if n, err = f.Multiplier(args); err != nil {
+				return 0, fmt.Errorf("'%s.%s': %v", o.Name, sel.Name, err)
+			}
+		}
+
+		var c uint64
+		if c, err = s.validate(child, sel.SelectionSet, vars, depth+1); err != nil {
+			return
+		}
+		cost += n * c
+	}
+
+	return
+}
+
+type executor struct {
+	vars   map[string]interface{}
+	errors []Error
+}
+
+func (e *executor) selections(o *Object, source interface{}, selections []*Selection, path []interface{}) *orderedMap {
+	m := &orderedMap{values: map[string]interface{}{}}
+	for _, sel := range selections {
+		key := sel.Key()
+		fieldPath := append(append([]interface{}{}, path...), key)
+
+		if sel.Name == "__typename" {
+			m.set(key, o.Name)
+			continue
+		}
+
+		f := o.Fields[sel.Name]
+		args, _ := arguments(sel.Arguments, e.vars)
+		v, err := f.Resolve(ResolveParams{Source: source, Args: args})
+		if err != nil {
+			e.errors = append(e.errors, Error{Message: err.Error(), Path: fieldPath})
+			m.set(key, nil)
+			continue
+		}
+
+		m.set(key, e.complete(f.Type, v, sel.SelectionSet, fieldPath))
+	}
+
+	return m
+}
+
+func (e *executor) complete(t Type, v interface{}, selections []*Selection, path []interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	switch t := t.(type) {
+	case *Object:
+		return e.selections(t, v, selections, path)
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			e.errors = append(e.errors, Error{Message: fmt.Sprintf("'%s' is not list", t), Path: path})
+			return nil
+		}
+		l := make([]interface{}, len(items))
+		for i, item := range items {
+			l[i] = e.complete(t.Of, item, selections, append(append([]interface{}{}, path...), i))
+		}
+		return l
+	}
+
+	return v
+}
+
+// arguments replaces the variables of the arguments.
+func arguments(args map[string]interface{}, vars map[string]interface{}) (map[string]interface{}, error) {
+	resolved := map[string]interface{}{}
+	for name, v := range args {
+		var err error
+		if resolved[name], err = resolveValue(v, vars); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+func resolveValue(v interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case Variable:
+		value, found := vars[string(v)]
+		if !found {
+			return nil, fmt.Errorf("variable, '$%s' is not defined", v)
+		}
+		return value, nil
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if l[i], err = resolveValue(item, vars); err != nil {
+				return nil, err
+			}
+		}
+		return l, nil
+	case map[string]interface{}:
+		return arguments(v, vars)
+	}
+	return v, nil
+}
+
+func objectOf(t Type) *Object {
+	switch t := t.(type) {
+	case *Object:
+		return t
+	case *List:
+		return objectOf(t.Of)
+	}
+	return nil
+}
+
+func hasString(l []string, s string) bool {
+	for _, i := range l {
+		if i == s {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedMap keeps the order of the selections in the result.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, v interface{}) {
+	if _, found := m.values[key]; !found {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/node/runner/api/graphql"
+)
+
+func queryGraphQL(t *testing.T, url string, req graphql.Request) (int, map[string]interface{}) {
+	resp, err := http.Post(url+GraphQLPattern, "application/json", strings.NewReader(string(common.MustMarshalJSON(req))))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &m), string(b))
+	return resp.StatusCode, m
+}
+
+func TestGraphQL(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	source := keypair.Random()
+	target := keypair.Random()
+	require.NoError(t, block.NewBlockAccount(source.Address(), common.BaseReserve).Save(st))
+	require.NoError(t, block.NewBlockAccount(target.Address(), common.BaseReserve).Save(st))
+
+	_, _, btList := prepareTxsWithKeyPair(st, source, target, 3)
+	latest := block.GetLatestBlock(st)
+
+	{ // relationships
+		status, m := queryGraphQL(t, ts.URL, graphql.Request{
+			Query: `{
+				latest_block {
+					hash
+					height
+					transactions(first: 2) {
+						nodes {
+							hash
+							block { height }
+							source_account { address }
+							operations { nodes { type target_account { address } } }
+						}
+						page_info { has_next_page end_cursor }
+					}
+				}
+			}`,
+		})
+		require.Equal(t, http.StatusOK, status)
+		require.Nil(t, m["errors"])
+
+		blk := m["data"].(map[string]interface{})["latest_block"].(map[string]interface{})
+		require.Equal(t, latest.Hash, blk["hash"])
+		require.Equal(t, float64(latest.Height), blk["height"])
+
+		txs := blk["transactions"].(map[string]interface{})
+		nodes := txs["nodes"].([]interface{})
+		require.Equal(t, 2, len(nodes))
+		require.Equal(t, true, txs["page_info"].(map[string]interface{})["has_next_page"])
+
+		var hashes []string
+		for _, bt := range btList {
+			hashes = append(hashes, bt.Hash)
+		}
+		for _, n := range nodes {
+			tx := n.(map[string]interface{})
+			require.Contains(t, hashes, tx["hash"])
+			require.Equal(t, float64(latest.Height), tx["block"].(map[string]interface{})["height"])
+			require.Equal(t, source.Address(), tx["source_account"].(map[string]interface{})["address"])
+
+			ops := tx["operations"].(map[string]interface{})["nodes"].([]interface{})
+			require.Equal(t, 1, len(ops))
+			op := ops[0].(map[string]interface{})
+			require.Equal(t, "payment", op["type"])
+			require.Equal(t, target.Address(), op["target_account"].(map[string]interface{})["address"])
+		}
+
+		// next page
+		cursor := txs["page_info"].(map[string]interface{})["end_cursor"].(string)
+		status, m = queryGraphQL(t, ts.URL, graphql.Request{
+			Query:     `query ($hash: String!, $after: String) { block(hash: $hash) { transactions(first: 2, after: $after) { nodes { hash } page_info { has_next_page } } } }`,
+			Variables: map[string]interface{}{"hash": latest.Hash, "after": cursor},
+		})
+		require.Equal(t, http.StatusOK, status)
+		txs = m["data"].(map[string]interface{})["block"].(map[string]interface{})["transactions"].(map[string]interface{})
+		require.Equal(t, 1, len(txs["nodes"].([]interface{})))
+		require.Equal(t, false, txs["page_info"].(map[string]interface{})["has_next_page"])
+	}
+
+	{ // not found
+		status, m := queryGraphQL(t, ts.URL, graphql.Request{
+			Query: `{ account(address: "GDEPYGGALDU7UN2FD7HP3T3R7SM3OZBTYXV7VHWZRM2Q7QAXHXUUQFHM") { address } }`,
+		})
+		require.Equal(t, http.StatusOK, status)
+		require.Nil(t, m["errors"])
+		require.Nil(t, m["data"].(map[string]interface{})["account"])
+	}
+
+	{ // GET
+		resp, err := http.Get(ts.URL + GraphQLPattern + "?query=" + url.QueryEscape(`{ account(address: "`+source.Address()+`") { address balance } }`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	{ // over the cost limit
+		status, m := queryGraphQL(t, ts.URL, graphql.Request{
+			Query: `{ blocks(first: 100) { nodes { transactions(first: 100) { nodes { hash source } } } } }`,
+		})
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, m["errors"].([]interface{})[0].(map[string]interface{})["message"], "cost")
+	}
+
+	{ // over the page limit
+		status, _ := queryGraphQL(t, ts.URL, graphql.Request{
+			Query: `{ blocks(first: 101) { nodes { hash } } }`,
+		})
+		require.Equal(t, http.StatusBadRequest, status)
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+)
+
+// GetLedgerHandler returns the ledger statistics of the latest block; with
+// `height`, the ledger of the block at the height is returned.
+func (api NetworkHandlerAPI) GetLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	var ledger block.Ledger
+	var err error
+	if s := r.URL.Query().Get("height"); len(s) > 0 {
+		var height uint64
+		if height, err = strconv.ParseUint(s, 10, 64); err != nil {
+			httputils.WriteJSONError(w, errors.InvalidQueryString.Clone().SetData("error", err.Error()))
+			return
+		}
+		ledger, err = block.GetLedger(api.storage, height)
+	} else {
+		ledger, err = block.GetLatestLedger(api.storage)
+	}
+
+	if err == errors.StorageRecordDoesNotExist {
+		httputils.WriteJSONError(w, errors.LedgerNotFound)
+		return
+	} else if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	httputils.MustWriteJSON(w, 200, resource.NewLedger(ledger))
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+)
+
+func TestGetLedgerHandler(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	genesis := block.GetLatestBlock(st)
+
+	next := block.TestMakeNewBlockWithPrevBlock(genesis, nil)
+	next.MustSave(st)
+	ledger, err := block.GetLedger(st, genesis.Height)
+	require.NoError(t, err)
+	ledger, err = ledger.Next(st, next, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, ledger.Save(st))
+
+	get := func(url string) (int, map[string]interface{}) {
+		resp, err := http.Get(ts.URL + url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		result := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(b, &result))
+
+		return resp.StatusCode, result
+	}
+
+	{ // latest
+		status, result := get(GetLedgerHandlerPattern)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, float64(next.Height), result["height"])
+		require.Equal(t, next.Hash, result["block"])
+		require.Equal(t, float64(2), result["accounts"])
+		require.Equal(t, "0", result["frozen_amount"])
+	}
+
+	{ // by height
+		status, result := get(GetLedgerHandlerPattern + "?height=1")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, genesis.Hash, result["block"])
+	}
+
+	{ // unknown height
+		status, _ := get(GetLedgerHandlerPattern + "?height=100")
+		require.Equal(t, http.StatusNotFound, status)
+	}
+
+	{ // invalid height
+		status, _ := get(GetLedgerHandlerPattern + "?height=a")
+		require.Equal(t, http.StatusBadRequest, status)
+	}
+}
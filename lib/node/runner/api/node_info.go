@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 
 	"boscoin.io/sebak/lib/common"
@@ -33,6 +34,16 @@ func (api NetworkHandlerAPI) GetNodeInfoHandler(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	if api.GetTimeouts != nil {
+		nodeInfo.Timeouts = api.GetTimeouts()
+	}
+
+	if api.GetSyncStatus != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), SyncStatusTimeout)
+		nodeInfo.Sync, _ = api.GetSyncStatus(ctx)
+		cancel()
+	}
+
 	var b []byte
 	var err error
 	if b, err = common.JSONMarshalIndent(nodeInfo); err != nil {
@@ -47,6 +47,7 @@ func TestAPIGetNodeInfoHandler(t *testing.T) {
 		NetworkID:                 string(networkID),
 		InitialBalance:            common.Amount(1000),
 		BaseReserve:               common.BaseReserve,
+		DataEntryReserve:          common.DataEntryReserve,
 		BaseFee:                   common.BaseFee,
 		BlockTime:                 time.Duration(5) * time.Second,
 		BlockTimeDelta:            time.Duration(3) * time.Second,
@@ -77,6 +78,16 @@ func TestAPIGetNodeInfoHandler(t *testing.T) {
 		GetLatestBlock: func() block.Block {
 			return block.GetLatestBlock(st)
 		},
+		GetTimeouts: func() node.NodeTimeouts {
+			return node.NodeTimeouts{
+				Adaptive:      true,
+				INIT:          time.Second,
+				SIGN:          2 * time.Second,
+				ACCEPT:        3 * time.Second,
+				ALLCONFIRM:    30 * time.Second,
+				RoundTripTime: 100 * time.Millisecond,
+			}
+		},
 	}
 
 	router := mux.NewRouter()
@@ -109,6 +120,7 @@ func TestAPIGetNodeInfoHandler(t *testing.T) {
 	js, _ := json.Marshal(policy)
 	rjs, _ := json.Marshal(receivedNodeInfo.Policy)
 	require.Equal(t, js, rjs)
+	require.Equal(t, apiHandler.GetTimeouts(), receivedNodeInfo.Timeouts)
 
 	// udpate localNode state
 	localNode.SetBooting()
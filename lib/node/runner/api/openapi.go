@@ -0,0 +1,440 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/network/httputils"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner/api/graphql"
+	"boscoin.io/sebak/lib/node/runner/api/openapi"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+const (
+	contentTypeHAL         = "application/hal+json"
+	contentTypeProblem     = "application/problem+json"
+	contentTypeEventStream = "text/event-stream"
+)
+
+// openAPIRoute is the entry of the route in the OpenAPI document. Every route
+// of the API, which is registered by `NodeRunner`, should have its entry.
+type openAPIRoute struct {
+	method      string
+	pattern     string
+	operationID string
+	summary     string
+	parameters  []openapi.Parameter
+	body        *openapi.Schema
+
+	// response is the name of the schema in the components; with `list`, the
+	// response is the list of it.
+	response    string
+	list        bool
+	contentType string
+	status      int
+}
+
+func queryParameter(name, description string, schema *openapi.Schema) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var (
+	schemaString  = &openapi.Schema{Type: "string"}
+	schemaInteger = &openapi.Schema{Type: "integer", Format: "int64"}
+	schemaBoolean = &openapi.Schema{Type: "boolean"}
+
+	// pageParameters are the queries of `PageQuery`
+	pageParameters = []openapi.Parameter{
+		queryParameter("cursor", "the cursor of the page", schemaString),
+		queryParameter(
+			"limit",
+			fmt.Sprintf("the number of the records; default is %d and maximum is %d", DefaultLimit, MaxLimit),
+			schemaInteger,
+		),
+		queryParameter("reverse", "the records in reverse order", schemaBoolean),
+	}
+
+	// filterParameters are the queries of `FilterQuery`
+	filterParameters = []openapi.Parameter{
+		queryParameter("min_height", "the minimum block height", schemaInteger),
+		queryParameter("max_height", "the maximum block height", schemaInteger),
+		queryParameter("since", "the minimum block time in RFC3339", &openapi.Schema{Type: "string", Format: "date-time"}),
+		queryParameter("until", "the maximum block time in RFC3339", &openapi.Schema{Type: "string", Format: "date-time"}),
+		queryParameter("min_amount", "the minimum amount of payment in GON"+filterScanNote, schemaString),
+		queryParameter("max_amount", "the maximum amount of payment in GON"+filterScanNote, schemaString),
+		queryParameter("counterparty", "the address of the other side of payment", schemaString),
+		queryParameter(
+			"direction",
+			"the payments sent by or received by the account",
+			&openapi.Schema{Type: "string", Enum: []interface{}{DirectionSent, DirectionReceived}},
+		),
+	}
+)
+
+// filterScanNote describes `MaxFilterScan` for the filters, which are not
+// indexed.
+var filterScanNote = fmt.Sprintf(
+	"; at most %d records are scanned by one request, so the page can have less records than the limit before the last page",
+	MaxFilterScan,
+)
+
+func operationTypeSchema() *openapi.Schema {
+	s := &openapi.Schema{Type: "string"}
+	for t := operation.TypeCreateAccount; t <= operation.TypeManageData; t++ {
+		s.Enum = append(s.Enum, t.String())
+	}
+
+	return s
+}
+
+func withParameters(parameters ...[]openapi.Parameter) (all []openapi.Parameter) {
+	for _, p := range parameters {
+		all = append(all, p...)
+	}
+
+	return
+}
+
+var openAPIRoutes = []openAPIRoute{
+	{
+		method: "GET", pattern: GetNodeInfoPattern, operationID: "getNodeInfo",
+		summary: "node information", response: "NodeInfo",
+	},
+	{
+		method: "GET", pattern: GetAccountHandlerPattern, operationID: "getAccount",
+		summary: "account", response: "Account",
+	},
+	{
+		method: "GET", pattern: GetAccountDataHandlerPattern, operationID: "getAccountData",
+		summary: "data entry of account", response: "AccountData",
+	},
+	{
+		method: "POST", pattern: GetAccountsHandlerPattern, operationID: "getAccounts",
+		summary:  "accounts of the addresses",
+		body:     &openapi.Schema{Type: "array", Items: schemaString},
+		response: "Account", list: true,
+	},
+	{
+		method: "GET", pattern: GetAccountTransactionsHandlerPattern, operationID: "getTransactionsByAccount",
+		summary:    "transactions of account",
+		parameters: withParameters(pageParameters, filterParameters),
+		response:   "Transaction", list: true,
+	},
+	{
+		method: "GET", pattern: GetAccountOperationsHandlerPattern, operationID: "getOperationsByAccount",
+		summary: "operations of account",
+		parameters: withParameters(
+			pageParameters,
+			filterParameters,
+			[]openapi.Parameter{queryParameter("type", "the type of operation", operationTypeSchema())},
+		),
+		response: "Operation", list: true,
+	},
+	{
+		method: "GET", pattern: GetAccountFrozenAccountHandlerPattern, operationID: "getFrozenAccountsByAccount",
+		summary:    "frozen accounts linked to account",
+		parameters: pageParameters,
+		response:   "FrozenAccount", list: true,
+	},
+	{
+		method: "GET", pattern: GetFrozenAccountHandlerPattern, operationID: "getFrozenAccounts",
+		summary:    "frozen accounts",
+		parameters: pageParameters,
+		response:   "FrozenAccount", list: true,
+	},
+	{
+		method: "GET", pattern: GetTransactionsHandlerPattern, operationID: "getTransactions",
+		summary:    "transactions",
+		parameters: pageParameters,
+		response:   "Transaction", list: true,
+	},
+	{
+		method: "POST", pattern: PostTransactionPattern, operationID: "postTransaction",
+		summary:  "submit transaction",
+		body:     openapi.Ref("TransactionMessage"),
+		response: "TransactionPost",
+	},
+	{
+		method: "POST", pattern: PostTransactionsBatchPattern, operationID: "postTransactionsBatch",
+		summary:  fmt.Sprintf("submit transactions; maximum is %d and each one is accepted or rejected", MaxTransactionsInBatch),
+		body:     &openapi.Schema{Type: "array", Items: openapi.Ref("TransactionMessage")},
+		response: "TransactionBatchResult", list: true,
+	},
+	{
+		method: "GET", pattern: GetTransactionByHashHandlerPattern, operationID: "getTransaction",
+		summary: "transaction", response: "Transaction",
+	},
+	{
+		method: "GET", pattern: GetTransactionOperationsHandlerPattern, operationID: "getOperationsByTransaction",
+		summary:    "operations of transaction",
+		parameters: pageParameters,
+		response:   "Operation", list: true,
+	},
+	{
+		method: "GET", pattern: GetTransactionOperationHandlerPattern, operationID: "getOperation",
+		summary: "operation of transaction by index", response: "Operation",
+	},
+	{
+		method: "GET", pattern: GetTransactionStatusHandlerPattern, operationID: "getTransactionStatus",
+		summary: "status of transaction", response: "TransactionStatus",
+	},
+	{
+		method: "GET", pattern: GetBlocksHandlerPattern, operationID: "getBlocks",
+		summary:    "blocks; the cursor is the block height",
+		parameters: pageParameters,
+		response:   "Block", list: true,
+	},
+	{
+		method: "GET", pattern: GetBlockHandlerPattern, operationID: "getBlock",
+		summary: "block by hash or height", response: "Block",
+	},
+	{
+		method: "GET", pattern: GetLedgerHandlerPattern, operationID: "getLedger",
+		summary: "ledger statistics of the latest block or the block at height",
+		parameters: []openapi.Parameter{
+			queryParameter("height", "the block height", schemaInteger),
+		},
+		response: "Ledger",
+	},
+	{
+		method: "GET", pattern: GetSyncHandlerPattern, operationID: "getSync",
+		summary: "progress of sync", response: "SyncInfo",
+		contentType: DefaultContentType,
+	},
+	{
+		method: "POST", pattern: PostSubscribePattern, operationID: "subscribe",
+		summary: "stream of the events of the conditions",
+		parameters: []openapi.Parameter{
+			queryParameter("cursor", "the last received event id", schemaString),
+			{Name: "Last-Event-ID", In: "header", Description: "the last received event id", Schema: schemaString},
+		},
+		body:        &openapi.Schema{Type: "array", Items: openapi.Ref("Conditions")},
+		contentType: contentTypeEventStream,
+	},
+	{
+		method: "GET", pattern: GetSubscribeWebSocketPattern, operationID: "subscribeWebSocket",
+		summary: "websocket of the events",
+		parameters: []openapi.Parameter{
+			queryParameter(
+				"overflow",
+				"how to handle the slow client",
+				&openapi.Schema{Type: "string", Enum: []interface{}{WebSocketOverflowDrop, WebSocketOverflowClose}},
+			),
+		},
+		status: http.StatusSwitchingProtocols,
+	},
+	{
+		method: "GET", pattern: GraphQLPattern, operationID: "getGraphQL",
+		summary: "read-only GraphQL query",
+		parameters: []openapi.Parameter{
+			queryParameter("query", "the query document", schemaString),
+			queryParameter("operationName", "the operation to run", schemaString),
+			queryParameter("variables", "the variables in JSON", schemaString),
+		},
+		response: "GraphQLResponse", contentType: DefaultContentType,
+	},
+	{
+		method: "POST", pattern: GraphQLPattern, operationID: "postGraphQL",
+		summary:  "read-only GraphQL query",
+		body:     openapi.Ref("GraphQLRequest"),
+		response: "GraphQLResponse", contentType: DefaultContentType,
+	},
+	{
+		method: "GET", pattern: GetOpenAPIHandlerPattern, operationID: "getOpenAPI",
+		summary: "this document", contentType: DefaultContentType,
+	},
+}
+
+// OpenAPIPath returns the path of the pattern in the OpenAPI document; the
+// node information is served at the root.
+func OpenAPIPath(pattern string) string {
+	if pattern == GetNodeInfoPattern {
+		return pattern
+	}
+
+	return fmt.Sprintf("%s/%s%s", network.UrlPathPrefixAPI, APIVersionV1, pattern)
+}
+
+// resourceSchema makes the schema of the HAL resource.
+func resourceSchema(r resource.Resource) *openapi.Schema {
+	s := openapi.SchemaOf(r.GetMap())
+	s.Properties["_links"] = &openapi.Schema{
+		Type: "object",
+		AdditionalProperties: &openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{"href": schemaString},
+		},
+	}
+
+	return s
+}
+
+func listSchema(name string) *openapi.Schema {
+	return &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"_links": {
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"self": {Type: "object", Properties: map[string]*openapi.Schema{"href": schemaString}},
+					"next": {Type: "object", Properties: map[string]*openapi.Schema{"href": schemaString}},
+					"prev": {Type: "object", Properties: map[string]*openapi.Schema{"href": schemaString}},
+				},
+			},
+			"_embedded": {
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"records": {Type: "array", Items: openapi.Ref(name)},
+				},
+			},
+		},
+	}
+}
+
+// errorCodeSchema lists the codes of `errors.Error`; the code is in `type` of
+// the problem like `httputils.ProblemTypeByCode`.
+func errorCodeSchema() *openapi.Schema {
+	s := &openapi.Schema{Type: "integer"}
+
+	var lines []string
+	for _, e := range errors.Errors() {
+		s.Enum = append(s.Enum, e.Code)
+		lines = append(lines, fmt.Sprintf("%d: %s", e.Code, e.Message))
+	}
+	s.Description = fmt.Sprintf(
+		"the code of error; the `type` of problem is `%s<code>`.\n\n%s",
+		httputils.HttpProblemErrorTypePrefix,
+		strings.Join(lines, "\n"),
+	)
+
+	return s
+}
+
+func openAPISchemas() map[string]*openapi.Schema {
+	operationResource := resource.NewOperation(&block.BlockOperation{}, 0)
+	operationResource.Block = &block.Block{}
+
+	// the result in batch is `TransactionPost` or `TransactionRejected`
+	batchResult := resourceSchema(resource.NewTransactionPost(transaction.Transaction{}))
+	batchResult.Properties["status"].Enum = []interface{}{"submitted", "rejected"}
+	batchResult.Properties["error"] = openapi.SchemaOf(errors.Error{})
+
+	return map[string]*openapi.Schema{
+		"Account":           resourceSchema(resource.NewAccount(&block.BlockAccount{})),
+		"AccountData":       resourceSchema(resource.NewAccountData("", "", nil)),
+		"Block":             resourceSchema(resource.NewBlock(&block.Block{})),
+		"FrozenAccount":     resourceSchema(resource.NewFrozenAccount(&block.BlockAccount{}, resource.FrozenAccountInfo{})),
+		"Ledger":            resourceSchema(resource.NewLedger(block.Ledger{})),
+		"Operation":         resourceSchema(operationResource),
+		"Transaction":       resourceSchema(resource.NewTransaction(&block.BlockTransaction{}, transaction.Transaction{})),
+		"TransactionPost":   resourceSchema(resource.NewTransactionPost(transaction.Transaction{})),
+		"TransactionStatus": resourceSchema(resource.NewTransactionStatus("", "")),
+
+		"TransactionBatchResult": batchResult,
+
+		"TransactionMessage": openapi.SchemaOf(transaction.Transaction{}),
+		"NodeInfo":           openapi.SchemaOf(node.NodeInfo{}),
+		"SyncInfo":           openapi.SchemaOf(node.NodeSyncInfo{}),
+		"Conditions":         openapi.SchemaOf(observer.Conditions{}),
+		"GraphQLRequest":     openapi.SchemaOf(graphql.Request{}),
+		"GraphQLResponse":    openapi.SchemaOf(graphql.Response{}),
+		"Problem":            openapi.SchemaOf(httputils.Problem{}),
+		"ErrorCode":          errorCodeSchema(),
+	}
+}
+
+func newOpenAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "SEBAK API",
+		Description: "The errors are the problem of RFC 7807; see `ErrorCode` for the codes.",
+		Version:     APIVersionV1,
+	})
+	doc.Components.Schemas = openAPISchemas()
+
+	for _, route := range openAPIRoutes {
+		path := OpenAPIPath(route.pattern)
+
+		op := &openapi.Operation{
+			Summary:     route.summary,
+			OperationID: route.operationID,
+			Responses: map[string]openapi.Response{
+				"default": {
+					Description: "error",
+					Content: map[string]openapi.MediaType{
+						contentTypeProblem: {Schema: openapi.Ref("Problem")},
+					},
+				},
+			},
+		}
+
+		for _, name := range openapi.PathParameters(path) {
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name: name, In: "path", Required: true, Schema: schemaString,
+			})
+		}
+		op.Parameters = append(op.Parameters, route.parameters...)
+
+		if route.body != nil {
+			op.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{DefaultContentType: {Schema: route.body}},
+			}
+		}
+
+		status := route.status
+		if status < 1 {
+			status = http.StatusOK
+		}
+		response := openapi.Response{Description: http.StatusText(status)}
+
+		contentType := route.contentType
+		if len(contentType) < 1 {
+			contentType = contentTypeHAL
+		}
+		var schema *openapi.Schema
+		switch {
+		case route.list:
+			schema = listSchema(route.response)
+		case len(route.response) > 0:
+			schema = openapi.Ref(route.response)
+		case status == http.StatusOK:
+			schema = &openapi.Schema{}
+		}
+		if schema != nil {
+			response.Content = map[string]openapi.MediaType{contentType: {Schema: schema}}
+		}
+		op.Responses[fmt.Sprintf("%d", status)] = response
+
+		doc.AddOperation(path, route.method, op)
+	}
+
+	return doc
+}
+
+var (
+	openAPIDocument     *openapi.Document
+	openAPIDocumentOnce sync.Once
+)
+
+// OpenAPIDocument returns the OpenAPI document of the API.
+func OpenAPIDocument() *openapi.Document {
+	openAPIDocumentOnce.Do(func() {
+		openAPIDocument = newOpenAPIDocument()
+	})
+
+	return openAPIDocument
+}
+
+// GetOpenAPIHandler serves the OpenAPI document of the API.
+func (api NetworkHandlerAPI) GetOpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	httputils.MustWriteJSON(w, 200, OpenAPIDocument())
+}
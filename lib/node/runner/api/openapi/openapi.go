@@ -0,0 +1,270 @@
+// Package openapi builds the OpenAPI 3 document of the API. The schemas are
+// made from the values by reflection, so the document follows the resources
+// without writing them again.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const Version = "3.0.0"
+
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	OperationID string              `json:"operationId"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// Ref refers the schema of the components by name.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// NewDocument makes the empty document.
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]map[string]*Operation{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+}
+
+// AddOperation adds the operation of the method to the path; the method is
+// in lower case like "get".
+func (d *Document) AddOperation(path, method string, op *Operation) {
+	if _, found := d.Paths[path]; !found {
+		d.Paths[path] = map[string]*Operation{}
+	}
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+// HasOperation checks the operation of the method is in the path.
+func (d *Document) HasOperation(path, method string) bool {
+	_, found := d.Paths[path][strings.ToLower(method)]
+	return found
+}
+
+// PathParameters returns the names of the parameters in the path template
+// like `/blocks/{id}`.
+func PathParameters(path string) (names []string) {
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			return
+		}
+		end := strings.Index(path[start:], "}")
+		if end < 0 {
+			return
+		}
+		names = append(names, path[start+1:start+end])
+		path = path[start+end+1:]
+	}
+}
+
+var (
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeJSONMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaOf makes the schema of the value. The map with string keys, like
+// `hal.Entry`, is the object of its entries, so the type of each entry is
+// found from the value; the other types are found from their type.
+func SchemaOf(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String && rv.Len() > 0 {
+		if _, ok := v.(json.Marshaler); !ok {
+			s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			for _, key := range rv.MapKeys() {
+				s.Properties[key.String()] = SchemaOf(rv.MapIndex(key).Interface())
+			}
+			return s
+		}
+	}
+
+	return schemaOfType(rv.Type(), map[reflect.Type]bool{})
+}
+
+func schemaOfType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == typeTime {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(typeJSONMarshaler) || reflect.PtrTo(t).Implements(typeJSONMarshaler) {
+		return schemaOfMarshaler(t)
+	}
+	if t.Implements(typeTextMarshaler) || reflect.PtrTo(t).Implements(typeTextMarshaler) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOfType(t.Elem(), visiting)}
+	case reflect.Struct:
+		// the recursive type is not expanded again
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addStructFields(s, t, visiting)
+		return s
+	}
+
+	// interface and the others can be any value
+	return &Schema{}
+}
+
+func addStructFields(s *Schema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+
+		if f.Anonymous && len(name) < 1 {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructFields(s, ft, visiting)
+				continue
+			}
+		}
+		if len(f.PkgPath) > 0 { // unexported
+			continue
+		}
+		if len(name) < 1 {
+			name = f.Name
+		}
+
+		fs := schemaOfType(f.Type, visiting)
+		if strings.Contains(opts, "string") {
+			fs = &Schema{Type: "string", Format: fs.Format}
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// schemaOfMarshaler finds the JSON type from the marshaled zero value.
+func schemaOfMarshaler(t reflect.Type) (s *Schema) {
+	s = &Schema{}
+	defer func() {
+		if r := recover(); r != nil {
+			s = &Schema{}
+		}
+	}()
+
+	b, err := json.Marshal(reflect.New(t).Interface())
+	if err != nil || len(b) < 1 {
+		return
+	}
+
+	switch b[0] {
+	case '"':
+		s.Type = "string"
+	case '{':
+		s.Type = "object"
+	case '[':
+		s.Type = "array"
+		s.Items = &Schema{}
+	case 't', 'f':
+		s.Type = "boolean"
+	case 'n':
+	default:
+		s.Type = "number"
+	}
+
+	return
+}
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEmbedded struct {
+	Name string `json:"name"`
+}
+
+type testValue struct {
+	testEmbedded
+	Height  uint64            `json:"height"`
+	Amount  uint64            `json:"amount,string"`
+	Time    time.Time         `json:"time"`
+	Tags    []string          `json:"tags"`
+	Data    []byte            `json:"data"`
+	Links   map[string]string `json:"links"`
+	Ignored string            `json:"-"`
+	Next    *testValue        `json:"next"`
+	hidden  string
+}
+
+func TestSchemaOf(t *testing.T) {
+	s := SchemaOf(testValue{})
+	require.Equal(t, "object", s.Type)
+	require.Equal(t, 8, len(s.Properties))
+
+	require.Equal(t, "string", s.Properties["name"].Type)
+	require.Equal(t, &Schema{Type: "integer", Format: "int64"}, s.Properties["height"])
+	require.Equal(t, "string", s.Properties["amount"].Type)
+	require.Equal(t, &Schema{Type: "string", Format: "date-time"}, s.Properties["time"])
+	require.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, s.Properties["tags"])
+	require.Equal(t, &Schema{Type: "string", Format: "byte"}, s.Properties["data"])
+	require.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, s.Properties["links"])
+	require.Equal(t, &Schema{Type: "object"}, s.Properties["next"])
+
+	// the entries of map are the properties
+	s = SchemaOf(map[string]interface{}{"id": "a", "count": 1, "ok": true})
+	require.Equal(t, "object", s.Type)
+	require.Equal(t, "string", s.Properties["id"].Type)
+	require.Equal(t, "integer", s.Properties["count"].Type)
+	require.Equal(t, "boolean", s.Properties["ok"].Type)
+}
+
+func TestPathParameters(t *testing.T) {
+	require.Nil(t, PathParameters("/blocks"))
+	require.Equal(t, []string{"id"}, PathParameters("/accounts/{id}/operations"))
+	require.Equal(t, []string{"id", "opindex"}, PathParameters("/transactions/{id}/operations/{opindex}"))
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/node/runner/api/openapi"
+)
+
+func TestGetOpenAPIHandler(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + GetOpenAPIHandlerPattern)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	b, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(b, &doc))
+	require.Equal(t, openapi.Version, doc.OpenAPI)
+
+	require.True(t, doc.HasOperation(OpenAPIPath(GetAccountHandlerPattern), "GET"))
+	require.True(t, doc.HasOperation(OpenAPIPath(PostTransactionPattern), "POST"))
+	require.True(t, doc.HasOperation(GetNodeInfoPattern, "GET"))
+	require.False(t, doc.HasOperation(OpenAPIPath(GetAccountHandlerPattern), "DELETE"))
+
+	{ // the parameters of path are described
+		for path, ops := range doc.Paths {
+			for method, op := range ops {
+				var names []string
+				for _, p := range op.Parameters {
+					if p.In == "path" {
+						names = append(names, p.Name)
+					}
+				}
+				require.Equal(t, openapi.PathParameters(path), names, "%s %s", method, path)
+			}
+		}
+	}
+
+	{ // the queries of `PageQuery`
+		op := doc.Paths[OpenAPIPath(GetBlocksHandlerPattern)]["get"]
+		var names []string
+		for _, p := range op.Parameters {
+			names = append(names, p.Name)
+		}
+		require.Equal(t, []string{"cursor", "limit", "reverse"}, names)
+	}
+
+	{ // the references are in the components
+		var check func(*openapi.Schema)
+		check = func(s *openapi.Schema) {
+			if s == nil {
+				return
+			}
+			if len(s.Ref) > 0 {
+				name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+				require.Contains(t, doc.Components.Schemas, name)
+			}
+			check(s.Items)
+			check(s.AdditionalProperties)
+			for _, p := range s.Properties {
+				check(p)
+			}
+		}
+		for _, ops := range doc.Paths {
+			for _, op := range ops {
+				if op.RequestBody != nil {
+					for _, m := range op.RequestBody.Content {
+						check(m.Schema)
+					}
+				}
+				for _, r := range op.Responses {
+					for _, m := range r.Content {
+						check(m.Schema)
+					}
+				}
+			}
+		}
+
+		account := doc.Components.Schemas["Account"]
+		require.Equal(t, "string", account.Properties["address"].Type)
+		require.NotNil(t, account.Properties["_links"])
+	}
+
+	{ // every error code
+		codes := doc.Components.Schemas["ErrorCode"].Enum
+		require.Equal(t, len(errors.Errors()), len(codes))
+		require.Contains(t, codes, float64(errors.LedgerNotFound.Code))
+	}
+}
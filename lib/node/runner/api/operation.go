@@ -7,6 +7,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network/httputils"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
@@ -30,7 +31,7 @@ func (api NetworkHandlerAPI) GetOperationsByTxHashOpIndexHandler(w http.Response
 			return nil, err
 		}
 		if !found {
-			return nil, errors.BlockTransactionDoesNotExists
+			return nil, api.blockTransactionNotFound(txHash)
 		}
 		bo, err := block.GetBlockOperationWithIndex(api.storage, txHash, opIndexInt)
 		if err != nil {
@@ -59,7 +60,11 @@ func (api NetworkHandlerAPI) GetOperationsByAccountHandler(w http.ResponseWriter
 		return
 	}
 
-	options := p.ListOptions()
+	f, err := NewFilterQuery(r)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
 
 	var oType operation.OperationType
 	oTypeStr := r.URL.Query().Get("type")
@@ -78,29 +83,59 @@ func (api NetworkHandlerAPI) GetOperationsByAccountHandler(w http.ResponseWriter
 		return
 	}
 
+	options, empty, err := f.ListOptions(api.storage, p)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
 	var txs []resource.Resource
 	blockCache := map[ /* block.Height */ uint64]*block.Block{}
 	var firstCursor []byte
 	var lastCursor []byte
-	{
-
+	if !empty {
+		// the most selective index is used and the others are filtered.
 		var iterFunc func() (block.BlockOperation, bool, []byte)
 		var closeFunc func()
-		if len(oTypeStr) > 0 {
+		switch {
+		case len(f.Counterparty) > 0:
+			iterFunc, closeFunc = block.GetBlockOperationsByCounterparty(api.storage, address, f.Counterparty, options)
+		case f.Direction == DirectionSent && len(oTypeStr) > 0:
+			iterFunc, closeFunc = block.GetBlockOperationsBySourceAndType(api.storage, address, oType, options)
+		case f.Direction == DirectionSent:
+			iterFunc, closeFunc = block.GetBlockOperationsBySource(api.storage, address, options)
+		case f.Direction == DirectionReceived && len(oTypeStr) > 0:
+			iterFunc, closeFunc = block.GetBlockOperationsByTargetAndType(api.storage, address, oType, options)
+		case f.Direction == DirectionReceived:
+			iterFunc, closeFunc = block.GetBlockOperationsByTarget(api.storage, address, options)
+		case len(oTypeStr) > 0:
 			iterFunc, closeFunc = block.GetBlockOperationsByPeersAndType(api.storage, address, oType, options)
-		} else {
+		default:
 			iterFunc, closeFunc = block.GetBlockOperationsByPeers(api.storage, address, options)
 		}
-		for {
+		for scanned := 0; scanned < MaxFilterScan && (p.Limit() < 1 || uint64(len(txs)) < p.Limit()); scanned++ {
 			t, hasNext, c := iterFunc()
 			if !hasNext {
 				break
 			}
+			// the cursor moves over the filtered out records too
 			if len(firstCursor) == 0 {
 				firstCursor = append(firstCursor, c...)
 			}
 			lastCursor = append([]byte{}, c...)
 
+			if len(oTypeStr) > 0 && t.Type != oType {
+				continue
+			}
+			if !f.MatchDirection(address, t.Source) {
+				continue
+			}
+			if f.HasAmount() {
+				if amount, ok := operationAmount(t); !ok || !f.MatchAmount(amount) {
+					continue
+				}
+			}
+
 			var blk *block.Block
 			var ok bool
 			if blk, ok = blockCache[t.Height]; !ok {
@@ -132,3 +167,17 @@ func (api NetworkHandlerAPI) GetOperationsByAccountHandler(w http.ResponseWriter
 	list := p.ResourceList(txs, firstCursor, lastCursor)
 	httputils.MustWriteJSON(w, 200, list)
 }
+
+// operationAmount returns the amount of the payable operation.
+func operationAmount(bo block.BlockOperation) (common.Amount, bool) {
+	body, err := operation.UnmarshalBodyJSON(bo.Type, bo.Body)
+	if err != nil {
+		return 0, false
+	}
+	pop, ok := body.(operation.Payable)
+	if !ok {
+		return 0, false
+	}
+
+	return pop.GetAmount(), true
+}
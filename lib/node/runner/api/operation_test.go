@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/require"
 	"io/ioutil"
+	"net/http"
 	"strings"
 	"testing"
 )
@@ -334,3 +335,46 @@ func TestGetOperationsByAccountHandlerPage(t *testing.T) {
 		}
 	}
 }
+
+func TestGetOperationsByAccountHandlerWithFilters(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	me, a, b, blks, btList := prepareFilterTxs(t, st)
+	url := strings.Replace(GetAccountOperationsHandlerPattern, "{id}", me.Address(), -1)
+
+	var opHashes []string
+	var amounts []common.Amount
+	for _, bt := range btList {
+		tp, err := block.GetTransactionPool(st, bt.Hash)
+		require.NoError(t, err)
+		tx := tp.Transaction()
+		opHashes = append(opHashes, block.NewBlockOperationKey(common.MustMakeObjectHashString(tx.B.Operations[0]), tx.GetHash()))
+		amounts = append(amounts, tx.B.Operations[0].B.(operation.Payable).GetAmount())
+	}
+
+	for query, expected := range map[string][]string{
+		"":                            opHashes,
+		"type=payment&direction=sent": opHashes[:3],
+		"type=create-account":         nil,
+		"direction=received":          opHashes[3:],
+		"counterparty=" + b.Address(): opHashes[2:3],
+		"counterparty=" + a.Address() + "&direction=received":                                                  opHashes[3:],
+		"max_height=" + fmt.Sprintf("%d", blks[1].Height) + "&direction=received":                              nil,
+		"min_height=" + fmt.Sprintf("%d", blks[1].Height) + "&max_height=" + fmt.Sprintf("%d", blks[2].Height): opHashes[2:],
+		"min_amount=" + amounts[3].String() + "&max_amount=" + amounts[3].String():                             opHashes[3:],
+	} {
+		status, hashes, _ := getRecords(t, ts, url+"?"+query)
+		require.Equal(t, http.StatusOK, status, query)
+		require.Equal(t, len(expected), len(hashes), query)
+		require.Subset(t, expected, hashes, query)
+	}
+
+	{ // the filters are kept in the next link
+		_, hashes, next := getRecords(t, ts, url+"?counterparty="+a.Address()+"&limit=2")
+		require.ElementsMatch(t, opHashes[:2], hashes)
+		_, hashes, _ = getRecords(t, ts, next)
+		require.Equal(t, opHashes[3:], hashes)
+	}
+}
@@ -141,9 +141,10 @@ func (p *PageQuery) parseRequest() error {
 }
 
 func (p PageQuery) urlValues(cursor []byte, reverse bool, limit uint64) url.Values {
-	v := url.Values{
-		"reverse": []string{strconv.FormatBool(reverse)},
-	}
+	// the other queries like filters are kept
+	v := p.request.URL.Query()
+	v.Del("cursor")
+	v.Set("reverse", strconv.FormatBool(reverse))
 
 	if len(cursor) > 0 {
 		if p.isEncodeCursor == true {
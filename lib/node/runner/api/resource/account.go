@@ -20,11 +20,17 @@ func NewAccount(ba *block.BlockAccount) *Account {
 }
 
 func (a Account) GetMap() hal.Entry {
+	data := map[string][]byte{}
+	for _, entry := range a.ba.Data {
+		data[entry.Key] = entry.Value
+	}
+
 	return hal.Entry{
 		"address":     a.ba.Address,
 		"sequence_id": a.ba.SequenceID,
 		"balance":     a.ba.Balance,
 		"linked":      a.ba.Linked,
+		"data":        data,
 	}
 }
 
@@ -35,6 +41,7 @@ func (a Account) Resource() *hal.Resource {
 	r := hal.NewResource(a, a.LinkSelf())
 	r.AddLink("transactions", hal.NewLink(strings.Replace(URLAccountTransactions, "{id}", address, -1)+"{?cursor,limit,order}", hal.LinkAttr{"templated": true}))
 	r.AddLink("operations", hal.NewLink(strings.Replace(URLAccountOperations, "{id}", accountID, -1)+"{?cursor,limit,order}", hal.LinkAttr{"templated": true}))
+	r.AddLink("data", hal.NewLink(strings.Replace(URLAccountData, "{id}", accountID, -1), hal.LinkAttr{"templated": true}))
 	return r
 }
 
@@ -42,3 +49,37 @@ func (a Account) LinkSelf() string {
 	address := a.ba.Address
 	return strings.Replace(URLAccounts, "{id}", address, -1)
 }
+
+// AccountData is the data entry of account; `value` is encoded in base64 like
+// `data` of `Account`.
+type AccountData struct {
+	address string
+	key     string
+	value   []byte
+}
+
+func NewAccountData(address, key string, value []byte) *AccountData {
+	return &AccountData{
+		address: address,
+		key:     key,
+		value:   value,
+	}
+}
+
+func (a AccountData) GetMap() hal.Entry {
+	return hal.Entry{
+		"address": a.address,
+		"key":     a.key,
+		"value":   a.value,
+	}
+}
+
+func (a AccountData) Resource() *hal.Resource {
+	r := hal.NewResource(a, a.LinkSelf())
+	r.AddLink("account", hal.NewLink(strings.Replace(URLAccounts, "{id}", a.address, -1)))
+	return r
+}
+
+func (a AccountData) LinkSelf() string {
+	return strings.NewReplacer("{id}", a.address, "{key}", a.key).Replace(URLAccountData)
+}
@@ -0,0 +1,37 @@
+package resource
+
+import (
+	"boscoin.io/sebak/lib/ballot"
+	"github.com/nvellon/hal"
+)
+
+type ConsensusState struct {
+	height      uint64
+	round       uint64
+	ballotState ballot.State
+}
+
+func NewConsensusState(height, round uint64, ballotState ballot.State) *ConsensusState {
+	return &ConsensusState{
+		height:      height,
+		round:       round,
+		ballotState: ballotState,
+	}
+}
+
+func (cs ConsensusState) GetMap() hal.Entry {
+	return hal.Entry{
+		"height":       cs.height,
+		"round":        cs.round,
+		"ballot_state": cs.ballotState.String(),
+	}
+}
+
+func (cs ConsensusState) Resource() *hal.Resource {
+	r := hal.NewResource(cs, cs.LinkSelf())
+	return r
+}
+
+func (cs ConsensusState) LinkSelf() string {
+	return URLNodeInfo
+}
@@ -8,12 +8,16 @@ const (
 	URLAccountTransactions   = APIPrefix + APIVersionV1 + "/accounts/{id}/transactions"
 	URLAccountOperations     = APIPrefix + APIVersionV1 + "/accounts/{id}/operations"
 	URLAccountFrozenAccounts = APIPrefix + APIVersionV1 + "/accounts/{id}/frozen-accounts"
+	URLAccountData           = APIPrefix + APIVersionV1 + "/accounts/{id}/data/{key}"
 	URLFrozenAccounts        = APIPrefix + APIVersionV1 + "/frozen-accounts"
 	URLTransactions          = APIPrefix + APIVersionV1 + "/transactions"
+	URLTransactionsBatch     = APIPrefix + APIVersionV1 + "/transactions/batch"
 	URLTransactionByHash     = APIPrefix + APIVersionV1 + "/transactions/{id}"
 	URLTransactionOperations = APIPrefix + APIVersionV1 + "/transactions/{id}/operations"
 	URLTransactionOperation  = APIPrefix + APIVersionV1 + "/transactions/{id}/operations/{opindex}"
 	URLTransactionStatus     = APIPrefix + APIVersionV1 + "/transactions/{id}/status"
 	URLOperations            = APIPrefix + APIVersionV1 + "/operations/{id}"
 	URLBlocks                = APIPrefix + APIVersionV1 + "/blocks/{id}"
+	URLLedger                = APIPrefix + APIVersionV1 + "/ledger"
+	URLNodeInfo              = "/"
 )
@@ -0,0 +1,39 @@
+package resource
+
+import (
+	"strconv"
+
+	"boscoin.io/sebak/lib/block"
+	"github.com/nvellon/hal"
+)
+
+type Ledger struct {
+	l block.Ledger
+}
+
+func NewLedger(l block.Ledger) *Ledger {
+	return &Ledger{l: l}
+}
+
+func (l Ledger) GetMap() hal.Entry {
+	return hal.Entry{
+		"height":          l.l.Height,
+		"block":           l.l.Block,
+		"total_supply":    strconv.FormatUint(l.l.TotalSupply, 10),
+		"frozen_amount":   strconv.FormatUint(l.l.FrozenAmount, 10),
+		"accounts":        l.l.Accounts,
+		"frozen_accounts": l.l.FrozenAccounts,
+		"inflation":       strconv.FormatUint(l.l.Inflation, 10),
+		"fees_collected":  strconv.FormatUint(l.l.FeesCollected, 10),
+		"since":           l.l.Since,
+	}
+}
+
+func (l Ledger) Resource() *hal.Resource {
+	r := hal.NewResource(l, l.LinkSelf())
+	return r
+}
+
+func (l Ledger) LinkSelf() string {
+	return URLLedger
+}
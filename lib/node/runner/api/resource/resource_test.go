@@ -25,6 +25,7 @@ func TestResourceAccount(t *testing.T) {
 	{
 		ba := block.TestMakeBlockAccount()
 		ba.SequenceID = 123
+		ba.ManageData("home_domain", []byte("boscoin.io"))
 		ba.MustSave(storage)
 
 		ra := NewAccount(ba)
@@ -38,9 +39,11 @@ func TestResourceAccount(t *testing.T) {
 			require.Equal(t, ba.Address, m["address"])
 			require.Equal(t, ba.SequenceID, uint64(m["sequence_id"].(float64)))
 			require.Equal(t, ba.GetBalance().String(), m["balance"])
+			require.Equal(t, map[string]interface{}{"home_domain": "Ym9zY29pbi5pbw=="}, m["data"])
 
 			l := m["_links"].(map[string]interface{})
 			require.Equal(t, strings.Replace(URLAccounts, "{id}", ba.Address, -1), l["self"].(map[string]interface{})["href"])
+			require.Equal(t, strings.Replace(URLAccountData, "{id}", ba.Address, -1), l["data"].(map[string]interface{})["href"])
 		}
 	}
 
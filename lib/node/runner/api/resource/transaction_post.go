@@ -3,6 +3,7 @@ package resource
 import (
 	"strings"
 
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/transaction"
 	"github.com/nvellon/hal"
 )
@@ -35,3 +36,33 @@ func (t TransactionPost) Resource() *hal.Resource {
 func (t TransactionPost) LinkSelf() string {
 	return strings.Replace(URLTransactions, "{id}", t.tx.H.Hash, -1)
 }
+
+// TransactionRejected is the result of the transaction in the batch, which is
+// not accepted; the hash is empty when the transaction can not be parsed.
+type TransactionRejected struct {
+	hash string
+	err  *errors.Error
+}
+
+func NewTransactionRejected(hash string, err *errors.Error) *TransactionRejected {
+	return &TransactionRejected{hash: hash, err: err}
+}
+
+func (t TransactionRejected) GetMap() hal.Entry {
+	return hal.Entry{
+		"hash":   t.hash,
+		"status": "rejected",
+		"error":  t.err,
+	}
+}
+
+func (t TransactionRejected) Resource() *hal.Resource {
+	return hal.NewResource(t, t.LinkSelf())
+}
+
+func (t TransactionRejected) LinkSelf() string {
+	if len(t.hash) < 1 {
+		return ""
+	}
+	return strings.Replace(URLTransactionByHash, "{id}", t.hash, -1)
+}
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"strings"
 
 	"github.com/GianlucaGuarini/go-observable"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network/httputils"
@@ -19,6 +21,14 @@ import (
 // DefaultContentType is "application/json"
 const DefaultContentType = "application/json"
 
+// StreamReplayMaxBlocks is the maximum number of the blocks, whose events are
+// replayed to resume the event stream.
+const StreamReplayMaxBlocks = 1000
+
+// PostSubscribeHandler streams the events of the conditions. The first message
+// is the cursor of the stream and every event has `event_id`; with
+// `Last-Event-ID` header or `cursor` query, the events after it are replayed
+// from storage before the new events.
 func (api NetworkHandlerAPI) PostSubscribeHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -46,31 +56,136 @@ func (api NetworkHandlerAPI) PostSubscribeHandler(w http.ResponseWriter, r *http
 		if len(args) <= 1 {
 			return nil, fmt.Errorf("render: value is empty") //TODO(anarcher): Error type
 		}
-		i := args[1]
+		return api.renderResource(args[1])
+	}
 
-		if i == nil {
-			return []byte{}, nil
+	cursor, resume, err := streamCursor(r)
+	if err != nil {
+		httputils.WriteJSONError(w, errors.BadRequestParameter)
+		return
+	}
+	if resume {
+		if err = api.checkReplay(cursor); err != nil {
+			httputils.WriteJSONError(w, err)
+			return
 		}
+	} else {
+		// the new stream starts after the events of the latest block
+		cursor = observer.EventID{Height: block.GetLatestBlock(api.storage).Height, Index: math.MaxUint64}
+	}
+
+	// the cursor is sent first, so the client can resume the stream even
+	// before the first event; replay until the latest block, start to observe
+	// and replay again the blocks stored in the meantime; the duplicated
+	// events are skipped by `observer.EventID`.
+	es := NewEventStream(w, r, renderFunc, DefaultContentType)
+	es.RenderCursor(cursor)
+	if err = api.replayEvents(es, events); err != nil {
+		es.RenderError(err)
+		return
+	}
+	run := es.Start(observer.ResourceObserver, events...)
+	if err = api.replayEvents(es, events); err != nil {
+		es.Stop()
+		es.RenderError(err)
+		return
+	}
+	run()
+}
+
+// streamCursor returns the `observer.EventID` to resume the stream from
+// `Last-Event-ID` header or `cursor` query.
+func streamCursor(r *http.Request) (id observer.EventID, resume bool, err error) {
+	s := r.Header.Get("Last-Event-ID")
+	if len(s) < 1 {
+		s = r.URL.Query().Get("cursor")
+	}
+	if len(s) < 1 {
+		return
+	}
+
+	if id, err = observer.ParseEventID(s); err != nil {
+		return
+	}
+	resume = true
+
+	return
+}
 
-		switch v := i.(type) {
-		case *block.BlockAccount:
-			r := resource.NewAccount(v)
-			return json.Marshal(r.Resource())
-		case *block.BlockTransaction:
-			tp, err := block.GetTransactionPool(api.storage, v.Hash)
-			if err != nil {
-				return nil, err
+func (api NetworkHandlerAPI) checkReplay(cursor observer.EventID) error {
+	latest := block.GetLatestBlock(api.storage)
+	if latest.Height > cursor.Height && latest.Height-cursor.Height > StreamReplayMaxBlocks {
+		return errors.StreamCursorTooOld
+	}
+
+	pruned, err := block.GetPrunedBlockHeight(api.storage)
+	if err != nil {
+		return err
+	}
+	if pruned > 0 && cursor.Height <= pruned {
+		return errors.Wrapf(errors.DataPruned, "blocks are pruned until %d", pruned)
+	}
+
+	return nil
+}
+
+// replayEvents renders the stored events after the last event of the stream.
+func (api NetworkHandlerAPI) replayEvents(es *EventStream, events []string) error {
+	subscribed := map[string]bool{}
+	for _, event := range events {
+		subscribed[event] = true
+	}
+
+	from := es.LastEventID().Height
+	if from < common.GenesisBlockHeight {
+		from = common.GenesisBlockHeight
+	}
+
+	latest := block.GetLatestBlock(api.storage)
+	for height := from; height <= latest.Height; height++ {
+		blk, err := block.GetBlockByHeight(api.storage, height)
+		if err != nil {
+			return err
+		}
+
+		err = blockEvents(api.storage, blk, func(event string, v interface{}, id observer.EventID) {
+			if subscribed[event] {
+				es.RenderEvent(v, id)
 			}
-			r := resource.NewTransaction(v, tp.Transaction())
-			return json.Marshal(r.Resource())
+		})
+		if err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
 
-		return json.Marshal(i)
+// renderResource renders the triggered value of `observer.ResourceObserver`.
+func (api NetworkHandlerAPI) renderResource(i interface{}) ([]byte, error) {
+	if i == nil {
+		return []byte{}, nil
 	}
 
-	es := NewEventStream(w, r, renderFunc, DefaultContentType)
-	es.Render(nil)
-	es.Run(observer.ResourceObserver, events...)
+	switch v := i.(type) {
+	case *block.BlockAccount:
+		r := resource.NewAccount(v)
+		return json.Marshal(r.Resource())
+	case *block.BlockTransaction:
+		tp, err := block.GetTransactionPool(api.storage, v.Hash)
+		if err != nil {
+			return nil, err
+		}
+		r := resource.NewTransaction(v, tp.Transaction())
+		return json.Marshal(r.Resource())
+	case *block.Block:
+		r := resource.NewBlock(v)
+		return json.Marshal(r.Resource())
+	case resource.Resource:
+		return json.Marshal(v.Resource())
+	}
+
+	return json.Marshal(i)
 }
 
 // EventStream handles chunked responses of a observable trigger
@@ -85,6 +200,13 @@ type EventStream struct {
 	err         error
 	rendered    bool
 	stop        chan struct{}
+	off         func()
+	lastID      observer.EventID
+}
+
+type streamEvent struct {
+	payload []byte
+	id      observer.EventID
 }
 
 type RenderFunc func(args ...interface{}) ([]byte, error)
@@ -155,6 +277,83 @@ func (s *EventStream) Render(args ...interface{}) {
 	s.flusher.Flush()
 }
 
+// RenderEvent renders the event of `observer.ResourceObserver` with it's
+// `observer.EventID`; the event, which is not after the last event, is
+// skipped.
+func (s *EventStream) RenderEvent(v interface{}, id observer.EventID) {
+	if s.err != nil || !id.After(s.lastID) {
+		return
+	}
+
+	payload, err := s.renderFunc("", v)
+	if err != nil {
+		payload = s.errMessage(err)
+	}
+	s.write(streamEvent{payload: payload, id: id})
+}
+
+// RenderCursor renders the `observer.EventID`, after which the stream starts,
+// as `{"event_id": <id>}`.
+func (s *EventStream) RenderCursor(id observer.EventID) {
+	if s.err != nil {
+		return
+	}
+
+	if !s.rendered {
+		s.writer.Header().Set("Content-Type", s.contentType)
+		s.rendered = true
+	}
+
+	s.lastID = id
+	fmt.Fprintf(s.writer, "%s\n", withEventID([]byte("{}"), id))
+	s.flusher.Flush()
+}
+
+// SetLastEventID sets the last event, which the client already received.
+func (s *EventStream) SetLastEventID(id observer.EventID) {
+	s.lastID = id
+}
+
+// RenderError renders the error message.
+func (s *EventStream) RenderError(err error) {
+	if s.err != nil {
+		return
+	}
+	s.write(streamEvent{payload: s.errMessage(err)})
+}
+
+// LastEventID returns the `observer.EventID` of the last rendered event.
+func (s *EventStream) LastEventID() observer.EventID {
+	return s.lastID
+}
+
+func (s *EventStream) write(e streamEvent) {
+	payload := e.payload
+	if !e.id.IsEmpty() {
+		if !e.id.After(s.lastID) {
+			return
+		}
+		s.lastID = e.id
+		payload = withEventID(payload, e.id)
+	}
+
+	fmt.Fprintf(s.writer, "%s\n", payload)
+	s.flusher.Flush()
+}
+
+// withEventID adds `event_id` to the rendered JSON object.
+func withEventID(payload []byte, id observer.EventID) []byte {
+	if len(payload) < 2 || payload[0] != '{' {
+		return payload
+	}
+
+	b := []byte(fmt.Sprintf(`{"event_id":"%s"`, id))
+	if payload[1] != '}' {
+		b = append(b, ',')
+	}
+	return append(b, payload[1:]...)
+}
+
 // Run start observing events.
 //
 // Simple use case:
@@ -177,15 +376,24 @@ func (s *EventStream) Start(ob *observable.Observable, events ...string) func()
 	}
 
 	event := strings.Join(events, " ")
-	msg := make(chan []byte)
+	msg := make(chan streamEvent)
 	s.stop = make(chan struct{})
 
 	onFunc := func(args ...interface{}) {
 		var (
 			payload []byte
 			err     error
+			id      observer.EventID
 		)
 
+		// the events of `observer.ResourceObserver` end with `observer.EventID`
+		if n := len(args); n > 0 {
+			if i, ok := args[n-1].(observer.EventID); ok {
+				id = i
+				args = args[:n-1]
+			}
+		}
+
 		if len(args) > 1 {
 			payload, err = s.renderFunc(args...)
 		} else {
@@ -196,24 +404,26 @@ func (s *EventStream) Start(ob *observable.Observable, events ...string) func()
 		}
 
 		if err != nil {
-			msg <- s.errMessage(err)
+			payload = s.errMessage(err)
 		}
 		select {
-		case msg <- payload:
+		case msg <- streamEvent{payload: payload, id: id}:
 		case <-s.stop:
 			return
 		}
 	}
 	ob.On(event, onFunc)
+	s.off = func() {
+		ob.Off(event, onFunc)
+	}
 
 	return func() {
 		defer ob.Off(event, onFunc)
 
 		for {
 			select {
-			case payload := <-msg:
-				fmt.Fprintf(s.writer, "%s\n", payload)
-				s.flusher.Flush()
+			case e := <-msg:
+				s.write(e)
 			case <-s.request.Context().Done():
 				close(s.stop)
 				return
@@ -221,8 +431,14 @@ func (s *EventStream) Start(ob *observable.Observable, events ...string) func()
 		}
 	}
 }
+
+// Stop stops observing events, which was started by Start, instead of running
+// it.
 func (s *EventStream) Stop() {
 	close(s.stop)
+	if s.off != nil {
+		s.off()
+	}
 }
 
 func (s *EventStream) errMessage(err error) []byte {
@@ -2,14 +2,23 @@ package api
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"testing"
 
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
 	"github.com/GianlucaGuarini/go-observable"
 	"github.com/stretchr/testify/require"
 )
@@ -136,3 +145,217 @@ func TestAPIStreamRun(t *testing.T) {
 		})
 	}
 }
+
+func TestPostSubscribeReplay(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	source := keypair.Random()
+	target := keypair.Random()
+	require.NoError(t, block.NewBlockAccount(source.Address(), common.BaseReserve).Save(st))
+	require.NoError(t, block.NewBlockAccount(target.Address(), common.BaseReserve).Save(st))
+
+	prepareTxsWithKeyPair(st, source, target, 2)
+	first := block.GetLatestBlock(st)
+	prepareTxsWithKeyPair(st, source, target, 2)
+	second := block.GetLatestBlock(st)
+
+	conditions := []observer.Conditions{{observer.NewCondition(observer.Tx, observer.Source, source.Address())}}
+	body := common.MustMarshalJSON(conditions)
+
+	subscribe := func(lastEventID string) *http.Response {
+		req, err := http.NewRequest("POST", ts.URL+PostSubscribePattern, bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Last-Event-ID", lastEventID)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	{ // wrong cursor
+		resp := subscribe("1")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp := subscribe(observer.EventID{Height: first.Height}.String())
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reader := bufio.NewReader(resp.Body)
+	readEventID := func() string {
+		for {
+			line, err := reader.ReadBytes('\n')
+			require.NoError(t, err)
+			if len(bytes.TrimSpace(line)) < 1 {
+				continue
+			}
+
+			var tx map[string]interface{}
+			require.NoError(t, json.Unmarshal(line, &tx))
+			return tx["event_id"].(string)
+		}
+	}
+
+	// the cursor is sent first and the first event of the cursor is skipped
+	require.Equal(t, fmt.Sprintf("%d-0", first.Height), readEventID())
+	require.Equal(t, fmt.Sprintf("%d-1", first.Height), readEventID())
+	require.Equal(t, fmt.Sprintf("%d-0", second.Height), readEventID())
+	require.Equal(t, fmt.Sprintf("%d-1", second.Height), readEventID())
+
+	// live events after replay; the block may be also replayed, but the
+	// events are not duplicated
+	prepareTxsWithKeyPair(st, source, target, 2)
+	third := block.GetLatestBlock(st)
+	TriggerEvent(st, third)
+	TriggerEvent(st, third)
+
+	require.Equal(t, fmt.Sprintf("%d-0", third.Height), readEventID())
+	require.Equal(t, fmt.Sprintf("%d-1", third.Height), readEventID())
+
+	prepareTxsWithKeyPair(st, source, target, 1)
+	fourth := block.GetLatestBlock(st)
+	TriggerEvent(st, fourth)
+	require.Equal(t, fmt.Sprintf("%d-0", fourth.Height), readEventID())
+}
+
+func TestPostSubscribeCursor(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	source := keypair.Random()
+	target := keypair.Random()
+	require.NoError(t, block.NewBlockAccount(source.Address(), common.BaseReserve).Save(st))
+	require.NoError(t, block.NewBlockAccount(target.Address(), common.BaseReserve).Save(st))
+
+	prepareTxsWithKeyPair(st, source, target, 2)
+	latest := block.GetLatestBlock(st)
+
+	conditions := []observer.Conditions{{observer.NewCondition(observer.Tx, observer.Source, source.Address())}}
+	req, err := http.NewRequest("POST", ts.URL+PostSubscribePattern, bytes.NewReader(common.MustMarshalJSON(conditions)))
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := ts.Client().Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	readLine := func() map[string]interface{} {
+		line, err := reader.ReadBytes('\n')
+		require.NoError(t, err)
+
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(line, &m))
+		return m
+	}
+
+	// the new stream starts after the latest block
+	cursor := readLine()
+	require.Equal(t, 1, len(cursor))
+	require.Equal(
+		t,
+		observer.EventID{Height: latest.Height, Index: math.MaxUint64}.String(),
+		cursor["event_id"],
+	)
+
+	prepareTxsWithKeyPair(st, source, target, 1)
+	next := block.GetLatestBlock(st)
+	TriggerEvent(st, next)
+	require.Equal(t, fmt.Sprintf("%d-0", next.Height), readLine()["event_id"])
+}
+
+func TestEventQueue(t *testing.T) {
+	q := NewEventQueue()
+	defer q.Stop()
+
+	var triggered []int
+	done := make(chan struct{})
+	for i := 0; i < 100; i++ {
+		i := i
+		q.Push(func() {
+			triggered = append(triggered, i)
+			if i == 99 {
+				close(done)
+			}
+		})
+	}
+
+	go q.Start()
+	<-done
+
+	for i, v := range triggered {
+		require.Equal(t, i, v)
+	}
+	require.Equal(t, 100, len(triggered))
+}
+
+func TestBlockEvents(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	source := keypair.Random()
+	frozen := keypair.Random()
+	require.NoError(t, block.NewBlockAccount(source.Address(), common.BaseReserve).Save(st))
+	require.NoError(t, block.NewBlockAccountLinked(frozen.Address(), common.BaseReserve, source.Address()).Save(st))
+
+	op, err := operation.NewOperation(operation.NewCreateAccount(frozen.Address(), common.BaseReserve, source.Address()))
+	require.NoError(t, err)
+	tx, err := transaction.NewTransaction(source.Address(), 0, op)
+	require.NoError(t, err)
+	tx.Sign(source, networkID)
+
+	blk := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(st), []string{tx.GetHash()})
+	blk.MustSave(st)
+	bt := block.NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+	bt.MustSave(st)
+	block.SaveTransactionPool(st, tx)
+
+	var events []string
+	var ids []string
+	var fa *resource.FrozenAccount
+	err = blockEvents(st, blk, func(event string, v interface{}, id observer.EventID) {
+		events = append(events, event)
+		ids = append(ids, id.String())
+		if f, ok := v.(*resource.FrozenAccount); ok {
+			fa = f
+		}
+	})
+	require.NoError(t, err)
+
+	accounts := []string{source.Address(), frozen.Address()}
+	sort.Strings(accounts)
+
+	id := func(index int) string {
+		return observer.EventID{Height: blk.Height, Index: uint64(index)}.String()
+	}
+	require.Equal(t, []string{
+		"tx-*",
+		"tx-source=" + source.Address(),
+		"tx-identifier=" + tx.GetHash(),
+		"tx-target=" + frozen.Address(),
+		"acc-*",
+		"acc-identifier=" + accounts[0],
+		"acc-*",
+		"acc-identifier=" + accounts[1],
+		"frozen-account-*",
+		"frozen-account-identifier=" + frozen.Address(),
+		"frozen-account-linked=" + source.Address(),
+		"block-*",
+		"block-identifier=" + blk.Hash,
+		"block-proposer=" + blk.Proposer,
+	}, events)
+	require.Equal(t, []string{
+		id(0), id(0), id(0), id(0),
+		id(1), id(1),
+		id(2), id(2),
+		id(3), id(3), id(3),
+		id(4), id(4), id(4),
+	}, ids)
+
+	require.NotNil(t, fa)
+	require.Equal(t, resource.FrozenState, fa.GetMap()["state"])
+	require.Equal(t, blk.Height, fa.GetMap()["create_block_height"])
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+)
+
+// SyncStatusTimeout is the timeout to get the status from syncer.
+var SyncStatusTimeout = 3 * time.Second
+
+// GetSyncHandler shows the progress of sync; how far behind the node is and
+// how long it will take.
+func (api NetworkHandlerAPI) GetSyncHandler(w http.ResponseWriter, r *http.Request) {
+	if api.GetSyncStatus == nil {
+		httputils.WriteJSONError(w, errors.NotImplemented)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), SyncStatusTimeout)
+	defer cancel()
+
+	status, err := api.GetSyncStatus(ctx)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	httputils.MustWriteJSON(w, 200, status)
+}
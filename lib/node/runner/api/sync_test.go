@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/node"
+)
+
+func TestGetSyncHandler(t *testing.T) {
+	apiHandler := NetworkHandlerAPI{}
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		apiHandler.GetSyncHandler(w, httptest.NewRequest("GET", GetSyncHandlerPattern, nil))
+		return w
+	}
+
+	// syncer is not set
+	require.Equal(t, http.StatusNotImplemented, get().Code)
+
+	expected := &node.NodeSyncInfo{
+		Syncing:         true,
+		StartHeight:     2,
+		TargetHeight:    100,
+		CurrentHeight:   50,
+		BlocksPerSecond: 10,
+		ETA:             5 * time.Second,
+		Peers:           []node.NodeSyncPeer{{Address: "a", Blocks: 49, Throughput: 10}},
+		Errors:          []node.NodeSyncError{{Component: "fetcher", Error: "failed", Time: time.Now().UTC()}},
+	}
+	apiHandler.GetSyncStatus = func(context.Context) (*node.NodeSyncInfo, error) {
+		return expected, nil
+	}
+
+	w := get()
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status node.NodeSyncInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, *expected, status)
+
+	// syncer is not responding
+	apiHandler.GetSyncStatus = func(ctx context.Context) (*node.NodeSyncInfo, error) {
+		return nil, context.DeadlineExceeded
+	}
+	require.Equal(t, http.StatusInternalServerError, get().Code)
+}
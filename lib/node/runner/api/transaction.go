@@ -9,7 +9,6 @@ import (
 
 	"boscoin.io/sebak/lib/block"
 	o "boscoin.io/sebak/lib/common/observer"
-	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network/httputils"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
 )
@@ -58,7 +57,7 @@ func (api NetworkHandlerAPI) GetTransactionByHashHandler(w http.ResponseWriter,
 		return
 	}
 	if !found {
-		httputils.WriteJSONError(w, errors.BlockTransactionDoesNotExists)
+		httputils.WriteJSONError(w, api.blockTransactionNotFound(key))
 		return
 	}
 	bt, err := block.GetBlockTransaction(api.storage, key)
@@ -86,20 +85,52 @@ func (api NetworkHandlerAPI) GetTransactionsByAccountHandler(w http.ResponseWrit
 		return
 	}
 
-	var options = p.ListOptions()
+	f, err := NewFilterQuery(r)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+
+	options, empty, err := f.ListOptions(api.storage, p)
+	if err != nil {
+		httputils.WriteJSONError(w, err)
+		return
+	}
+	if empty {
+		httputils.MustWriteJSON(w, 200, p.ResourceList(nil, nil, nil))
+		return
+	}
+
+	// the most selective index is used and the others are filtered.
+	var iterFunc func() (block.BlockTransaction, bool, []byte)
+	var closeFunc func()
+	switch {
+	case len(f.Counterparty) > 0:
+		iterFunc, closeFunc = block.GetBlockTransactionsByCounterparty(api.storage, address, f.Counterparty, options)
+	case f.Direction == DirectionSent:
+		iterFunc, closeFunc = block.GetBlockTransactionsBySource(api.storage, address, options)
+	case f.Direction == DirectionReceived:
+		iterFunc, closeFunc = block.GetBlockTransactionsByTarget(api.storage, address, options)
+	default:
+		iterFunc, closeFunc = block.GetBlockTransactionsByAccount(api.storage, address, options)
+	}
+
 	var firstCursor []byte
 	var cursor []byte
 	var txs []resource.Resource
-	iterFunc, closeFunc := block.GetBlockTransactionsByAccount(api.storage, address, options)
-	for {
+	for scanned := 0; scanned < MaxFilterScan && (p.Limit() < 1 || uint64(len(txs)) < p.Limit()); scanned++ {
 		t, hasNext, c := iterFunc()
 		if !hasNext {
 			break
 		}
+		// the cursor moves over the filtered out records too
 		cursor = append([]byte{}, c...)
 		if len(firstCursor) == 0 {
 			firstCursor = append(firstCursor, c...)
 		}
+		if !f.MatchDirection(address, t.Source) || !f.MatchAmount(t.Amount) {
+			continue
+		}
 		tp, err := block.GetTransactionPool(api.storage, t.Hash)
 		if err != nil {
 			httputils.WriteJSONError(w, err)
@@ -122,6 +153,8 @@ func (api NetworkHandlerAPI) GetTransactionStatusByHashHandler(w http.ResponseWr
 	}
 	if found, _ := block.ExistsBlockTransaction(api.storage, key); found {
 		status = "confirmed"
+	} else if pruned, _ := block.IsPrunedBlockTransaction(api.storage, key); pruned {
+		status = "confirmed"
 	}
 
 	payload := resource.NewTransactionStatus(key, status)
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/network/httputils"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
 	"boscoin.io/sebak/lib/transaction"
@@ -56,3 +58,81 @@ func (api NetworkHandlerAPI) PostTransactionsHandler(
 		httputils.WriteJSONError(w, err)
 	}
 }
+
+const (
+	// MaxTransactionsInBatch is the maximum number of the transactions in the
+	// request of `PostTransactionsBatchHandler`.
+	MaxTransactionsInBatch = 1000
+
+	// MaxTransactionsBatchBodySize is the maximum size of the request body of
+	// `PostTransactionsBatchHandler`.
+	MaxTransactionsBatchBodySize = 4 * 1024 * 1024
+)
+
+// PostTransactionsBatchHandler receives the array of the transactions; each
+// transaction is checked by the checker functions like
+// `PostTransactionsHandler`. The result of each transaction is in the same
+// order as the request, so the rejected transaction does not fail the others.
+// Every transaction is charged to the rate limit of the request.
+func (api NetworkHandlerAPI) PostTransactionsBatchHandler(
+	w http.ResponseWriter,
+	r *http.Request,
+	handler func([]byte, []common.CheckerFunc) (transaction.Transaction, error),
+	funcs []common.CheckerFunc,
+) {
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxTransactionsBatchBodySize))
+	if err != nil {
+		httputils.WriteJSONError(w, errors.BadRequestParameter.Clone().SetData("error", err.Error()))
+		return
+	}
+
+	var items []json.RawMessage
+	if err = json.Unmarshal(body, &items); err != nil {
+		httputils.WriteJSONError(w, errors.BadRequestParameter.Clone().SetData("error", err.Error()))
+		return
+	}
+	if len(items) < 1 {
+		httputils.WriteJSONError(w, errors.BadRequestParameter.Clone().SetData("error", "empty transactions"))
+		return
+	}
+	if len(items) > MaxTransactionsInBatch {
+		httputils.WriteJSONError(w, errors.TransactionsInBatchLimitExceeded.Clone().SetData("max", MaxTransactionsInBatch))
+		return
+	}
+
+	// the request itself is already charged
+	if ok, err := network.ChargeRateLimit(r, len(items)-1); err != nil {
+		httputils.WriteJSONError(w, errors.HTTPServerError.Clone().SetData("error", err.Error()))
+		return
+	} else if !ok {
+		httputils.WriteJSONError(w, errors.TooManyRequests)
+		return
+	}
+
+	var rs []resource.Resource
+	for _, item := range items {
+		tx, err := handler(item, funcs)
+		if err == nil {
+			rs = append(rs, resource.NewTransactionPost(tx))
+			continue
+		}
+
+		e, ok := err.(*errors.Error)
+		if !ok {
+			e = errors.HTTPProblem.Clone().SetData("error", err.Error())
+		}
+
+		var hash string
+		if json.Unmarshal(item, &tx) == nil && len(tx.B.Source) > 0 {
+			hash = tx.B.MakeHashString()
+		}
+		rs = append(rs, resource.NewTransactionRejected(hash, e))
+	}
+
+	list := resource.NewResourceList(rs, resource.URLTransactionsBatch, "", "")
+	if err = httputils.WriteJSON(w, 200, list); err != nil {
+		httputils.WriteJSONError(w, err)
+	}
+}
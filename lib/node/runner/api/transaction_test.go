@@ -3,15 +3,22 @@ package api
 import (
 	"boscoin.io/sebak/lib/block"
 	"bufio"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/node/runner/api/resource"
+	"boscoin.io/sebak/lib/storage"
 )
 
 func TestGetTransactionByHashHandler(t *testing.T) {
@@ -262,3 +269,158 @@ func TestGetTransactionsHandlerPage(t *testing.T) {
 		}
 	}
 }
+
+func TestGetPrunedTransactionHandler(t *testing.T) {
+	ts, storage := prepareAPIServer()
+	defer storage.Close()
+	defer ts.Close()
+
+	_, tx, bt := prepareTxWithoutSave(storage)
+	blk := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(storage), []string{tx.GetHash()})
+	blk.MustSave(storage)
+	bt.Block = blk.Hash
+	bt.MustSave(storage)
+	block.SaveTransactionPool(storage, *tx)
+
+	bs, err := storage.OpenBatch()
+	require.NoError(t, err)
+	_, err = block.PruneBlock(bs, blk)
+	require.NoError(t, err)
+	require.NoError(t, bs.Commit())
+
+	{
+		req, _ := http.NewRequest("GET", ts.URL+GetTransactionsHandlerPattern+"/"+bt.Hash, nil)
+		resp, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusGone, resp.StatusCode)
+
+		readByte, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		recv := make(map[string]interface{})
+		common.MustUnmarshalJSON(readByte, &recv)
+		require.Equal(t, http.StatusGone, int(recv["status"].(float64)))
+		require.True(t, strings.HasSuffix(recv["type"].(string), strconv.FormatUint(uint64(errors.DataPruned.Code), 10)))
+	}
+
+	{ // pruned transaction was confirmed
+		respBody := request(ts, strings.Replace(GetTransactionStatusHandlerPattern, "{id}", bt.Hash, -1), false)
+		defer respBody.Close()
+		readByte, err := ioutil.ReadAll(respBody)
+		require.NoError(t, err)
+		var status resource.TransactionStatus
+		common.MustUnmarshalJSON(readByte, &status)
+
+		require.Equal(t, "confirmed", status.Status)
+	}
+}
+
+// prepareFilterTxs makes the transactions between the accounts in the blocks;
+// me sends 2 transactions to a, 1 to b and a sends 1 to me.
+func prepareFilterTxs(t *testing.T, st *storage.LevelDBBackend) (me, a, b *keypair.Full, blks []block.Block, btList []block.BlockTransaction) {
+	me, a, b = keypair.Random(), keypair.Random(), keypair.Random()
+	for _, kp := range []*keypair.Full{me, a, b} {
+		require.NoError(t, block.NewBlockAccount(kp.Address(), common.BaseReserve).Save(st))
+	}
+
+	for _, pair := range [][2]*keypair.Full{{me, a}, {me, b}, {a, me}} {
+		n := 1
+		if pair[1] == a {
+			n = 2
+		}
+		_, _, bts := prepareTxsWithKeyPair(st, pair[0], pair[1], n)
+		blks = append(blks, block.GetLatestBlock(st))
+		btList = append(btList, bts...)
+	}
+
+	return
+}
+
+// getRecords requests the list and returns the hashes of records and the next
+// link.
+func getRecords(t *testing.T, ts *httptest.Server, url string) (status int, hashes []string, next string) {
+	resp, err := ts.Client().Get(ts.URL + url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil, ""
+	}
+
+	recv := map[string]interface{}{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&recv))
+	records, _ := recv["_embedded"].(map[string]interface{})["records"].([]interface{})
+	for _, r := range records {
+		hashes = append(hashes, r.(map[string]interface{})["hash"].(string))
+	}
+	next = recv["_links"].(map[string]interface{})["next"].(map[string]interface{})["href"].(string)
+
+	return resp.StatusCode, hashes, next
+}
+
+func TestGetTransactionsByAccountHandlerWithFilters(t *testing.T) {
+	ts, st := prepareAPIServer()
+	defer st.Close()
+	defer ts.Close()
+
+	me, a, _, blks, btList := prepareFilterTxs(t, st)
+	url := strings.Replace(GetAccountTransactionsHandlerPattern, "{id}", me.Address(), -1)
+
+	hashesOf := func(bts ...block.BlockTransaction) (hashes []string) {
+		for _, bt := range bts {
+			hashes = append(hashes, bt.Hash)
+		}
+		return
+	}
+
+	for query, expected := range map[string][]string{
+		"":                            hashesOf(btList...),
+		"direction=sent":              hashesOf(btList[:3]...),
+		"direction=received":          hashesOf(btList[3]),
+		"counterparty=" + a.Address(): hashesOf(btList[0], btList[1], btList[3]),
+		"counterparty=" + a.Address() + "&direction=sent":                                                                hashesOf(btList[:2]...),
+		"min_height=" + strconv.FormatUint(blks[1].Height, 10):                                                           hashesOf(btList[2:]...),
+		"min_height=" + strconv.FormatUint(blks[1].Height, 10) + "&max_height=" + strconv.FormatUint(blks[1].Height, 10): hashesOf(btList[2]),
+		"since=" + neturl.QueryEscape(blks[2].ProposedTime):                                                              hashesOf(btList[3]),
+		"until=" + neturl.QueryEscape(blks[0].ProposedTime):                                                              hashesOf(btList[:2]...),
+		"min_amount=" + btList[2].Amount.String() + "&max_amount=" + btList[2].Amount.String():                           hashesOf(btList[2]),
+	} {
+		status, hashes, _ := getRecords(t, ts, url+"?"+query)
+		require.Equal(t, http.StatusOK, status, query)
+		require.Equal(t, len(expected), len(hashes), query)
+		require.Subset(t, expected, hashes, query)
+	}
+
+	{ // the filters are kept in the next link
+		_, hashes, next := getRecords(t, ts, url+"?direction=sent&limit=2")
+		require.ElementsMatch(t, hashesOf(btList[:2]...), hashes)
+		require.Contains(t, next, "direction=sent")
+
+		_, hashes, _ = getRecords(t, ts, next)
+		require.Equal(t, hashesOf(btList[2]), hashes)
+	}
+
+	{ // the scan stops at `MaxFilterScan` and the next link continues it
+		defer func(n int) { MaxFilterScan = n }(MaxFilterScan)
+		MaxFilterScan = 2
+
+		query := "?min_amount=" + btList[2].Amount.String() + "&max_amount=" + btList[2].Amount.String()
+		_, hashes, next := getRecords(t, ts, url+query)
+		require.Empty(t, hashes)
+		require.Contains(t, next, "min_amount=")
+
+		_, hashes, _ = getRecords(t, ts, next)
+		require.Equal(t, hashesOf(btList[2]), hashes)
+	}
+
+	{ // empty time range
+		_, hashes, _ := getRecords(t, ts, url+"?until="+neturl.QueryEscape("2000-01-01T00:00:00Z"))
+		require.Empty(t, hashes)
+	}
+
+	for _, query := range []string{"direction=unknown", "min_height=a", "since=yesterday", "max_amount=-1"} {
+		status, _, _ := getRecords(t, ts, url+"?"+query)
+		require.Equal(t, http.StatusBadRequest, status, query)
+	}
+}
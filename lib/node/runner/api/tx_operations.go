@@ -70,6 +70,9 @@ func (api NetworkHandlerAPI) getBlockByTx(hash string) (*block.Block, error) {
 	if found, err := block.ExistsBlockTransaction(api.storage, hash); err != nil {
 		return nil, err
 	} else if !found {
+		if err = api.blockTransactionNotFound(hash); err == errors.DataPruned {
+			return nil, err
+		}
 		return nil, errors.BlockTransactionDoesNotExists.Clone().SetData("status", http.StatusNotFound)
 	}
 
@@ -1 +1,18 @@
 package api
+
+import (
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// blockTransactionNotFound returns `errors.DataPruned` for the pruned
+// `BlockTransaction`.
+func (api NetworkHandlerAPI) blockTransactionNotFound(hash string) error {
+	if pruned, err := block.IsPrunedBlockTransaction(api.storage, hash); err != nil {
+		return err
+	} else if pruned {
+		return errors.DataPruned
+	}
+
+	return errors.BlockTransactionDoesNotExists
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GianlucaGuarini/go-observable"
+	"golang.org/x/net/websocket"
+
+	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+)
+
+const (
+	// WebSocketHeartbeat is the interval of the heartbeat message
+	WebSocketHeartbeat = 30 * time.Second
+	// WebSocketBufferSize is the number of the messages waiting to be sent
+	// to a client
+	WebSocketBufferSize = 100
+	// WebSocketWriteTimeout is how long to wait for a client to receive a
+	// message
+	WebSocketWriteTimeout = 10 * time.Second
+	// WebSocketMaxSubscriptions is the maximum number of the subscriptions
+	// of one connection
+	WebSocketMaxSubscriptions = 100
+
+	// WebSocketOverflowDrop drops the events when the buffer of a slow
+	// client is full; the next event tells the number of the dropped events.
+	WebSocketOverflowDrop = "drop"
+	// WebSocketOverflowClose closes the connection of a slow client.
+	WebSocketOverflowClose = "close"
+)
+
+// SubscribeWebSocketHandler serves the events of `observer.ResourceObserver`
+// through websocket. Unlike `PostSubscribeHandler`, the client can change the
+// subscriptions without reconnecting by sending `observer.MessageSubscribe`
+// and `observer.MessageUnsubscribe`.
+//
+// `overflow` query decides what to do with the slow client; `drop`(default)
+// or `close`.
+func (api NetworkHandlerAPI) SubscribeWebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	overflow := r.URL.Query().Get("overflow")
+	switch overflow {
+	case "":
+		overflow = WebSocketOverflowDrop
+	case WebSocketOverflowDrop, WebSocketOverflowClose:
+	default:
+		httputils.WriteJSONError(w, errors.BadRequestParameter)
+		return
+	}
+
+	server := websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			newWebSocketConn(api, ws, observer.ResourceObserver, overflow).run()
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+type webSocketSubscription struct {
+	event string
+	fn    func(...interface{})
+}
+
+type webSocketConn struct {
+	sync.Mutex
+
+	api       NetworkHandlerAPI
+	ws        *websocket.Conn
+	ob        *observable.Observable
+	overflow  string
+	heartbeat time.Duration
+	out       chan observer.Message
+	closed    chan struct{}
+	closeOnce sync.Once
+	done      bool
+	dropped   uint64
+	subs      map[string][]webSocketSubscription
+}
+
+func newWebSocketConn(api NetworkHandlerAPI, ws *websocket.Conn, ob *observable.Observable, overflow string) *webSocketConn {
+	return &webSocketConn{
+		api:       api,
+		ws:        ws,
+		ob:        ob,
+		overflow:  overflow,
+		heartbeat: WebSocketHeartbeat,
+		out:       make(chan observer.Message, WebSocketBufferSize),
+		closed:    make(chan struct{}),
+		subs:      map[string][]webSocketSubscription{},
+	}
+}
+
+func (c *webSocketConn) run() {
+	// the deadlines of the http server are still left in the hijacked
+	// connection
+	c.ws.SetDeadline(time.Time{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.write()
+	}()
+
+	c.read()
+	c.close()
+	wg.Wait()
+}
+
+func (c *webSocketConn) read() {
+	for {
+		var m observer.Message
+		if err := websocket.JSON.Receive(c.ws, &m); err != nil {
+			switch err.(type) {
+			case *json.SyntaxError, *json.UnmarshalTypeError:
+				c.reply(observer.Message{Type: observer.MessageError, Error: err.Error()})
+				continue
+			}
+			return
+		}
+
+		switch m.Type {
+		case observer.MessageSubscribe:
+			if err := c.subscribe(m.ID, m.Conditions); err != nil {
+				c.reply(observer.Message{Type: observer.MessageError, ID: m.ID, Error: err.Error()})
+				continue
+			}
+			c.reply(observer.Message{Type: observer.MessageSubscribed, ID: m.ID})
+		case observer.MessageUnsubscribe:
+			c.unsubscribe(m.ID)
+			c.reply(observer.Message{Type: observer.MessageUnsubscribed, ID: m.ID})
+		default:
+			c.reply(observer.Message{Type: observer.MessageError, ID: m.ID, Error: "unknown message type"})
+		}
+	}
+}
+
+func (c *webSocketConn) write() {
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var m observer.Message
+		select {
+		case <-c.closed:
+			return
+		case m = <-c.out:
+			if m.Type == observer.MessageEvent {
+				m.Dropped = atomic.SwapUint64(&c.dropped, 0)
+			}
+		case <-ticker.C:
+			m = observer.Message{Type: observer.MessageHeartbeat}
+		}
+
+		c.ws.SetWriteDeadline(time.Now().Add(WebSocketWriteTimeout))
+		if err := websocket.JSON.Send(c.ws, m); err != nil {
+			c.close()
+			return
+		}
+	}
+}
+
+// reply sends the response of the client request; unlike the events, it is
+// not dropped.
+func (c *webSocketConn) reply(m observer.Message) {
+	select {
+	case c.out <- m:
+	case <-c.closed:
+	}
+}
+
+// push sends the event without blocking, because it is called by
+// `observable.Observable.Trigger()`, which holds the lock.
+func (c *webSocketConn) push(m observer.Message) {
+	select {
+	case <-c.closed:
+		return
+	case c.out <- m:
+		return
+	default:
+	}
+
+	if c.overflow == WebSocketOverflowClose {
+		// `close()` calls `observable.Observable.Off()`, which waits for
+		// the lock of the running `Trigger()`
+		go c.close()
+		return
+	}
+	atomic.AddUint64(&c.dropped, 1)
+}
+
+func (c *webSocketConn) subscribe(id string, conditions observer.Conditions) error {
+	if err := conditions.Validate(); err != nil {
+		return err
+	}
+	if len(id) < 1 {
+		id = conditions.String()
+	}
+
+	c.unsubscribe(id)
+
+	c.Lock()
+	defer c.Unlock()
+
+	if c.done {
+		return fmt.Errorf("connection is closed")
+	}
+	if len(c.subs) >= WebSocketMaxSubscriptions {
+		return fmt.Errorf("subscriptions over limit, %d", WebSocketMaxSubscriptions)
+	}
+
+	var subs []webSocketSubscription
+	for _, condition := range conditions {
+		event := condition.String()
+		fn := func(args ...interface{}) {
+			var i interface{}
+			if len(args) > 0 {
+				i = args[0]
+			}
+
+			m := observer.Message{Type: observer.MessageEvent, ID: id, Event: event}
+			if len(args) > 1 {
+				if eventID, ok := args[len(args)-1].(observer.EventID); ok {
+					m.EventID = eventID.String()
+				}
+			}
+			if data, err := c.api.renderResource(i); err != nil {
+				m.Error = err.Error()
+			} else {
+				m.Data = data
+			}
+			c.push(m)
+		}
+		c.ob.On(event, fn)
+		subs = append(subs, webSocketSubscription{event: event, fn: fn})
+	}
+	c.subs[id] = subs
+
+	return nil
+}
+
+func (c *webSocketConn) unsubscribe(id string) {
+	c.Lock()
+	subs := c.subs[id]
+	delete(c.subs, id)
+	c.Unlock()
+
+	for _, s := range subs {
+		c.ob.Off(s.event, s.fn)
+	}
+}
+
+func (c *webSocketConn) close() {
+	c.closeOnce.Do(func() {
+		c.Lock()
+		c.done = true
+		var ids []string
+		for id := range c.subs {
+			ids = append(ids, id)
+		}
+		c.Unlock()
+
+		for _, id := range ids {
+			c.unsubscribe(id)
+		}
+
+		close(c.closed)
+		c.ws.Close()
+	})
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/client"
+	"boscoin.io/sebak/lib/common/observer"
+)
+
+func receiveWebSocket(t *testing.T, ws *websocket.Conn) (m observer.Message) {
+	ws.SetReadDeadline(time.Now().Add(3 * time.Second))
+	require.NoError(t, websocket.JSON.Receive(ws, &m))
+	return
+}
+
+func TestSubscribeWebSocket(t *testing.T) {
+	api := NetworkHandlerAPI{}
+	server := httptest.NewServer(http.HandlerFunc(api.SubscribeWebSocketHandler))
+	defer server.Close()
+
+	location := "ws" + strings.TrimPrefix(server.URL, "http")
+	ws, err := websocket.Dial(location, "", server.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	address := "GDPQ2LBYP3RL3O675H2N5IEYM6PRJNUA5QFMKXIHGTKEB5KS5T3KHFA2"
+	conditions := observer.Conditions{observer.NewCondition(observer.Acc, observer.Identifier, address)}
+
+	{ // wrong conditions
+		require.NoError(t, websocket.JSON.Send(ws, observer.Message{
+			Type:       observer.MessageSubscribe,
+			ID:         "wrong",
+			Conditions: observer.Conditions{observer.NewCondition(observer.Acc, observer.Identifier)},
+		}))
+		m := receiveWebSocket(t, ws)
+		require.Equal(t, observer.MessageError, m.Type)
+		require.Equal(t, "wrong", m.ID)
+	}
+
+	require.NoError(t, websocket.JSON.Send(ws, observer.Message{Type: observer.MessageSubscribe, ID: "a", Conditions: conditions}))
+	m := receiveWebSocket(t, ws)
+	require.Equal(t, observer.MessageSubscribed, m.Type)
+	require.Equal(t, "a", m.ID)
+
+	ba := block.NewBlockAccount(address, 100)
+	observer.ResourceObserver.Trigger(conditions[0].String(), ba)
+
+	m = receiveWebSocket(t, ws)
+	require.Equal(t, observer.MessageEvent, m.Type)
+	require.Equal(t, "a", m.ID)
+	require.Equal(t, conditions[0].String(), m.Event)
+
+	var account map[string]interface{}
+	require.NoError(t, json.Unmarshal(m.Data, &account))
+	require.Equal(t, address, account["address"])
+
+	require.NoError(t, websocket.JSON.Send(ws, observer.Message{Type: observer.MessageUnsubscribe, ID: "a"}))
+	m = receiveWebSocket(t, ws)
+	require.Equal(t, observer.MessageUnsubscribed, m.Type)
+
+	// not subscribed any more
+	observer.ResourceObserver.Trigger(conditions[0].String(), ba)
+	require.NoError(t, websocket.JSON.Send(ws, observer.Message{Type: "unknown"}))
+	m = receiveWebSocket(t, ws)
+	require.Equal(t, observer.MessageError, m.Type)
+}
+
+func TestSubscribeWebSocketOverflow(t *testing.T) {
+	c := newWebSocketConn(NetworkHandlerAPI{}, nil, nil, WebSocketOverflowDrop)
+	for i := 0; i < WebSocketBufferSize+3; i++ {
+		c.push(observer.Message{Type: observer.MessageEvent})
+	}
+	require.Equal(t, WebSocketBufferSize, len(c.out))
+	require.Equal(t, uint64(3), atomic.LoadUint64(&c.dropped))
+}
+
+func TestWebSocketStreamReconnect(t *testing.T) {
+	var requests int32
+	api := NetworkHandlerAPI{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// refuse the first connection
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		api.SubscribeWebSocketHandler(w, r)
+	}))
+	defer server.Close()
+
+	address := "GALQG5SCKCPXUG4ODPMFZJGZ6XBVJTLAJFR7OJKJOJVARA7M4H5SGSOG"
+	condition := observer.NewCondition(observer.Acc, observer.Identifier, address)
+
+	received := make(chan observer.Message, 10)
+	stream := client.MustNewClient(server.URL).NewWebSocketStream(func(m observer.Message) {
+		received <- m
+	})
+	stream.ReconnectMin = 10 * time.Millisecond
+	require.NoError(t, stream.Subscribe("b", observer.Conditions{condition}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go stream.Run(ctx)
+
+	select {
+	case m := <-received:
+		require.Equal(t, observer.MessageSubscribed, m.Type)
+		require.Equal(t, "b", m.ID)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "not subscribed")
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	observer.ResourceObserver.Trigger(condition.String(), block.NewBlockAccount(address, 100))
+	select {
+	case m := <-received:
+		require.Equal(t, observer.MessageEvent, m.Type)
+		require.Equal(t, "b", m.ID)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "event not received")
+	}
+}
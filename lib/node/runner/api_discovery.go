@@ -20,19 +20,24 @@ func (nh NetworkHandlerNode) DiscoveryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	peer := network.PeerFromRequest(r)
+
 	dm, err := network.DiscoveryMessageFromJSON(body)
 	if err != nil {
+		nh.peerScorer.Punish(peer, err)
 		http.Error(w, err.Error(), httputils.StatusCode(err))
 		return
 	}
 
 	if err := dm.IsWellFormed(nh.conf); err != nil {
+		nh.peerScorer.Punish(peer, err)
 		http.Error(w, err.Error(), httputils.StatusCode(err))
 		return
 	}
 
 	if !nh.localNode.HasValidators(dm.B.Address) {
 		err := errors.DiscoveryFromUnknownValidator
+		nh.peerScorer.Punish(peer, err)
 		http.Error(w, err.Error(), httputils.StatusCode(err))
 		return
 	}
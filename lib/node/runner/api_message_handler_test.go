@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 
+	"boscoin.io/sebak/lib/client"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
 	"boscoin.io/sebak/lib/errors"
@@ -200,3 +202,78 @@ func TestNodeMessageHandlerNotWellformedTransaction(t *testing.T) {
 		)
 	}
 }
+
+func TestNodeMessageHandlerTransactionsBatch(t *testing.T) {
+	p := &HelperTestNodeMessageHandler{}
+	p.Prepare()
+	defer p.Done()
+
+	apiHandler := api.NetworkHandlerAPI{}
+	p.router.HandleFunc(
+		client.UrlPrefixForAPIV1+api.PostTransactionsBatchPattern,
+		func(w http.ResponseWriter, r *http.Request) {
+			apiHandler.PostTransactionsBatchHandler(
+				w, r,
+				p.nodeHandler.ReceiveTransaction, HandleTransactionCheckerFuncsWithoutBroadcast,
+			)
+		},
+	).Methods("POST").MatcherFunc(common.PostAndJSONMatcher)
+
+	c := client.MustNewClient(p.server.URL)
+
+	tx := p.makeTransaction()
+	txBody, _ := tx.Serialize()
+
+	invalid := p.makeTransaction()
+	invalid.H.Signature = "findme"
+	invalidBody, _ := invalid.Serialize()
+
+	results, err := c.SubmitTransactions(txBody, txBody, invalidBody, []byte(`{"T": "broken"}`))
+	require.NoError(t, err)
+	require.Equal(t, 4, len(results))
+
+	// accepted
+	require.Equal(t, tx.GetHash(), results[0].Hash)
+	require.Equal(t, "submitted", results[0].Status)
+	require.Nil(t, results[0].Error)
+	require.True(t, p.TransactionPool.Has(tx.GetHash()))
+
+	// known transaction in the same batch
+	require.Equal(t, tx.GetHash(), results[1].Hash)
+	require.Equal(t, "rejected", results[1].Status)
+	require.Equal(t, errors.NewButKnownMessage.Code, results[1].Error.Code)
+
+	// invalid signature
+	require.Equal(t, invalid.GetHash(), results[2].Hash)
+	require.Equal(t, "rejected", results[2].Status)
+	require.Equal(t, errors.InvalidTransaction.Code, results[2].Error.Code)
+	require.False(t, p.TransactionPool.Has(invalid.GetHash()))
+
+	// not transaction
+	require.Equal(t, "", results[3].Hash)
+	require.Equal(t, "rejected", results[3].Status)
+	require.NotNil(t, results[3].Error)
+
+	{ // empty batch
+		_, err := c.SubmitTransactions()
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, err.(client.Error).Problem.Status)
+	}
+
+	{ // too many transactions
+		txs := make([][]byte, api.MaxTransactionsInBatch+1)
+		for i := range txs {
+			txs[i] = txBody
+		}
+		_, err := c.SubmitTransactions(txs...)
+		require.Error(t, err)
+		require.Equal(t, errors.TransactionsInBatchLimitExceeded.Message, err.(client.Error).Problem.Title)
+	}
+
+	{ // too large body
+		large := []byte(`"` + strings.Repeat("a", api.MaxTransactionsBatchBodySize) + `"`)
+		_, err := c.SubmitTransactions(txBody, large)
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, err.(client.Error).Problem.Status)
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
 
+	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/network/httputils"
 	"boscoin.io/sebak/lib/node"
@@ -34,6 +35,8 @@ type NetworkHandlerNode struct {
 	transactionPool *transaction.Pool
 	urlPrefix       string
 	conf            common.Config
+	peerScorer      *network.PeerScorer
+	snapshots       *SnapshotManager
 }
 
 func NewNetworkHandlerNode(localNode *node.LocalNode, network network.Network, storage *storage.LevelDBBackend, consensus *consensus.ISAAC, transactionPool *transaction.Pool, urlPrefix string, conf common.Config) *NetworkHandlerNode {
@@ -48,6 +51,17 @@ func NewNetworkHandlerNode(localNode *node.LocalNode, network network.Network, s
 	}
 }
 
+// SetPeerScorer sets `network.PeerScorer`; the failures of the incoming
+// messages will be scored to the peer.
+func (api *NetworkHandlerNode) SetPeerScorer(scorer *network.PeerScorer) {
+	api.peerScorer = scorer
+}
+
+// SetSnapshots sets `SnapshotManager`, which serves the state snapshots.
+func (api *NetworkHandlerNode) SetSnapshots(snapshots *SnapshotManager) {
+	api.snapshots = snapshots
+}
+
 func (api NetworkHandlerNode) HandlerURLPattern(pattern string) string {
 	return fmt.Sprintf("%s%s", api.urlPrefix, pattern)
 }
@@ -84,7 +98,11 @@ func (api NetworkHandlerNode) ConnectHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	api.network.MessageBroker().Receive(common.NetworkMessage{Type: common.ConnectMessage, Data: body})
+	api.network.MessageBroker().Receive(common.NetworkMessage{
+		Type: common.ConnectMessage,
+		Data: body,
+		Peer: network.PeerFromRequest(r),
+	})
 
 	b, err := NodeInfoWithRequest(api.localNode, r)
 	if err != nil {
@@ -144,13 +162,17 @@ func (api NetworkHandlerNode) ReceiveTransaction(body []byte, funcs []common.Che
 func (api NetworkHandlerNode) MessageHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	body, err := ioutil.ReadAll(r.Body)
+	body, err := readNodeMessage(r, func(b []byte) (common.Message, error) {
+		return transaction.NewTransactionFromRLP(b)
+	})
 	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+		api.peerScorer.Punish(network.PeerFromRequest(r), err)
+		http.Error(w, err.Error(), httputils.StatusCode(err))
 		return
 	}
 
 	if _, err = api.ReceiveTransaction(body, HandleTransactionCheckerFuncsWithoutBroadcast); err != nil {
+		api.peerScorer.Punish(network.PeerFromRequest(r), err)
 		http.Error(w, err.Error(), httputils.StatusCode(err))
 		return
 	}
@@ -159,19 +181,47 @@ func (api NetworkHandlerNode) MessageHandler(w http.ResponseWriter, r *http.Requ
 func (api NetworkHandlerNode) BallotHandler(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
-	if ct := r.Header.Get("Content-Type"); strings.ToLower(ct) != "application/json" {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	body, err := readNodeMessage(r, func(b []byte) (common.Message, error) {
+		return ballot.NewBallotFromRLP(b)
+	})
+	if err != nil {
+		api.peerScorer.Punish(network.PeerFromRequest(r), err)
+		http.Error(w, err.Error(), httputils.StatusCode(err))
 		return
 	}
 
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Error reading request body", http.StatusInternalServerError)
+	api.network.MessageBroker().Receive(common.NetworkMessage{
+		Type: common.BallotMessage,
+		Data: body,
+		Peer: network.PeerFromRequest(r),
+	})
+	api.network.MessageBroker().Response(w, body)
+
+	return
+}
+
+// readNodeMessage reads the message from the request body. The message in
+// `common.ContentTypeRLP` is decoded by `fromRLP` and converted to JSON, so
+// the message broker always gets JSON.
+func readNodeMessage(r *http.Request, fromRLP func([]byte) (common.Message, error)) (body []byte, err error) {
+	ct := common.MediaType(r.Header.Get("Content-Type"))
+	if ct != common.ContentTypeJSON && ct != common.ContentTypeRLP {
+		err = errors.UnsupportedContentType
 		return
 	}
 
-	api.network.MessageBroker().Receive(common.NetworkMessage{Type: common.BallotMessage, Data: body})
-	api.network.MessageBroker().Response(w, body)
+	if body, err = ioutil.ReadAll(r.Body); err != nil {
+		err = errors.HTTPServerError.Clone().SetData("error", err.Error())
+		return
+	}
+
+	if ct == common.ContentTypeRLP {
+		var message common.Message
+		if message, err = fromRLP(body); err != nil {
+			return
+		}
+		body, err = message.Serialize()
+	}
 
 	return
 }
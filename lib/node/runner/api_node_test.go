@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 
+	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/keypair"
@@ -28,6 +30,7 @@ import (
 	"boscoin.io/sebak/lib/node"
 	"boscoin.io/sebak/lib/storage"
 	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
 )
 
 func getPort() string {
@@ -230,3 +233,63 @@ func TestGetNodeInfoHandler(t *testing.T) {
 		require.Equal(t, publishEndpoint.String(), received["endpoint"])
 	}
 }
+
+// TestBallotHandlerRLP checks `BallotHandler` receives the ballot in both JSON
+// and RLP; the message broker always gets JSON.
+func TestBallotHandlerRLP(t *testing.T) {
+	conf := common.NewTestConfig()
+	endpoint := common.MustParseEndpoint("http://localhost:12345")
+	localNode := node.NewTestLocalNode(keypair.Random(), endpoint)
+
+	config, _ := network.NewHTTP2NetworkConfigFromEndpoint(localNode.Alias(), endpoint)
+	nt := network.NewHTTP2Network(config)
+	messageBroker := &TestMessageBroker{network: nt}
+	nt.SetMessageBroker(messageBroker)
+
+	apiHandler := NetworkHandlerNode{network: nt, localNode: localNode, conf: conf}
+
+	basis := voting.Basis{Height: 1, BlockHash: common.MustMakeObjectHashString("block")}
+	b := GenerateEmptyTxBallot(localNode, basis, ballot.StateSIGN, localNode, conf)
+
+	post := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", network.UrlPathPrefixNode+BallotHandlerPattern, bytes.NewBuffer(body))
+		r.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		apiHandler.BallotHandler(w, r)
+		return w
+	}
+
+	jsonBody, _ := b.Serialize()
+	rlpBody, err := b.SerializeRLP()
+	require.NoError(t, err)
+
+	{
+		w := post(common.ContentTypeJSON, jsonBody)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	{
+		w := post(common.ContentTypeRLP, rlpBody)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	require.Equal(t, 2, len(messageBroker.Messages))
+	for _, m := range messageBroker.Messages {
+		received, err := ballot.NewBallotFromJSON(m.Data)
+		require.NoError(t, err)
+		require.Equal(t, b.GetHash(), received.GetHash())
+		require.NoError(t, received.IsWellFormed(conf))
+	}
+
+	{ // unknown content type
+		w := post("text/plain", jsonBody)
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	}
+
+	{ // broken RLP
+		w := post(common.ContentTypeRLP, rlpBody[:len(rlpBody)-1])
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	}
+
+	require.Equal(t, 2, len(messageBroker.Messages))
+}
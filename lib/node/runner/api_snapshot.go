@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"net/http"
+	"strconv"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network/httputils"
+)
+
+const GetSnapshotPattern = "/snapshot"
+
+// GetSnapshotHandler serves the latest state snapshot. With `mode=header`,
+// only the signed `block.SnapshotHeader` is returned; with `height`, the
+// snapshot must be taken at the height.
+func (nh NetworkHandlerNode) GetSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var height uint64
+	if s := r.URL.Query().Get("height"); len(s) > 0 {
+		var err error
+		if height, err = strconv.ParseUint(s, 10, 64); err != nil {
+			httputils.WriteJSONError(w, errors.BadRequestParameter.Clone().SetData("error", err.Error()))
+			return
+		}
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if len(mode) > 0 && mode != "header" {
+		httputils.WriteJSONError(w, errors.BadRequestParameter.Clone().SetData("error", "unknown mode"))
+		return
+	}
+
+	var snapshot *StateSnapshot
+	if nh.snapshots != nil {
+		snapshot = nh.snapshots.Acquire()
+	}
+	if snapshot == nil {
+		httputils.WriteJSONError(w, errors.StateSnapshotNotFound)
+		return
+	}
+	defer snapshot.Done()
+
+	if height > 0 && snapshot.Header.Block.Height != height {
+		httputils.WriteJSONError(w, errors.StateSnapshotNotFound)
+		return
+	}
+
+	if mode == "header" {
+		httputils.MustWriteJSON(w, http.StatusOK, snapshot.Header)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := block.WriteSnapshot(snapshot.Storage(), w, snapshot.Header); err != nil {
+		// the response is already started; the client will find the broken
+		// snapshot by the state root
+		log.Error("failed to write snapshot", "height", snapshot.Header.Block.Height, "error", err)
+	}
+}
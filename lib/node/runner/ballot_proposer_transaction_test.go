@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
@@ -783,7 +784,9 @@ func TestProposedTransactionWithWrongNumberOfOperations(t *testing.T) {
 }
 
 func TestCheckInflationBlockIncrease(t *testing.T) {
-	nodeRunners, _ := createTestNodeRunnersHTTP2NetworkWithReady(1)
+	// the node runner is started after the transit signal is set, otherwise
+	// the first blocks can be made before the states are watched.
+	nodeRunners, _ := createTestNodeRunnersHTTP2Network(1)
 	defer func() {
 		for _, nr := range nodeRunners {
 			nr.Stop()
@@ -809,6 +812,12 @@ func TestCheckInflationBlockIncrease(t *testing.T) {
 	nr.isaacStateManager.SetTransitSignal(func(state consensus.ISAACState) {
 		recv <- state
 	})
+
+	go func() {
+		if err := nr.Start(); err != nil && err != http.ErrServerClosed {
+			panic(err)
+		}
+	}()
 	<-recv // first ballot.StateINIT
 
 	checkInflation := func(previous, inflationAmount common.Amount, blockHeight uint64) common.Amount {
@@ -821,9 +830,11 @@ func TestCheckInflationBlockIncrease(t *testing.T) {
 		)
 		state = <-recv // ballot.StateSIGN
 		require.Equal(t, blockHeight, state.Height)
-		<-recv         // ballot.StateACCEPT
-		<-recv         // ballot.StateALLCONFIRM
-		state = <-recv // ballot.StateINIT
+		// ballot.StateACCEPT and ballot.StateALLCONFIRM are not signaled when
+		// the state manager already moved to the next ballot.StateINIT.
+		for state = <-recv; state.BallotState != ballot.StateINIT; state = <-recv {
+			require.Equal(t, blockHeight, state.Height)
+		}
 		require.Equal(t, ballot.StateINIT, state.BallotState)
 		require.Equal(t, blockHeight+1, isaac.LatestBlock().Height)
 		require.Equal(t, blockHeight+1, state.Height)
@@ -38,10 +38,27 @@ func NewSavingBlockOperations(st *storage.LevelDBBackend, logger logging.Logger)
 	return sb
 }
 
-func (sb *SavingBlockOperations) getCheckedBlockKey() string {
+func getCheckedBlockKey() string {
 	return fmt.Sprintf("%s-last-checked-block", common.InternalPrefix)
 }
 
+func (sb *SavingBlockOperations) getCheckedBlockKey() string {
+	return getCheckedBlockKey()
+}
+
+// SetCheckedBlockHeight marks the `BlockOperation`s of the blocks until the
+// height as checked; the restored snapshot does not have the blocks before
+// the snapshot.
+func SetCheckedBlockHeight(st *storage.LevelDBBackend, height uint64) error {
+	if found, err := st.Has(getCheckedBlockKey()); err != nil {
+		return err
+	} else if found {
+		return st.Set(getCheckedBlockKey(), height)
+	}
+
+	return st.New(getCheckedBlockKey(), height)
+}
+
 func (sb *SavingBlockOperations) getCheckedBlockHeight() uint64 {
 	var checked uint64
 	if err := sb.st.Get(sb.getCheckedBlockKey(), &checked); err != nil {
@@ -146,6 +163,11 @@ func (sb *SavingBlockOperations) check(startBlockHeight uint64) (err error) {
 	if latestBlockHeight == common.GenesisBlockHeight {
 		return
 	}
+	if checked := sb.getCheckedBlockHeight(); checked > startBlockHeight {
+		// checked by another, like restoring snapshot
+		startBlockHeight = checked
+		sb.checkedBlockHeight = checked
+	}
 	if latestBlockHeight <= startBlockHeight {
 		return
 	}
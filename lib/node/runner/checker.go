@@ -285,6 +285,7 @@ func BallotCheckSYNC(c common.Checker, args ...interface{}) error {
 			return err
 		}
 		checker.NodeRunner.SavingBlockOperations().Save(*blk)
+		checker.NodeRunner.Snapshots().Check()
 
 		checker.NodeRunner.TransitISAACState(b.VotingBasis(), ballot.StateALLCONFIRM)
 		log.Debug("finish current ballot; latestHeight == syncHeight-1", "ballot", b.GetHash())
@@ -294,6 +295,7 @@ func BallotCheckSYNC(c common.Checker, args ...interface{}) error {
 			return err
 		}
 		checker.NodeRunner.SavingBlockOperations().Save(*blk)
+		checker.NodeRunner.Snapshots().Check()
 
 		checker.NodeRunner.NextHeight()
 		return nil
@@ -778,8 +780,12 @@ func saveBlock(checker *BallotChecker) error {
 		checker.LatestBlockSources = append(checker.LatestBlockSources, tx.B.Source)
 	}
 	checker.NodeRunner.SavingBlockOperations().Save(*blk)
+	checker.NodeRunner.Snapshots().Check()
 
-	go api.TriggerEvent(checker.NodeRunner.Storage(), proposedTransactions)
+	st, stored := checker.NodeRunner.Storage(), *blk
+	checker.NodeRunner.EventQueue().Push(func() {
+		api.TriggerEvent(st, stored)
+	})
 
 	return nil
 }
@@ -68,9 +68,12 @@ func IsNew(c common.Checker, args ...interface{}) (err error) {
 
 	var validTransactions []string
 	for _, hash := range checker.Transactions {
-		// check transaction is already stored
+		// check transaction is already stored, even if it was pruned
 		var found bool
-		if found, err = block.ExistsBlockTransaction(checker.NodeRunner.Storage(), hash); err != nil || found {
+		if found, err = block.ExistsBlockTransaction(checker.NodeRunner.Storage(), hash); err == nil && !found {
+			found, err = block.IsPrunedBlockTransaction(checker.NodeRunner.Storage(), hash)
+		}
+		if err != nil || found {
 			if !checker.CheckTransactionsOnly {
 				err = errors.NewButKnownMessage
 				return
@@ -268,6 +271,40 @@ func ValidateTx(st *storage.LevelDBBackend, config common.Config, tx transaction
 		}
 	}
 
+	// check, the balance keeps the reserve of data entries
+	if entries := dataEntriesAfter(ba, tx); entries > 0 {
+		if entries > common.MaxDataEntries {
+			err = errors.DataEntriesLimitExceeded
+			return
+		}
+		if ba.Balance-totalAmount < common.DataEntryReserve.MustMult(entries) {
+			err = errors.DataEntryReserveInsufficient
+			return
+		}
+	}
+
+	return
+}
+
+// dataEntriesAfter returns the number of the data entries of the source
+// account after the `ManageData` operations of the transaction.
+func dataEntriesAfter(ba *block.BlockAccount, tx transaction.Transaction) (entries int) {
+	keys := map[string]bool{}
+	for _, entry := range ba.Data {
+		keys[entry.Key] = true
+	}
+	for _, op := range tx.B.Operations {
+		if opb, ok := op.B.(operation.ManageData); ok {
+			keys[opb.Key] = !opb.IsDelete()
+		}
+	}
+
+	for _, exists := range keys {
+		if exists {
+			entries++
+		}
+	}
+
 	return
 }
 
@@ -359,6 +396,23 @@ func ValidateOp(st *storage.LevelDBBackend, config common.Config, source *block.
 		if bo.Type == operation.TypeUnfreezingRequest {
 			return errors.UnfreezingRequestAlreadyReceived
 		}
+	case operation.TypeManageData:
+		var ok bool
+		var casted operation.ManageData
+		if casted, ok = op.B.(operation.ManageData); !ok {
+			return errors.TypeOperationBodyNotMatched
+		}
+		// Frozen account can only send the unfreezing request and the
+		// withdrawal of its balance
+		if source.IsFrozen() {
+			return errors.FrozenAccountNoManageData
+		}
+
+		if casted.IsDelete() {
+			if _, found := source.GetData(casted.Key); !found {
+				return errors.DataEntryNotFound
+			}
+		}
 	case operation.TypeInflationPF:
 		var ok bool
 		var inflationPF operation.InflationPF
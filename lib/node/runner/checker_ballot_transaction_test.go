@@ -12,6 +12,7 @@
 package runner
 
 import (
+	"fmt"
 	"testing"
 
 	"boscoin.io/sebak/lib/block"
@@ -228,6 +229,110 @@ func TestValidateOpCreateExistsAccount(t *testing.T) {
 	require.Nil(t, ValidateTx(st1, common.Config{}, tx))
 }
 
+// Test the data entries and the reserve of them
+func TestValidateTxManageData(t *testing.T) {
+	kps := keypair.Random()
+	kpt := keypair.Random()
+
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	bas := block.BlockAccount{
+		Address: kps.Address(),
+		Balance: common.DataEntryReserve.MustMult(2).MustAdd(common.BaseFee),
+	}
+	bas.ManageData("home_domain", []byte("boscoin.io"))
+	bas.MustSave(st)
+	bat := block.BlockAccount{
+		Address: kpt.Address(),
+		Balance: common.Amount(1 * common.AmountPerCoin),
+	}
+	bat.MustSave(st)
+
+	makeTx := func(opbs ...operation.Body) transaction.Transaction {
+		tx := transaction.Transaction{
+			H: transaction.Header{
+				Version: common.TransactionVersionV1,
+				Created: common.NowISO8601(),
+			},
+			B: transaction.Body{
+				Source:     kps.Address(),
+				Fee:        common.BaseFee,
+				SequenceID: 0,
+			},
+		}
+		for _, opb := range opbs {
+			op, err := operation.NewOperation(opb)
+			require.NoError(t, err)
+			tx.B.Operations = append(tx.B.Operations, op)
+		}
+		tx.H.Hash = tx.B.MakeHashString()
+		return tx
+	}
+
+	{ // new entry within the reserve
+		tx := makeTx(operation.NewManageData("kyc", []byte("findme")))
+		require.Nil(t, ValidateTx(st, common.Config{}, tx))
+	}
+
+	{ // over the reserve
+		tx := makeTx(
+			operation.NewManageData("kyc", []byte("findme")),
+			operation.NewManageData("congress", []byte("findme")),
+		)
+		require.Equal(t, errors.DataEntryReserveInsufficient, ValidateTx(st, common.Config{}, tx))
+
+		// deleting one in the same transaction keeps the reserve
+		tx = makeTx(
+			operation.NewManageData("kyc", []byte("findme")),
+			operation.NewManageData("congress", []byte("findme")),
+			operation.NewManageData("home_domain", nil),
+		)
+		require.Nil(t, ValidateTx(st, common.Config{}, tx))
+	}
+
+	{ // payment can not spend the reserve
+		tx := makeTx(operation.Payment{Target: kpt.Address(), Amount: common.DataEntryReserve.MustAdd(1)})
+		require.Equal(t, errors.DataEntryReserveInsufficient, ValidateTx(st, common.Config{}, tx))
+
+		tx = makeTx(operation.Payment{Target: kpt.Address(), Amount: common.DataEntryReserve})
+		require.Nil(t, ValidateTx(st, common.Config{}, tx))
+	}
+
+	{ // delete unknown entry
+		tx := makeTx(operation.NewManageData("kyc", nil))
+		require.Equal(t, errors.DataEntryNotFound, ValidateTx(st, common.Config{}, tx))
+
+		tx = makeTx(operation.NewManageData("home_domain", nil))
+		require.Nil(t, ValidateTx(st, common.Config{}, tx))
+	}
+
+	{ // too many entries
+		bas.Balance = common.DataEntryReserve.MustMult(common.MaxDataEntries + 1).MustAdd(common.BaseFee)
+		for i := 1; i < common.MaxDataEntries; i++ {
+			bas.ManageData(fmt.Sprintf("key-%d", i), []byte("findme"))
+		}
+		bas.MustSave(st)
+
+		tx := makeTx(operation.NewManageData("kyc", []byte("findme")))
+		require.Equal(t, errors.DataEntriesLimitExceeded, ValidateTx(st, common.Config{}, tx))
+
+		tx = makeTx(operation.NewManageData("home_domain", []byte("sebak")))
+		require.Nil(t, ValidateTx(st, common.Config{}, tx))
+	}
+
+	{ // frozen account
+		bas.Linked = kpt.Address()
+		bas.MustSave(st)
+
+		tx := makeTx(operation.NewManageData("home_domain", []byte("sebak")))
+		require.Equal(t, errors.FrozenAccountNoManageData, ValidateTx(st, common.Config{}, tx))
+
+		tx = makeTx(operation.NewManageData("home_domain", nil))
+		require.Equal(t, errors.FrozenAccountNoManageData, ValidateTx(st, common.Config{}, tx))
+	}
+}
+
 func TestOpsInBalotLimit(t *testing.T) {
 	var checkerFuncs = []common.CheckerFunc{
 		IsNew,
@@ -64,7 +64,7 @@ func TransactionUnmarshal(c common.Checker, args ...interface{}) (err error) {
 }
 
 // HasTransaction checks transaction is in
-// `Pool` And `Block`
+// `Pool` And `Block`, including the pruned one
 func HasTransaction(c common.Checker, args ...interface{}) (err error) {
 	checker := c.(*MessageChecker)
 
@@ -80,6 +80,13 @@ func HasTransaction(c common.Checker, args ...interface{}) (err error) {
 		return errors.NewButKnownMessage
 	}
 
+	// the pruned transaction was also stored in the block
+	if pruned, err := block.IsPrunedBlockTransaction(checker.Storage, hash); err != nil {
+		return err
+	} else if pruned {
+		return errors.NewButKnownMessage
+	}
+
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/transaction"
@@ -94,3 +95,39 @@ func TestMessageCheckerWithInvalidHash(t *testing.T) {
 	require.EqualError(t, err, "unexpected end of JSON input")
 	require.NotEqual(t, checker.Transaction, invalidTx)
 }
+
+func TestMessageCheckerPrunedTransaction(t *testing.T) {
+	_, tx := transaction.TestMakeTransaction(networkID, 1)
+
+	nodeRunner, localNode := MakeNodeRunner()
+	checker := &MessageChecker{
+		DefaultChecker:  common.DefaultChecker{},
+		LocalNode:       localNode,
+		Consensus:       nodeRunner.Consensus(),
+		Storage:         nodeRunner.Storage(),
+		TransactionPool: nodeRunner.TransactionPool,
+		Transaction:     tx,
+		Log:             nodeRunner.Log(),
+		Conf:            nodeRunner.Conf,
+	}
+	require.NoError(t, HasTransaction(checker))
+
+	st := nodeRunner.Storage()
+	blk := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(st), []string{tx.GetHash()})
+	blk.MustSave(st)
+	bt := block.NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+	bt.MustSave(st)
+
+	bs, err := st.OpenBatch()
+	require.NoError(t, err)
+	_, err = block.PruneBlock(bs, blk)
+	require.NoError(t, err)
+	require.NoError(t, bs.Commit())
+
+	exists, err := block.ExistsBlockTransaction(st, tx.GetHash())
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// the pruned transaction is not accepted again
+	require.Equal(t, errors.NewButKnownMessage, HasTransaction(checker))
+}
@@ -105,6 +105,11 @@ func finishBallotWithProposedTxs(st *storage.LevelDBBackend, b ballot.Ballot, pr
 		return nil, err
 	}
 
+	if err = FinishLedger(st, *blk, proposedTransactions, b.ProposerTransaction()); err != nil {
+		log.Error("failed to finish ledger", "block", blk.Hash, "error", err)
+		return nil, err
+	}
+
 	return blk, nil
 }
 
@@ -188,6 +193,12 @@ func finishOperation(st *storage.LevelDBBackend, source string, op operation.Ope
 			return errors.UnknownOperationType
 		}
 		return finishInflationPF(st, source, pop, log)
+	case operation.TypeManageData:
+		pop, ok := op.B.(operation.ManageData)
+		if !ok {
+			return errors.UnknownOperationType
+		}
+		return finishManageData(st, source, pop, log)
 
 	default:
 		err = errors.UnknownOperationType
@@ -269,6 +280,21 @@ func finishInflationPF(st *storage.LevelDBBackend, source string, opb operation.
 	return
 }
 
+func finishManageData(st *storage.LevelDBBackend, source string, opb operation.ManageData, log logging.Logger) (err error) {
+	var baSource *block.BlockAccount
+	if baSource, err = block.GetBlockAccount(st, source); err != nil {
+		err = errors.BlockAccountDoesNotExists
+		return
+	}
+
+	baSource.ManageData(opb.Key, opb.Value)
+	if err = baSource.Save(st); err != nil {
+		return
+	}
+
+	return
+}
+
 func FinishProposerTransaction(st *storage.LevelDBBackend, blk block.Block, ptx ballot.ProposerTransaction, log logging.Logger) (err error) {
 	if err = ProcessProposerTransaction(st, blk, ptx, log); err != nil {
 		return err
@@ -285,6 +311,23 @@ func FinishProposerTransaction(st *storage.LevelDBBackend, blk block.Block, ptx
 	return
 }
 
+// FinishLedger saves the ledger of the block, which is made from the ledger
+// of the previous block; it should be called after the accounts are changed by
+// the transactions.
+func FinishLedger(st *storage.LevelDBBackend, blk block.Block, transactions []*transaction.Transaction, ptx ballot.ProposerTransaction) (err error) {
+	var prev block.Ledger
+	if prev, err = block.GetLedger(st, blk.Height-1); err != nil {
+		return
+	}
+
+	var ledger block.Ledger
+	if ledger, err = prev.Next(st, blk, transactions, &ptx.Transaction); err != nil {
+		return
+	}
+
+	return ledger.Save(st)
+}
+
 func ProcessProposerTransaction(st *storage.LevelDBBackend, blk block.Block, ptx ballot.ProposerTransaction, log logging.Logger) (err error) {
 	{
 		var opb operation.CollectTxFee
@@ -163,3 +163,52 @@ func TestFinishBallot(t *testing.T) {
 	err = testFinishBallot(true, 100, 100)
 	require.NoError(t, err)
 }
+
+func TestFinishTransactionsManageData(t *testing.T) {
+	st := storage.NewTestStorage()
+	defer st.Close()
+
+	kp := keypair.Random()
+	ba := block.NewBlockAccount(kp.Address(), common.BaseReserve.MustMult(10))
+	ba.MustSave(st)
+
+	finish := func(sequenceID uint64, opbs ...operation.Body) {
+		var ops []operation.Operation
+		for _, opb := range opbs {
+			op, err := operation.NewOperation(opb)
+			require.NoError(t, err)
+			ops = append(ops, op)
+		}
+		tx, err := transaction.NewTransaction(kp.Address(), sequenceID, ops...)
+		require.NoError(t, err)
+		tx.Sign(kp, networkID)
+
+		blk := block.TestMakeNewBlock([]string{tx.GetHash()})
+		require.NoError(t, FinishTransactions(blk, []*transaction.Transaction{&tx}, st))
+	}
+
+	finish(
+		0,
+		operation.NewManageData("home_domain", []byte("boscoin.io")),
+		operation.NewManageData("kyc", []byte("findme")),
+	)
+
+	fetched, err := block.GetBlockAccount(st, kp.Address())
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		[]block.DataEntry{
+			{Key: "home_domain", Value: []byte("boscoin.io")},
+			{Key: "kyc", Value: []byte("findme")},
+		},
+		fetched.Data,
+	)
+	require.Equal(t, ba.Balance.MustSub(common.BaseFee.MustMult(2)), fetched.Balance)
+	require.Equal(t, uint64(1), fetched.SequenceID)
+
+	finish(1, operation.NewManageData("kyc", nil))
+
+	fetched, err = block.GetBlockAccount(st, kp.Address())
+	require.NoError(t, err)
+	require.Equal(t, []block.DataEntry{{Key: "home_domain", Value: []byte("boscoin.io")}}, fetched.Data)
+}
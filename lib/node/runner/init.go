@@ -9,14 +9,22 @@ import (
 )
 
 var log logging.Logger = logging.New("module", "noderunner")
+var auditLog logging.Logger = logging.New("module", "audit")
 var DebugPProf bool = false
 var startTime time.Time
 
 func init() {
 	SetLogging(common.DefaultLogLevel, common.DefaultLogHandler)
+	SetAuditLogging(common.DefaultLogHandler)
 	startTime = time.Now()
 }
 
 func SetLogging(level logging.Lvl, handler logging.Handler) {
 	log.SetHandler(logging.LvlFilterHandler(level, handler))
 }
+
+// SetAuditLogging sets the handler of the audit log of the admin API; the
+// audit log is not filtered by the log level.
+func SetAuditLogging(handler logging.Handler) {
+	auditLog.SetHandler(handler)
+}
@@ -10,6 +10,7 @@ import (
 	"boscoin.io/sebak/lib/consensus"
 	"boscoin.io/sebak/lib/metrics"
 	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner/api"
 	"boscoin.io/sebak/lib/voting"
 )
 
@@ -22,9 +23,10 @@ type ISAACStateManager struct {
 	state                  consensus.ISAACState
 	stateTransit           chan consensus.ISAACState
 	stop                   chan struct{}
-	blockTimeBuffer        time.Duration              // the time to wait to adjust the block creation time.
-	transitSignal          func(consensus.ISAACState) // the function is called when the ISAACState is changed.
-	firstProposedBlockTime time.Time                  // the time at which the first consensus block was saved(height 2). It is used for calculating `blockTimeBuffer`.
+	blockTimeBuffer        time.Duration               // the time to wait to adjust the block creation time.
+	transitSignal          func(consensus.ISAACState)  // the function is called when the ISAACState is changed.
+	firstProposedBlockTime time.Time                   // the time at which the first consensus block was saved(height 2). It is used for calculating `blockTimeBuffer`.
+	timeouts               *consensus.AdaptiveTimeouts // the effective timeouts of ballot states.
 
 	Conf common.Config
 }
@@ -41,6 +43,7 @@ func NewISAACStateManager(nr *NodeRunner, conf common.Config) *ISAACStateManager
 		stop:            make(chan struct{}),
 		blockTimeBuffer: 2 * time.Second,
 		transitSignal:   func(consensus.ISAACState) {},
+		timeouts:        consensus.NewAdaptiveTimeouts(conf),
 
 		Conf: conf,
 	}
@@ -78,11 +81,12 @@ func (sm *ISAACStateManager) setBlockTimeBuffer() {
 	}
 
 	ballotProposedTime := getBallotProposedTime(b.ProposedTime)
+	now := common.Now()
 	sm.blockTimeBuffer = calculateBlockTimeBuffer(
 		b.Height,
 		sm.Conf.BlockTime,
-		time.Now().Sub(sm.firstProposedBlockTime),
-		time.Now().Sub(ballotProposedTime),
+		now.Sub(sm.firstProposedBlockTime),
+		now.Sub(ballotProposedTime),
 		sm.Conf.BlockTimeDelta,
 	)
 	sm.nr.Log().Debug(
@@ -91,7 +95,7 @@ func (sm *ISAACStateManager) setBlockTimeBuffer() {
 		"firstProposedBlockTime", sm.firstProposedBlockTime,
 		"height", b.Height,
 		"proposedTime", b.ProposedTime,
-		"now", time.Now(),
+		"now", now,
 	)
 
 	return
@@ -193,15 +197,18 @@ func (sm *ISAACStateManager) Start() {
 	sm.nr.localNode.SetConsensus()
 	sm.nr.Log().Debug("begin ISAACStateManager.Start()", "ISAACState", sm.State())
 	go func() {
-		timer := time.NewTimer(time.Duration(1 * time.Hour))
-		begin := time.Now() // measure for block interval time
+		timer := common.NewTimer(time.Duration(1 * time.Hour))
+		begin := time.Now()      // measure for block interval time
+		var stateBegin time.Time // measure for ballot latency
 		for {
 			select {
-			case <-timer.C:
+			case <-timer.C():
 				sm.nr.Log().Debug("timeout", "ISAACState", sm.State())
 				switch sm.State().BallotState {
 				case ballot.StateINIT:
+					sm.timeouts.Expired(ballot.StateINIT)
 					sm.setBallotState(ballot.StateSIGN)
+					stateBegin = common.Now()
 					sm.transitSignal(sm.State())
 					sm.resetTimer(timer, ballot.StateSIGN)
 				case ballot.StateSIGN:
@@ -210,6 +217,7 @@ func (sm *ISAACStateManager) Start() {
 							sm.nr.Log().Debug("break; BallotSendRecord().Sent(sm.State) == true", "ISAACState", sm.State())
 							break
 						}
+						sm.timeouts.Expired(ballot.StateSIGN)
 						go sm.broadcastExpiredBallot(sm.State().Round, ballot.StateSIGN)
 					}
 				case ballot.StateACCEPT:
@@ -218,6 +226,7 @@ func (sm *ISAACStateManager) Start() {
 							sm.nr.Log().Debug("break; BallotSendRecord().Sent(sm.State) == true", "ISAACState", sm.State())
 							break
 						}
+						sm.timeouts.Expired(ballot.StateACCEPT)
 						go sm.broadcastExpiredBallot(sm.State().Round, ballot.StateACCEPT)
 					}
 				case ballot.StateALLCONFIRM:
@@ -232,11 +241,17 @@ func (sm *ISAACStateManager) Start() {
 					break
 				}
 
+				sm.observeLatency(current, state, stateBegin)
+				stateBegin = common.Now()
+
 				if state.BallotState == ballot.StateINIT {
 					begin = metrics.Consensus.SetBlockIntervalSeconds(begin)
+					sm.updateRoundTripTime()
 
 					if sm.nr.localNode.State() == node.StateCONSENSUS {
-						sm.proposeOrWait(timer, state.Round)
+						// the node, which is not proposer waits for
+						// `blockTimeBuffer` before the ballot of proposer.
+						stateBegin = stateBegin.Add(sm.proposeOrWait(timer, state.Round))
 					}
 				} else {
 					sm.resetTimer(timer, state.BallotState)
@@ -251,6 +266,50 @@ func (sm *ISAACStateManager) Start() {
 	}()
 }
 
+// observeLatency measures the latency of the current state, which is finished
+// by the ballots from the other validators. If the local node sent the ballot
+// in the current state, the latency is measured from the time of sending.
+func (sm *ISAACStateManager) observeLatency(current, target consensus.ISAACState, stateBegin time.Time) {
+	if stateBegin.IsZero() || current.Height != target.Height || current.Round != target.Round {
+		return
+	}
+
+	if sent, found := sm.nr.BallotSendRecord().SentTime(current); found && sent.After(stateBegin) {
+		stateBegin = sent
+	}
+
+	latency := common.Now().Sub(stateBegin)
+	if latency < 0 {
+		return
+	}
+
+	sm.timeouts.Observe(current.BallotState, latency)
+	metrics.Consensus.ObserveBallotLatency(current.BallotState.String(), latency)
+}
+
+// updateRoundTripTime sets the round trip time of the connections to the
+// validators for the timeouts.
+func (sm *ISAACStateManager) updateRoundTripTime() {
+	rtt := consensus.QuorumRoundTripTime(
+		sm.nr.ConnectionManager().RoundTripTimes(),
+		sm.nr.Policy().Threshold(),
+	)
+	sm.timeouts.SetRoundTripTime(rtt)
+	metrics.Consensus.SetRoundTripTime(rtt)
+}
+
+// Timeouts returns the effective timeouts of ballot states.
+func (sm *ISAACStateManager) Timeouts() node.NodeTimeouts {
+	return node.NodeTimeouts{
+		Adaptive:      sm.timeouts.Enabled(),
+		INIT:          sm.timeouts.Timeout(ballot.StateINIT),
+		SIGN:          sm.timeouts.Timeout(ballot.StateSIGN),
+		ACCEPT:        sm.timeouts.Timeout(ballot.StateACCEPT),
+		ALLCONFIRM:    sm.timeouts.Timeout(ballot.StateALLCONFIRM),
+		RoundTripTime: sm.timeouts.RoundTripTime(),
+	}
+}
+
 func (sm *ISAACStateManager) broadcastExpiredBallot(round uint64, state ballot.State) {
 	sm.nr.Log().Debug("begin ISAACStateManager.broadcastExpiredBallot", "round", round, "ballotState", state)
 
@@ -273,40 +332,44 @@ func (sm *ISAACStateManager) broadcastExpiredBallot(round uint64, state ballot.S
 	return
 }
 
-func (sm *ISAACStateManager) resetTimer(timer *time.Timer, state ballot.State) {
-	switch state {
-	case ballot.StateINIT:
-		timer.Reset(sm.Conf.TimeoutINIT)
-	case ballot.StateSIGN:
-		timer.Reset(sm.Conf.TimeoutSIGN)
-	case ballot.StateACCEPT:
-		timer.Reset(sm.Conf.TimeoutACCEPT)
-	case ballot.StateALLCONFIRM:
-		timer.Reset(sm.Conf.TimeoutALLCONFIRM)
-	}
+func (sm *ISAACStateManager) resetTimer(timer common.Timer, state ballot.State) {
+	timeout := sm.timeouts.Timeout(state)
+	metrics.Consensus.SetTimeout(state.String(), timeout)
+	timer.Reset(timeout)
 }
 
 // In proposeOrWait,
 // if nr.localNode is proposer, it proposes new ballot,
 // but if not, it waits for receiving ballot from the other proposer.
-func (sm *ISAACStateManager) proposeOrWait(timer *time.Timer, round uint64) {
+// It returns the time to wait before the ballot of the other proposer.
+func (sm *ISAACStateManager) proposeOrWait(timer common.Timer, round uint64) time.Duration {
 	timer.Reset(time.Duration(1 * time.Hour))
 	sm.setBlockTimeBuffer()
 	height := sm.nr.consensus.LatestBlock().Height
 	proposer := sm.nr.Consensus().SelectProposer(height, round)
 	log.Debug("selected proposer", "proposer", proposer)
 
-	if proposer == sm.nr.localNode.Address() {
-		time.Sleep(sm.blockTimeBuffer)
+	timeout := sm.timeouts.Timeout(ballot.StateINIT)
+	metrics.Consensus.SetTimeout(ballot.StateINIT.String(), timeout)
+
+	if proposer == sm.nr.localNode.Address() && sm.nr.ProposingPaused() {
+		// the other validators will expire the round of this proposer
+		log.Info("proposing is paused", "height", height, "round", round)
+	} else if proposer == sm.nr.localNode.Address() {
+		common.Sleep(sm.blockTimeBuffer)
 		if _, err := sm.nr.proposeNewBallot(round); err == nil {
 			log.Debug("propose new ballot", "proposer", proposer, "round", round, "ballotState", ballot.StateSIGN)
 		} else {
 			log.Error("failed to proposeNewBallot", "height", height, "error", err)
 		}
-		timer.Reset(sm.Conf.TimeoutINIT)
-	} else {
-		timer.Reset(sm.blockTimeBuffer + sm.Conf.TimeoutINIT)
+		timer.Reset(timeout)
+
+		return 0
 	}
+
+	timer.Reset(sm.blockTimeBuffer + timeout)
+
+	return sm.blockTimeBuffer
 }
 
 func (sm *ISAACStateManager) State() consensus.ISAACState {
@@ -321,6 +384,8 @@ func (sm *ISAACStateManager) setState(state consensus.ISAACState) {
 	sm.nr.Log().Debug("begin ISAACStateManager.setState()", "state", state)
 	sm.state = state
 
+	sm.triggerState(state)
+
 	return
 }
 
@@ -330,9 +395,19 @@ func (sm *ISAACStateManager) setBallotState(ballotState ballot.State) {
 	sm.nr.Log().Debug("begin ISAACStateManager.setBallotState()", "state", sm.state)
 	sm.state.BallotState = ballotState
 
+	sm.triggerState(sm.state)
+
 	return
 }
 
+// triggerState pushes the consensus state to the event queue of NodeRunner
+// under the lock of ISAACStateManager, so the states are triggered in order.
+func (sm *ISAACStateManager) triggerState(state consensus.ISAACState) {
+	sm.nr.EventQueue().Push(func() {
+		api.TriggerConsensusState(state)
+	})
+}
+
 func (sm *ISAACStateManager) Stop() {
 	go func() {
 		sm.stop <- struct{}{}
@@ -9,7 +9,9 @@ import (
 
 	"boscoin.io/sebak/lib/ballot"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/observer"
 	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/node/runner/api/resource"
 	"boscoin.io/sebak/lib/voting"
 )
 
@@ -388,3 +390,76 @@ func TestStateTransitFromTimeoutSignToAccept(t *testing.T) {
 	require.Equal(t, 0, sign)
 	require.Equal(t, 1, accept)
 }
+
+// 1. All 3 Nodes.
+// 2. Proposer itself.
+// 3. `TimeoutAdaptive` is set.
+// 4. Until the state is finished, the configured timeouts are used.
+// 5. After INIT is finished by SIGN, the timeout of INIT is adapted to the
+//    latency and it is bounded by `TimeoutMin`.
+func TestStateAdaptiveTimeouts(t *testing.T) {
+	conf := common.NewTestConfig()
+	conf.TimeoutINIT = time.Hour
+	conf.TimeoutSIGN = time.Hour
+	conf.TimeoutACCEPT = time.Hour
+	conf.TimeoutAdaptive = true
+	conf.TimeoutMin = 300 * time.Millisecond
+
+	recv := make(chan struct{}, 10)
+	nr, _, _ := createNodeRunnerForTesting(3, conf, recv)
+
+	transited := make(chan consensus.ISAACState, 10)
+	nr.isaacStateManager.SetTransitSignal(func(state consensus.ISAACState) {
+		transited <- state
+	})
+
+	timeouts := nr.isaacStateManager.Timeouts()
+	require.True(t, timeouts.Adaptive)
+	require.Equal(t, time.Hour, timeouts.INIT)
+
+	nr.startStateManager()
+	defer nr.StopStateManager()
+
+	state := <-transited
+	require.Equal(t, ballot.StateINIT, state.BallotState)
+	<-recv
+
+	nr.isaacStateManager.TransitISAACState(state.Height, state.Round, ballot.StateSIGN)
+	state = <-transited
+	require.Equal(t, ballot.StateSIGN, state.BallotState)
+
+	timeouts = nr.isaacStateManager.Timeouts()
+	require.Equal(t, conf.TimeoutMin, timeouts.INIT)
+	require.Equal(t, time.Hour, timeouts.SIGN)
+	require.Equal(t, time.Hour, timeouts.ACCEPT)
+}
+
+// The consensus states are triggered in the order of the transitions.
+func TestStateTriggerInOrder(t *testing.T) {
+	conf := common.NewTestConfig()
+	nr, _, _ := createNodeRunnerForTesting(3, conf, nil)
+
+	received := make(chan *resource.ConsensusState, 10)
+	event := observer.NewCondition(observer.ConsensusState, observer.All).String()
+	onFunc := func(args ...interface{}) {
+		received <- args[0].(*resource.ConsensusState)
+	}
+	observer.ResourceObserver.On(event, onFunc)
+	defer observer.ResourceObserver.Off(event, onFunc)
+
+	for round := uint64(0); round < 5; round++ {
+		nr.isaacStateManager.setState(consensus.ISAACState{Height: 2, Round: round, BallotState: ballot.StateINIT})
+		nr.isaacStateManager.setBallotState(ballot.StateSIGN)
+	}
+
+	go nr.EventQueue().Start()
+	defer nr.EventQueue().Stop()
+
+	for round := uint64(0); round < 5; round++ {
+		for _, state := range []ballot.State{ballot.StateINIT, ballot.StateSIGN} {
+			m := (<-received).GetMap()
+			require.Equal(t, round, m["round"])
+			require.Equal(t, state.String(), m["ballot_state"])
+		}
+	}
+}
@@ -258,7 +258,7 @@ func TestJSONRPCServerDBGetIterator(t *testing.T) {
 	jp.prepare()
 	defer jp.done()
 
-	expectedPrefix := string(0x00)
+	expectedPrefix := string(rune(0x00))
 	expected := []string{}
 	{ // store data in storage
 		total := 10
@@ -273,7 +273,7 @@ func TestJSONRPCServerDBGetIterator(t *testing.T) {
 	}
 
 	{ // store another data, which has different prefix
-		prefix := string(0x01)
+		prefix := string(rune(0x01))
 		total := 3
 
 		for i := 0; i < total; i++ {
@@ -342,7 +342,7 @@ func TestJSONRPCServerDBGetIteratorWithLimit(t *testing.T) {
 	jp.prepare()
 	defer jp.done()
 
-	expectedPrefix := string(0x00)
+	expectedPrefix := string(rune(0x00))
 	expected := []string{}
 	{ // store data in storage
 		total := 10
@@ -357,7 +357,7 @@ func TestJSONRPCServerDBGetIteratorWithLimit(t *testing.T) {
 	}
 
 	{ // store another data, which has different prefix
-		prefix := string(0x01)
+		prefix := string(rune(0x01))
 		total := 3
 
 		for i := 0; i < total; i++ {
@@ -404,7 +404,7 @@ func TestJSONRPCServerDBSnapshot(t *testing.T) {
 	jp.prepare()
 	defer jp.done()
 
-	expectedPrefix := string(0x00)
+	expectedPrefix := string(rune(0x00))
 
 	{ // without snapshot
 		args := DBGetIteratorArgs{
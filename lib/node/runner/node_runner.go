@@ -8,9 +8,11 @@
 package runner
 
 import (
+	"context"
 	"net/http"
 	"net/http/pprof"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	ghandlers "github.com/gorilla/handlers"
@@ -90,6 +92,7 @@ type NodeRunner struct {
 	storage           *storage.LevelDBBackend
 	isaacStateManager *ISAACStateManager
 	ballotSendRecord  *consensus.BallotSendRecord
+	peerScorer        *network.PeerScorer
 
 	handleBaseBallotCheckerFuncs   []common.CheckerFunc
 	handleINITBallotCheckerFuncs   []common.CheckerFunc
@@ -97,13 +100,22 @@ type NodeRunner struct {
 	handleACCEPTBallotCheckerFuncs []common.CheckerFunc
 
 	handleBallotCheckerDeferFunc common.CheckerDeferFunc
+	proposeBallotFunc            ProposeBallotFunc
 
 	log logging.Logger
 
 	Conf                  common.Config
 	nodeInfo              node.NodeInfo
 	savingBlockOperations *SavingBlockOperations
+	snapshots             *SnapshotManager
+	pruner                *Pruner
+	eventQueue            *api.EventQueue
+	getSyncStatus         func(context.Context) (*node.NodeSyncInfo, error)
 	jsonrpcServer         *jsonrpcServer
+	adminServer           *adminServer
+	logLevelHandler       *common.LevelHandler
+	proposingPaused       uint32
+	shutdownFunc          func()
 }
 
 func NewNodeRunner(
@@ -126,6 +138,7 @@ func NewNodeRunner(
 		Conf:            conf,
 	}
 	nr.ballotSendRecord = consensus.NewBallotSendRecord(localNode.Alias())
+	nr.peerScorer = network.NewPeerScorer(conf.PeerBanThreshold, conf.PeerBanDuration)
 
 	nr.localNode.SetBooting()
 
@@ -134,6 +147,12 @@ func NewNodeRunner(
 	nr.policy.SetValidators(len(nr.localNode.GetValidators()))
 
 	nr.connectionManager = c.ConnectionManager()
+
+	if err = checkSchemaVersion(nr.storage); err != nil {
+		nr.log.Error("failed to check schema version of storage", "error", err)
+		return
+	}
+
 	nr.savingBlockOperations = NewSavingBlockOperations(
 		nr.Storage(),
 		nr.Log(),
@@ -144,10 +163,22 @@ func NewNodeRunner(
 		return
 	}
 
+	nr.snapshots = NewSnapshotManager(
+		nr.Storage(),
+		nr.localNode.Keypair(),
+		conf.NetworkID,
+		conf.SnapshotInterval,
+		nr.Log(),
+	)
+	nr.pruner = NewPruner(nr.Storage(), conf.PruneKeepBlocks, nr.Log())
+	nr.eventQueue = api.NewEventQueue()
+
 	nr.SetHandleBaseBallotCheckerFuncs(DefaultHandleBaseBallotCheckerFuncs...)
 	nr.SetHandleINITBallotCheckerFuncs(DefaultHandleINITBallotCheckerFuncs...)
 	nr.SetHandleSIGNBallotCheckerFuncs(DefaultHandleSIGNBallotCheckerFuncs...)
 	nr.SetHandleACCEPTBallotCheckerFuncs(DefaultHandleACCEPTBallotCheckerFuncs...)
+	nr.SetProposeBallotFunc(DefaultProposeBallot)
+	nr.SetShutdownFunc(nr.Stop)
 
 	{
 		// find common account
@@ -163,11 +194,36 @@ func NewNodeRunner(
 	if conf.JSONRPCEndpoint != nil {
 		nr.jsonrpcServer = newJSONRPCServer(conf.JSONRPCEndpoint, nr.storage)
 	}
+	if conf.AdminEndpoint != nil {
+		if nr.adminServer, err = newAdminServer(nr, conf.AdminEndpoint, conf.AdminToken); err != nil {
+			nr.log.Error("failed to make admin server", "error", err)
+			return
+		}
+	}
 
 	return
 }
 
+// checkSchemaVersion checks the schema version of storage before the node
+// starts; in `NewNodeRunner`, the `storage` argument shadows the package.
+func checkSchemaVersion(st *storage.LevelDBBackend) error {
+	return storage.CheckSchemaVersion(st)
+}
+
 func (nr *NodeRunner) Ready() {
+	// banned peers are rejected before rate limit
+	if err := nr.network.AddMiddleware(network.RouterNameNode, network.PeerBanMiddleware(nr.log, nr.peerScorer)); err != nil {
+		nr.log.Error("`network.PeerBanMiddleware` for `RouterNameNode` has an error", "err", err)
+		return
+	}
+
+	// the peers can send the ballots and transactions in RLP
+	acceptPostMiddleware := network.AcceptPostMiddleware(common.ContentTypeJSON, common.ContentTypeRLP)
+	if err := nr.network.AddMiddleware(network.RouterNameNode, acceptPostMiddleware); err != nil {
+		nr.log.Error("`network.AcceptPostMiddleware` for `RouterNameNode` has an error", "err", err)
+		return
+	}
+
 	rateLimitMiddlewareAPI := network.RateLimitMiddleware(nr.log, nr.Conf.RateLimitRuleAPI)
 	if err := nr.network.AddMiddleware(network.RouterNameAPI, rateLimitMiddlewareAPI); err != nil {
 		nr.log.Error("`network.RateLimitMiddleware` for `RouterNameAPI` has an error", "err", err)
@@ -257,6 +313,8 @@ func (nr *NodeRunner) Ready() {
 		network.UrlPathPrefixNode,
 		nr.Conf,
 	)
+	nodeHandler.SetPeerScorer(nr.peerScorer)
+	nodeHandler.SetSnapshots(nr.snapshots)
 
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(NodeInfoHandlerPattern), nodeHandler.NodeInfoHandler)
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(ConnectHandlerPattern), nodeHandler.ConnectHandler).
@@ -267,10 +325,10 @@ func (nr *NodeRunner) Ready() {
 		Headers("Content-Type", "application/json")
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(MessageHandlerPattern), nodeHandler.MessageHandler).
 		Methods("POST").
-		Headers("Content-Type", "application/json")
+		MatcherFunc(common.ContentTypeMatcher(common.ContentTypeJSON, common.ContentTypeRLP))
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(BallotHandlerPattern), nodeHandler.BallotHandler).
 		Methods("POST").
-		Headers("Content-Type", "application/json")
+		MatcherFunc(common.ContentTypeMatcher(common.ContentTypeJSON, common.ContentTypeRLP))
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(GetBlocksPattern), nodeHandler.GetBlocksHandler).
 		Methods("GET", "POST").
 		MatcherFunc(common.PostAndJSONMatcher)
@@ -279,6 +337,8 @@ func (nr *NodeRunner) Ready() {
 		MatcherFunc(common.PostAndJSONMatcher)
 	nr.network.AddHandler(nodeHandler.HandlerURLPattern(GetBallotPattern), nodeHandler.GetBallotHandler).
 		Methods("GET")
+	nr.network.AddHandler(nodeHandler.HandlerURLPattern(GetSnapshotPattern), nodeHandler.GetSnapshotHandler).
+		Methods("GET")
 	nr.network.AddHandler(network.UrlPathPrefixMetric, promhttp.Handler().ServeHTTP)
 
 	// api handlers
@@ -290,11 +350,17 @@ func (nr *NodeRunner) Ready() {
 		nr.nodeInfo,
 	)
 	apiHandler.GetLatestBlock = nr.Consensus().LatestBlock
+	apiHandler.GetTimeouts = nr.isaacStateManager.Timeouts
+	apiHandler.GetSyncStatus = nr.getSyncStatus
 
 	nr.network.AddHandler(
 		apiHandler.HandlerURLPattern(api.GetAccountHandlerPattern),
 		baCache.WrapHandlerFunc(apiHandler.GetAccountHandler),
 	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetAccountDataHandlerPattern),
+		baCache.WrapHandlerFunc(apiHandler.GetAccountDataHandler),
+	).Methods("GET", "OPTIONS")
 	nr.network.AddHandler(
 		apiHandler.HandlerURLPattern(api.GetAccountsHandlerPattern),
 		baCache.WrapHandlerFunc(apiHandler.GetAccountsHandler),
@@ -335,6 +401,10 @@ func (nr *NodeRunner) Ready() {
 		apiHandler.HandlerURLPattern(api.PostSubscribePattern),
 		listCache.WrapHandlerFunc(apiHandler.PostSubscribeHandler),
 	).Methods("POST", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetSubscribeWebSocketPattern),
+		apiHandler.SubscribeWebSocketHandler,
+	).Methods("GET")
 
 	TransactionsHandler := func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "POST" {
@@ -360,6 +430,20 @@ func (nr *NodeRunner) Ready() {
 		apiHandler.HandlerURLPattern(api.GetTransactionsHandlerPattern),
 		TransactionsHandler,
 	).Methods("GET", "POST", "OPTIONS").MatcherFunc(common.PostAndJSONMatcher)
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.PostTransactionsBatchPattern),
+		func(w http.ResponseWriter, r *http.Request) {
+			checkerFuncs := HandleTransactionCheckerFuncs
+			if nr.Conf.WatcherMode == true {
+				checkerFuncs = HandleTransactionCheckerForWatcherFuncs
+			}
+
+			apiHandler.PostTransactionsBatchHandler(
+				w, r,
+				nodeHandler.ReceiveTransaction, checkerFuncs,
+			)
+		},
+	).Methods("POST", "OPTIONS").MatcherFunc(common.PostAndJSONMatcher)
 
 	nr.network.AddHandler(
 		apiHandler.HandlerURLPattern(api.GetBlocksHandlerPattern),
@@ -369,6 +453,22 @@ func (nr *NodeRunner) Ready() {
 		apiHandler.HandlerURLPattern(api.GetBlockHandlerPattern),
 		cache.WrapHandlerFunc(apiHandler.GetBlockHandler),
 	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetLedgerHandlerPattern),
+		apiHandler.GetLedgerHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetSyncHandlerPattern),
+		apiHandler.GetSyncHandler,
+	).Methods("GET", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GraphQLPattern),
+		apiHandler.GraphQLHandler,
+	).Methods("GET", "POST", "OPTIONS")
+	nr.network.AddHandler(
+		apiHandler.HandlerURLPattern(api.GetOpenAPIHandlerPattern),
+		apiHandler.GetOpenAPIHandler,
+	).Methods("GET", "OPTIONS")
 
 	// pprof
 	if DebugPProf == true {
@@ -392,6 +492,8 @@ func (nr *NodeRunner) Start() (err error) {
 	go nr.ConnectValidators()
 	go nr.InitRound()
 	go nr.savingBlockOperations.Start()
+	go nr.pruner.Start()
+	go nr.eventQueue.Start()
 
 	if nr.jsonrpcServer != nil {
 		go func() {
@@ -402,6 +504,14 @@ func (nr *NodeRunner) Start() (err error) {
 			}
 		}()
 	}
+	if nr.adminServer != nil {
+		go func() {
+			if err := nr.adminServer.Start(); err != nil {
+				log.Crit("failed to start adminServer", "error", err)
+				nr.Stop()
+			}
+		}()
+	}
 
 	if err = nr.network.Start(); err != nil {
 		return
@@ -413,9 +523,15 @@ func (nr *NodeRunner) Start() (err error) {
 func (nr *NodeRunner) Stop() {
 	nr.network.Stop()
 	nr.isaacStateManager.Stop()
+	nr.snapshots.Stop()
+	nr.pruner.Stop()
+	nr.eventQueue.Stop()
 	if nr.jsonrpcServer != nil {
 		nr.jsonrpcServer.Stop()
 	}
+	if nr.adminServer != nil {
+		nr.adminServer.Stop()
+	}
 }
 
 func (nr *NodeRunner) Node() *node.LocalNode {
@@ -454,10 +570,22 @@ func (nr *NodeRunner) SavingBlockOperations() *SavingBlockOperations {
 	return nr.savingBlockOperations
 }
 
+func (nr *NodeRunner) Snapshots() *SnapshotManager {
+	return nr.snapshots
+}
+
+func (nr *NodeRunner) EventQueue() *api.EventQueue {
+	return nr.eventQueue
+}
+
 func (nr *NodeRunner) BallotSendRecord() *consensus.BallotSendRecord {
 	return nr.ballotSendRecord
 }
 
+func (nr *NodeRunner) PeerScorer() *network.PeerScorer {
+	return nr.peerScorer
+}
+
 func (nr *NodeRunner) ConnectValidators() {
 	ticker := time.NewTicker(time.Millisecond * 5)
 	for _ = range ticker.C {
@@ -491,8 +619,12 @@ func (nr *NodeRunner) SetHandleACCEPTBallotCheckerFuncs(f ...common.CheckerFunc)
 
 // Read from the network channel and forwards to `handleMessage`
 func (nr *NodeRunner) handleMessages() {
+	counter, _ := nr.network.(network.MessageCounter)
 	for message := range nr.network.ReceiveMessage() {
 		nr.handleMessage(message)
+		if counter != nil {
+			counter.MessageHandled()
+		}
 	}
 }
 
@@ -506,6 +638,7 @@ func (nr *NodeRunner) handleMessage(message common.NetworkMessage) {
 	case common.ConnectMessage:
 		if _, err := node.NewValidatorFromString(message.Data); err != nil {
 			nr.log.Error("invalid validator data was received", "error", err)
+			nr.peerScorer.Add(message.Peer, network.PeerFailureMalformed)
 			return
 		}
 	case common.BallotMessage:
@@ -532,6 +665,7 @@ func (nr *NodeRunner) handleBallotMessage(message common.NetworkMessage) (err er
 	if err = common.RunChecker(baseChecker, nr.handleBallotCheckerDeferFunc); err != nil {
 		if _, ok := err.(common.CheckerErrorStop); !ok {
 			nr.log.Debug("failed to handle ballot", "error", err)
+			nr.peerScorer.Punish(message.Peer, err)
 			return
 		}
 	}
@@ -632,19 +766,103 @@ var NewBallotTransactionCheckerFuncs = []common.CheckerFunc{
 	BallotTransactionsSameSource,
 }
 
+// ProposeBallotFunc makes the new INIT ballot of the round and broadcasts it
+// as proposer.
+type ProposeBallotFunc func(nr *NodeRunner, round uint64) (ballot.Ballot, error)
+
+// SetSyncStatusFunc sets the function to get the progress of sync, which is
+// shown by API; it should be set before `Ready()`.
+func (nr *NodeRunner) SetSyncStatusFunc(f func(context.Context) (*node.NodeSyncInfo, error)) {
+	nr.getSyncStatus = f
+}
+
+// SetLogLevelHandler sets the handler of the log level, which is changed by
+// the admin API; without it, the log level can not be changed.
+func (nr *NodeRunner) SetLogLevelHandler(h *common.LevelHandler) {
+	nr.logLevelHandler = h
+}
+
+// SetShutdownFunc sets the function to shut down the node gracefully, which
+// is called by the admin API; by default, it is `Stop()`.
+func (nr *NodeRunner) SetShutdownFunc(f func()) {
+	nr.shutdownFunc = f
+}
+
+func (nr *NodeRunner) shutdown() {
+	nr.log.Info("shutting down")
+	nr.shutdownFunc()
+}
+
+// PauseProposing stops proposing the ballot when this node is the proposer;
+// the other validators expire the round of this node. It returns `false` if
+// already paused.
+func (nr *NodeRunner) PauseProposing() bool {
+	return atomic.CompareAndSwapUint32(&nr.proposingPaused, 0, 1)
+}
+
+// ResumeProposing resumes proposing; it returns `false` if not paused.
+func (nr *NodeRunner) ResumeProposing() bool {
+	return atomic.CompareAndSwapUint32(&nr.proposingPaused, 1, 0)
+}
+
+func (nr *NodeRunner) ProposingPaused() bool {
+	return atomic.LoadUint32(&nr.proposingPaused) == 1
+}
+
+func (nr *NodeRunner) SetProposeBallotFunc(f ProposeBallotFunc) {
+	nr.proposeBallotFunc = f
+}
+
 func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
+	return nr.proposeBallotFunc(nr, round)
+}
+
+// DefaultProposeBallot proposes the valid transactions in `TransactionPool`.
+func DefaultProposeBallot(nr *NodeRunner, round uint64) (ballot.Ballot, error) {
+	basis := nr.ProposedVotingBasis(round)
+	nr.log.Debug("new round proposed", "block-basis", basis)
+
+	validTransactions, err := nr.ProposedTransactions(basis)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	blt, err := nr.MakeProposedBallot(basis, validTransactions)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+	blt.Sign(nr.localNode.Keypair(), nr.Conf.NetworkID)
+
+	nr.log.Debug(
+		"new ballot created",
+		"ballot", blt.GetHash(),
+		"basis", basis,
+		"valid-transactions", len(validTransactions),
+		"transactionpool", nr.TransactionPool.Len(),
+	)
+
+	nr.BroadcastBallot(*blt)
+
+	return *blt, nil
+}
+
+func (nr *NodeRunner) ProposedVotingBasis(round uint64) voting.Basis {
 	b := nr.consensus.LatestBlock()
-	basis := voting.Basis{
+	return voting.Basis{
 		Round:     round,
 		Height:    b.Height,
 		BlockHash: b.Hash,
 		TotalTxs:  b.TotalTxs,
 		TotalOps:  b.TotalOps,
 	}
+}
 
+// ProposedTransactions collects the valid transactions from `TransactionPool`
+// within `OpsInBallotLimit`; the invalid transactions are removed from the
+// pool.
+func (nr *NodeRunner) ProposedTransactions(basis voting.Basis) ([]transaction.Transaction, error) {
 	// collect incoming transactions from `Pool`
 	availableTransactions := nr.TransactionPool.AvailableTransactions(nr.Conf.TxsLimit)
-	nr.log.Debug("new round proposed", "block-basis", basis)
 
 	transactionsChecker := &BallotTransactionChecker{
 		DefaultChecker:        common.DefaultChecker{Funcs: NewBallotTransactionCheckerFuncs},
@@ -675,23 +893,21 @@ func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
 	}
 
 	var validTransactions []transaction.Transaction
-	var validTransactionHashes []string
 	var ops int
 	for _, hash := range transactionsChecker.ValidTransactions {
 		var tx transaction.Transaction
 		var found bool
 		var err error
 		if tx, found, err = transactionsChecker.transactionCache.Get(hash); err != nil {
-			return ballot.Ballot{}, err
+			return nil, err
 		} else if !found {
-			return ballot.Ballot{}, errors.TransactionNotFound
+			return nil, errors.TransactionNotFound
 		}
 
 		if ops+len(tx.B.Operations) > nr.Conf.OpsInBallotLimit {
 			continue
 		}
 
-		validTransactionHashes = append(validTransactionHashes, hash)
 		validTransactions = append(validTransactions, tx)
 
 		ops += len(tx.B.Operations)
@@ -700,39 +916,39 @@ func (nr *NodeRunner) proposeNewBallot(round uint64) (ballot.Ballot, error) {
 		}
 	}
 
-	proposerAddr := nr.consensus.SelectProposer(b.Height, round)
-	blt := ballot.NewBallot(nr.localNode.Address(), proposerAddr, basis, validTransactionHashes)
+	return validTransactions, nil
+}
+
+// MakeProposedBallot makes the INIT ballot with the proposer transaction of
+// the given transactions; the ballot is not signed yet.
+func (nr *NodeRunner) MakeProposedBallot(basis voting.Basis, transactions []transaction.Transaction) (*ballot.Ballot, error) {
+	var hashes []string
+	for _, tx := range transactions {
+		hashes = append(hashes, tx.GetHash())
+	}
+
+	proposerAddr := nr.consensus.SelectProposer(basis.Height, basis.Round)
+	blt := ballot.NewBallot(nr.localNode.Address(), proposerAddr, basis, hashes)
 	blt.SetVote(ballot.StateINIT, voting.YES)
 
-	opc, err := ballot.NewCollectTxFeeFromBallot(*blt, nr.Conf.CommonAccountAddress, validTransactions...)
+	opc, err := ballot.NewCollectTxFeeFromBallot(*blt, nr.Conf.CommonAccountAddress, transactions...)
 	if err != nil {
-		return ballot.Ballot{}, err
+		return nil, err
 	}
 
 	opi, err := ballot.NewInflationFromBallot(*blt, nr.Conf.CommonAccountAddress, nr.Conf.InitialBalance)
 	if err != nil {
-		return ballot.Ballot{}, err
+		return nil, err
 	}
 
 	ptx, err := ballot.NewProposerTransactionFromBallot(*blt, opc, opi)
 	if err != nil {
-		return ballot.Ballot{}, err
+		return nil, err
 	}
 
 	blt.SetProposerTransaction(ptx)
-	blt.Sign(nr.localNode.Keypair(), nr.Conf.NetworkID)
 
-	nr.log.Debug(
-		"new ballot created",
-		"ballot", blt.GetHash(),
-		"basis", basis,
-		"valid-transactions", len(validTransactions),
-		"transactionpool", nr.TransactionPool.Len(),
-	)
-
-	nr.BroadcastBallot(*blt)
-
-	return *blt, nil
+	return blt, nil
 }
 
 func (nr *NodeRunner) NodeInfo() node.NodeInfo {
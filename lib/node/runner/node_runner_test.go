@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 
 	"boscoin.io/sebak/lib/ballot"
@@ -16,6 +18,7 @@ import (
 	"boscoin.io/sebak/lib/consensus"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner/api"
 	"boscoin.io/sebak/lib/transaction"
 	"boscoin.io/sebak/lib/voting"
 )
@@ -205,6 +208,44 @@ func TestCreateNodeRunner(t *testing.T) {
 	require.Equal(t, 3, len(nodeRunners))
 }
 
+// Check that every route of the API is in the OpenAPI document.
+func TestNodeRunnerOpenAPIRoutes(t *testing.T) {
+	nodeRunners, _ := createTestNodeRunnersHTTP2Network(1)
+	nr := nodeRunners[0]
+	defer nr.Storage().Close()
+
+	nr.Ready()
+
+	doc := api.OpenAPIDocument()
+
+	var checked int
+	err := nr.Network().(*network.HTTP2Network).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		if path != api.GetNodeInfoPattern && !strings.HasPrefix(path, network.UrlPathPrefixAPI+"/") {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			if method == "OPTIONS" {
+				continue
+			}
+			require.True(t, doc.HasOperation(path, method), "%s %s is not in the OpenAPI document", method, path)
+			checked++
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.True(t, checked > 0)
+}
+
 /*
 func TestNodeRunnerSaveBlock(t *testing.T) {
 	numberOfNodes := 4
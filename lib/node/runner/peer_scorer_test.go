@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/voting"
+)
+
+func receiveBallotFromPeer(nr *NodeRunner, b *ballot.Ballot, peer string) error {
+	data, err := b.Serialize()
+	if err != nil {
+		panic(err)
+	}
+
+	return nr.handleBallotMessage(common.NetworkMessage{Type: common.BallotMessage, Data: data, Peer: peer})
+}
+
+func TestPeerScoreByBallot(t *testing.T) {
+	nr, localNode := MakeNodeRunner()
+	conf := common.NewTestConfig()
+
+	latestBlock := nr.Consensus().LatestBlock()
+	basis := voting.Basis{
+		Round:     0,
+		Height:    latestBlock.Height,
+		BlockHash: latestBlock.Hash,
+		TotalTxs:  latestBlock.TotalTxs,
+		TotalOps:  latestBlock.TotalOps,
+	}
+
+	peer := "1.2.3.4"
+
+	{ // from unknown validator
+		unknownNode := node.NewTestLocalNode0()
+		b := GenerateEmptyTxBallot(localNode, basis, ballot.StateSIGN, unknownNode, conf)
+
+		err := receiveBallotFromPeer(nr, b, peer)
+		require.Equal(t, errors.BallotFromUnknownValidator, err)
+
+		peers := nr.PeerScorer().Peers()
+		require.Equal(t, 1, len(peers))
+		require.Equal(t, peer, peers[0].Peer)
+		require.Equal(t, uint64(1), peers[0].Failures[network.PeerFailureUnknownValidator])
+	}
+
+	{ // bad signature
+		b := GenerateEmptyTxBallot(localNode, basis, ballot.StateSIGN, localNode, conf)
+		b.H.Signature = b.H.ProposerSignature[:len(b.H.ProposerSignature)-2]
+
+		err := receiveBallotFromPeer(nr, b, peer)
+		require.Error(t, err)
+
+		peers := nr.PeerScorer().Peers()
+		require.Equal(t, uint64(1), peers[0].Failures[network.PeerFailureBadSignature])
+	}
+
+	{ // ballot made by local node does not have peer
+		unknownNode := node.NewTestLocalNode0()
+		b := GenerateEmptyTxBallot(localNode, basis, ballot.StateSIGN, unknownNode, conf)
+
+		err := ReceiveBallot(nr, b)
+		require.Equal(t, errors.BallotFromUnknownValidator, err)
+		require.Equal(t, 1, len(nr.PeerScorer().Peers()))
+	}
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"time"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// PruneCheckInterval is the interval to check the blocks to be pruned.
+var PruneCheckInterval = 10 * time.Second
+
+// Pruner removes the transactions and operations of the blocks, which are
+// older than `keepBlocks` from the latest block, in background. The blocks
+// are pruned after `SavingBlockOperations` checks them.
+type Pruner struct {
+	st         *storage.LevelDBBackend
+	keepBlocks uint64
+	log        logging.Logger
+
+	stop chan struct{}
+}
+
+func NewPruner(st *storage.LevelDBBackend, keepBlocks uint64, logger logging.Logger) *Pruner {
+	if logger == nil {
+		logger = log
+	}
+
+	return &Pruner{
+		st:         st,
+		keepBlocks: keepBlocks,
+		log:        logger.New(logging.Ctx{"m": "Pruner"}),
+		stop:       make(chan struct{}),
+	}
+}
+
+func (p *Pruner) Start() {
+	if p.keepBlocks < 1 {
+		return
+	}
+
+	p.log.Debug("start pruning", "keep-blocks", p.keepBlocks)
+
+	ticker := time.NewTicker(PruneCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Prune(); err != nil {
+			p.log.Error("failed to prune", "error", err)
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pruner) Stop() {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+}
+
+// Prune prunes the blocks until the latest height - `keepBlocks`.
+func (p *Pruner) Prune() (err error) {
+	if p.keepBlocks < 1 {
+		return
+	}
+
+	latest := block.GetLatestBlock(p.st)
+	if latest.Height <= p.keepBlocks {
+		return
+	}
+	until := latest.Height - p.keepBlocks
+
+	var checked uint64
+	if err = p.st.Get(getCheckedBlockKey(), &checked); err == errors.StorageRecordDoesNotExist {
+		return nil
+	} else if err != nil {
+		return
+	}
+	if until > checked {
+		until = checked
+	}
+
+	var pruned uint64
+	if pruned, err = block.GetPrunedBlockHeight(p.st); err != nil {
+		return
+	}
+	if pruned < common.GenesisBlockHeight {
+		pruned = common.GenesisBlockHeight
+	}
+
+	for height := pruned + 1; height <= until; height++ {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		if err = p.pruneBlock(height); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Pruner) pruneBlock(height uint64) (err error) {
+	var blk block.Block
+	if blk, err = block.GetBlockByHeight(p.st, height); err != nil {
+		return
+	}
+
+	var bs *storage.LevelDBBackend
+	if bs, err = p.st.OpenBatch(); err != nil {
+		return
+	}
+
+	var pruned int
+	if pruned, err = block.PruneBlock(bs, blk); err != nil {
+		bs.Discard()
+		return
+	}
+	if err = bs.Commit(); err != nil {
+		bs.Discard()
+		return
+	}
+
+	p.log.Debug("block pruned", "height", height, "transactions", pruned)
+
+	return
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestPruner(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	var hashes []string
+	for i := 0; i < 4; i++ {
+		_, tx := transaction.TestMakeTransaction(conf.NetworkID, 1)
+		blk := block.TestMakeNewBlockWithPrevBlock(block.GetLatestBlock(st), []string{tx.GetHash()})
+		blk.MustSave(st)
+		bt := block.NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+		bt.MustSave(st)
+		_, err := block.SaveTransactionPool(st, tx)
+		require.NoError(t, err)
+		hashes = append(hashes, tx.GetHash())
+	}
+
+	p := NewPruner(st, 1, nil)
+
+	{ // not checked by `SavingBlockOperations` yet
+		require.NoError(t, p.Prune())
+		pruned, err := block.GetPrunedBlockHeight(st)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), pruned)
+	}
+
+	require.NoError(t, SetCheckedBlockHeight(st, 3))
+	require.NoError(t, p.Prune())
+
+	pruned, err := block.GetPrunedBlockHeight(st)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), pruned)
+
+	for _, hash := range hashes[:2] {
+		_, err = block.GetBlockTransaction(st, hash)
+		require.Equal(t, errors.DataPruned, err)
+	}
+	for _, hash := range hashes[2:] {
+		_, err = block.GetBlockTransaction(st, hash)
+		require.NoError(t, err)
+	}
+
+	{ // the next blocks are pruned after checked
+		require.NoError(t, SetCheckedBlockHeight(st, 5))
+		require.NoError(t, p.Prune())
+
+		pruned, err := block.GetPrunedBlockHeight(st)
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), pruned)
+
+		_, err = block.GetBlockTransaction(st, hashes[2])
+		require.Equal(t, errors.DataPruned, err)
+		_, err = block.GetBlockTransaction(st, hashes[3])
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,286 @@
+package simulation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// ByzantineBehavior is the way of the validator, which does not follow the
+// protocol. The byzantine validators are only for testing how the honest
+// validators react to them.
+//
+// The equivocation, which proposes the different ballots to the different
+// validators in the same round, is out of scope; the YES votes for the
+// different proposals of the same proposer are counted together by
+// `consensus.RoundVote`, so the honest validators can not be safe against
+// it.
+type ByzantineBehavior string
+
+const (
+	// ByzantineUnknownTransactions proposes the transactions, which the
+	// proposer does not have, so nobody can get them.
+	ByzantineUnknownTransactions ByzantineBehavior = "unknown-transactions"
+
+	// ByzantineYesToInvalid proposes the invalid transactions and votes YES
+	// to every INIT ballot without checking the transactions.
+	ByzantineYesToInvalid ByzantineBehavior = "yes-to-invalid"
+
+	// ByzantineWrongProposedTime proposes the ballot with the proposed time,
+	// which is too ahead.
+	ByzantineWrongProposedTime ByzantineBehavior = "wrong-proposed-time"
+)
+
+var ByzantineBehaviors = []ByzantineBehavior{
+	ByzantineUnknownTransactions,
+	ByzantineYesToInvalid,
+	ByzantineWrongProposedTime,
+}
+
+func ParseByzantineBehavior(s string) (ByzantineBehavior, error) {
+	for _, b := range ByzantineBehaviors {
+		if string(b) == s {
+			return b, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown byzantine behavior: %q", s)
+}
+
+// Byzantine makes the node behave as the byzantine validator.
+type Byzantine struct {
+	Node     int               `json:"node"`
+	Behavior ByzantineBehavior `json:"behavior"`
+}
+
+// ParseByzantine parses the byzantine node from the string,
+// `<behavior>:<node index>`, for example, `yes-to-invalid:1`.
+func ParseByzantine(s string) (b Byzantine, err error) {
+	s = strings.TrimSpace(s)
+	i := strings.Index(s, ":")
+	if i < 0 {
+		err = fmt.Errorf("node of byzantine is missing: %q", s)
+		return
+	}
+
+	if b.Behavior, err = ParseByzantineBehavior(s[:i]); err != nil {
+		return
+	}
+	if b.Node, err = strconv.Atoi(strings.TrimSpace(s[i+1:])); err != nil {
+		return
+	}
+
+	return
+}
+
+func (b Byzantine) String() string {
+	return fmt.Sprintf("%s:%d", b.Behavior, b.Node)
+}
+
+// SetByzantine makes the `NodeRunner` behave as the byzantine validator by
+// replacing the ballot checkers and the way of proposing ballot.
+func SetByzantine(nr *runner.NodeRunner, behavior ByzantineBehavior) error {
+	switch behavior {
+	case ByzantineUnknownTransactions:
+		nr.SetProposeBallotFunc(ProposeBallotWithUnknownTransactions)
+	case ByzantineYesToInvalid:
+		nr.SetProposeBallotFunc(ProposeBallotWithInvalidTransactions)
+		nr.SetHandleBaseBallotCheckerFuncs(ByzantineHandleBaseBallotCheckerFuncs...)
+	case ByzantineWrongProposedTime:
+		nr.SetProposeBallotFunc(ProposeBallotWithWrongProposedTime)
+	default:
+		return fmt.Errorf("unknown byzantine behavior: %q", behavior)
+	}
+
+	nr.Log().Warn("node runner is byzantine", "behavior", behavior)
+
+	return nil
+}
+
+// ByzantineHandleBaseBallotCheckerFuncs handles the INIT ballot by
+// `ByzantineHandleINITBallotCheckerFuncs` after the base checkers.
+var ByzantineHandleBaseBallotCheckerFuncs = []common.CheckerFunc{
+	runner.BallotUnmarshal,
+	runner.BallotNotFromKnownValidators,
+	runner.BallotCheckSYNC,
+	runner.BallotCheckBasis,
+	HandleINITBallotByzantine,
+}
+
+// ByzantineHandleINITBallotCheckerFuncs votes YES to every INIT ballot.
+var ByzantineHandleINITBallotCheckerFuncs = []common.CheckerFunc{
+	runner.BallotAlreadyVoted,
+	runner.BallotVote,
+	runner.BallotIsSameProposer,
+	INITBallotVoteYES,
+	runner.SIGNBallotBroadcast,
+	runner.TransitStateToSIGN,
+}
+
+// HandleINITBallotByzantine runs `ByzantineHandleINITBallotCheckerFuncs` for
+// the INIT ballot and stops, so the default INIT checkers of `NodeRunner` are
+// not run.
+func HandleINITBallotByzantine(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*runner.BallotChecker)
+	if checker.Ballot.State() != ballot.StateINIT {
+		return
+	}
+
+	initChecker := &runner.BallotChecker{
+		DefaultChecker:     common.DefaultChecker{Funcs: ByzantineHandleINITBallotCheckerFuncs},
+		NodeRunner:         checker.NodeRunner,
+		Conf:               checker.Conf,
+		LocalNode:          checker.LocalNode,
+		Ballot:             checker.Ballot,
+		VotingHole:         checker.VotingHole,
+		IsNew:              checker.IsNew,
+		IsMine:             checker.IsMine,
+		Log:                checker.Log,
+		LatestBlockSources: checker.LatestBlockSources,
+	}
+	if err = common.RunChecker(initChecker, nil); err != nil {
+		if _, ok := err.(common.CheckerStop); !ok {
+			return
+		}
+	}
+
+	return runner.NewCheckerStopCloseConsensus(checker, "INIT ballot is handled by byzantine node")
+}
+
+// INITBallotVoteYES votes YES without validating the transactions of ballot.
+func INITBallotVoteYES(c common.Checker, args ...interface{}) (err error) {
+	checker := c.(*runner.BallotChecker)
+	checker.VotingHole = voting.YES
+
+	return
+}
+
+// makeByzantineTransaction makes the payment transaction from the account,
+// which does not exist.
+func makeByzantineTransaction(nr *runner.NodeRunner) (tx transaction.Transaction, err error) {
+	kp := keypair.Random()
+	body := operation.NewPayment(nr.Conf.CommonAccountAddress, common.Amount(1))
+
+	var op operation.Operation
+	if op, err = operation.NewOperation(body); err != nil {
+		return
+	}
+	if tx, err = transaction.NewTransaction(kp.Address(), 0, op); err != nil {
+		return
+	}
+	tx.Sign(kp, nr.Conf.NetworkID)
+
+	return
+}
+
+// ProposeBallotWithUnknownTransactions proposes the valid transactions with
+// the transaction, which is not in `TransactionPool` and storage.
+func ProposeBallotWithUnknownTransactions(nr *runner.NodeRunner, round uint64) (ballot.Ballot, error) {
+	basis := nr.ProposedVotingBasis(round)
+
+	transactions, err := nr.ProposedTransactions(basis)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	unknown, err := makeByzantineTransaction(nr)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+	transactions = append(transactions, unknown)
+
+	blt, err := nr.MakeProposedBallot(basis, transactions)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+	blt.Sign(nr.Node().Keypair(), nr.Conf.NetworkID)
+
+	nr.BroadcastBallot(*blt)
+
+	return *blt, nil
+}
+
+// ProposeBallotWithInvalidTransactions proposes every transaction in
+// `TransactionPool` without checking them, with the transaction from the
+// account, which does not exist. The invalid transaction is kept in
+// `TransactionPool`, so the other validators can get it.
+func ProposeBallotWithInvalidTransactions(nr *runner.NodeRunner, round uint64) (ballot.Ballot, error) {
+	basis := nr.ProposedVotingBasis(round)
+
+	invalid, err := makeByzantineTransaction(nr)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+	if err = nr.TransactionPool.AddFromNode(invalid); err != nil {
+		return ballot.Ballot{}, err
+	}
+	if _, err = block.SaveTransactionPool(nr.Storage(), invalid); err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	var transactions []transaction.Transaction
+	for _, hash := range nr.TransactionPool.AvailableTransactions(nr.Conf.TxsLimit) {
+		if tx, found := nr.TransactionPool.Get(hash); found {
+			transactions = append(transactions, tx)
+		}
+	}
+
+	blt, err := nr.MakeProposedBallot(basis, transactions)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+	blt.Sign(nr.Node().Keypair(), nr.Conf.NetworkID)
+
+	nr.BroadcastBallot(*blt)
+
+	return *blt, nil
+}
+
+// ProposeBallotWithWrongProposedTime proposes the valid transactions, but the
+// proposed time is ahead of the allowed duration.
+func ProposeBallotWithWrongProposedTime(nr *runner.NodeRunner, round uint64) (ballot.Ballot, error) {
+	basis := nr.ProposedVotingBasis(round)
+
+	transactions, err := nr.ProposedTransactions(basis)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+
+	blt, err := nr.MakeProposedBallot(basis, transactions)
+	if err != nil {
+		return ballot.Ballot{}, err
+	}
+	blt.Sign(nr.Node().Keypair(), nr.Conf.NetworkID)
+
+	proposed := common.Now().Add(2 * common.BallotConfirmedTimeAllowDuration)
+	signWithProposedTime(blt, nr.Node().Keypair(), nr.Conf.NetworkID, proposed)
+
+	nr.BroadcastBallot(*blt)
+
+	return *blt, nil
+}
+
+// signWithProposedTime signs the ballot again with the given proposed time
+// instead of now.
+func signWithProposedTime(b *ballot.Ballot, kp keypair.KP, networkID []byte, proposed time.Time) {
+	b.B.Proposed.Confirmed = common.FormatISO8601(proposed)
+	hash := common.MustMakeObjectHash(b.B.Proposed)
+	signature, _ := keypair.MakeSignature(kp, networkID, string(hash))
+	b.H.ProposerSignature = base58.Encode(signature)
+
+	b.H.Hash = b.B.MakeHashString()
+	signature, _ = keypair.MakeSignature(kp, networkID, b.H.Hash)
+	b.H.Signature = base58.Encode(signature)
+}
@@ -0,0 +1,112 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/voting"
+)
+
+type fixedSelector string
+
+func (s fixedSelector) Select(_ uint64, _ uint64) string {
+	return string(s)
+}
+
+func TestParseByzantineBehavior(t *testing.T) {
+	for _, b := range ByzantineBehaviors {
+		parsed, err := ParseByzantineBehavior(string(b))
+		require.NoError(t, err)
+		require.Equal(t, b, parsed)
+	}
+
+	_, err := ParseByzantineBehavior("honest")
+	require.Error(t, err)
+
+	nr, _, _ := runner.NewTestNodeRunner(2, common.NewTestConfig())
+	require.Error(t, SetByzantine(nr, ByzantineBehavior("honest")))
+}
+
+// TestByzantineYesToInvalid checks the byzantine node votes YES to the INIT
+// ballot with the transaction, which the honest node votes NO.
+func TestByzantineYesToInvalid(t *testing.T) {
+	for _, byzantine := range []bool{false, true} {
+		conf := common.NewTestConfig()
+		nr, nodes, cm := runner.NewTestNodeRunner(4, conf)
+		nr.Consensus().SetProposerSelector(fixedSelector(nodes[1].Address()))
+		if byzantine {
+			require.NoError(t, SetByzantine(nr, ByzantineYesToInvalid))
+		}
+
+		// the transaction is not in the pool and the proposer can not be
+		// reached, so the honest node can not validate it
+		invalid, err := makeByzantineTransaction(nr)
+		require.NoError(t, err)
+
+		// INIT ballot from the other proposer
+		ballotINIT, err := nr.MakeProposedBallot(nr.ProposedVotingBasis(0), []transaction.Transaction{invalid})
+		require.NoError(t, err)
+		ballotINIT.Sign(nodes[1].Keypair(), conf.NetworkID)
+		err = runner.ReceiveBallot(nr, ballotINIT)
+		if byzantine {
+			// the default INIT checkers are not run
+			_, ok := err.(common.CheckerStop)
+			require.True(t, ok)
+		} else {
+			require.NoError(t, err)
+		}
+
+		messages := cm.Messages()
+		require.Equal(t, 1, len(messages))
+		ballotSIGN := messages[0].(ballot.Ballot)
+		require.Equal(t, ballot.StateSIGN, ballotSIGN.State())
+		if byzantine {
+			require.Equal(t, voting.YES, ballotSIGN.Vote())
+		} else {
+			require.Equal(t, voting.NO, ballotSIGN.Vote())
+		}
+	}
+}
+
+func TestProposeBallotWithUnknownTransactions(t *testing.T) {
+	nr, _, cm := runner.NewTestNodeRunner(4, common.NewTestConfig())
+	require.NoError(t, SetByzantine(nr, ByzantineUnknownTransactions))
+
+	tx, _ := runner.GetTransaction()
+	require.NoError(t, nr.TransactionPool.Add(tx))
+
+	b, err := ProposeBallotWithUnknownTransactions(nr, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(b.Transactions()))
+
+	// the proposer does not have the unknown transaction
+	unknown := b.Transactions()[1]
+	require.False(t, nr.TransactionPool.Has(unknown))
+	require.NoError(t, b.IsWellFormed(nr.Conf))
+
+	messages := cm.Messages()
+	require.Equal(t, 1, len(messages))
+	require.Equal(t, b.GetHash(), messages[0].GetHash())
+}
+
+func TestProposeBallotWithWrongProposedTime(t *testing.T) {
+	nr, _, cm := runner.NewTestNodeRunner(4, common.NewTestConfig())
+	require.NoError(t, SetByzantine(nr, ByzantineWrongProposedTime))
+
+	b, err := ProposeBallotWithWrongProposedTime(nr, 0)
+	require.NoError(t, err)
+
+	// the signatures are valid, but the proposed time is wrong
+	require.NoError(t, b.VerifySource(nr.Conf.NetworkID))
+	require.NoError(t, b.VerifyProposer(nr.Conf.NetworkID))
+	require.Equal(t, errors.MessageHasIncorrectTime, b.IsWellFormed(nr.Conf))
+
+	messages := cm.Messages()
+	require.Equal(t, 1, len(messages))
+}
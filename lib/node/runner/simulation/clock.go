@@ -0,0 +1,160 @@
+package simulation
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"boscoin.io/sebak/lib/common"
+)
+
+// VirtualClock is the `common.Clock`, which does not move by itself; the
+// time moves only by `AdvanceTo`, and then the timers, whose deadline is
+// reached, are fired. Like the timer of go 1.23 or later, the fired time,
+// which is not received, is dropped when the timer is reset or stopped.
+type VirtualClock struct {
+	sync.Mutex
+
+	now      time.Time
+	timers   map[*virtualTimer]struct{}
+	fired    map[*virtualTimer]struct{} // fired, but may not be received
+	activity *uint64                    // increased when the timers are changed
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{
+		now:      start,
+		timers:   map[*virtualTimer]struct{}{},
+		fired:    map[*virtualTimer]struct{}{},
+		activity: new(uint64),
+	}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.now
+}
+
+func (c *VirtualClock) NewTimer(d time.Duration) common.Timer {
+	c.Lock()
+	defer c.Unlock()
+
+	t := &virtualTimer{
+		clock:    c,
+		c:        make(chan time.Time, 1),
+		deadline: c.now.Add(d),
+	}
+	c.timers[t] = struct{}{}
+	atomic.AddUint64(c.activity, 1)
+
+	return t
+}
+
+// Next returns the earliest deadline of the active timers.
+func (c *VirtualClock) Next() (next time.Time, found bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	for t := range c.timers {
+		if !found || t.deadline.Before(next) {
+			next = t.deadline
+			found = true
+		}
+	}
+
+	return
+}
+
+// AdvanceTo moves the time to `to` and fires the timers, whose deadline is
+// not after `to`. The time does not go back.
+func (c *VirtualClock) AdvanceTo(to time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	if to.After(c.now) {
+		c.now = to
+	}
+
+	for t := range c.timers {
+		if t.deadline.After(c.now) {
+			continue
+		}
+		delete(c.timers, t)
+		select {
+		case t.c <- c.now:
+			c.fired[t] = struct{}{}
+		default:
+		}
+		atomic.AddUint64(c.activity, 1)
+	}
+}
+
+// Pending returns the number of the fired timers, whose time is not received
+// yet.
+func (c *VirtualClock) Pending() (n int) {
+	c.Lock()
+	defer c.Unlock()
+
+	for t := range c.fired {
+		if len(t.c) < 1 {
+			delete(c.fired, t)
+			continue
+		}
+		n++
+	}
+
+	return
+}
+
+// Activity is increased whenever the timers are created, reset, stopped or
+// fired; it is used to know the nodes are idle.
+func (c *VirtualClock) Activity() uint64 {
+	return atomic.LoadUint64(c.activity)
+}
+
+type virtualTimer struct {
+	clock    *VirtualClock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *virtualTimer) Reset(d time.Duration) bool {
+	t.clock.Lock()
+	defer t.clock.Unlock()
+
+	_, active := t.clock.timers[t]
+	t.drain()
+	t.deadline = t.clock.now.Add(d)
+	t.clock.timers[t] = struct{}{}
+	atomic.AddUint64(t.clock.activity, 1)
+
+	return active
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.Lock()
+	defer t.clock.Unlock()
+
+	_, active := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	t.drain()
+	atomic.AddUint64(t.clock.activity, 1)
+
+	return active
+}
+
+// drain drops the fired time, which is not received; it must be called with
+// the lock of clock.
+func (t *virtualTimer) drain() {
+	select {
+	case <-t.c:
+	default:
+	}
+	delete(t.clock.fired, t)
+}
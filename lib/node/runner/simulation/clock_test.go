@@ -0,0 +1,82 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualClock(t *testing.T) {
+	clock := NewVirtualClock(Epoch)
+	require.Equal(t, Epoch, clock.Now())
+
+	_, found := clock.Next()
+	require.False(t, found)
+
+	t1 := clock.NewTimer(time.Second)
+	t2 := clock.NewTimer(2 * time.Second)
+
+	next, found := clock.Next()
+	require.True(t, found)
+	require.Equal(t, Epoch.Add(time.Second), next)
+
+	clock.AdvanceTo(next)
+	require.Equal(t, next, clock.Now())
+	select {
+	case fired := <-t1.C():
+		require.Equal(t, next, fired)
+	default:
+		require.Fail(t, "timer must be fired")
+	}
+	select {
+	case <-t2.C():
+		require.Fail(t, "timer must not be fired")
+	default:
+	}
+
+	// stopped timer is not fired
+	require.True(t, t2.Stop())
+	_, found = clock.Next()
+	require.False(t, found)
+
+	// reset timer is fired at the new deadline
+	t1.Reset(3 * time.Second)
+	next, _ = clock.Next()
+	require.Equal(t, Epoch.Add(4*time.Second), next)
+
+	clock.AdvanceTo(Epoch.Add(10 * time.Second))
+	select {
+	case <-t1.C():
+	default:
+		require.Fail(t, "timer must be fired")
+	}
+}
+
+func TestVirtualClockPending(t *testing.T) {
+	clock := NewVirtualClock(Epoch)
+
+	t1 := clock.NewTimer(time.Second)
+	t2 := clock.NewTimer(time.Second)
+	t3 := clock.NewTimer(time.Second)
+	require.Equal(t, 0, clock.Pending())
+
+	clock.AdvanceTo(Epoch.Add(time.Second))
+	require.Equal(t, 3, clock.Pending())
+
+	// received
+	<-t1.C()
+	require.Equal(t, 2, clock.Pending())
+
+	// the fired time is dropped by reset and stop
+	t2.Reset(time.Second)
+	require.False(t, t3.Stop())
+	require.Equal(t, 0, clock.Pending())
+	select {
+	case <-t2.C():
+		require.Fail(t, "fired time must be dropped by reset")
+	case <-t3.C():
+		require.Fail(t, "fired time must be dropped by stop")
+	default:
+	}
+}
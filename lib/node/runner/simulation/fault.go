@@ -0,0 +1,159 @@
+package simulation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FaultKind string
+
+const (
+	// FaultCrash stops the nodes from sending and receiving messages.
+	FaultCrash FaultKind = "crash"
+
+	// FaultPartition separates the nodes from the other nodes.
+	FaultPartition FaultKind = "partition"
+
+	// FaultDrop drops the messages in the given rate.
+	FaultDrop FaultKind = "drop"
+
+	// FaultDelay delays the messages additionally.
+	FaultDelay FaultKind = "delay"
+)
+
+// Fault is applied to the messages between nodes from `Start` until `End`
+// of simulation time. If `End` is 0, it lasts until the end of simulation.
+type Fault struct {
+	Kind  FaultKind     `json:"kind"`
+	Nodes []int         `json:"nodes,omitempty"` // index of nodes; for `FaultDrop` and `FaultDelay`, empty means all nodes
+	Rate  float64       `json:"rate,omitempty"`  // for `FaultDrop`
+	Delay time.Duration `json:"delay,omitempty"` // for `FaultDelay`
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end,omitempty"`
+}
+
+// ParseFault parses the fault from the string,
+// `<kind>[:<argument>][@<start>[-<end>]]`. The argument is the comma
+// separated node indices for `crash` and `partition`, the rate for `drop`
+// and the duration for `delay`, for example,
+//
+//	crash:1@10s-40s
+//	partition:0,1@1m
+//	drop:0.1
+//	delay:500ms@20s-30s
+func ParseFault(s string) (f Fault, err error) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "@"); i >= 0 {
+		period := s[i+1:]
+		s = s[:i]
+
+		start := period
+		var end string
+		if j := strings.Index(period, "-"); j >= 0 {
+			start, end = period[:j], period[j+1:]
+		}
+		if f.Start, err = time.ParseDuration(start); err != nil {
+			return
+		}
+		if len(end) > 0 {
+			if f.End, err = time.ParseDuration(end); err != nil {
+				return
+			}
+			if f.End <= f.Start {
+				err = fmt.Errorf("end of fault must be after start: %q", period)
+				return
+			}
+		}
+	}
+
+	var argument string
+	if i := strings.Index(s, ":"); i >= 0 {
+		s, argument = s[:i], s[i+1:]
+	}
+
+	f.Kind = FaultKind(s)
+	switch f.Kind {
+	case FaultCrash, FaultPartition:
+		if len(argument) < 1 {
+			err = fmt.Errorf("nodes of %s fault are missing", f.Kind)
+			return
+		}
+		for _, n := range strings.Split(argument, ",") {
+			var i int
+			if i, err = strconv.Atoi(strings.TrimSpace(n)); err != nil {
+				return
+			}
+			f.Nodes = append(f.Nodes, i)
+		}
+	case FaultDrop:
+		if f.Rate, err = strconv.ParseFloat(argument, 64); err != nil {
+			return
+		}
+		if f.Rate < 0 || f.Rate > 1 {
+			err = fmt.Errorf("rate of drop fault must be in [0, 1]: %v", f.Rate)
+			return
+		}
+	case FaultDelay:
+		if f.Delay, err = time.ParseDuration(argument); err != nil {
+			return
+		}
+	default:
+		err = fmt.Errorf("unknown fault: %q", s)
+		return
+	}
+
+	return
+}
+
+func (f Fault) String() string {
+	var argument string
+	switch f.Kind {
+	case FaultCrash, FaultPartition:
+		var nodes []string
+		for _, n := range f.Nodes {
+			nodes = append(nodes, strconv.Itoa(n))
+		}
+		argument = strings.Join(nodes, ",")
+	case FaultDrop:
+		argument = strconv.FormatFloat(f.Rate, 'f', -1, 64)
+	case FaultDelay:
+		argument = f.Delay.String()
+	}
+
+	s := fmt.Sprintf("%s:%s@%s", f.Kind, argument, f.Start)
+	if f.End > 0 {
+		s += "-" + f.End.String()
+	}
+
+	return s
+}
+
+// IsActive checks the fault is applied at the elapsed time of simulation.
+func (f Fault) IsActive(elapsed time.Duration) bool {
+	return elapsed >= f.Start && (f.End == 0 || elapsed < f.End)
+}
+
+func (f Fault) has(i int) bool {
+	for _, n := range f.Nodes {
+		if n == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Affects checks the fault is applied to the messages from node `from` to
+// node `to`.
+func (f Fault) Affects(from, to int) bool {
+	switch f.Kind {
+	case FaultCrash:
+		return f.has(from) || f.has(to)
+	case FaultPartition:
+		return f.has(from) != f.has(to)
+	default:
+		return len(f.Nodes) < 1 || f.has(from) || f.has(to)
+	}
+}
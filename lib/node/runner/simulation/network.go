@@ -0,0 +1,229 @@
+package simulation
+
+import (
+	"sort"
+	"time"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// connectionManager broadcasts the messages through `Simulation`, so the
+// messages are delayed or dropped by the simulated network.
+type connectionManager struct {
+	network.ConnectionManager
+
+	simulation *Simulation
+	index      int
+}
+
+func (c *connectionManager) Broadcast(message common.Message) {
+	c.simulation.broadcast(c.index, message)
+}
+
+// GetConnection returns the client, which sends the ballots and messages
+// through `Simulation`.
+func (c *connectionManager) GetConnection(address string) network.NetworkClient {
+	client := c.ConnectionManager.GetConnection(address)
+	if client == nil {
+		return nil
+	}
+
+	to := c.simulation.indexOf(address)
+	if to < 0 {
+		return client
+	}
+
+	return &networkClient{NetworkClient: client, simulation: c.simulation, from: c.index, to: to}
+}
+
+type networkClient struct {
+	network.NetworkClient
+
+	simulation *Simulation
+	from       int
+	to         int
+}
+
+func (c *networkClient) SendBallot(message interface{}) ([]byte, error) {
+	c.simulation.send(c.from, c.to, message.(common.Message))
+	return nil, nil
+}
+
+func (c *networkClient) SendMessage(message interface{}) ([]byte, error) {
+	c.simulation.send(c.from, c.to, message.(common.Message))
+	return nil, nil
+}
+
+// envelope is the message on the way to the other node.
+type envelope struct {
+	arrival time.Time
+	from    int
+	to      int
+	message common.Message
+}
+
+// broadcast puts the message from the node into the queue for every other
+// node, with latency; the message is delivered when its arrival time comes.
+func (s *Simulation) broadcast(from int, message common.Message) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.count(message)
+	for to := range s.nodes {
+		if to == from {
+			continue
+		}
+		s.enqueue(from, to, message)
+	}
+}
+
+// send puts the message from the node into the queue only for the given
+// node.
+func (s *Simulation) send(from, to int, message common.Message) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.count(message)
+	s.enqueue(from, to, message)
+}
+
+func (s *Simulation) count(message common.Message) {
+	s.activity++
+	if b, ok := message.(ballot.Ballot); ok && b.Vote() == voting.EXP {
+		s.expired[b.State()]++
+	}
+}
+
+func (s *Simulation) enqueue(from, to int, message common.Message) {
+	now := s.clock.Now()
+	elapsed := now.Sub(s.started)
+
+	arrival := now.Add(s.conf.Latency)
+	if s.conf.Jitter > 0 {
+		arrival = arrival.Add(time.Duration(s.rand.Int63n(int64(s.conf.Jitter))))
+	}
+
+	var dropped bool
+	var held time.Duration
+	for _, f := range s.conf.Faults {
+		if !f.IsActive(elapsed) || !f.Affects(from, to) {
+			continue
+		}
+
+		switch f.Kind {
+		case FaultCrash, FaultPartition:
+			// the messages to the crashed node and between the
+			// partitions are held until the fault ends, as the
+			// reconnected nodes get the running ballots from each
+			// other; the crashed node sends nothing.
+			if f.End == 0 || (f.Kind == FaultCrash && f.has(from)) {
+				dropped = true
+			} else if f.End > held {
+				held = f.End
+			}
+		case FaultDrop:
+			dropped = dropped || s.rand.Float64() < f.Rate
+		case FaultDelay:
+			arrival = arrival.Add(f.Delay)
+		}
+	}
+
+	if dropped {
+		s.dropped++
+		return
+	}
+	if held > 0 {
+		s.held++
+		if heal := s.started.Add(held + s.conf.Latency); heal.After(arrival) {
+			arrival = heal
+		}
+	}
+
+	s.queue = append(s.queue, envelope{arrival: arrival, from: from, to: to, message: message})
+	s.sent++
+}
+
+// nextArrival returns the earliest arrival time of the messages in queue.
+func (s *Simulation) nextArrival() (next time.Time, found bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, e := range s.queue {
+		if !found || e.arrival.Before(next) {
+			next = e.arrival
+			found = true
+		}
+	}
+
+	return
+}
+
+// arrived pops the messages, which arrived until now. The messages are
+// ordered by the arrival time, sender, receiver and hash, not by the order of
+// broadcast, which depends on the scheduling of goroutines.
+func (s *Simulation) arrived() (arrived []envelope) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.clock.Now()
+
+	var remains []envelope
+	for _, e := range s.queue {
+		if e.arrival.After(now) {
+			remains = append(remains, e)
+		} else {
+			arrived = append(arrived, e)
+		}
+	}
+	s.queue = remains
+
+	sort.Slice(arrived, func(i, j int) bool {
+		a, b := arrived[i], arrived[j]
+		switch {
+		case !a.arrival.Equal(b.arrival):
+			return a.arrival.Before(b.arrival)
+		case a.from != b.from:
+			return a.from < b.from
+		case a.to != b.to:
+			return a.to < b.to
+		default:
+			return a.message.GetHash() < b.message.GetHash()
+		}
+	})
+
+	return
+}
+
+// deliver sends the arrived messages to the receivers; it must not be called
+// with lock, because the receivers may broadcast the new messages while
+// handling them.
+func (s *Simulation) deliver() int {
+	arrived := s.arrived()
+	for _, e := range arrived {
+		sender := s.connectionManagers[e.from]
+		receiver := s.nodes[e.to]
+
+		// the client of the embedded connection manager sends the message
+		// directly
+		client := sender.ConnectionManager.GetConnection(receiver.Node().Address())
+		if client == nil {
+			continue
+		}
+
+		var err error
+		switch e.message.GetType() {
+		case common.BallotMessage:
+			_, err = client.SendBallot(e.message)
+		default:
+			_, err = client.SendMessage(e.message)
+		}
+		if err != nil {
+			s.log.Error("failed to deliver message", "from", e.from, "to", e.to, "error", err)
+		}
+	}
+
+	return len(arrived)
+}
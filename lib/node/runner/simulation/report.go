@@ -0,0 +1,177 @@
+package simulation
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// Report is the result of simulation.
+type Report struct {
+	Nodes           int            `json:"nodes"`
+	Threshold       int            `json:"threshold"`
+	Seed            int64          `json:"seed"`
+	Duration        time.Duration  `json:"duration"` // simulated time
+	Elapsed         time.Duration  `json:"elapsed"`  // wall clock time to run simulation
+	Faults          []string       `json:"faults,omitempty"`
+	Byzantine       []string       `json:"byzantine,omitempty"`
+	Blocks          uint64         `json:"blocks"` // new blocks of the highest node
+	BlocksPerMinute float64        `json:"blocks-per-minute"`
+	RoundsPerHeight float64        `json:"rounds-per-height"`
+	Expired         map[string]int `json:"expired"` // number of EXP ballots by ballot state
+	Submitted       int            `json:"submitted"`
+	Confirmed       uint64         `json:"confirmed"`
+	MessagesSent    int            `json:"messages-sent"`
+	MessagesDropped int            `json:"messages-dropped"`
+	MessagesHeld    int            `json:"messages-held"` // delivered after crash or partition ends
+	Agreed          bool           `json:"agreed"`        // every honest node has the same blocks until the lowest height
+	NodeReports     []NodeReport   `json:"node-reports"`
+}
+
+// NodeReport is the final state of node.
+type NodeReport struct {
+	Alias     string `json:"alias"`
+	Address   string `json:"address"`
+	Height    uint64 `json:"height"`
+	Round     uint64 `json:"round"`
+	BlockHash string `json:"block-hash"`
+	TotalTxs  uint64 `json:"total-txs"`
+	StateHash string `json:"state-hash"` // hash of every account
+	Byzantine bool   `json:"byzantine"`
+}
+
+func newNodeReport(nr *runner.NodeRunner) (r NodeReport) {
+	latest := block.GetLatestBlock(nr.Storage())
+	r = NodeReport{
+		Alias:     nr.Node().Alias(),
+		Address:   nr.Node().Address(),
+		Height:    latest.Height,
+		Round:     latest.Round,
+		BlockHash: latest.Hash,
+		TotalTxs:  latest.TotalTxs,
+	}
+
+	r.StateHash = makeStateHash(nr.Storage())
+
+	return
+}
+
+// makeStateHash makes the hash of every account, ordered by address.
+func makeStateHash(st *storage.LevelDBBackend) string {
+	var accounts []block.BlockAccount
+
+	iterFunc, closeFunc := block.GetBlockAccountsByCreated(st, storage.NewDefaultListOptions(false, nil, 0))
+	for {
+		ba, hasNext, _ := iterFunc()
+		if !hasNext {
+			break
+		}
+		accounts = append(accounts, *ba)
+	}
+	closeFunc()
+
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Address < accounts[j].Address })
+
+	return common.MustMakeObjectHashString(accounts)
+}
+
+// roundsPerHeight is the average number of rounds to make a block after
+// genesis.
+func roundsPerHeight(st *storage.LevelDBBackend, height uint64) (float64, error) {
+	if height <= common.GenesisBlockHeight {
+		return 0, nil
+	}
+
+	var rounds uint64
+	for h := common.GenesisBlockHeight + 1; h <= height; h++ {
+		blk, err := block.GetBlockByHeight(st, h)
+		if err != nil {
+			return 0, err
+		}
+		rounds += blk.Round + 1
+	}
+
+	return float64(rounds) / float64(height-common.GenesisBlockHeight), nil
+}
+
+// isAgreed checks every honest node has the same block at the lowest height
+// of honest nodes.
+func isAgreed(nodes []*runner.NodeRunner, reports []NodeReport) bool {
+	var honest []*runner.NodeRunner
+	var lowest uint64
+	for i, r := range reports {
+		if r.Byzantine {
+			continue
+		}
+		if len(honest) < 1 || r.Height < lowest {
+			lowest = r.Height
+		}
+		honest = append(honest, nodes[i])
+	}
+
+	var hash string
+	for _, nr := range honest {
+		blk, err := block.GetBlockByHeight(nr.Storage(), lowest)
+		if err != nil {
+			return false
+		}
+		if len(hash) < 1 {
+			hash = blk.Hash
+		} else if hash != blk.Hash {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r Report) String() string {
+	b := new(bytes.Buffer)
+
+	fmt.Fprintf(b, "nodes: %d, threshold: %d, seed: %d\n", r.Nodes, r.Threshold, r.Seed)
+	fmt.Fprintf(b, "simulated %s in %s\n", r.Duration, r.Elapsed)
+	for _, f := range r.Faults {
+		fmt.Fprintf(b, "fault: %s\n", f)
+	}
+	for _, byzantine := range r.Byzantine {
+		fmt.Fprintf(b, "byzantine: %s\n", byzantine)
+	}
+	fmt.Fprintf(b, "blocks: %d (%.2f blocks/minute)\n", r.Blocks, r.BlocksPerMinute)
+	fmt.Fprintf(b, "rounds per height: %.2f\n", r.RoundsPerHeight)
+
+	var states []string
+	for state := range r.Expired {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	fmt.Fprintf(b, "expired ballots:")
+	for _, state := range states {
+		fmt.Fprintf(b, " %s=%d", state, r.Expired[state])
+	}
+	fmt.Fprintln(b)
+
+	fmt.Fprintf(b, "transactions: %d submitted, %d confirmed\n", r.Submitted, r.Confirmed)
+	fmt.Fprintf(b, "messages: %d sent, %d dropped, %d held\n", r.MessagesSent, r.MessagesDropped, r.MessagesHeld)
+	fmt.Fprintf(b, "agreed: %v\n", r.Agreed)
+	fmt.Fprintln(b)
+
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "node\theight\tround\ttotal-txs\tblock-hash\tstate-hash")
+	for _, n := range r.NodeReports {
+		alias := n.Alias
+		if n.Byzantine {
+			alias += "*"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n", alias, n.Height, n.Round, n.TotalTxs, n.BlockHash, n.StateHash)
+	}
+	w.Flush()
+
+	return b.String()
+}
@@ -0,0 +1,442 @@
+// Simulation of the consensus network in a process
+//
+// `Simulation` runs the `NodeRunner`s on `network.MemoryNetwork` with the
+// virtual clock. The messages between nodes are delayed by the configured
+// latency and the faults, and the time of the nodes moves only when every node
+// becomes quiescent, so minutes of consensus can be simulated in seconds and
+// the timeout settings can be tested before changing them in production.
+//
+// The nodes are quiescent when every message of `network.MemoryNetwork` is
+// handled, every fired timer of the virtual clock is received and nothing
+// happens while the simulation yields the processor. The keypairs, latencies,
+// faults and workload are decided by the seed, and the messages are delivered
+// in the order of arrival time, not of goroutine scheduling. The short
+// goroutines, which `NodeRunner` starts to pass the work to itself, like
+// receiving its own ballot, are not counted, so the reports of the same seed
+// can still be different in rare cases.
+package simulation
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/consensus"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+// Config is the setting of simulation.
+type Config struct {
+	Nodes     int
+	Threshold int           // voting threshold in percent
+	Duration  time.Duration // simulated time
+	Latency   time.Duration // latency of messages between nodes
+	Jitter    time.Duration // random additional latency
+	Seed      int64
+	Faults    []Fault
+	Byzantine []Byzantine
+	Workload  Workload
+
+	// Node is the config of every node; the timeouts and block time.
+	Node common.Config
+}
+
+func NewConfig() Config {
+	conf := common.NewTestConfig()
+	conf.NetworkID = []byte("sebak-simulation")
+	conf.BlockTime = common.DefaultBlockTime
+	conf.PeerBanThreshold = common.DefaultPeerBanThreshold
+	conf.PeerBanDuration = common.DefaultPeerBanDuration
+
+	return Config{
+		Nodes:     4,
+		Threshold: 67,
+		Duration:  5 * time.Minute,
+		Latency:   50 * time.Millisecond,
+		Jitter:    20 * time.Millisecond,
+		Seed:      1,
+		Workload: Workload{
+			Accounts: 10,
+			Interval: time.Second,
+			Size:     5,
+		},
+		Node: conf,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Nodes < 1 {
+		return fmt.Errorf("number of nodes must be greater than 0")
+	}
+	if c.Threshold < 1 || c.Threshold > 100 {
+		return fmt.Errorf("threshold must be in [1, 100]")
+	}
+	if c.Duration <= 0 {
+		return fmt.Errorf("duration must be greater than 0")
+	}
+	for _, f := range c.Faults {
+		for _, n := range f.Nodes {
+			if n < 0 || n >= c.Nodes {
+				return fmt.Errorf("node of fault, %q is out of range", f)
+			}
+		}
+	}
+	for _, b := range c.Byzantine {
+		if b.Node < 0 || b.Node >= c.Nodes {
+			return fmt.Errorf("node of byzantine, %q is out of range", b)
+		}
+	}
+
+	return nil
+}
+
+// newKeypair makes the keypair from the seed of simulation.
+func newKeypair(seed int64, name ...interface{}) *keypair.Full {
+	return keypair.Master(fmt.Sprintf("sebak-simulation-%d-%v", seed, name)).(*keypair.Full)
+}
+
+type Simulation struct {
+	sync.Mutex
+
+	conf               Config
+	clock              *VirtualClock
+	rand               *rand.Rand
+	started            time.Time
+	nodes              []*runner.NodeRunner
+	networks           []*network.MemoryNetwork
+	connectionManagers []*connectionManager
+	workload           *workload
+	log                logging.Logger
+
+	queue    []envelope
+	activity uint64
+	expired  map[ballot.State]int
+	sent     int
+	dropped  int
+	held     int
+}
+
+// Epoch is the beginning of the virtual clock.
+var Epoch = time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// running serializes `Simulation.Run`, because the virtual clock replaces the
+// global clock.
+var running sync.Mutex
+
+// New creates the nodes of simulation. The virtual clock replaces the clock of
+// consensus by `common.SetClock`, so only one simulation runs in a process at
+// once; `Simulation.Run` waits for the running one.
+func New(conf Config) (s *Simulation, err error) {
+	if err = conf.Validate(); err != nil {
+		return
+	}
+
+	s = &Simulation{
+		conf:    conf,
+		clock:   NewVirtualClock(Epoch),
+		rand:    rand.New(rand.NewSource(conf.Seed)),
+		started: Epoch,
+		log:     log.New(logging.Ctx{"seed": conf.Seed}),
+		expired: map[ballot.State]int{},
+	}
+
+	genesisKP := newKeypair(conf.Seed, "genesis")
+	commonKP := newKeypair(conf.Seed, "common")
+	s.workload = newWorkload(s, conf.Workload, genesisKP)
+
+	var nodes []*node.LocalNode
+	var prev *network.MemoryNetwork
+	for i := 0; i < conf.Nodes; i++ {
+		mn := prev.NewMemoryNetwork()
+		prev = mn
+
+		kp := newKeypair(conf.Seed, "node", i)
+		var localNode *node.LocalNode
+		if localNode, err = node.NewLocalNode(kp, mn.Endpoint(), fmt.Sprintf("node%d", i)); err != nil {
+			return
+		}
+		mn.SetLocalNode(localNode)
+
+		s.networks = append(s.networks, mn)
+		nodes = append(nodes, localNode)
+	}
+
+	for _, n := range nodes {
+		for _, v := range nodes {
+			if err = n.AddValidators(v.ConvertToValidator()); err != nil {
+				return
+			}
+		}
+	}
+
+	for i, localNode := range nodes {
+		var nr *runner.NodeRunner
+		if nr, err = s.newNodeRunner(i, localNode, s.networks[i], genesisKP, commonKP); err != nil {
+			return
+		}
+		s.nodes = append(s.nodes, nr)
+	}
+
+	for _, b := range conf.Byzantine {
+		if err = SetByzantine(s.nodes[b.Node], b.Behavior); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (s *Simulation) newNodeRunner(
+	index int,
+	localNode *node.LocalNode,
+	n *network.MemoryNetwork,
+	genesisKP, commonKP *keypair.Full,
+) (nr *runner.NodeRunner, err error) {
+	var policy *consensus.ISAACVotingThresholdPolicy
+	if policy, err = consensus.NewDefaultVotingThresholdPolicy(s.conf.Threshold); err != nil {
+		return
+	}
+
+	var st *storage.LevelDBBackend
+	var storageConfig *storage.Config
+	if storageConfig, err = storage.NewConfigFromString("memory://"); err != nil {
+		return
+	}
+	if st, err = storage.NewStorage(storageConfig); err != nil {
+		return
+	}
+
+	genesisAccount := block.NewBlockAccount(genesisKP.Address(), s.conf.Node.InitialBalance)
+	if err = genesisAccount.Save(st); err != nil {
+		return
+	}
+	commonAccount := block.NewBlockAccount(commonKP.Address(), 0)
+	if err = commonAccount.Save(st); err != nil {
+		return
+	}
+	if _, err = block.MakeGenesisBlock(st, *genesisAccount, *commonAccount, s.conf.Node.NetworkID); err != nil {
+		return
+	}
+
+	cm := &connectionManager{
+		ConnectionManager: network.NewValidatorConnectionManager(localNode, n, policy, s.conf.Node),
+		simulation:        s,
+		index:             index,
+	}
+	s.connectionManagers = append(s.connectionManagers, cm)
+
+	var is *consensus.ISAAC
+	if is, err = consensus.NewISAAC(localNode, policy, cm, st, s.conf.Node, nil); err != nil {
+		return
+	}
+
+	return runner.NewNodeRunner(localNode, policy, n, is, st, transaction.NewPool(s.conf.Node), s.conf.Node)
+}
+
+func (s *Simulation) Nodes() []*runner.NodeRunner {
+	return s.nodes
+}
+
+// indexOf returns the index of node by address; if not found, -1.
+func (s *Simulation) indexOf(address string) int {
+	for i, nr := range s.nodes {
+		if nr.Node().Address() == address {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isByzantine checks the node is one of `Config.Byzantine`.
+func (s *Simulation) isByzantine(index int) bool {
+	for _, b := range s.conf.Byzantine {
+		if b.Node == index {
+			return true
+		}
+	}
+
+	return false
+}
+
+// highest returns the node, which has the highest block.
+func (s *Simulation) highest() (highest *runner.NodeRunner) {
+	var height uint64
+	for _, nr := range s.nodes {
+		if h := nr.Consensus().LatestBlock().Height; highest == nil || h > height {
+			highest = nr
+			height = h
+		}
+	}
+
+	return
+}
+
+func (s *Simulation) getActivity() uint64 {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.activity + s.clock.Activity()
+}
+
+// pending returns the number of the messages, which are not handled by the
+// nodes and the fired timers, which are not received.
+func (s *Simulation) pending() (n int) {
+	for _, mn := range s.networks {
+		n += mn.Pending()
+	}
+
+	return n + s.clock.Pending()
+}
+
+// settleYields is how many times the simulation yields the processor without
+// any activity of the nodes before it decides the nodes are quiescent.
+const settleYields = 1000
+
+// settle delivers the arrived messages until the nodes are quiescent.
+func (s *Simulation) settle() {
+	for idle := 0; idle < settleYields; {
+		before := s.getActivity()
+		delivered := s.deliver()
+		runtime.Gosched()
+		if delivered > 0 || s.pending() > 0 || s.getActivity() != before {
+			idle = 0
+			continue
+		}
+		idle++
+	}
+}
+
+// waitForConsensus waits for every node to start consensus.
+func (s *Simulation) waitForConsensus(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, nr := range s.nodes {
+		for nr.Node().State() != node.StateCONSENSUS {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("node, %q does not start consensus", nr.Node().Alias())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	return nil
+}
+
+// Run starts the nodes and moves the virtual clock until `Config.Duration`.
+func (s *Simulation) Run() (report Report, err error) {
+	running.Lock()
+	defer running.Unlock()
+
+	common.SetClock(s.clock)
+	defer common.SetClock(common.WallClock)
+
+	begin := time.Now()
+	for _, nr := range s.nodes {
+		go nr.Start()
+	}
+	defer func() {
+		for _, nr := range s.nodes {
+			nr.Stop()
+		}
+	}()
+
+	if err = s.waitForConsensus(10 * time.Second); err != nil {
+		return
+	}
+
+	end := s.started.Add(s.conf.Duration)
+	for {
+		s.settle()
+		s.workload.tick()
+
+		next := end
+		if t, found := s.nextArrival(); found && t.Before(next) {
+			next = t
+		}
+		if t, found := s.clock.Next(); found && t.Before(next) {
+			next = t
+		}
+		if s.workload.enabled() && s.workload.next.Before(next) {
+			next = s.workload.next
+		}
+
+		s.clock.AdvanceTo(next)
+		if !next.Before(end) {
+			break
+		}
+	}
+	s.settle()
+
+	report = s.report(time.Since(begin))
+
+	return
+}
+
+func (s *Simulation) report(elapsed time.Duration) (r Report) {
+	s.Lock()
+	defer s.Unlock()
+
+	r = Report{
+		Nodes:           s.conf.Nodes,
+		Threshold:       s.conf.Threshold,
+		Seed:            s.conf.Seed,
+		Duration:        s.conf.Duration,
+		Elapsed:         elapsed,
+		Expired:         map[string]int{},
+		Submitted:       s.workload.submitted,
+		MessagesSent:    s.sent,
+		MessagesDropped: s.dropped,
+		MessagesHeld:    s.held,
+	}
+
+	for _, f := range s.conf.Faults {
+		r.Faults = append(r.Faults, f.String())
+	}
+	for _, state := range []ballot.State{ballot.StateINIT, ballot.StateSIGN, ballot.StateACCEPT} {
+		r.Expired[state.String()] = s.expired[state]
+	}
+
+	for _, b := range s.conf.Byzantine {
+		r.Byzantine = append(r.Byzantine, b.String())
+	}
+
+	// the highest honest node
+	var highest NodeReport
+	for i, nr := range s.nodes {
+		nodeReport := newNodeReport(nr)
+		nodeReport.Byzantine = s.isByzantine(i)
+		r.NodeReports = append(r.NodeReports, nodeReport)
+		if !nodeReport.Byzantine && nodeReport.Height > highest.Height {
+			highest = nodeReport
+		}
+	}
+	r.Agreed = isAgreed(s.nodes, r.NodeReports)
+
+	r.Blocks = highest.Height - common.GenesisBlockHeight
+	r.BlocksPerMinute = float64(r.Blocks) / s.conf.Duration.Minutes()
+
+	for _, nr := range s.nodes {
+		if nr.Node().Address() != highest.Address {
+			continue
+		}
+		r.RoundsPerHeight, _ = roundsPerHeight(nr.Storage(), highest.Height)
+		if genesis, err := block.GetBlockByHeight(nr.Storage(), common.GenesisBlockHeight); err == nil {
+			// every block has the proposer transaction
+			r.Confirmed = highest.TotalTxs - genesis.TotalTxs - r.Blocks
+		}
+	}
+
+	return
+}
@@ -0,0 +1,178 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/node/runner"
+)
+
+func TestParseFault(t *testing.T) {
+	{ // crash
+		f, err := ParseFault("crash:1@10s-40s")
+		require.NoError(t, err)
+		require.Equal(t, FaultCrash, f.Kind)
+		require.Equal(t, []int{1}, f.Nodes)
+		require.Equal(t, 10*time.Second, f.Start)
+		require.Equal(t, 40*time.Second, f.End)
+		require.Equal(t, "crash:1@10s-40s", f.String())
+
+		require.True(t, f.Affects(1, 2))
+		require.True(t, f.Affects(2, 1))
+		require.False(t, f.Affects(0, 2))
+		require.False(t, f.IsActive(5*time.Second))
+		require.True(t, f.IsActive(10*time.Second))
+		require.False(t, f.IsActive(40*time.Second))
+	}
+
+	{ // partition without end
+		f, err := ParseFault("partition:0,1@1m")
+		require.NoError(t, err)
+		require.Equal(t, FaultPartition, f.Kind)
+		require.Equal(t, []int{0, 1}, f.Nodes)
+		require.Equal(t, time.Minute, f.Start)
+		require.Equal(t, time.Duration(0), f.End)
+
+		require.False(t, f.Affects(0, 1))
+		require.True(t, f.Affects(0, 2))
+		require.True(t, f.Affects(3, 1))
+		require.True(t, f.IsActive(time.Hour))
+	}
+
+	{ // drop and delay affect every node
+		f, err := ParseFault("drop:0.1")
+		require.NoError(t, err)
+		require.Equal(t, FaultDrop, f.Kind)
+		require.Equal(t, 0.1, f.Rate)
+		require.True(t, f.Affects(2, 3))
+
+		f, err = ParseFault("delay:500ms@20s-30s")
+		require.NoError(t, err)
+		require.Equal(t, FaultDelay, f.Kind)
+		require.Equal(t, 500*time.Millisecond, f.Delay)
+	}
+
+	{ // wrong faults
+		for _, s := range []string{"crash", "unknown:1", "drop:2", "delay:fast", "crash:1@30s-10s", "crash:a"} {
+			_, err := ParseFault(s)
+			require.Error(t, err, s)
+		}
+	}
+}
+
+func TestSimulation(t *testing.T) {
+	conf := NewConfig()
+	conf.Duration = 30 * time.Second
+
+	s, err := New(conf)
+	require.NoError(t, err)
+
+	report, err := s.Run()
+	require.NoError(t, err)
+
+	require.True(t, report.Agreed)
+	require.True(t, report.Blocks > 0)
+	require.True(t, report.Confirmed > 0)
+	require.Equal(t, conf.Nodes, len(report.NodeReports))
+
+	// the virtual clock is restored
+	require.Equal(t, common.WallClock, common.GetClock())
+}
+
+// TestSimulationPartition checks the nodes agree on the same blocks after
+// the partition, which blocks the consensus, ends.
+func TestSimulationPartition(t *testing.T) {
+	conf := NewConfig()
+	conf.Duration = time.Minute
+	conf.Faults = []Fault{
+		{Kind: FaultPartition, Nodes: []int{0, 1}, Start: 10 * time.Second, End: 30 * time.Second},
+	}
+
+	s, err := New(conf)
+	require.NoError(t, err)
+
+	report, err := s.Run()
+	require.NoError(t, err)
+
+	require.True(t, report.Agreed)
+	require.True(t, report.MessagesHeld > 0)
+
+	var expired int
+	for _, c := range report.Expired {
+		expired += c
+	}
+	require.True(t, expired > 0)
+
+	for _, n := range report.NodeReports {
+		require.Equal(t, report.NodeReports[0].Height, n.Height)
+		require.Equal(t, report.NodeReports[0].StateHash, n.StateHash)
+	}
+	// the nodes make blocks again after partition
+	require.True(t, report.Blocks > uint64(10*time.Second/conf.Node.BlockTime))
+}
+
+func TestParseByzantine(t *testing.T) {
+	b, err := ParseByzantine("yes-to-invalid:1")
+	require.NoError(t, err)
+	require.Equal(t, Byzantine{Node: 1, Behavior: ByzantineYesToInvalid}, b)
+	require.Equal(t, "yes-to-invalid:1", b.String())
+
+	for _, s := range []string{"yes-to-invalid", "honest:1", "yes-to-invalid:a", "equivocation:1"} {
+		_, err := ParseByzantine(s)
+		require.Error(t, err, s)
+	}
+}
+
+// TestSimulationByzantine checks the honest nodes agree on the same blocks
+// with one byzantine node of four.
+func TestSimulationByzantine(t *testing.T) {
+	for _, behavior := range ByzantineBehaviors {
+		t.Run(string(behavior), func(t *testing.T) {
+			conf := NewConfig()
+			conf.Duration = 30 * time.Second
+			conf.Byzantine = []Byzantine{{Node: 1, Behavior: behavior}}
+
+			s, err := New(conf)
+			require.NoError(t, err)
+
+			report, err := s.Run()
+			require.NoError(t, err)
+
+			require.True(t, report.Agreed)
+			require.True(t, report.Blocks > 0)
+
+			// no transaction except the submitted ones is confirmed
+			require.True(t, report.Confirmed <= uint64(report.Submitted))
+
+			var honest []*runner.NodeRunner
+			for i, n := range report.NodeReports {
+				if !n.Byzantine {
+					honest = append(honest, s.Nodes()[i])
+				}
+			}
+			require.Equal(t, conf.Nodes-1, len(honest))
+
+			// every pair of the honest nodes has the same blocks until
+			// the lower height of them
+			for i, a := range honest {
+				for _, b := range honest[i+1:] {
+					height := a.Consensus().LatestBlock().Height
+					if h := b.Consensus().LatestBlock().Height; h < height {
+						height = h
+					}
+					for h := common.GenesisBlockHeight; h <= height; h++ {
+						ba, err := block.GetBlockByHeight(a.Storage(), h)
+						require.NoError(t, err)
+						bb, err := block.GetBlockByHeight(b.Storage(), h)
+						require.NoError(t, err)
+						require.Equal(t, ba.Hash, bb.Hash, "height %d of %s and %s", h, a.Node().Alias(), b.Node().Alias())
+					}
+				}
+			}
+		})
+	}
+}
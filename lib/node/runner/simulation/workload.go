@@ -0,0 +1,141 @@
+package simulation
+
+import (
+	"time"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+// Workload is the transactions submitted to the nodes. Every `Interval`,
+// `Size` transactions are submitted; until `Accounts` accounts are created,
+// the genesis account creates new account, and the created accounts send
+// payments to each other.
+type Workload struct {
+	Accounts int           `json:"accounts"`
+	Interval time.Duration `json:"interval"`
+	Size     int           `json:"size"`
+}
+
+type workload struct {
+	Workload
+
+	simulation *Simulation
+	genesis    *keypair.Full
+	accounts   []*keypair.Full
+	pending    map[string]string // source address: hash of pending transaction
+	next       time.Time
+
+	submitted int
+}
+
+func newWorkload(s *Simulation, w Workload, genesis *keypair.Full) *workload {
+	return &workload{
+		Workload:   w,
+		simulation: s,
+		genesis:    genesis,
+		pending:    map[string]string{},
+		next:       s.started.Add(w.Interval),
+	}
+}
+
+func (w *workload) enabled() bool {
+	return w.Interval > 0 && w.Size > 0
+}
+
+// isPending checks the last transaction of source is still in the pool of
+// the reference node.
+func (w *workload) isPending(nr *runner.NodeRunner, source string) bool {
+	hash, found := w.pending[source]
+	if !found {
+		return false
+	}
+	if nr.TransactionPool.Has(hash) {
+		return true
+	}
+
+	delete(w.pending, source)
+	return false
+}
+
+func (w *workload) tick() {
+	s := w.simulation
+	if !w.enabled() || s.clock.Now().Before(w.next) {
+		return
+	}
+	w.next = w.next.Add(w.Interval)
+
+	reference := s.highest()
+	for i := 0; i < w.Size; i++ {
+		var tx transaction.Transaction
+		var err error
+		if len(w.accounts) < w.Accounts {
+			if w.isPending(reference, w.genesis.Address()) {
+				continue
+			}
+			kp := newKeypair(s.conf.Seed, "account", len(w.accounts))
+			amount := common.BaseReserve.MustMult(100)
+			tx, err = w.makeTransaction(reference, w.genesis, operation.NewCreateAccount(kp.Address(), amount, ""))
+			if err == nil {
+				w.accounts = append(w.accounts, kp)
+			}
+		} else if len(w.accounts) > 1 {
+			source := w.accounts[s.rand.Intn(len(w.accounts))]
+			if w.isPending(reference, source.Address()) {
+				continue
+			}
+			if exists, _ := block.ExistsBlockAccount(reference.Storage(), source.Address()); !exists {
+				continue
+			}
+			target := w.accounts[s.rand.Intn(len(w.accounts))]
+			for target == source {
+				target = w.accounts[s.rand.Intn(len(w.accounts))]
+			}
+			tx, err = w.makeTransaction(reference, source, operation.NewPayment(target.Address(), common.Amount(1)))
+		} else {
+			return
+		}
+
+		if err != nil {
+			s.log.Error("failed to make transaction", "error", err)
+			continue
+		}
+
+		w.pending[tx.Source()] = tx.GetHash()
+		w.submitted++
+
+		// the transaction is already propagated to every node
+		for _, nr := range s.nodes {
+			if err := nr.TransactionPool.AddFromNode(tx); err != nil {
+				s.log.Error("failed to add transaction", "node", nr.Node().Alias(), "error", err)
+				continue
+			}
+			if _, err := block.SaveTransactionPool(nr.Storage(), tx); err != nil {
+				s.log.Error("failed to save transaction", "node", nr.Node().Alias(), "error", err)
+			}
+		}
+	}
+}
+
+func (w *workload) makeTransaction(nr *runner.NodeRunner, source *keypair.Full, body operation.Body) (tx transaction.Transaction, err error) {
+	var ba *block.BlockAccount
+	if ba, err = block.GetBlockAccount(nr.Storage(), source.Address()); err != nil {
+		return
+	}
+
+	var op operation.Operation
+	if op, err = operation.NewOperation(body); err != nil {
+		return
+	}
+
+	if tx, err = transaction.NewTransaction(source.Address(), ba.SequenceID, op); err != nil {
+		return
+	}
+	tx.Sign(source, nr.NetworkID())
+
+	return
+}
@@ -0,0 +1,188 @@
+package runner
+
+import (
+	"sync"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// StateSnapshot is the state snapshot of storage at `Header.Block`.
+type StateSnapshot struct {
+	sync.Mutex
+
+	Header  block.SnapshotHeader
+	storage *storage.LevelDBBackend
+
+	refs     int
+	released bool
+}
+
+func (s *StateSnapshot) acquire() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.released {
+		return false
+	}
+	s.refs++
+
+	return true
+}
+
+// Done releases the snapshot acquired by `SnapshotManager.Acquire()`.
+func (s *StateSnapshot) Done() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.refs--
+	s.releaseIfDone()
+}
+
+func (s *StateSnapshot) Storage() *storage.LevelDBBackend {
+	return s.storage
+}
+
+func (s *StateSnapshot) release() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.released = true
+	s.releaseIfDone()
+}
+
+func (s *StateSnapshot) releaseIfDone() {
+	if s.released && s.refs < 1 && s.storage != nil {
+		s.storage.Release()
+		s.storage = nil
+	}
+}
+
+// SnapshotManager takes the state snapshot when the block of every `interval`
+// height is stored and keeps the latest one for fast sync. The state root is
+// calculated in background, so the snapshot is served after a while.
+type SnapshotManager struct {
+	sync.RWMutex
+
+	st        *storage.LevelDBBackend
+	kp        keypair.KP
+	networkID []byte
+	interval  uint64
+	log       logging.Logger
+
+	taking uint64 // the height of the snapshot being taken
+	latest *StateSnapshot
+}
+
+func NewSnapshotManager(st *storage.LevelDBBackend, kp keypair.KP, networkID []byte, interval uint64, logger logging.Logger) *SnapshotManager {
+	if logger == nil {
+		logger = log
+	}
+
+	return &SnapshotManager{
+		st:        st,
+		kp:        kp,
+		networkID: networkID,
+		interval:  interval,
+		log:       logger.New(logging.Ctx{"m": "SnapshotManager"}),
+	}
+}
+
+// Check takes the snapshot if the latest block is at the snapshot height; it
+// should be called after new block is stored.
+func (m *SnapshotManager) Check() {
+	if m.interval < 1 {
+		return
+	}
+
+	snapshot, err := m.st.OpenSnapshot()
+	if err != nil {
+		m.log.Error("failed to open snapshot", "error", err)
+		return
+	}
+
+	blk := block.GetLatestBlock(snapshot)
+	if blk.Height%m.interval != 0 {
+		snapshot.Release()
+		return
+	}
+
+	m.Lock()
+	if m.taking >= blk.Height || (m.latest != nil && m.latest.Header.Block.Height >= blk.Height) {
+		m.Unlock()
+		snapshot.Release()
+		return
+	}
+	m.taking = blk.Height
+	m.Unlock()
+
+	go m.take(snapshot, blk)
+}
+
+func (m *SnapshotManager) take(snapshot *storage.LevelDBBackend, blk block.Block) {
+	m.log.Debug("taking snapshot", "height", blk.Height, "block", blk.Hash)
+
+	root, accounts, err := block.GetStateRoot(snapshot)
+	if err != nil {
+		m.log.Error("failed to get state root", "height", blk.Height, "error", err)
+		snapshot.Release()
+		return
+	}
+
+	recordsRoot, records, err := block.GetRecordsRoot(snapshot)
+	if err != nil {
+		m.log.Error("failed to get records root", "height", blk.Height, "error", err)
+		snapshot.Release()
+		return
+	}
+
+	s := &StateSnapshot{
+		Header: block.SnapshotHeader{
+			Version:     block.SnapshotVersion,
+			Block:       blk,
+			StateRoot:   root,
+			Accounts:    accounts,
+			RecordsRoot: recordsRoot,
+			Records:     records,
+		},
+		storage: snapshot,
+	}
+	s.Header.Sign(m.kp, m.networkID)
+
+	m.Lock()
+	previous := m.latest
+	m.latest = s
+	m.Unlock()
+
+	if previous != nil {
+		previous.release()
+	}
+
+	m.log.Info("snapshot taken", "height", blk.Height, "block", blk.Hash, "state-root", root, "accounts", accounts, "records", records)
+}
+
+// Acquire returns the latest snapshot; the snapshot should be released by
+// `StateSnapshot.Done()`. If no snapshot is taken, it returns nil.
+func (m *SnapshotManager) Acquire() *StateSnapshot {
+	m.RLock()
+	defer m.RUnlock()
+
+	if m.latest == nil || !m.latest.acquire() {
+		return nil
+	}
+
+	return m.latest
+}
+
+func (m *SnapshotManager) Stop() {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.latest != nil {
+		m.latest.release()
+		m.latest = nil
+	}
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+)
+
+func TestSnapshotManager(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	kp := keypair.Random()
+	localNode, err := node.NewLocalNode(kp, nil, "")
+	require.NoError(t, err)
+
+	m := NewSnapshotManager(st, kp, conf.NetworkID, 2, nil)
+	defer m.Stop()
+
+	nh := NewNetworkHandlerNode(localNode, nil, st, nil, nil, network.UrlPathPrefixNode, conf)
+	nh.SetSnapshots(m)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		nh.GetSnapshotHandler(w, httptest.NewRequest("GET", network.UrlPathPrefixNode+GetSnapshotPattern+query, nil))
+		return w
+	}
+
+	// genesis is not at the snapshot height
+	m.Check()
+	require.Nil(t, m.Acquire())
+	require.Equal(t, http.StatusNotFound, get("").Code)
+
+	m.interval = 1
+	m.Check()
+	var snapshot *StateSnapshot
+	for i := 0; i < 100 && snapshot == nil; i++ {
+		if snapshot = m.Acquire(); snapshot == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	require.NotNil(t, snapshot)
+	snapshot.Done()
+
+	w := get("?mode=header")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var header block.SnapshotHeader
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &header))
+	require.NoError(t, header.Verify(conf.NetworkID))
+	require.Equal(t, kp.Address(), header.Node)
+	require.Equal(t, uint64(1), header.Block.Height)
+
+	require.Equal(t, http.StatusNotFound, get("?height=2").Code)
+	require.Equal(t, http.StatusBadRequest, get("?mode=unknown").Code)
+	require.Equal(t, http.StatusOK, get("?height=1").Code)
+
+	// released snapshot is not served
+	m.Stop()
+	require.Nil(t, m.Acquire())
+}
@@ -165,6 +165,13 @@ func createNodeRunnerForTesting(n int, conf common.Config, recv chan struct{}) (
 	return nr, nodes, connectionManager
 }
 
+// NewTestNodeRunner makes the `NodeRunner` of the first node of `n`
+// validators for the other packages; the sent messages are kept in
+// `TestConnectionManager`.
+func NewTestNodeRunner(n int, conf common.Config) (*NodeRunner, []*node.LocalNode, *TestConnectionManager) {
+	return createNodeRunnerForTesting(n, conf, nil)
+}
+
 func MakeConsensusAndBlock(t *testing.T, tx transaction.Transaction, nr *NodeRunner, nodes []*node.LocalNode, proposer *node.LocalNode) (block.Block, error) {
 	nr.TransactionPool.AddFromNode(tx)
 
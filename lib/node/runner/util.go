@@ -119,6 +119,7 @@ func NewNodeInfo(nr *NodeRunner) node.NodeInfo {
 		NetworkID:                 string(nr.NetworkID()),
 		InitialBalance:            nr.Conf.InitialBalance,
 		BaseReserve:               common.BaseReserve,
+		DataEntryReserve:          common.DataEntryReserve,
 		BaseFee:                   common.BaseFee,
 		BlockTime:                 nr.Conf.BlockTime,
 		BlockTimeDelta:            nr.Conf.BlockTimeDelta,
@@ -138,8 +139,9 @@ func NewNodeInfo(nr *NodeRunner) node.NodeInfo {
 	}
 
 	return node.NodeInfo{
-		Node:   nd,
-		Policy: policy,
+		Node:     nd,
+		Policy:   policy,
+		Timeouts: nr.isaacStateManager.Timeouts(),
 	}
 }
 
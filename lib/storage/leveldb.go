@@ -302,6 +302,18 @@ func (st *LevelDBBackend) GetIterator(prefix string, option ListOptions) (func()
 	if len(prefix) > 0 {
 		dbRange = leveldbUtil.BytesPrefix(st.makeKey(prefix))
 	}
+	if o, ok := option.(*RangeListOptions); ok {
+		start, limit := o.Range()
+		if dbRange == nil {
+			dbRange = &leveldbUtil.Range{}
+		}
+		if len(start) > 0 {
+			dbRange.Start = append(st.makeKey(prefix), start...)
+		}
+		if len(limit) > 0 {
+			dbRange.Limit = append(st.makeKey(prefix), limit...)
+		}
+	}
 
 	iter := st.Core.NewIterator(dbRange, nil)
 
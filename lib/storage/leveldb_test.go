@@ -265,6 +265,44 @@ func TestLevelDBIteratorSeek(t *testing.T) {
 	return
 }
 
+func TestLevelDBIteratorRange(t *testing.T) {
+	st := NewTestStorage()
+	defer st.Close()
+
+	for i := 0; i < 100; i++ {
+		st.New(fmt.Sprintf("a-%02d", i), 0)
+		st.New(fmt.Sprintf("b-%02d", i), 0)
+	}
+
+	collect := func(prefix string, options ListOptions) (collected []string) {
+		it, closeFunc := st.GetIterator(prefix, options)
+		defer closeFunc()
+		for {
+			v, hasNext := it()
+			if !hasNext {
+				break
+			}
+			collected = append(collected, string(v.Key))
+		}
+		return
+	}
+
+	{ // start and limit
+		collected := collect("a-", NewRangeListOptions(NewDefaultListOptions(false, nil, 0), []byte("10"), []byte("13")))
+		require.Equal(t, []string{"a-10", "a-11", "a-12"}, collected)
+	}
+
+	{ // without limit, the range ends at the end of prefix
+		collected := collect("a-", NewRangeListOptions(NewDefaultListOptions(true, nil, 2), []byte("50"), nil))
+		require.Equal(t, []string{"a-99", "a-98"}, collected)
+	}
+
+	{ // cursor inside range
+		collected := collect("b-", NewRangeListOptions(NewDefaultListOptions(true, []byte("b-12"), 0), []byte("10"), []byte("20")))
+		require.Equal(t, []string{"b-11", "b-10"}, collected)
+	}
+}
+
 func TestLevelDBIteratorLimit(t *testing.T) {
 	st := NewTestStorage()
 	defer st.Close()
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/errors"
+)
+
+// DefaultMigrationBatchSize is the number of records written in one batch by
+// `Migrator.Rewrite`.
+const DefaultMigrationBatchSize int = 1000
+
+// Migration changes the storage of `Version - 1` to `Version`. The records are
+// written in batches, so `Migrate` must be able to run again over the
+// partially migrated storage.
+type Migration struct {
+	Version     uint64
+	Description string
+	Migrate     func(*Migrator) error
+}
+
+var migrations []Migration
+
+// RegisterMigration adds the migration; the versions of the registered
+// migrations must be sequential from `BaseSchemaVersion + 1`.
+func RegisterMigration(m Migration) {
+	if m.Version != SchemaVersion()+1 {
+		panic(fmt.Errorf("migration version must be %d, not %d", SchemaVersion()+1, m.Version))
+	}
+
+	migrations = append(migrations, m)
+}
+
+// Migrations returns the registered migrations.
+func Migrations() []Migration {
+	return append([]Migration{}, migrations...)
+}
+
+// RewriteFunc returns the new key and value of the record; if `newKey` is
+// empty, the record is removed.
+type RewriteFunc func(key, value []byte) (newKey, newValue []byte, err error)
+
+type Migrator struct {
+	st         *LevelDBBackend
+	migrations []Migration
+	log        logging.Logger
+
+	BatchSize int
+}
+
+func NewMigrator(st *LevelDBBackend, ms []Migration, logger logging.Logger) *Migrator {
+	ms = append([]Migration{}, ms...)
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+
+	return &Migrator{
+		st:         st,
+		migrations: ms,
+		log:        logger,
+		BatchSize:  DefaultMigrationBatchSize,
+	}
+}
+
+func (m *Migrator) Storage() *LevelDBBackend {
+	return m.st
+}
+
+func (m *Migrator) Log() logging.Logger {
+	return m.log
+}
+
+// Version returns the schema version of storage.
+func (m *Migrator) Version() (uint64, error) {
+	version, _, err := getStoredSchemaVersion(m.st)
+	return version, err
+}
+
+// Pending returns the migrations, which are not applied to storage yet.
+func (m *Migrator) Pending() (pending []Migration, err error) {
+	var version uint64
+	if version, err = m.Version(); err != nil {
+		return
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+
+	return
+}
+
+// Run applies the pending migrations in order; after each migration, the
+// schema version is recorded.
+func (m *Migrator) Run() (applied []Migration, err error) {
+	var version uint64
+	var recorded bool
+	if version, recorded, err = getStoredSchemaVersion(m.st); err != nil {
+		return
+	} else if !recorded {
+		if err = SetSchemaVersion(m.st, version); err != nil {
+			return
+		}
+	}
+
+	var pending []Migration
+	if pending, err = m.Pending(); err != nil {
+		return
+	}
+
+	for _, migration := range pending {
+		m.log.Info("start migration", "version", migration.Version, "description", migration.Description)
+		if err = migration.Migrate(m); err != nil {
+			err = errors.Wrapf(err, "failed to migrate to version %d", migration.Version)
+			return
+		}
+		if err = SetSchemaVersion(m.st, migration.Version); err != nil {
+			return
+		}
+		m.log.Info("migration done", "version", migration.Version)
+
+		applied = append(applied, migration)
+	}
+
+	return
+}
+
+// EachFunc writes the changes of the record to the batch and returns whether
+// anything is changed.
+type EachFunc func(batch *LevelDBBackend, key, value []byte) (changed bool, err error)
+
+// Each calls `fn` with the records, which have the prefix. The changes are
+// written in batches of `BatchSize` changed records and the progress is
+// logged at every batch.
+func (m *Migrator) Each(prefix string, fn EachFunc) (n uint64, err error) {
+	var batch *LevelDBBackend
+	if batch, err = m.st.OpenBatch(); err != nil {
+		return
+	}
+
+	var changed int
+	write := func() error {
+		if changed < 1 {
+			return nil
+		}
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+		m.log.Info("records migrated", "prefix", fmt.Sprintf("%q", prefix), "records", n)
+		changed = 0
+
+		return nil
+	}
+
+	iterFunc, closeFunc := m.st.GetIterator(prefix, nil)
+	defer closeFunc()
+
+	for {
+		item, hasNext := iterFunc()
+		if !hasNext {
+			break
+		}
+
+		key := append([]byte{}, item.Key...)
+		value := append([]byte{}, item.Value...)
+
+		var ok bool
+		if ok, err = fn(batch, key, value); err != nil {
+			batch.Discard()
+			return
+		} else if !ok {
+			continue
+		}
+
+		n++
+		changed++
+		if changed >= m.BatchSize {
+			if err = write(); err != nil {
+				return
+			}
+		}
+	}
+
+	err = write()
+
+	return
+}
+
+// Rewrite rewrites the records, which have the prefix, by `fn` through
+// `Each`.
+func (m *Migrator) Rewrite(prefix string, fn RewriteFunc) (n uint64, err error) {
+	return m.Each(prefix, func(batch *LevelDBBackend, key, value []byte) (bool, error) {
+		newKey, newValue, err := fn(key, value)
+		if err != nil {
+			return false, err
+		}
+
+		if len(newKey) < 1 {
+			return true, batch.Core.Delete(key, nil)
+		} else if string(newKey) == string(key) {
+			if string(newValue) == string(value) {
+				return false, nil
+			}
+			return true, batch.Core.Put(key, newValue, nil)
+		}
+
+		if err = batch.Core.Delete(key, nil); err != nil {
+			return false, err
+		}
+		return true, batch.Core.Put(newKey, newValue, nil)
+	})
+}
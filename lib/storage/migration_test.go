@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestCheckSchemaVersion(t *testing.T) {
+	{ // empty storage is recorded as current version
+		st := NewTestStorage()
+		defer st.Close()
+
+		require.NoError(t, CheckSchemaVersion(st))
+		version, recorded, err := GetSchemaVersion(st)
+		require.NoError(t, err)
+		require.True(t, recorded)
+		require.Equal(t, SchemaVersion(), version)
+	}
+
+	{ // storage without schema version is regarded as `BaseSchemaVersion`
+		st := NewTestStorage()
+		defer st.Close()
+		require.NoError(t, st.New("showme", 1))
+
+		require.NoError(t, CheckSchemaVersion(st))
+		version, _, err := GetSchemaVersion(st)
+		require.NoError(t, err)
+		require.Equal(t, BaseSchemaVersion, version)
+	}
+
+	{ // newer version
+		st := NewTestStorage()
+		defer st.Close()
+		require.NoError(t, SetSchemaVersion(st, SchemaVersion()+1))
+
+		err := CheckSchemaVersion(st)
+		require.Equal(t, errors.StorageSchemaNotSupported, errors.Cause(err))
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	defer func(ms []Migration) { migrations = ms }(migrations)
+	migrations = nil
+
+	st := NewTestStorage()
+	defer st.Close()
+
+	for i := 0; i < 25; i++ {
+		require.NoError(t, st.New(fmt.Sprintf("old-%02d", i), i))
+	}
+	require.NoError(t, st.New("other", 0))
+
+	RegisterMigration(Migration{
+		Version:     BaseSchemaVersion + 1,
+		Description: "rename old to new",
+		Migrate: func(m *Migrator) error {
+			_, err := m.Rewrite("old-", func(key, value []byte) ([]byte, []byte, error) {
+				return []byte(strings.Replace(string(key), "old-", "new-", 1)), value, nil
+			})
+			return err
+		},
+	})
+	RegisterMigration(Migration{
+		Version:     BaseSchemaVersion + 2,
+		Description: "remove odd records",
+		Migrate: func(m *Migrator) error {
+			_, err := m.Rewrite("new-", func(key, value []byte) ([]byte, []byte, error) {
+				var i int
+				if _, err := fmt.Sscanf(string(value), "%d", &i); err != nil {
+					return nil, nil, err
+				}
+				if i%2 == 1 {
+					return nil, nil, nil
+				}
+				return key, value, nil
+			})
+			return err
+		},
+	})
+	require.Panics(t, func() { RegisterMigration(Migration{Version: BaseSchemaVersion + 5}) })
+	require.Equal(t, BaseSchemaVersion+2, SchemaVersion())
+
+	err := CheckSchemaVersion(st)
+	require.Equal(t, errors.StorageSchemaOutdated, errors.Cause(err))
+
+	m := NewMigrator(st, Migrations(), common.NopLogger())
+	m.BatchSize = 10
+
+	pending, err := m.Pending()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(pending))
+
+	applied, err := m.Run()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(applied))
+	require.NoError(t, CheckSchemaVersion(st))
+
+	for i := 0; i < 25; i++ {
+		exists, err := st.Has(fmt.Sprintf("old-%02d", i))
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		exists, err = st.Has(fmt.Sprintf("new-%02d", i))
+		require.NoError(t, err)
+		require.Equal(t, i%2 == 0, exists)
+	}
+	exists, err := st.Has("other")
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// nothing to migrate
+	applied, err = m.Run()
+	require.NoError(t, err)
+	require.Empty(t, applied)
+}
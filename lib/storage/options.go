@@ -82,3 +82,24 @@ func (o DefaultListOptions) URLValues() url.Values {
 func (o DefaultListOptions) Encode() string {
 	return o.URLValues().Encode()
 }
+
+// RangeListOptions limits the keys of the iterator from `start` to `limit`;
+// the both are the suffixes after the prefix of iterator, `limit` is
+// exclusive and the empty `limit` means the end of the prefix.
+type RangeListOptions struct {
+	ListOptions
+	start []byte
+	limit []byte
+}
+
+func NewRangeListOptions(options ListOptions, start, limit []byte) *RangeListOptions {
+	return &RangeListOptions{
+		ListOptions: options,
+		start:       start,
+		limit:       limit,
+	}
+}
+
+func (o RangeListOptions) Range() (start, limit []byte) {
+	return o.start, o.limit
+}
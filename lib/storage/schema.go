@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// BaseSchemaVersion is the schema version of the storage, which was created
+// before the schema version was recorded.
+const BaseSchemaVersion uint64 = 1
+
+var schemaVersionKey = fmt.Sprintf("%s-schema-version", common.InternalPrefix)
+
+// SchemaVersion returns the schema version of this node; it is the version of
+// the last registered migration.
+func SchemaVersion() uint64 {
+	if len(migrations) < 1 {
+		return BaseSchemaVersion
+	}
+
+	return migrations[len(migrations)-1].Version
+}
+
+// GetSchemaVersion returns the schema version recorded in storage. If it is
+// not recorded, `recorded` is false.
+func GetSchemaVersion(st *LevelDBBackend) (version uint64, recorded bool, err error) {
+	var b []byte
+	if b, err = st.GetRaw(schemaVersionKey); err != nil {
+		if err == errors.StorageRecordDoesNotExist {
+			err = nil
+		}
+		return
+	}
+
+	if version, err = strconv.ParseUint(string(b), 10, 64); err != nil {
+		err = errors.Wrapf(err, "invalid schema version, %q", string(b))
+		return
+	}
+	recorded = true
+
+	return
+}
+
+// SetSchemaVersion records the schema version.
+func SetSchemaVersion(st *LevelDBBackend, version uint64) error {
+	return setLevelDBCoreError(
+		st.Core.Put(st.makeKey(schemaVersionKey), []byte(strconv.FormatUint(version, 10)), nil),
+	)
+}
+
+// getStoredSchemaVersion returns the schema version of storage; the empty
+// storage is regarded as the current version and the storage, which has
+// records without the schema version, as `BaseSchemaVersion`.
+func getStoredSchemaVersion(st *LevelDBBackend) (version uint64, recorded bool, err error) {
+	if version, recorded, err = GetSchemaVersion(st); err != nil || recorded {
+		return
+	}
+
+	iterFunc, closeFunc := st.GetIterator("", nil)
+	_, hasNext := iterFunc()
+	closeFunc()
+
+	if hasNext {
+		version = BaseSchemaVersion
+	} else {
+		version = SchemaVersion()
+	}
+
+	return
+}
+
+// CheckSchemaVersion checks the schema version of storage is same with
+// `SchemaVersion()`. If the version is not recorded yet, it will be recorded.
+func CheckSchemaVersion(st *LevelDBBackend) (err error) {
+	var version uint64
+	var recorded bool
+	if version, recorded, err = getStoredSchemaVersion(st); err != nil {
+		return
+	}
+
+	if !recorded {
+		if err = SetSchemaVersion(st, version); err != nil {
+			return
+		}
+	}
+
+	current := SchemaVersion()
+	if version < current {
+		return errors.Wrapf(errors.StorageSchemaOutdated, "storage=%d node=%d", version, current)
+	} else if version > current {
+		return errors.Wrapf(errors.StorageSchemaNotSupported, "storage=%d node=%d", version, current)
+	}
+
+	return
+}
@@ -47,7 +47,7 @@ func (so *stateObject) Serialize() ([]byte, error) {
 }
 
 func (so *stateObject) Deserialize(encoded []byte) error {
-	return json.Unmarshal(encoded, so.data)
+	return json.Unmarshal(encoded, &so.data)
 }
 
 /* GETTERS */
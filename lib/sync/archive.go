@@ -0,0 +1,569 @@
+package sync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+
+	logging "github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+)
+
+const (
+	ArchiveMagic   = "sebak-archive"
+	ArchiveVersion = 2
+)
+
+// ArchiveHeader is the first line of archive. It is covered by the checksum of
+// the last record.
+type ArchiveHeader struct {
+	Magic     string `json:"magic"`
+	Version   int    `json:"version"`
+	NetworkID string `json:"network-id"`
+	From      uint64 `json:"from"`
+	To        uint64 `json:"to"`
+	Created   string `json:"created"`
+}
+
+type ArchiveRecordType string
+
+const (
+	ArchiveRecordBlock               ArchiveRecordType = "block"
+	ArchiveRecordTransaction         ArchiveRecordType = "transaction"
+	ArchiveRecordProposerTransaction ArchiveRecordType = "proposer-transaction"
+	ArchiveRecordBallot              ArchiveRecordType = "ballot"
+	ArchiveRecordEnd                 ArchiveRecordType = "end"
+)
+
+// archiveRecord is the line of archive after header. `Checksum` is the
+// sha256 of `Data`.
+type archiveRecord struct {
+	Type     ArchiveRecordType `json:"type"`
+	Height   uint64            `json:"height"`
+	Data     json.RawMessage   `json:"data"`
+	Checksum string            `json:"checksum"`
+}
+
+// archiveEnd is the data of the last record; `Checksum` is the sha256 of the
+// header line and the checksums of every record, so the modified header and
+// the missing or reordered records can be found.
+type archiveEnd struct {
+	Records  uint64 `json:"records"`
+	Checksum string `json:"checksum"`
+}
+
+// ArchiveBlock is the block with the transactions in the order of
+// `Block.Transactions`, the proposer transaction and the ballots. The ballots
+// are optional and `ExportArchive` does not write them; the node does not
+// keep the ballots of the confirmed blocks.
+type ArchiveBlock struct {
+	Block               block.Block
+	Transactions        []transaction.Transaction
+	ProposerTransaction *ballot.ProposerTransaction
+	Ballots             []ballot.Ballot
+}
+
+func (a ArchiveBlock) SyncInfo() *SyncInfo {
+	blk := a.Block
+	si := &SyncInfo{
+		Height: blk.Height,
+		Block:  &blk,
+		Ptx:    a.ProposerTransaction,
+	}
+	for _, tx := range a.Transactions {
+		bt := block.NewBlockTransactionFromTransaction(blk.Hash, blk.Height, blk.ProposedTime, tx)
+		si.Bts = append(si.Bts, &bt)
+	}
+
+	return si
+}
+
+type ArchiveWriter struct {
+	w       *bufio.Writer
+	sum     hash.Hash
+	records uint64
+}
+
+// NewArchiveWriter writes the header and returns `ArchiveWriter`; `Close()`
+// must be called after writing blocks, otherwise the archive is not
+// complete.
+func NewArchiveWriter(w io.Writer, header ArchiveHeader) (*ArchiveWriter, error) {
+	header.Magic = ArchiveMagic
+	header.Version = ArchiveVersion
+
+	b, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	aw := &ArchiveWriter{w: bufio.NewWriter(w), sum: sha256.New()}
+	if _, err = aw.w.Write(append(b, '\n')); err != nil {
+		return nil, err
+	}
+	aw.sum.Write(b)
+
+	return aw, nil
+}
+
+func (aw *ArchiveWriter) writeLine(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err = aw.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (aw *ArchiveWriter) writeRecord(t ArchiveRecordType, height uint64, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	checksum := sha256.Sum256(data)
+	r := archiveRecord{
+		Type:     t,
+		Height:   height,
+		Data:     data,
+		Checksum: hex.EncodeToString(checksum[:]),
+	}
+	if err = aw.writeLine(r); err != nil {
+		return err
+	}
+
+	aw.sum.Write([]byte(r.Checksum))
+	aw.records++
+
+	return nil
+}
+
+func (aw *ArchiveWriter) Write(a ArchiveBlock) error {
+	height := a.Block.Height
+	if err := aw.writeRecord(ArchiveRecordBlock, height, a.Block); err != nil {
+		return err
+	}
+	for _, tx := range a.Transactions {
+		if err := aw.writeRecord(ArchiveRecordTransaction, height, tx); err != nil {
+			return err
+		}
+	}
+	if a.ProposerTransaction != nil {
+		if err := aw.writeRecord(ArchiveRecordProposerTransaction, height, a.ProposerTransaction.Transaction); err != nil {
+			return err
+		}
+	}
+	for _, blt := range a.Ballots {
+		if err := aw.writeRecord(ArchiveRecordBallot, height, blt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close writes the last record and flushes.
+func (aw *ArchiveWriter) Close() error {
+	end := archiveEnd{
+		Records:  aw.records,
+		Checksum: hex.EncodeToString(aw.sum.Sum(nil)),
+	}
+	if err := aw.writeRecord(ArchiveRecordEnd, 0, end); err != nil {
+		return err
+	}
+
+	return aw.w.Flush()
+}
+
+type ArchiveReader struct {
+	r       *bufio.Reader
+	header  ArchiveHeader
+	sum     hash.Hash
+	records uint64
+	next    *archiveRecord
+	ended   bool
+}
+
+// NewArchiveReader reads and checks the header.
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	ar := &ArchiveReader{r: bufio.NewReader(r), sum: sha256.New()}
+
+	line, err := ar.readLine()
+	if err != nil {
+		return nil, errors.Wrap(errors.InvalidArchive, "failed to read header")
+	}
+	if err = json.Unmarshal(line, &ar.header); err != nil {
+		return nil, errors.Wrap(errors.InvalidArchive, err.Error())
+	}
+	if ar.header.Magic != ArchiveMagic {
+		return nil, errors.Wrap(errors.InvalidArchive, "not archive")
+	}
+	if ar.header.Version != ArchiveVersion {
+		return nil, errors.Wrapf(errors.InvalidArchive, "unsupported version: %d", ar.header.Version)
+	}
+	ar.sum.Write(bytes.TrimSuffix(line, []byte("\n")))
+
+	return ar, nil
+}
+
+func (ar *ArchiveReader) Header() ArchiveHeader {
+	return ar.header
+}
+
+func (ar *ArchiveReader) readLine() ([]byte, error) {
+	line, err := ar.r.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+
+	return line, err
+}
+
+// readRecord reads the next record and checks its checksum.
+func (ar *ArchiveReader) readRecord() (*archiveRecord, error) {
+	if ar.next != nil {
+		r := ar.next
+		ar.next = nil
+		return r, nil
+	}
+
+	line, err := ar.readLine()
+	if err == io.EOF {
+		return nil, errors.Wrap(errors.InvalidArchive, "archive is truncated")
+	} else if err != nil {
+		return nil, err
+	}
+
+	var r archiveRecord
+	if err = json.Unmarshal(line, &r); err != nil {
+		return nil, errors.Wrap(errors.InvalidArchive, err.Error())
+	}
+
+	checksum := sha256.Sum256(r.Data)
+	if hex.EncodeToString(checksum[:]) != r.Checksum {
+		return nil, errors.Wrapf(errors.ArchiveChecksumMismatch, "%s record of height %d", r.Type, r.Height)
+	}
+
+	if r.Type == ArchiveRecordEnd {
+		return &r, nil
+	}
+
+	ar.sum.Write([]byte(r.Checksum))
+	ar.records++
+
+	return &r, nil
+}
+
+func (ar *ArchiveReader) checkEnd(r *archiveRecord) error {
+	var end archiveEnd
+	if err := json.Unmarshal(r.Data, &end); err != nil {
+		return errors.Wrap(errors.InvalidArchive, err.Error())
+	}
+	if end.Records != ar.records || end.Checksum != hex.EncodeToString(ar.sum.Sum(nil)) {
+		return errors.Wrap(errors.ArchiveChecksumMismatch, "records do not match")
+	}
+
+	return nil
+}
+
+// Next returns the next block; after the last block, it checks the checksum
+// of every record and returns `io.EOF`.
+func (ar *ArchiveReader) Next() (*ArchiveBlock, error) {
+	if ar.ended {
+		return nil, io.EOF
+	}
+
+	r, err := ar.readRecord()
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Type {
+	case ArchiveRecordEnd:
+		if err = ar.checkEnd(r); err != nil {
+			return nil, err
+		}
+		ar.ended = true
+		return nil, io.EOF
+	case ArchiveRecordBlock:
+	default:
+		return nil, errors.Wrapf(errors.InvalidArchive, "expected block record, but %s record of height %d", r.Type, r.Height)
+	}
+
+	a := &ArchiveBlock{}
+	if err = json.Unmarshal(r.Data, &a.Block); err != nil {
+		return nil, errors.Wrap(errors.InvalidArchive, err.Error())
+	}
+
+	for {
+		if r, err = ar.readRecord(); err != nil {
+			return nil, err
+		}
+		if r.Type == ArchiveRecordBlock || r.Type == ArchiveRecordEnd {
+			ar.next = r
+			break
+		}
+		if r.Height != a.Block.Height {
+			return nil, errors.Wrapf(errors.InvalidArchive, "%s record of height %d in block of height %d", r.Type, r.Height, a.Block.Height)
+		}
+
+		switch r.Type {
+		case ArchiveRecordTransaction:
+			var tx transaction.Transaction
+			if err = json.Unmarshal(r.Data, &tx); err != nil {
+				return nil, errors.Wrap(errors.InvalidArchive, err.Error())
+			}
+			a.Transactions = append(a.Transactions, tx)
+		case ArchiveRecordProposerTransaction:
+			var tx transaction.Transaction
+			if err = json.Unmarshal(r.Data, &tx); err != nil {
+				return nil, errors.Wrap(errors.InvalidArchive, err.Error())
+			}
+			a.ProposerTransaction = &ballot.ProposerTransaction{Transaction: tx}
+		case ArchiveRecordBallot:
+			var blt ballot.Ballot
+			if err = json.Unmarshal(r.Data, &blt); err != nil {
+				return nil, errors.Wrap(errors.InvalidArchive, err.Error())
+			}
+			a.Ballots = append(a.Ballots, blt)
+		default:
+			return nil, errors.Wrapf(errors.InvalidArchive, "unknown record type: %s", r.Type)
+		}
+	}
+
+	if err = a.check(); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// check checks the transactions are same with the transactions of block.
+func (a ArchiveBlock) check() error {
+	blk := a.Block
+	if len(a.Transactions) != len(blk.Transactions) {
+		return errors.Wrapf(errors.InvalidArchive, "transactions of height %d do not match", blk.Height)
+	}
+	for i, tx := range a.Transactions {
+		if tx.GetHash() != blk.Transactions[i] {
+			return errors.Wrapf(errors.InvalidArchive, "transactions of height %d do not match", blk.Height)
+		}
+	}
+
+	if blk.ProposerTransaction == "" {
+		if a.ProposerTransaction != nil {
+			return errors.Wrapf(errors.InvalidArchive, "unexpected proposer transaction of height %d", blk.Height)
+		}
+	} else if a.ProposerTransaction == nil || a.ProposerTransaction.GetHash() != blk.ProposerTransaction {
+		return errors.Wrapf(errors.InvalidArchive, "proposer transaction of height %d does not match", blk.Height)
+	}
+
+	return nil
+}
+
+// ExportArchive writes the blocks from `from` to `to` of storage. If `to` is
+// 0, the blocks until the latest block are written. The ballots are not
+// written, because they are not stored; the imported blocks are validated
+// with their transactions by `BlockValidator`.
+func ExportArchive(st *storage.LevelDBBackend, w io.Writer, networkID []byte, from, to uint64) (header ArchiveHeader, err error) {
+	var exists bool
+	if exists, err = block.ExistsBlockByHeight(st, common.GenesisBlockHeight); err != nil {
+		return
+	} else if !exists {
+		err = errors.BlockNotFound
+		return
+	}
+
+	latest := block.GetLatestBlock(st)
+	if from < common.GenesisBlockHeight {
+		from = common.GenesisBlockHeight
+	}
+	if to == 0 || to > latest.Height {
+		to = latest.Height
+	}
+	if from > to {
+		err = errors.Wrapf(errors.BadRequestParameter, "invalid range: %d-%d", from, to)
+		return
+	}
+
+	// the pruned blocks can not be archived
+	var pruned uint64
+	if pruned, err = block.GetPrunedBlockHeight(st); err != nil {
+		return
+	} else if pruned > 0 && from <= pruned {
+		err = errors.Wrapf(errors.DataPruned, "blocks are pruned until %d", pruned)
+		return
+	}
+
+	header = ArchiveHeader{
+		NetworkID: string(networkID),
+		From:      from,
+		To:        to,
+		Created:   common.NowISO8601(),
+	}
+
+	var aw *ArchiveWriter
+	if aw, err = NewArchiveWriter(w, header); err != nil {
+		return
+	}
+
+	for height := from; height <= to; height++ {
+		var a ArchiveBlock
+		if a, err = getArchiveBlock(st, height); err != nil {
+			return
+		}
+		if err = aw.Write(a); err != nil {
+			return
+		}
+	}
+
+	err = aw.Close()
+
+	return
+}
+
+func getArchiveBlock(st *storage.LevelDBBackend, height uint64) (a ArchiveBlock, err error) {
+	if a.Block, err = block.GetBlockByHeight(st, height); err != nil {
+		return
+	}
+
+	var tp block.TransactionPool
+	for _, hash := range a.Block.Transactions {
+		if tp, err = block.GetTransactionPool(st, hash); err != nil {
+			return
+		}
+		a.Transactions = append(a.Transactions, tp.Transaction())
+	}
+
+	if a.Block.ProposerTransaction != "" {
+		if tp, err = block.GetTransactionPool(st, a.Block.ProposerTransaction); err != nil {
+			return
+		}
+		a.ProposerTransaction = &ballot.ProposerTransaction{Transaction: tp.Transaction()}
+	}
+
+	return
+}
+
+// ImportArchive validates the blocks of archive by `BlockValidator` and
+// stores them. The genesis block is made again from the genesis transaction
+// and compared with the archived one. The blocks, which already exist in
+// storage, must be same with the archived ones.
+func ImportArchive(ctx context.Context, st *storage.LevelDBBackend, r io.Reader, cfg common.Config, logger logging.Logger) (header ArchiveHeader, imported uint64, err error) {
+	var ar *ArchiveReader
+	if ar, err = NewArchiveReader(r); err != nil {
+		return
+	}
+	header = ar.Header()
+
+	if header.NetworkID != string(cfg.NetworkID) {
+		err = errors.Wrapf(errors.InvalidArchive, "different network id: %q", header.NetworkID)
+		return
+	}
+
+	validator := NewBlockValidator(st, transaction.NewPool(cfg), cfg, func(v *BlockValidator) {
+		v.logger = logger
+	})
+
+	for {
+		var a *ArchiveBlock
+		if a, err = ar.Next(); err == io.EOF {
+			err = nil
+			return
+		} else if err != nil {
+			return
+		}
+
+		var exists bool
+		if exists, err = block.ExistsBlockByHeight(st, a.Block.Height); err != nil {
+			return
+		} else if exists {
+			var blk block.Block
+			if blk, err = block.GetBlockByHeight(st, a.Block.Height); err != nil {
+				return
+			}
+			if blk.Hash != a.Block.Hash {
+				err = errors.Wrapf(errors.HashDoesNotMatch, "different block of height %d already exists", blk.Height)
+				return
+			}
+			logger.Debug("block already exists", "height", blk.Height)
+			continue
+		}
+
+		if a.Block.Height == common.GenesisBlockHeight {
+			err = importGenesisBlock(st, *a, cfg)
+		} else if exists, err = block.ExistsBlockByHeight(st, a.Block.Height-1); err == nil && !exists {
+			err = errors.Wrapf(errors.InvalidArchive, "previous block of height %d does not exist", a.Block.Height)
+		} else if err == nil {
+			err = validator.Validate(ctx, a.SyncInfo())
+		}
+		if err != nil {
+			return
+		}
+
+		logger.Debug("block imported", "height", a.Block.Height, "hash", a.Block.Hash)
+		imported++
+	}
+}
+
+// importGenesisBlock makes the genesis block and the accounts from the
+// genesis transaction; the genesis block can not be validated by
+// `BlockValidator`.
+func importGenesisBlock(st *storage.LevelDBBackend, a ArchiveBlock, cfg common.Config) (err error) {
+	if len(a.Transactions) != 1 || len(a.Transactions[0].B.Operations) != 2 {
+		return errors.Wrap(errors.WrongBlockFound, "invalid genesis block")
+	}
+	tx := a.Transactions[0]
+
+	var accounts []*block.BlockAccount
+	for _, op := range tx.B.Operations {
+		opb, ok := op.B.(operation.CreateAccount)
+		if !ok {
+			return errors.Wrap(errors.WrongBlockFound, "invalid genesis transaction")
+		}
+		accounts = append(accounts, block.NewBlockAccount(opb.Target, opb.Amount))
+	}
+
+	var bs *storage.LevelDBBackend
+	if bs, err = st.OpenBatch(); err != nil {
+		return
+	}
+
+	var blk *block.Block
+	for _, account := range accounts {
+		if err = account.Save(bs); err != nil {
+			bs.Discard()
+			return
+		}
+	}
+	if blk, err = block.MakeGenesisBlock(bs, *accounts[0], *accounts[1], cfg.NetworkID); err != nil {
+		bs.Discard()
+		return
+	}
+
+	var tp block.TransactionPool
+	if tp, err = block.GetTransactionPool(bs, tx.GetHash()); err != nil {
+		bs.Discard()
+		return
+	}
+	if blk.Hash != a.Block.Hash || tp.Transaction().H.Signature != tx.H.Signature {
+		bs.Discard()
+		return errors.Wrap(errors.HashDoesNotMatch, "different genesis block")
+	}
+
+	return bs.Commit()
+}
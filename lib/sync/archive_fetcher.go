@@ -0,0 +1,488 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/metrics"
+)
+
+// ArchiveIndexName is the file name of the index of archive segments. The
+// static HTTP mirror must serve it; in the local directory, it is optional.
+const ArchiveIndexName = "index.json"
+
+// ArchiveFetcherCursors is the number of archive segments kept opened by
+// `ArchiveFetcher` for the sequential reads.
+const ArchiveFetcherCursors = 4
+
+// ArchiveSegment is the archive file, which has the blocks from `From` to
+// `To`.
+type ArchiveSegment struct {
+	Name string `json:"name"`
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+}
+
+// ArchiveIndex is the list of archive segments ordered by height.
+type ArchiveIndex struct {
+	NetworkID string           `json:"network-id"`
+	Segments  []ArchiveSegment `json:"segments"`
+}
+
+func (idx ArchiveIndex) find(height uint64) (ArchiveSegment, bool) {
+	for _, s := range idx.Segments {
+		if s.From <= height && height <= s.To {
+			return s, true
+		}
+	}
+
+	return ArchiveSegment{}, false
+}
+
+// Highest returns the highest block height in the archive segments.
+func (idx ArchiveIndex) Highest() (height uint64) {
+	for _, s := range idx.Segments {
+		if s.To > height {
+			height = s.To
+		}
+	}
+
+	return
+}
+
+// BuildArchiveIndex reads the headers of the archive files in `dir`; the
+// files, which are not archive, are skipped.
+func BuildArchiveIndex(dir string) (idx ArchiveIndex, err error) {
+	var files []os.FileInfo
+	if files, err = ioutil.ReadDir(dir); err != nil {
+		return
+	}
+
+	for _, fi := range files {
+		if fi.IsDir() || fi.Name() == ArchiveIndexName {
+			continue
+		}
+
+		var header ArchiveHeader
+		if header, err = readArchiveHeader(filepath.Join(dir, fi.Name())); err != nil {
+			if errors.Cause(err) == errors.InvalidArchive {
+				err = nil
+				continue
+			}
+			return
+		}
+
+		if len(idx.NetworkID) < 1 {
+			idx.NetworkID = header.NetworkID
+		} else if idx.NetworkID != header.NetworkID {
+			err = errors.Wrapf(errors.InvalidArchive, "network id of %s does not match", fi.Name())
+			return
+		}
+		idx.Segments = append(idx.Segments, ArchiveSegment{Name: fi.Name(), From: header.From, To: header.To})
+	}
+
+	sort.Slice(idx.Segments, func(i, j int) bool { return idx.Segments[i].From < idx.Segments[j].From })
+
+	return
+}
+
+func readArchiveHeader(path string) (header ArchiveHeader, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer f.Close()
+
+	var ar *ArchiveReader
+	if ar, err = NewArchiveReader(f); err != nil {
+		return
+	}
+
+	return ar.Header(), nil
+}
+
+// ArchiveSource serves the archive segments.
+type ArchiveSource interface {
+	Index(ctx context.Context) (ArchiveIndex, error)
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	String() string
+}
+
+// NewArchiveSource returns the `ArchiveSource` of `source`; the `http://`
+// or `https://` url is the static HTTP mirror, and the others are the local
+// directory.
+func NewArchiveSource(source string, client Doer) (ArchiveSource, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		u, err := url.Parse(source)
+		if err != nil {
+			return nil, err
+		}
+		return &httpArchiveSource{base: u, client: client}, nil
+	}
+
+	dir := strings.TrimPrefix(source, "file://")
+	if fi, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not directory", dir)
+	}
+
+	return &dirArchiveSource{dir: dir}, nil
+}
+
+type dirArchiveSource struct {
+	dir string
+}
+
+func (s *dirArchiveSource) Index(ctx context.Context) (idx ArchiveIndex, err error) {
+	var b []byte
+	if b, err = ioutil.ReadFile(filepath.Join(s.dir, ArchiveIndexName)); os.IsNotExist(err) {
+		return BuildArchiveIndex(s.dir)
+	} else if err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(b, &idx); err != nil {
+		err = errors.Wrap(errors.InvalidArchive, err.Error())
+	}
+
+	return
+}
+
+func (s *dirArchiveSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	if filepath.Base(name) != name {
+		return nil, errors.Wrapf(errors.InvalidArchive, "invalid segment name: %s", name)
+	}
+
+	return os.Open(filepath.Join(s.dir, name))
+}
+
+func (s *dirArchiveSource) String() string {
+	return s.dir
+}
+
+type httpArchiveSource struct {
+	base   *url.URL
+	client Doer
+}
+
+func (s *httpArchiveSource) get(ctx context.Context, name string) (io.ReadCloser, error) {
+	u, err := s.base.Parse(strings.TrimSuffix(s.base.Path, "/") + "/" + url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s: unexpected status code: %d", u.String(), resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+func (s *httpArchiveSource) Index(ctx context.Context) (idx ArchiveIndex, err error) {
+	var body io.ReadCloser
+	if body, err = s.get(ctx, ArchiveIndexName); err != nil {
+		return
+	}
+	defer body.Close()
+
+	if err = json.NewDecoder(body).Decode(&idx); err != nil {
+		err = errors.Wrap(errors.InvalidArchive, err.Error())
+	}
+
+	return
+}
+
+func (s *httpArchiveSource) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	return s.get(ctx, name)
+}
+
+func (s *httpArchiveSource) String() string {
+	return s.base.String()
+}
+
+// archiveCursor is the opened archive segment; `next` is the height of the
+// next block to be read.
+type archiveCursor struct {
+	segment ArchiveSegment
+	rc      io.ReadCloser
+	ar      *ArchiveReader
+	next    uint64
+}
+
+func (c *archiveCursor) Close() {
+	c.rc.Close()
+}
+
+type ArchiveFetcherOption func(*ArchiveFetcher)
+
+// ArchiveFetcher fetches the blocks from the archive segments of
+// `ArchiveSource` instead of the other nodes. The fetched blocks are
+// validated by `Validator` like the blocks from `BlockFetcher`.
+type ArchiveFetcher struct {
+	sync.Mutex
+
+	source        ArchiveSource
+	networkID     []byte
+	retryInterval time.Duration
+	logger        log15.Logger
+
+	index   *ArchiveIndex
+	cursors []*archiveCursor
+}
+
+func NewArchiveFetcher(source ArchiveSource, networkID []byte, opts ...ArchiveFetcherOption) *ArchiveFetcher {
+	f := &ArchiveFetcher{
+		source:        source,
+		networkID:     networkID,
+		retryInterval: RetryInterval,
+		logger:        common.NopLogger(),
+	}
+
+	for _, o := range opts {
+		o(f)
+	}
+
+	return f
+}
+
+// Fetch fetches the block of `si.Height`; if the block is not in the
+// archive yet, it tries again until canceled.
+func (f *ArchiveFetcher) Fetch(ctx context.Context, si *SyncInfo) (*SyncInfo, error) {
+	for {
+		infos, err := f.FetchRange(ctx, si.Height, si.Height, si.NodeList)
+		if err == nil {
+			return infos[0], nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		f.logger.Error("fetch err", "err", err, "height", si.Height)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(f.retryInterval):
+		}
+	}
+}
+
+// FetchRange reads the blocks from `from` to `to` in the archive segment,
+// which has `from`. If the segment ends before `to`, the blocks until the end
+// of segment are returned.
+func (f *ArchiveFetcher) FetchRange(ctx context.Context, from, to uint64, nodeList *NodeList) ([]*SyncInfo, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	infos, err := f.fetchRange(ctx, from, to, nodeList)
+	if err != nil {
+		if ctx.Err() == nil {
+			metrics.Sync.AddFetchError(err)
+		}
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+func (f *ArchiveFetcher) fetchRange(ctx context.Context, from, to uint64, nodeList *NodeList) ([]*SyncInfo, error) {
+	segment, err := f.segment(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := f.cursor(ctx, segment, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*SyncInfo
+	for c.next <= to && c.next <= segment.To {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		a, err := c.ar.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			f.closeCursor(c)
+			return nil, err
+		}
+		if a.Block.Height != c.next {
+			f.closeCursor(c)
+			return nil, errors.Wrapf(errors.InvalidArchive, "%s: expected height %d, but %d", segment.Name, c.next, a.Block.Height)
+		}
+		c.next++
+
+		if a.Block.Height < from {
+			continue
+		}
+
+		si := a.SyncInfo()
+		si.NodeList = nodeList
+		si.Node = segment.Name
+		infos = append(infos, si)
+	}
+
+	if c.next > segment.To {
+		f.closeCursor(c)
+	}
+
+	if len(infos) < 1 {
+		return nil, errors.Wrapf(errors.BlockNotFound, "%s: height %d", segment.Name, from)
+	}
+
+	f.logger.Debug("fetched from archive", "segment", segment.Name, "from", from, "to", from+uint64(len(infos))-1)
+
+	return infos, nil
+}
+
+// segment finds the segment of `height`; if not found, the index is loaded
+// again, because the new segments can be added to the source.
+func (f *ArchiveFetcher) segment(ctx context.Context, height uint64) (ArchiveSegment, error) {
+	if f.index != nil {
+		if s, found := f.index.find(height); found {
+			return s, nil
+		}
+	}
+
+	if err := f.loadIndex(ctx); err != nil {
+		return ArchiveSegment{}, err
+	}
+
+	if s, found := f.index.find(height); found {
+		return s, nil
+	}
+
+	return ArchiveSegment{}, errors.Wrapf(errors.BlockNotFound, "height %d is not in archive %s", height, f.source)
+}
+
+func (f *ArchiveFetcher) loadIndex(ctx context.Context) error {
+	idx, err := f.source.Index(ctx)
+	if err != nil {
+		return err
+	}
+	if idx.NetworkID != string(f.networkID) {
+		return errors.Wrapf(errors.InvalidArchive, "network id of archive %s does not match", f.source)
+	}
+
+	f.index = &idx
+
+	return nil
+}
+
+// cursor returns the opened segment, which can read `from`; if there is no
+// such one, the segment is opened.
+func (f *ArchiveFetcher) cursor(ctx context.Context, segment ArchiveSegment, from uint64) (*archiveCursor, error) {
+	var found *archiveCursor
+	for _, c := range f.cursors {
+		if c.segment.Name != segment.Name || c.next > from {
+			continue
+		}
+		if found == nil || c.next > found.next {
+			found = c
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+
+	rc, err := f.source.Open(ctx, segment.Name)
+	if err != nil {
+		return nil, err
+	}
+	ar, err := NewArchiveReader(rc)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+
+	header := ar.Header()
+	if header.NetworkID != string(f.networkID) || header.From != segment.From || header.To != segment.To {
+		rc.Close()
+		return nil, errors.Wrapf(errors.InvalidArchive, "header of %s does not match with index", segment.Name)
+	}
+
+	c := &archiveCursor{segment: segment, rc: rc, ar: ar, next: header.From}
+	f.cursors = append(f.cursors, c)
+	if len(f.cursors) > ArchiveFetcherCursors {
+		f.closeCursor(f.cursors[0])
+	}
+
+	return c, nil
+}
+
+func (f *ArchiveFetcher) closeCursor(c *archiveCursor) {
+	for i, o := range f.cursors {
+		if o == c {
+			f.cursors = append(f.cursors[:i], f.cursors[i+1:]...)
+			break
+		}
+	}
+	c.Close()
+}
+
+// ReportInvalid drops the index and the opened segments, so the segment of
+// invalid block is read again from the source.
+func (f *ArchiveFetcher) ReportInvalid(name string, err error) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.logger.Error("invalid block in archive", "segment", name, "err", err)
+
+	f.index = nil
+	for len(f.cursors) > 0 {
+		f.closeCursor(f.cursors[0])
+	}
+}
+
+// Highest returns the highest block height in the archive.
+func (f *ArchiveFetcher) Highest(ctx context.Context) (uint64, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.loadIndex(ctx); err != nil {
+		return 0, err
+	}
+
+	return f.index.Highest(), nil
+}
+
+// Close closes the opened segments.
+func (f *ArchiveFetcher) Close() {
+	f.Lock()
+	defer f.Unlock()
+
+	for len(f.cursors) > 0 {
+		f.closeCursor(f.cursors[0])
+	}
+}
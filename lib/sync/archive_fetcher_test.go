@@ -0,0 +1,124 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/transaction"
+)
+
+func TestArchiveFetcher(t *testing.T) {
+	conf := common.NewTestConfig()
+
+	src := block.InitTestBlockchain()
+	defer src.Close()
+	makeTestChain(t, src, conf, 5)
+
+	dir, err := ioutil.TempDir("", "sebak-archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, r := range [][2]uint64{{1, 3}, {4, 6}} {
+		f, err := os.Create(filepath.Join(dir, fmt.Sprintf("%d-%d.archive", r[0], r[1])))
+		require.NoError(t, err)
+		_, err = ExportArchive(src, f, conf.NetworkID, r[0], r[1])
+		require.NoError(t, err)
+		f.Close()
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not archive"), 0644))
+
+	idx, err := BuildArchiveIndex(dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(idx.Segments))
+	require.Equal(t, string(conf.NetworkID), idx.NetworkID)
+	require.Equal(t, uint64(6), idx.Highest())
+
+	// the fetched blocks are validated by `BlockValidator`
+	check := func(f *ArchiveFetcher) {
+		dst := block.InitTestBlockchain()
+		defer dst.Close()
+		v := NewBlockValidator(dst, transaction.NewPool(conf), conf)
+
+		ctx := context.Background()
+		height, err := f.Highest(ctx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(6), height)
+
+		// the range is cut at the end of segment
+		infos, err := f.FetchRange(ctx, 2, 6, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(infos))
+
+		si, err := f.Fetch(ctx, &SyncInfo{Height: 4})
+		require.NoError(t, err)
+		infos = append(infos, si)
+
+		more, err := f.FetchRange(ctx, 5, 10, nil)
+		require.NoError(t, err)
+		infos = append(infos, more...)
+
+		for i, si := range infos {
+			require.Equal(t, uint64(i+2), si.Height)
+			require.NoError(t, v.Validate(ctx, si))
+
+			expected, err := block.GetBlockByHeight(src, si.Height)
+			require.NoError(t, err)
+			blk, err := block.GetBlockByHeight(dst, si.Height)
+			require.NoError(t, err)
+			require.Equal(t, expected.Hash, blk.Hash)
+		}
+
+		_, err = f.FetchRange(ctx, 7, 7, nil)
+		require.Equal(t, errors.BlockNotFound, errors.Cause(err))
+
+		f.Close()
+	}
+
+	{ // local directory without index
+		source, err := NewArchiveSource(dir, nil)
+		require.NoError(t, err)
+		check(NewArchiveFetcher(source, conf.NetworkID))
+	}
+
+	b, err := common.JSONMarshalIndent(idx)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ArchiveIndexName), b, 0644))
+
+	{ // static HTTP mirror
+		ts := httptest.NewServer(http.StripPrefix("/mirror/", http.FileServer(http.Dir(dir))))
+		defer ts.Close()
+
+		source, err := NewArchiveSource(ts.URL+"/mirror/", http.DefaultClient)
+		require.NoError(t, err)
+		check(NewArchiveFetcher(source, conf.NetworkID))
+
+		// index is required
+		source, err = NewArchiveSource(ts.URL+"/unknown", http.DefaultClient)
+		require.NoError(t, err)
+		_, err = NewArchiveFetcher(source, conf.NetworkID).Highest(context.Background())
+		require.Error(t, err)
+	}
+
+	{ // different network id
+		source, err := NewArchiveSource("file://"+dir, nil)
+		require.NoError(t, err)
+		_, err = NewArchiveFetcher(source, []byte("another")).FetchRange(context.Background(), 2, 2, nil)
+		require.Equal(t, errors.InvalidArchive, errors.Cause(err))
+	}
+
+	{ // not directory
+		_, err := NewArchiveSource(filepath.Join(dir, "README"), nil)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,197 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/ballot"
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/storage"
+	"boscoin.io/sebak/lib/transaction"
+	"boscoin.io/sebak/lib/transaction/operation"
+	"boscoin.io/sebak/lib/voting"
+)
+
+// makeTestChain appends the blocks, which have a create-account transaction
+// and proposer transaction, through `BlockValidator`.
+func makeTestChain(t *testing.T, st *storage.LevelDBBackend, conf common.Config, n int, opts ...BlockValidatorOption) {
+	v := NewBlockValidator(st, transaction.NewPool(conf), conf, opts...)
+	proposer := keypair.Random()
+
+	for i := 0; i < n; i++ {
+		prev := block.GetLatestBlock(st)
+
+		genesisAccount, err := block.GetBlockAccount(st, block.GenesisKP.Address())
+		require.NoError(t, err)
+
+		tx := transaction.MakeTransactionCreateAccount(conf.NetworkID, block.GenesisKP, keypair.Random().Address(), common.BaseReserve)
+		tx.B.SequenceID = genesisAccount.SequenceID
+		tx.Sign(block.GenesisKP, conf.NetworkID)
+
+		inflation, err := common.CalculateInflation(conf.InitialBalance)
+		require.NoError(t, err)
+
+		opc, err := operation.NewOperation(operation.NewCollectTxFee(block.CommonKP.Address(), tx.B.Fee, 1, prev.Height, prev.Hash, prev.TotalTxs))
+		require.NoError(t, err)
+		opi, err := operation.NewOperation(operation.NewOperationBodyInflation(block.CommonKP.Address(), inflation, conf.InitialBalance, prev.Height, prev.Hash, prev.TotalTxs))
+		require.NoError(t, err)
+		ptx, err := ballot.NewProposerTransaction(proposer.Address(), opc, opi)
+		require.NoError(t, err)
+		ptx.Sign(proposer, conf.NetworkID)
+
+		blk := block.NewBlock(
+			proposer.Address(),
+			voting.Basis{
+				Height:    prev.Height + 1,
+				BlockHash: prev.Hash,
+				TotalTxs:  prev.TotalTxs + 2,
+				TotalOps:  prev.TotalOps + 3,
+			},
+			ptx.GetHash(),
+			[]string{tx.GetHash()},
+			common.NowISO8601(),
+		)
+
+		si := ArchiveBlock{Block: *blk, Transactions: []transaction.Transaction{tx}, ProposerTransaction: &ptx}.SyncInfo()
+		require.NoError(t, v.Validate(context.Background(), si))
+	}
+}
+
+func TestArchiveExportImport(t *testing.T) {
+	conf := common.NewTestConfig()
+
+	src := block.InitTestBlockchain()
+	defer src.Close()
+	makeTestChain(t, src, conf, 3)
+
+	b := new(bytes.Buffer)
+	header, err := ExportArchive(src, b, conf.NetworkID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), header.From)
+	require.Equal(t, uint64(4), header.To)
+
+	dst := storage.NewTestStorage()
+	defer dst.Close()
+
+	_, imported, err := ImportArchive(context.Background(), dst, bytes.NewReader(b.Bytes()), conf, common.NopLogger())
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), imported)
+
+	for height := uint64(1); height <= 4; height++ {
+		expected, err := block.GetBlockByHeight(src, height)
+		require.NoError(t, err)
+		blk, err := block.GetBlockByHeight(dst, height)
+		require.NoError(t, err)
+		require.Equal(t, expected.Hash, blk.Hash)
+	}
+
+	expected, err := block.GetBlockAccount(src, block.CommonKP.Address())
+	require.NoError(t, err)
+	account, err := block.GetBlockAccount(dst, block.CommonKP.Address())
+	require.NoError(t, err)
+	require.Equal(t, expected.Balance, account.Balance)
+
+	result, err := block.Verify(dst, false)
+	require.NoError(t, err)
+	require.Empty(t, result.Divergences)
+
+	{ // importing again skips the existing blocks
+		_, imported, err := ImportArchive(context.Background(), dst, bytes.NewReader(b.Bytes()), conf, common.NopLogger())
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), imported)
+	}
+
+	{ // part of chain can be imported over the existing blocks
+		b := new(bytes.Buffer)
+		_, err := ExportArchive(src, b, conf.NetworkID, 3, 4)
+		require.NoError(t, err)
+
+		partial := block.InitTestBlockchain()
+		defer partial.Close()
+
+		_, _, err = ImportArchive(context.Background(), partial, bytes.NewReader(b.Bytes()), conf, common.NopLogger())
+		require.Error(t, err) // block of height 2 does not exist
+	}
+
+	{ // different network id
+		conf := common.NewTestConfig()
+		conf.NetworkID = []byte("another")
+
+		st := storage.NewTestStorage()
+		defer st.Close()
+
+		_, _, err := ImportArchive(context.Background(), st, bytes.NewReader(b.Bytes()), conf, common.NopLogger())
+		require.Error(t, err)
+	}
+}
+
+func TestArchiveReaderChecksum(t *testing.T) {
+	conf := common.NewTestConfig()
+
+	st := block.InitTestBlockchain()
+	defer st.Close()
+	makeTestChain(t, st, conf, 2)
+
+	b := new(bytes.Buffer)
+	_, err := ExportArchive(st, b, conf.NetworkID, 0, 0)
+	require.NoError(t, err)
+
+	readAll := func(s string) error {
+		ar, err := NewArchiveReader(strings.NewReader(s))
+		if err != nil {
+			return err
+		}
+		for {
+			if _, err = ar.Next(); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	require.NoError(t, readAll(b.String()))
+
+	lines := strings.SplitAfter(b.String(), "\n")
+
+	{ // modified record
+		modified := make([]string, len(lines))
+		copy(modified, lines)
+		modified[1] = strings.Replace(modified[1], `"round":0`, `"round":1`, 1)
+		err := readAll(strings.Join(modified, ""))
+		require.Equal(t, errors.ArchiveChecksumMismatch, errors.Cause(err))
+	}
+
+	{ // modified header
+		modified := make([]string, len(lines))
+		copy(modified, lines)
+		modified[0] = strings.Replace(modified[0], `"from":1`, `"from":2`, 1)
+		require.NotEqual(t, lines[0], modified[0])
+		err := readAll(strings.Join(modified, ""))
+		require.Equal(t, errors.ArchiveChecksumMismatch, errors.Cause(err))
+	}
+
+	{ // missing record
+		missing := append([]string{}, lines[:2]...)
+		missing = append(missing, lines[3:]...)
+		require.Error(t, readAll(strings.Join(missing, "")))
+	}
+
+	{ // truncated
+		truncated := strings.Join(lines[:len(lines)-2], "")
+		require.Equal(t, errors.InvalidArchive, errors.Cause(readAll(truncated)))
+	}
+
+	{ // not archive
+		_, err := NewArchiveReader(strings.NewReader("{}\n"))
+		require.Equal(t, errors.InvalidArchive, errors.Cause(err))
+	}
+}
@@ -1,8 +1,10 @@
 package sync
 
 import (
+	"context"
 	"time"
 
+	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/network"
 	"boscoin.io/sebak/lib/node"
@@ -14,8 +16,11 @@ import (
 
 const (
 	SyncPoolSize             uint64 = 300
+	SyncBatchSize            uint64 = 50
+	SyncFetchAhead                  = 4
 	FetchTimeout                    = 1 * time.Minute
 	RetryInterval                   = 10 * time.Second
+	CommitRetries                   = 5 // retries of the block, which failed by the local error
 	CheckBlockHeightInterval        = 30 * time.Second
 	CheckPrevBlockInterval          = 30 * time.Second
 	WatchInterval                   = 5 * time.Second
@@ -29,13 +34,18 @@ type Config struct {
 	nodelist          *NodeList
 	logger            log15.Logger
 	commonCfg         common.Config
+	archiveFetcher    *ArchiveFetcher
+	finishBlockFunc   func(block.Block)
 
 	SyncPoolSize             uint64
+	SyncBatchSize            uint64 // if less than 2, the blocks are fetched one by one
+	SyncFetchAhead           int
 	FetchTimeout             time.Duration
 	RetryInterval            time.Duration
 	CheckBlockHeightInterval time.Duration
 	CheckPrevBlockInterval   time.Duration
 	WatchInterval            time.Duration
+	Source                   string // archive directory or HTTP mirror; if empty, blocks are fetched from the nodes
 }
 
 func NewConfig(localNode *node.LocalNode,
@@ -53,6 +63,8 @@ func NewConfig(localNode *node.LocalNode,
 		nodelist:          &NodeList{},
 
 		SyncPoolSize:             SyncPoolSize,
+		SyncBatchSize:            SyncBatchSize,
+		SyncFetchAhead:           SyncFetchAhead,
 		FetchTimeout:             FetchTimeout,
 		RetryInterval:            RetryInterval,
 		CheckBlockHeightInterval: CheckBlockHeightInterval,
@@ -65,23 +77,33 @@ func NewConfig(localNode *node.LocalNode,
 	return c, nil
 }
 
-func (c *Config) NewSyncer() *Syncer {
-	f := c.NewFetcher()
+func (c *Config) NewSyncer() (*Syncer, error) {
+	f, err := c.NewFetcher()
+	if err != nil {
+		return nil, err
+	}
 	v := c.NewValidator()
 	s := NewSyncer(f, v, c.storage, func(s *Syncer) {
 		s.nodelist = c.nodelist
 		s.poolSize = c.SyncPoolSize
 		s.checkInterval = c.CheckBlockHeightInterval
+		s.batchSize = c.SyncBatchSize
+		s.fetchAhead = c.SyncFetchAhead
+		s.retryInterval = c.RetryInterval
 		s.logger = c.logger.New("submodule", "syncer")
 	})
 
 	c.LoggingConfig()
-	return s
+	return s, nil
 }
 
-func (c *Config) NewFetcher() Fetcher {
+func (c *Config) NewFetcher() (Fetcher, error) {
 	client := c.NewHTTP2Client()
 
+	if len(c.Source) > 0 {
+		return c.NewArchiveFetcher(client)
+	}
+
 	f := NewBlockFetcher(
 		c.connectionManager,
 		client,
@@ -93,7 +115,36 @@ func (c *Config) NewFetcher() Fetcher {
 			f.logger = c.logger.New("submodule", "fetcher")
 		},
 	)
-	return f
+	return f, nil
+}
+
+// NewArchiveFetcher returns `ArchiveFetcher`, which reads the archive
+// segments of `Source`.
+func (c *Config) NewArchiveFetcher(client Doer) (*ArchiveFetcher, error) {
+	source, err := NewArchiveSource(c.Source, client)
+	if err != nil {
+		c.logger.Error("invalid sync source", "source", c.Source, "err", err)
+		return nil, err
+	}
+
+	if c.archiveFetcher == nil {
+		c.archiveFetcher = NewArchiveFetcher(
+			source,
+			c.commonCfg.NetworkID,
+			func(f *ArchiveFetcher) {
+				f.retryInterval = c.RetryInterval
+				f.logger = c.logger.New("submodule", "fetcher")
+			},
+		)
+	}
+
+	return c.archiveFetcher, nil
+}
+
+// SetFinishBlockFunc sets the func, which is called after the synced block is
+// committed; it must be set before the syncer starts.
+func (c *Config) SetFinishBlockFunc(f func(block.Block)) {
+	c.finishBlockFunc = f
 }
 
 func (c *Config) NewValidator() Validator {
@@ -104,14 +155,43 @@ func (c *Config) NewValidator() Validator {
 		func(v *BlockValidator) {
 			v.prevBlockWaitTimeout = c.CheckPrevBlockInterval
 			v.logger = c.logger.New("submodule", "validator")
+			v.finishBlockFunc = func(blk block.Block) {
+				if c.finishBlockFunc != nil {
+					c.finishBlockFunc(blk)
+				}
+			}
 		})
 	return v
 }
 
-func (c *Config) NewWatcher(s SyncController) *Watcher {
+// NewSnapshotFetcher returns `SnapshotFetcher`; `threshold` is the percentage
+// of validators to attest the snapshot.
+func (c *Config) NewSnapshotFetcher(threshold int) *SnapshotFetcher {
+	return NewSnapshotFetcher(
+		c.storage,
+		c.NewHTTP2Client(),
+		c.localNode,
+		c.commonCfg.NetworkID,
+		threshold,
+		func(f *SnapshotFetcher) {
+			f.logger = c.logger.New("submodule", "snapshot")
+		},
+	)
+}
+
+func (c *Config) NewWatcher(s SyncController) (*Watcher, error) {
 	c.logger.Info("watcher config", "watchInterval", c.WatchInterval)
 
 	client := c.NewHTTP2Client()
+
+	var f *ArchiveFetcher
+	if len(c.Source) > 0 {
+		var err error
+		if f, err = c.NewArchiveFetcher(client); err != nil {
+			return nil, err
+		}
+	}
+
 	w := NewWatcher(
 		s, client,
 		c.connectionManager,
@@ -119,10 +199,16 @@ func (c *Config) NewWatcher(s SyncController) *Watcher {
 		c.localNode,
 		func(w *Watcher) {
 			w.interval = c.WatchInterval
+			if f != nil {
+				w.highest = func(ctx context.Context) (uint64, []string, error) {
+					height, err := f.Highest(ctx)
+					return height, nil, err
+				}
+			}
 		},
 	)
 	w.SetLogger(c.logger.New("submodule", "watcher"))
-	return w
+	return w, nil
 }
 
 func (c *Config) NewHTTP2Client() *common.HTTP2Client {
@@ -137,10 +223,13 @@ func (c *Config) NewHTTP2Client() *common.HTTP2Client {
 func (c *Config) LoggingConfig() {
 	c.logger.Info("syncer config",
 		"poolSize", c.SyncPoolSize,
+		"batchSize", c.SyncBatchSize,
+		"fetchAhead", c.SyncFetchAhead,
 		"fetchTimeout", c.FetchTimeout,
 		"retryInterval", c.RetryInterval,
 		"checkInterval", c.CheckBlockHeightInterval,
 		"checkPrevBlockInterval", c.CheckPrevBlockInterval,
+		"source", c.Source,
 	)
 }
 
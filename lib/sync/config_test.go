@@ -23,8 +23,18 @@ func TestNewConfig(t *testing.T) {
 	cfg.SyncPoolSize = 100
 	cfg.logger = common.NopLogger()
 
-	syncer := cfg.NewSyncer()
+	syncer, err := cfg.NewSyncer()
+	require.NoError(t, err)
 
 	require.NotNil(t, syncer)
 	require.Equal(t, syncer.poolSize, cfg.SyncPoolSize)
+
+	{ // invalid source
+		cfg.Source = "file:///not-existing-sync-source"
+
+		_, err := cfg.NewSyncer()
+		require.Error(t, err)
+		_, err = cfg.NewWatcher(syncer)
+		require.Error(t, err)
+	}
 }
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"boscoin.io/sebak/lib/ballot"
@@ -33,6 +34,8 @@ type BlockFetcher struct {
 	fetchTimeout  time.Duration
 	retryInterval time.Duration
 
+	peers *PeerStats
+
 	logger log15.Logger
 }
 
@@ -54,6 +57,8 @@ func NewBlockFetcher(
 
 		fetchTimeout:  1 * time.Minute,
 		retryInterval: 30 * time.Second,
+
+		peers: NewPeerStats(),
 	}
 
 	for _, opt := range opts {
@@ -77,7 +82,7 @@ func (f *BlockFetcher) Fetch(ctx context.Context, syncInfo *SyncInfo) (*SyncInfo
 					return false, ctx.Err()
 				}
 				f.logger.Error("fetch err", "err", err, "height", height)
-				metrics.Sync.AddFetchError()
+				metrics.Sync.AddFetchError(err)
 				c := time.After(f.retryInterval) //afterFunc?
 				select {
 				case <-ctx.Done():
@@ -159,50 +164,182 @@ func (f *BlockFetcher) fetch(ctx context.Context, si *SyncInfo) error {
 		return err
 	}
 
-	blk := blocks[0].(block.Block)
+	if err := fillSyncInfo(si, blocks[0].(block.Block), bts); err != nil {
+		return err
+	}
+
+	f.logger.Debug("end fetch", "height", height)
+	return nil
+}
+
+// fillSyncInfo sets the block and it's transactions, which are ordered by
+// `block.Transactions`.
+func fillSyncInfo(si *SyncInfo, blk block.Block, bts []interface{}) error {
 	si.Block = &blk
+	si.Bts = si.Bts[:0]
+	si.Ptx = nil
 
-	{
-		btmap := make(map[string]*block.BlockTransaction) // For ordering txs by block.Transactions
+	btmap := make(map[string]*block.BlockTransaction) // For ordering txs by block.Transactions
 
-		for _, bt := range bts {
-			bt, ok := bt.(block.BlockTransaction)
-			if !ok {
-				return errors.InvalidTransaction
-			}
-			btmap[bt.Hash] = &bt
+	for _, bt := range bts {
+		bt, ok := bt.(block.BlockTransaction)
+		if !ok {
+			return errors.InvalidTransaction
 		}
+		btmap[bt.Hash] = &bt
+	}
 
-		for _, hash := range blk.Transactions {
-			bt, ok := btmap[hash]
-			if !ok {
-				return errors.Wrapf(errors.TransactionNotFound, "block hash: %s height: %d", hash, blk.Height)
-			}
+	for _, hash := range blk.Transactions {
+		bt, ok := btmap[hash]
+		if !ok {
+			return errors.Wrapf(errors.TransactionNotFound, "block hash: %s height: %d", hash, blk.Height)
+		}
+		if bt.Transaction().IsEmpty() {
+			return errors.Wrapf(errors.TransactionNotFound, "tx in btx not found: tx %s not found of height %s", hash, blk.Height)
+		}
+		si.Bts = append(si.Bts, bt)
+	}
+
+	if blk.ProposerTransaction != "" {
+		if bt, ok := btmap[blk.ProposerTransaction]; ok {
 			if bt.Transaction().IsEmpty() {
-				return errors.Wrapf(errors.TransactionNotFound, "tx in btx not found: tx %s not found of height %s", hash, blk.Height)
+				return errors.Wrapf(errors.TransactionNotFound, "proposer tx in btx not found: tx %s not found of height %s", blk.ProposerTransaction, blk.Height)
 			}
-			si.Bts = append(si.Bts, bt)
+			ptx := &ballot.ProposerTransaction{Transaction: bt.Transaction()}
+			si.Ptx = ptx
+		} else {
+			return errors.Wrapf(errors.TransactionNotFound, "proposer transaction block hash: %v", blk.ProposerTransaction)
 		}
+	}
 
-		if blk.ProposerTransaction != "" {
-			if bt, ok := btmap[blk.ProposerTransaction]; ok {
-				if bt.Transaction().IsEmpty() {
-					return errors.Wrapf(errors.TransactionNotFound, "proposer tx in btx not found: tx %s not found of height %s", blk.ProposerTransaction, blk.Height)
+	return nil
+}
+
+// FetchRange fetches the blocks from `from` to `to` at once from one of the
+// nodes, which is picked by the throughput. The returned `SyncInfo`s are
+// ordered by height from `from`, but may be less than requested.
+func (f *BlockFetcher) FetchRange(ctx context.Context, from, to uint64, nodeList *NodeList) ([]*SyncInfo, error) {
+	addr := f.peers.Pick(f.candidates(nodeList.NodeAddrs()))
+	if len(addr) < 1 {
+		f.logger.Error("Alive Node addrs not exists", "from", from, "to", to, "nodes", nodeList.NodeAddrs())
+		return nil, errors.NodeNotFound
+	}
+
+	begin := time.Now()
+	infos, err := f.fetchRange(ctx, addr, from, to, nodeList)
+	f.peers.Done(addr, len(infos), time.Since(begin), err)
+	if err != nil {
+		if err != context.Canceled {
+			metrics.Sync.AddFetchError(err)
+		}
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+func (f *BlockFetcher) fetchRange(ctx context.Context, addr string, from, to uint64, nodeList *NodeList) ([]*SyncInfo, error) {
+	n := f.localNode.Validator(addr)
+	if n == nil {
+		return nil, errors.NodeNotFound
+	}
+
+	apiURL := apiClientRangeURL(n, from, to)
+	f.logger.Debug("fetching range from node", "node", addr, "from", from, "to", to, "url", apiURL.String())
+
+	req, err := http.NewRequest("GET", apiURL.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "api request")
+	}
+
+	resp, err := f.apiClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch: unexpected status code: %d", resp.StatusCode)
+	}
+
+	var infos []*SyncInfo
+	var blk *block.Block
+	var bts []interface{}
+
+	flush := func() error {
+		if blk == nil {
+			return nil
+		}
+		expected := from + uint64(len(infos))
+		if blk.Height != expected {
+			return errors.Wrapf(errors.BlockNotFound, "fetch: expected height %d, but %d", expected, blk.Height)
+		}
+		si := &SyncInfo{Height: blk.Height, NodeList: nodeList, Node: addr}
+		if err := fillSyncInfo(si, *blk, bts); err != nil {
+			return err
+		}
+		infos = append(infos, si)
+		blk, bts = nil, nil
+		return nil
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			itemType, item, uerr := api.UnmarshalNodeItemResponse(line)
+			if uerr != nil {
+				return nil, errors.Wrap(uerr, "response failed to unmarshal")
+			}
+
+			switch itemType {
+			case api.NodeItemBlock:
+				if ferr := flush(); ferr != nil {
+					return nil, ferr
 				}
-				ptx := &ballot.ProposerTransaction{Transaction: bt.Transaction()}
-				si.Ptx = ptx
-			} else {
-				return errors.Wrapf(errors.TransactionNotFound, "proposer transaction block hash: %v", blk.ProposerTransaction)
+				b := item.(block.Block)
+				blk = &b
+			case api.NodeItemBlockTransaction:
+				bts = append(bts, item)
+			case api.NodeItemError:
+				return nil, fmt.Errorf("fetch: node error: %v", item)
 			}
 		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
 	}
 
-	f.logger.Debug("end fetch", "height", height)
-	return nil
+	if len(infos) < 1 {
+		return nil, errors.New("fetch: block not found in response")
+	}
+	if uint64(len(infos)) > to-from+1 {
+		infos = infos[:to-from+1]
+	}
+
+	f.logger.Debug("end fetch range", "node", addr, "from", from, "to", from+uint64(len(infos))-1)
+
+	return infos, nil
 }
 
-// pickRandomNode choose one node by random. It is very protype for choosing fetching which node
-func (f *BlockFetcher) pickRandomNode(nodeAddrs []string) node.Node {
+// ReportInvalid penalizes the node, which sent the invalid block.
+func (f *BlockFetcher) ReportInvalid(addr string, err error) {
+	f.peers.Penalize(addr, err)
+}
+
+// Peers returns the fetching statistics of nodes.
+func (f *BlockFetcher) Peers() *PeerStats {
+	return f.peers
+}
+
+// candidates returns the connected nodes among `nodeAddrs`; if `nodeAddrs` is
+// empty, all the connected nodes.
+func (f *BlockFetcher) candidates(nodeAddrs []string) []string {
 	ac := f.connectionManager.AllConnected()
 	if len(ac) <= 0 {
 		return nil
@@ -227,6 +364,12 @@ func (f *BlockFetcher) pickRandomNode(nodeAddrs []string) node.Node {
 		}
 	}
 
+	return addressList
+}
+
+// pickRandomNode choose one node by random. It is very protype for choosing fetching which node
+func (f *BlockFetcher) pickRandomNode(nodeAddrs []string) node.Node {
+	addressList := f.candidates(nodeAddrs)
 	if len(addressList) <= 0 {
 		return nil
 	}
@@ -276,12 +419,21 @@ func (f *BlockFetcher) unmarshalResp(body io.Reader) (map[api.NodeItemDataType][
 }
 
 func apiClientURL(n node.Node, height uint64) *url.URL {
+	return apiClientRangeURL(n, height, height)
+}
+
+// apiClientRangeURL returns the url to get the full blocks from `from` to
+// `to`.
+func apiClientRangeURL(n node.Node, from, to uint64) *url.URL {
 	ep := n.Endpoint()
 	u := url.URL(*ep)
 	u.Path = network.UrlPathPrefixNode + runner.GetBlocksPattern
 	q := u.Query()
-	q.Set("height-range", fmt.Sprintf("%d-%d", height, height+1))
+	q.Set("height-range", fmt.Sprintf("%d-%d", from, to+1))
 	q.Set("mode", "full")
+	if to-from+1 > storage.DefaultMaxLimitListOptions {
+		q.Set("limit", strconv.FormatUint(to-from+1, 10))
+	}
 	u.RawQuery = q.Encode()
 
 	return &u
@@ -81,6 +81,82 @@ func TestBlockFetcher(t *testing.T) {
 	require.Equal(t, bk.TransactionsRoot, si.Block.TransactionsRoot)
 }
 
+func TestBlockFetcherFetchRange(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	localNode := node.NewTestLocalNode0()
+
+	var addrs []string
+	for _, name := range []string{"n1", "n2"} {
+		kp := keypair.Random()
+		v, _ := node.NewValidator(kp.Address(), common.MustParseEndpoint("https://"+name+"?NodeName="+name), name)
+		localNode.AddValidators(v)
+		addrs = append(addrs, kp.Address())
+	}
+	cm := &mockConnectionManager{allConnected: addrs}
+
+	genesis := block.GetLatestBlock(st)
+	bt, err := block.GetBlockTransaction(st, genesis.Transactions[0])
+	require.NoError(t, err)
+	tp, err := block.GetTransactionPool(st, bt.Hash)
+	require.NoError(t, err)
+	bt.Message = tp.Message
+
+	blocks := []block.Block{genesis}
+	for i := 0; i < 3; i++ {
+		blocks = append(blocks, block.TestMakeNewBlockWithPrevBlock(blocks[len(blocks)-1], []string{}))
+	}
+
+	var ranges []string
+	cli := mockDoer{
+		handleFunc: func(req *http.Request) (*http.Response, error) {
+			ranges = append(ranges, req.URL.Query().Get("height-range"))
+
+			w := httptest.NewRecorder()
+			for _, bk := range blocks {
+				renderNodeItem(w, api.NodeItemBlock, bk)
+				if bk.Height == common.GenesisBlockHeight {
+					renderNodeItem(w, api.NodeItemBlockTransaction, bt)
+				}
+			}
+			return w.Result(), nil
+		},
+	}
+
+	f := NewBlockFetcher(cm, cli, st, localNode)
+	f.logger = log
+
+	ctx := context.Background()
+	nodelist := &NodeList{}
+	nodelist.SetLatestNodeAddrs(addrs)
+
+	infos, err := f.FetchRange(ctx, 1, 3, nodelist)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1-4"}, ranges)
+	require.Equal(t, 3, len(infos)) // more blocks than requested are ignored
+	for i, si := range infos {
+		require.Equal(t, uint64(i+1), si.Height)
+		require.Equal(t, blocks[i].Hash, si.Block.Hash)
+		require.Contains(t, addrs, si.Node)
+	}
+	require.Equal(t, 1, len(infos[0].Bts))
+
+	// the other node, which was not tried yet, is picked next
+	_, err = f.FetchRange(ctx, 1, 3, nodelist)
+	require.NoError(t, err)
+	stats := f.Peers().Stats()
+	require.Equal(t, 2, len(stats))
+	for _, stat := range stats {
+		require.Equal(t, uint64(3), stat.Blocks)
+		require.Equal(t, 0, stat.InFlight)
+	}
+
+	// the response, which does not start from the requested height
+	_, err = f.FetchRange(ctx, 2, 3, nodelist)
+	require.Error(t, err)
+}
+
 func TestLargeFetch(t *testing.T) {
 	f := &BlockFetcher{}
 	f.logger = log
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/network"
@@ -47,6 +48,13 @@ func (m *mockConnectionManager) Discovery(network.DiscoveryMessage) error {
 	return nil
 }
 
+func (m *mockConnectionManager) TriggerDiscovery() {}
+func (m *mockConnectionManager) Reconnect()        {}
+
+func (m *mockConnectionManager) RoundTripTimes() map[string]time.Duration {
+	return nil
+}
+
 type mockDoer struct {
 	handleFunc func(*http.Request) (*http.Response, error)
 }
@@ -73,3 +81,19 @@ type mockValidator struct {
 func (v mockValidator) Validate(ctx context.Context, si *SyncInfo) error {
 	return v.validateFunc(ctx, si)
 }
+
+type mockRangeFetcher struct {
+	mockFetcher
+	fetchRangeFunc    func(context.Context, uint64, uint64) ([]*SyncInfo, error)
+	reportInvalidFunc func(string, error)
+}
+
+func (f mockRangeFetcher) FetchRange(ctx context.Context, from, to uint64, nodeList *NodeList) ([]*SyncInfo, error) {
+	return f.fetchRangeFunc(ctx, from, to)
+}
+
+func (f mockRangeFetcher) ReportInvalid(node string, err error) {
+	if f.reportInvalidFunc != nil {
+		f.reportInvalidFunc(node, err)
+	}
+}
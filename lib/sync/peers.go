@@ -0,0 +1,144 @@
+package sync
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// PeerThroughputWeight is the weight of the latest fetch for the moving
+// average of throughput.
+const PeerThroughputWeight = 0.3
+
+// PeerStat is the fetching statistics of a peer.
+type PeerStat struct {
+	Address    string    `json:"address"`
+	Blocks     uint64    `json:"blocks"`     // fetched blocks
+	Failures   uint64    `json:"failures"`   // failed fetches and invalid blocks
+	InFlight   int       `json:"in-flight"`  // fetches in progress
+	Throughput float64   `json:"throughput"` // blocks per second
+	LastError  string    `json:"last-error,omitempty"`
+	Updated    time.Time `json:"updated"`
+
+	consecutiveFailures int
+}
+
+// PeerStats tracks the throughput of peers for fetching blocks, so the
+// faster peers are picked more and the failing peers are avoided.
+type PeerStats struct {
+	sync.Mutex
+	peers map[string]*PeerStat
+}
+
+func NewPeerStats() *PeerStats {
+	return &PeerStats{peers: map[string]*PeerStat{}}
+}
+
+func (ps *PeerStats) get(addr string) *PeerStat {
+	p, ok := ps.peers[addr]
+	if !ok {
+		p = &PeerStat{Address: addr}
+		ps.peers[addr] = p
+	}
+	return p
+}
+
+// Pick returns the best peer among `addrs` and marks it in flight; the result
+// should be reported by `Done`. The peer with less fetches in flight is
+// preferred, and the peer not tried yet is explored before the faster one.
+// If `addrs` is empty, Pick returns empty string.
+func (ps *PeerStats) Pick(addrs []string) string {
+	if len(addrs) < 1 {
+		return ""
+	}
+
+	ps.Lock()
+	defer ps.Unlock()
+
+	candidates := make([]*PeerStat, 0, len(addrs))
+	for _, addr := range addrs {
+		candidates = append(candidates, ps.get(addr))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.consecutiveFailures != b.consecutiveFailures {
+			return a.consecutiveFailures < b.consecutiveFailures
+		}
+		if a.InFlight != b.InFlight {
+			return a.InFlight < b.InFlight
+		}
+		if (a.Blocks == 0) != (b.Blocks == 0) {
+			return a.Blocks == 0
+		}
+		return a.Throughput > b.Throughput
+	})
+
+	p := candidates[0]
+	p.InFlight++
+
+	return p.Address
+}
+
+// Done reports the result of fetch from the peer picked by `Pick`.
+func (ps *PeerStats) Done(addr string, blocks int, elapsed time.Duration, err error) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	p := ps.get(addr)
+	if p.InFlight > 0 {
+		p.InFlight--
+	}
+	p.Updated = time.Now()
+
+	if err != nil {
+		p.fail(err)
+		return
+	}
+
+	p.consecutiveFailures = 0
+	p.Blocks += uint64(blocks)
+	if elapsed <= 0 {
+		return
+	}
+
+	throughput := float64(blocks) / elapsed.Seconds()
+	if p.Throughput == 0 {
+		p.Throughput = throughput
+	} else {
+		p.Throughput = PeerThroughputWeight*throughput + (1-PeerThroughputWeight)*p.Throughput
+	}
+}
+
+// Penalize records the failure of the peer, which is found after fetch, like
+// the invalid block.
+func (ps *PeerStats) Penalize(addr string, err error) {
+	ps.Lock()
+	defer ps.Unlock()
+
+	p := ps.get(addr)
+	p.Updated = time.Now()
+	p.fail(err)
+}
+
+func (p *PeerStat) fail(err error) {
+	p.Failures++
+	p.consecutiveFailures++
+	if err != nil {
+		p.LastError = err.Error()
+	}
+}
+
+// Stats returns the copy of statistics ordered by address.
+func (ps *PeerStats) Stats() []PeerStat {
+	ps.Lock()
+	defer ps.Unlock()
+
+	stats := make([]PeerStat, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		stats = append(stats, *p)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Address < stats[j].Address })
+
+	return stats
+}
@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerStats(t *testing.T) {
+	ps := NewPeerStats()
+	addrs := []string{"a", "b", "c"}
+
+	require.Equal(t, "", ps.Pick(nil))
+
+	// untried peers are picked first
+	picked := map[string]bool{}
+	for range addrs {
+		addr := ps.Pick(addrs)
+		picked[addr] = true
+	}
+	require.Equal(t, 3, len(picked))
+
+	ps.Done("a", 10, time.Second, nil)
+	ps.Done("b", 10, 100*time.Millisecond, nil)
+	ps.Done("c", 0, time.Second, errors.New("failed"))
+
+	// faster peer is preferred and failing peer is avoided
+	require.Equal(t, "b", ps.Pick(addrs))
+	require.Equal(t, "a", ps.Pick(addrs))
+	ps.Done("a", 10, time.Second, nil)
+	ps.Done("b", 10, 100*time.Millisecond, nil)
+
+	ps.Penalize("b", errors.New("invalid block"))
+	require.Equal(t, "a", ps.Pick(addrs))
+	ps.Done("a", 10, time.Second, nil)
+
+	stats := ps.Stats()
+	require.Equal(t, 3, len(stats))
+	require.Equal(t, "a", stats[0].Address)
+	require.Equal(t, uint64(30), stats[0].Blocks)
+	require.InDelta(t, 10, stats[0].Throughput, 0.001)
+	require.Equal(t, uint64(1), stats[1].Failures)
+	require.Equal(t, "invalid block", stats[1].LastError)
+	require.Equal(t, uint64(1), stats[2].Failures)
+	require.Equal(t, "failed", stats[2].LastError)
+	for _, stat := range stats {
+		require.Equal(t, 0, stat.InFlight)
+	}
+}
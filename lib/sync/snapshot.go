@@ -0,0 +1,362 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// SnapshotRestoreBatchSize is the number of accounts committed at once while
+// restoring snapshot.
+const SnapshotRestoreBatchSize = 1000
+
+// SnapshotFetcher restores the state snapshot served by the validators, so
+// the new node does not need to validate the blocks from genesis; after
+// restoring, the remaining blocks are synced by `Syncer`.
+//
+// The snapshot is restored only when the signed headers of the snapshot from
+// `threshold` percent of the validators have same block and state root.
+type SnapshotFetcher struct {
+	storage   *storage.LevelDBBackend
+	apiClient Doer
+	localNode *node.LocalNode
+	networkID []byte
+	threshold int
+
+	logger log15.Logger
+}
+
+type SnapshotFetcherOption = func(f *SnapshotFetcher)
+
+func NewSnapshotFetcher(
+	st *storage.LevelDBBackend,
+	client Doer,
+	localNode *node.LocalNode,
+	networkID []byte,
+	threshold int,
+	opts ...SnapshotFetcherOption) *SnapshotFetcher {
+
+	f := &SnapshotFetcher{
+		storage:   st,
+		apiClient: client,
+		localNode: localNode,
+		networkID: networkID,
+		threshold: threshold,
+		logger:    common.NopLogger(),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// quorum returns the number of validators to attest the snapshot.
+func (f *SnapshotFetcher) quorum() int {
+	v := float64(len(f.localNode.GetValidators())) * (float64(f.threshold) / float64(100))
+	return int(math.Ceil(v))
+}
+
+// Fetch restores the latest snapshot attested by the validators. If the
+// storage already has the blocks after genesis, nothing is restored and the
+// returned header is nil.
+func (f *SnapshotFetcher) Fetch(ctx context.Context) (*block.SnapshotHeader, error) {
+	latest := block.GetLatestBlock(f.storage)
+	if latest.Height > common.GenesisBlockHeight {
+		f.logger.Info("skip fast sync; storage already has blocks", "height", latest.Height)
+		return nil, nil
+	}
+
+	headers := f.fetchHeaders(ctx)
+
+	header, validators := f.attested(headers)
+	if header == nil {
+		return nil, errors.StateSnapshotNotFinalized
+	}
+	if header.Block.Height <= latest.Height {
+		f.logger.Info("skip fast sync; snapshot is not newer than storage", "height", header.Block.Height)
+		return nil, nil
+	}
+	f.logger.Info(
+		"found attested snapshot",
+		"height", header.Block.Height,
+		"block", header.Block.Hash,
+		"state-root", header.StateRoot,
+		"validators", validators,
+	)
+
+	var err error
+	for _, address := range validators {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if err = f.restoreFrom(ctx, f.localNode.Validator(address), *header); err == nil {
+			return header, nil
+		}
+		f.logger.Error("failed to restore snapshot", "node", address, "error", err)
+	}
+
+	return nil, err
+}
+
+// fetchHeaders collects the valid headers of the validators.
+func (f *SnapshotFetcher) fetchHeaders(ctx context.Context) map[string]block.SnapshotHeader {
+	headers := map[string]block.SnapshotHeader{}
+	for address, validator := range f.localNode.GetValidators() {
+		if address == f.localNode.Address() {
+			continue
+		}
+
+		resp, err := f.request(ctx, validator, 0, true)
+		if err != nil {
+			f.logger.Debug("failed to get snapshot header", "node", address, "error", err)
+			continue
+		}
+
+		var header block.SnapshotHeader
+		err = json.NewDecoder(resp.Body).Decode(&header)
+		resp.Body.Close()
+		if err != nil {
+			f.logger.Debug("failed to decode snapshot header", "node", address, "error", err)
+			continue
+		}
+
+		if header.Node != address {
+			f.logger.Debug("snapshot header from another node", "node", address, "signer", header.Node)
+			continue
+		}
+		if err = header.Verify(f.networkID); err != nil {
+			f.logger.Debug("invalid snapshot header", "node", address, "error", err)
+			continue
+		}
+
+		headers[address] = header
+	}
+
+	return headers
+}
+
+// attested returns the highest snapshot, whose header is signed by the quorum
+// of validators, and the addresses of the validators.
+func (f *SnapshotFetcher) attested(headers map[string]block.SnapshotHeader) (*block.SnapshotHeader, []string) {
+	var addresses []string
+	for address := range headers {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	var found *block.SnapshotHeader
+	var foundValidators []string
+	quorum := f.quorum()
+	for _, address := range addresses {
+		header := headers[address]
+
+		var validators []string
+		for _, a := range addresses {
+			if header.SameState(headers[a]) {
+				validators = append(validators, a)
+			}
+		}
+		if len(validators) < quorum {
+			continue
+		}
+		if found == nil || header.Block.Height > found.Block.Height {
+			h := header
+			found = &h
+			foundValidators = validators
+		}
+	}
+
+	return found, foundValidators
+}
+
+func (f *SnapshotFetcher) request(ctx context.Context, n node.Node, height uint64, headerOnly bool) (*http.Response, error) {
+	u := url.URL(*n.Endpoint())
+	u.Path = network.UrlPathPrefixNode + runner.GetSnapshotPattern
+	q := u.Query()
+	if height > 0 {
+		q.Set("height", strconv.FormatUint(height, 10))
+	}
+	if headerOnly {
+		q.Set("mode", "header")
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.apiClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.Wrapf(errors.StateSnapshotNotFound, "status=%d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+func (f *SnapshotFetcher) restoreFrom(ctx context.Context, n node.Node, header block.SnapshotHeader) error {
+	if n == nil {
+		return errors.NodeNotFound
+	}
+
+	resp, err := f.request(ctx, n, header.Block.Height, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return RestoreSnapshot(f.storage, resp.Body, header, f.logger)
+}
+
+// RestoreSnapshot stores the accounts, the records of frozen accounts and
+// block of snapshot, which should have the same state with the attested
+// header. The block is stored after all the accounts and records are stored,
+// so the interrupted restore can be tried again.
+func RestoreSnapshot(st *storage.LevelDBBackend, r io.Reader, attested block.SnapshotHeader, logger log15.Logger) (err error) {
+	var sr *block.SnapshotReader
+	if sr, err = block.NewSnapshotReader(r); err != nil {
+		return
+	}
+	if header := sr.Header(); !header.SameState(attested) {
+		return errors.Wrapf(
+			errors.InvalidStateSnapshot,
+			"snapshot is different from attested; height=%d block=%s state-root=%s",
+			header.Block.Height, header.Block.Hash, header.StateRoot,
+		)
+	}
+
+	// the accounts and records are stored after the roots are checked
+	var accounts []block.BlockAccount
+	for {
+		var account block.BlockAccount
+		if account, err = sr.Next(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+		accounts = append(accounts, account)
+	}
+
+	var records []block.SnapshotRecord
+	for {
+		var record block.SnapshotRecord
+		if record, err = sr.NextRecord(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+		records = append(records, record)
+	}
+
+	var bs *storage.LevelDBBackend
+	commit := func() error {
+		if bs == nil {
+			return nil
+		}
+		if err := bs.Commit(); err != nil {
+			bs.Discard()
+			return err
+		}
+		bs = nil
+		return nil
+	}
+
+	for i := range accounts {
+		if bs == nil {
+			if bs, err = st.OpenBatch(); err != nil {
+				return
+			}
+		}
+		if err = accounts[i].Save(bs); err != nil {
+			bs.Discard()
+			return
+		}
+		if (i+1)%SnapshotRestoreBatchSize == 0 {
+			if err = commit(); err != nil {
+				return
+			}
+			logger.Debug("accounts restored", "accounts", i+1)
+		}
+	}
+	if err = commit(); err != nil {
+		return
+	}
+
+	if len(records) > 0 {
+		if bs, err = st.OpenBatch(); err != nil {
+			return
+		}
+		for _, record := range records {
+			if err = record.Save(bs); err != nil {
+				bs.Discard()
+				return
+			}
+		}
+		if err = commit(); err != nil {
+			return
+		}
+	}
+
+	blk := attested.Block
+	if bs, err = st.OpenBatch(); err != nil {
+		return
+	}
+	if err = blk.Save(bs); err != nil {
+		bs.Discard()
+		return
+	}
+	if err = runner.SetCheckedBlockHeight(bs, blk.Height); err != nil {
+		bs.Discard()
+		return
+	}
+	// the transactions before the snapshot are unknown, so the ledger is
+	// made from the restored accounts.
+	var ledger block.Ledger
+	if ledger, err = block.NewLedgerFromState(st, blk); err != nil {
+		bs.Discard()
+		return
+	}
+	if err = ledger.Save(bs); err != nil {
+		bs.Discard()
+		return
+	}
+	if err = commit(); err != nil {
+		return
+	}
+
+	logger.Info(
+		"snapshot restored",
+		"height", blk.Height,
+		"block", blk.Hash,
+		"accounts", len(accounts),
+		"records", len(records),
+	)
+
+	return
+}
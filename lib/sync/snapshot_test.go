@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
+	"boscoin.io/sebak/lib/network"
+	"boscoin.io/sebak/lib/node"
+	"boscoin.io/sebak/lib/node/runner"
+	"boscoin.io/sebak/lib/storage"
+)
+
+// newTestSnapshotServer serves the snapshot of `st` at the latest block like
+// the validator.
+func newTestSnapshotServer(t *testing.T, st *storage.LevelDBBackend, conf common.Config) (*node.Validator, *runner.SnapshotManager, *httptest.Server) {
+	kp := keypair.Random()
+	latest := block.GetLatestBlock(st)
+
+	m := runner.NewSnapshotManager(st, kp, conf.NetworkID, latest.Height, common.NopLogger())
+	m.Check()
+
+	var snapshot *runner.StateSnapshot
+	for i := 0; i < 100 && snapshot == nil; i++ {
+		if snapshot = m.Acquire(); snapshot == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	require.NotNil(t, snapshot)
+	snapshot.Done()
+
+	localNode, err := node.NewLocalNode(kp, nil, "")
+	require.NoError(t, err)
+	nh := runner.NewNetworkHandlerNode(localNode, nil, st, nil, nil, network.UrlPathPrefixNode, conf)
+	nh.SetSnapshots(m)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(nh.HandlerURLPattern(runner.GetSnapshotPattern), nh.GetSnapshotHandler)
+	server := httptest.NewServer(mux)
+
+	endpoint, err := common.ParseEndpoint(server.URL)
+	require.NoError(t, err)
+	validator, err := node.NewValidator(kp.Address(), endpoint, "")
+	require.NoError(t, err)
+
+	return validator, m, server
+}
+
+func TestSnapshotFetcher(t *testing.T) {
+	conf := common.NewTestConfig()
+
+	src := block.InitTestBlockchain()
+	defer src.Close()
+	makeTestChain(t, src, conf, 3)
+
+	var validators []*node.Validator
+	for i := 0; i < 3; i++ {
+		validator, m, server := newTestSnapshotServer(t, src, conf)
+		defer server.Close()
+		defer m.Stop()
+		validators = append(validators, validator)
+	}
+
+	// the next block is made after the snapshot
+	b := new(bytes.Buffer)
+	makeTestChain(t, src, conf, 1)
+	_, err := ExportArchive(src, b, conf.NetworkID, 5, 5)
+	require.NoError(t, err)
+
+	newFetcher := func(dst *storage.LevelDBBackend, validators ...*node.Validator) *SnapshotFetcher {
+		localNode, err := node.NewLocalNode(keypair.Random(), nil, "")
+		require.NoError(t, err)
+		localNode.AddValidators(validators...)
+
+		return NewSnapshotFetcher(dst, &http.Client{}, localNode, conf.NetworkID, 67)
+	}
+
+	{ // without enough validators
+		dst := block.InitTestBlockchain()
+		defer dst.Close()
+
+		unknown, err := node.NewValidator(keypair.Random().Address(), validators[0].Endpoint(), "")
+		require.NoError(t, err)
+
+		_, err = newFetcher(dst, validators[0], unknown).Fetch(context.Background())
+		require.Equal(t, errors.StateSnapshotNotFinalized, err)
+		require.Equal(t, uint64(1), block.GetLatestBlock(dst).Height)
+	}
+
+	dst := block.InitTestBlockchain()
+	defer dst.Close()
+
+	header, err := newFetcher(dst, validators...).Fetch(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, header)
+	require.Equal(t, uint64(4), header.Block.Height)
+
+	latest := block.GetLatestBlock(dst)
+	require.Equal(t, header.Block.Hash, latest.Hash)
+
+	root, accounts, err := block.GetStateRoot(dst)
+	require.NoError(t, err)
+	require.Equal(t, header.StateRoot, root)
+	require.Equal(t, header.Accounts, accounts)
+
+	// the remaining blocks are validated over the snapshot
+	_, imported, err := ImportArchive(context.Background(), dst, b, conf, common.NopLogger())
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), imported)
+
+	expected, err := block.GetBlockAccount(src, block.GenesisKP.Address())
+	require.NoError(t, err)
+	account, err := block.GetBlockAccount(dst, block.GenesisKP.Address())
+	require.NoError(t, err)
+	require.Equal(t, expected.Balance, account.Balance)
+	require.Equal(t, expected.SequenceID, account.SequenceID)
+
+	{ // storage, which has blocks, is not restored
+		header, err := newFetcher(dst, validators...).Fetch(context.Background())
+		require.NoError(t, err)
+		require.Nil(t, header)
+	}
+}
@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"boscoin.io/sebak/lib/metrics"
+	"boscoin.io/sebak/lib/node"
+)
+
+// SyncStatus returns the progress of sync with the rate, the peers and the
+// recent errors, which are collected by `metrics.Sync`.
+func (s *Syncer) SyncStatus(ctx context.Context) (*node.NodeSyncInfo, error) {
+	p, err := s.SyncProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current := s.latestBlockHeight()
+	info := &node.NodeSyncInfo{
+		Syncing:         current < p.HighestBlock,
+		StartHeight:     p.StartingBlock,
+		TargetHeight:    p.HighestBlock,
+		CurrentHeight:   current,
+		BlocksPerSecond: metrics.Sync.BlocksPerSecond(),
+		Peers:           []node.NodeSyncPeer{},
+		Errors:          []node.NodeSyncError{},
+	}
+	if info.Syncing && info.BlocksPerSecond > 0 {
+		remains := float64(p.HighestBlock - current)
+		info.ETA = time.Duration(remains / info.BlocksPerSecond * float64(time.Second))
+	}
+
+	var stats []PeerStat
+	if f, ok := s.fetcher.(interface{ Peers() *PeerStats }); ok {
+		stats = f.Peers().Stats()
+	}
+	for _, stat := range stats {
+		info.Peers = append(info.Peers, node.NodeSyncPeer{
+			Address:    stat.Address,
+			Blocks:     stat.Blocks,
+			Failures:   stat.Failures,
+			InFlight:   stat.InFlight,
+			Throughput: stat.Throughput,
+			LastError:  stat.LastError,
+		})
+	}
+	if len(stats) < 1 { // the fetcher does not track the peers
+		for _, addr := range s.nodelist.NodeAddrs() {
+			info.Peers = append(info.Peers, node.NodeSyncPeer{Address: addr})
+		}
+	}
+
+	for _, e := range metrics.Sync.RecentErrors() {
+		info.Errors = append(info.Errors, node.NodeSyncError{
+			Component: e.Component,
+			Error:     e.Error,
+			Time:      e.Time,
+		})
+	}
+
+	return info, nil
+}
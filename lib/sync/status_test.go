@@ -0,0 +1,83 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/metrics"
+)
+
+type peersFetcher struct {
+	mockFetcher
+	peers *PeerStats
+}
+
+func (f peersFetcher) Peers() *PeerStats {
+	return f.peers
+}
+
+func TestSyncerSyncStatus(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	peers := NewPeerStats()
+	fetcher := peersFetcher{
+		// the blocks are never fetched
+		mockFetcher: mockFetcher{fetchFunc: func(ctx context.Context, si *SyncInfo) (*SyncInfo, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}},
+		peers: peers,
+	}
+
+	syncer := NewSyncer(fetcher, &mockValidator{}, st, func(s *Syncer) {
+		s.afterFunc = func(time.Duration) <-chan time.Time { return nil }
+		s.batchSize = 1
+		s.poolSize = 1
+	})
+	go syncer.Start()
+	defer syncer.Stop()
+
+	ctx := context.Background()
+
+	status, err := syncer.SyncStatus(ctx)
+	require.NoError(t, err)
+	require.False(t, status.Syncing)
+	require.Equal(t, uint64(1), status.CurrentHeight)
+	require.Equal(t, time.Duration(0), status.ETA)
+
+	peers.Pick([]string{"a"})
+	peers.Done("a", 10, time.Second, nil)
+
+	metrics.Sync = metrics.NopSyncMetrics()
+	metrics.Sync.SetHeight(1)
+	time.Sleep(10 * time.Millisecond)
+	metrics.Sync.SetHeight(11)
+	metrics.Sync.AddFetchError(errors.New("fetch failed"))
+	metrics.Sync.AddValidateError(errors.New("invalid block"))
+
+	syncer.SetSyncTargetBlock(ctx, 100, []string{"a"})
+
+	status, err = syncer.SyncStatus(ctx)
+	require.NoError(t, err)
+	require.True(t, status.Syncing)
+	require.Equal(t, uint64(2), status.StartHeight)
+	require.Equal(t, uint64(100), status.TargetHeight)
+	require.Equal(t, uint64(1), status.CurrentHeight)
+	require.True(t, status.BlocksPerSecond > 0)
+	require.True(t, status.ETA > 0)
+
+	require.Equal(t, 1, len(status.Peers))
+	require.Equal(t, "a", status.Peers[0].Address)
+	require.Equal(t, uint64(10), status.Peers[0].Blocks)
+
+	require.Equal(t, 2, len(status.Errors))
+	require.Equal(t, metrics.SyncFetcher, status.Errors[0].Component)
+	require.Equal(t, "fetch failed", status.Errors[0].Error)
+	require.Equal(t, metrics.SyncValidator, status.Errors[1].Component)
+}
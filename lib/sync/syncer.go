@@ -3,11 +3,13 @@ package sync
 import (
 	"context"
 	"strconv"
+	"sync"
 	"time"
 
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
 	"boscoin.io/sebak/lib/common/observer"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/metrics"
 	"boscoin.io/sebak/lib/storage"
 	"github.com/inconshreveable/log15"
@@ -28,6 +30,15 @@ type Syncer struct {
 
 	poolSize      uint64
 	checkInterval time.Duration
+	batchSize     uint64 // blocks fetched at once by `RangeFetcher`
+	fetchAhead    int    // batches fetched ahead of validation
+	retryInterval time.Duration
+	commitRetries int
+
+	pipelining   bool
+	pipelineDone chan struct{}
+	pipelineWG   sync.WaitGroup
+	committed    chan uint64
 
 	afterFunc  AfterFunc
 	workPool   *Pool
@@ -57,6 +68,13 @@ func NewSyncer(
 
 		poolSize:      SyncPoolSize,
 		checkInterval: CheckBlockHeightInterval,
+		batchSize:     SyncBatchSize,
+		fetchAhead:    SyncFetchAhead,
+		retryInterval: RetryInterval,
+		commitRetries: CommitRetries,
+
+		pipelineDone: make(chan struct{}),
+		committed:    make(chan uint64),
 
 		afterFunc: time.After,
 
@@ -87,6 +105,7 @@ func (s *Syncer) Stop() error {
 	s.stop <- c
 	<-c
 	s.workPool.Finish()
+	s.pipelineWG.Wait()
 	s.logger.Info("stopped syncer")
 	return nil
 }
@@ -155,6 +174,13 @@ func (s *Syncer) loop() {
 			s.logger.Debug("got notification finished height")
 			onNotify = false // reset onNotify for singleflight
 			s.sync(syncProgress)
+		case height := <-s.committed:
+			if height > syncProgress.CurrentBlock {
+				syncProgress.CurrentBlock = height
+			}
+		case <-s.pipelineDone:
+			s.pipelining = false
+			s.sync(syncProgress)
 		case req := <-s.requestHighestBlock:
 			height := req.height
 			nodeAddrs := req.nodeAddrs
@@ -165,7 +191,8 @@ func (s *Syncer) loop() {
 				s.sync(syncProgress)
 			}
 		case c := <-s.getSyncProgress:
-			c <- syncProgress
+			p := *syncProgress
+			c <- &p
 		case c := <-s.stop:
 			close(c)
 			return
@@ -206,6 +233,32 @@ func (s *Syncer) sync(p *SyncProgress) {
 		return
 	}
 
+	if rf, ok := s.fetcher.(RangeFetcher); ok && s.batchSize > 1 {
+		if s.pipelining {
+			s.logger.Debug("sync progress skip: pipeline is running", "cur", p.CurrentBlock, "high", p.HighestBlock)
+			return
+		}
+
+		s.pipelining = true
+		s.pipelineWG.Add(1)
+		go func() {
+			defer s.pipelineWG.Done()
+			s.pipeline(s.ctx, rf, startHeight, highestHeight)
+
+			select {
+			case s.pipelineDone <- struct{}{}:
+			case <-s.ctx.Done():
+			}
+		}()
+
+		// `CurrentBlock` is updated by the committed blocks of pipeline
+		p.StartingBlock = startHeight
+		p.CurrentBlock = startHeight - 1
+		s.logger.Info("sync progress",
+			"start", p.StartingBlock, "cur", p.CurrentBlock, "high", p.HighestBlock)
+		return
+	}
+
 	for height := startHeight; height <= highestHeight; height++ {
 		s.logger.Debug("work height", "height", height)
 		// TryAdd for unblocking when the pool is full. Just keep syncprogress for next sync
@@ -245,6 +298,7 @@ func (s *Syncer) work(height uint64) bool {
 		for {
 			select {
 			case <-ctx.Done():
+				err = ctx.Err()
 				break L
 			default:
 				begin := time.Now()
@@ -264,7 +318,7 @@ func (s *Syncer) work(height uint64) bool {
 						break L
 					}
 					s.logger.Error("validate failure", "err", err, "height", height)
-					metrics.Sync.AddValidateError()
+					metrics.Sync.AddValidateError(err)
 					continue
 				}
 				metrics.Sync.ObserveDurationSeconds(begin, metrics.SyncValidator)
@@ -290,3 +344,177 @@ func (s *Syncer) latestBlockHeight() uint64 {
 	blk := block.GetLatestBlock(s.storage)
 	return blk.Height
 }
+
+// pipeline fetches the blocks from `from` to `to` by batch from several nodes
+// at once, and validates them strictly in order. At most `fetchAhead` batches
+// are fetched ahead of validation.
+func (s *Syncer) pipeline(ctx context.Context, rf RangeFetcher, from, to uint64) {
+	s.logger.Debug("start pipeline", "from", from, "to", to, "batch", s.batchSize, "ahead", s.fetchAhead)
+
+	if l, ok := s.validator.(CheckpointLinker); ok {
+		fetch := func(ctx context.Context, from, to uint64) ([]*SyncInfo, error) {
+			infos := s.fetchBatch(ctx, rf, from, to)
+			if infos == nil {
+				return nil, ctx.Err()
+			}
+			return infos, nil
+		}
+		if err := l.LinkCheckpoint(ctx, from, s.batchSize, fetch); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// the blocks, which are not linked, are fully validated
+			s.logger.Error("failed to link checkpoint", "err", err, "from", from)
+		}
+	}
+
+	// the rest batches are not fetched, once a block is not committed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan chan []*SyncInfo, s.fetchAhead)
+	go func() {
+		defer close(batches)
+
+		for start := from; start <= to; start += s.batchSize {
+			end := start + s.batchSize - 1
+			if end > to {
+				end = to
+			}
+
+			c := make(chan []*SyncInfo, 1)
+			select {
+			case batches <- c:
+			case <-ctx.Done():
+				return
+			}
+			go func(start, end uint64) {
+				c <- s.fetchBatch(ctx, rf, start, end)
+			}(start, end)
+		}
+	}()
+
+L:
+	for c := range batches {
+		var infos []*SyncInfo
+		select {
+		case infos = <-c:
+		case <-ctx.Done():
+		}
+		if infos == nil { // canceled
+			break
+		}
+
+		for _, si := range infos {
+			if !s.commit(ctx, rf, si) {
+				break L
+			}
+		}
+	}
+
+	// drain the batches not to leak the producer
+	cancel()
+	for range batches {
+	}
+
+	s.logger.Debug("end pipeline", "from", from, "to", to, "latest", s.latestBlockHeight())
+}
+
+// fetchBatch fetches all the blocks from `from` to `to`; if failed, it tries
+// again until canceled.
+func (s *Syncer) fetchBatch(ctx context.Context, rf RangeFetcher, from, to uint64) []*SyncInfo {
+	var infos []*SyncInfo
+	for height := from; height <= to; {
+		begin := time.Now()
+		fetched, err := rf.FetchRange(ctx, height, to, s.nodelist)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			s.logger.Error("fetch failure", "err", err, "from", height, "to", to)
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(s.retryInterval):
+			}
+			continue
+		}
+		metrics.Sync.ObserveDurationSeconds(begin, metrics.SyncFetcher)
+
+		infos = append(infos, fetched...)
+		height += uint64(len(fetched))
+	}
+
+	return infos
+}
+
+// commit validates and stores the fetched block; if it is invalid, the block
+// is fetched again from another node. If it fails by the local error, like
+// storage error, it is validated again at most `commitRetries` times.
+func (s *Syncer) commit(ctx context.Context, rf RangeFetcher, si *SyncInfo) bool {
+	var retries int
+	for {
+		begin := time.Now()
+		err := s.validator.Validate(ctx, si)
+		if err == nil {
+			metrics.Sync.ObserveDurationSeconds(begin, metrics.SyncValidator)
+			metrics.Sync.SetHeight(si.Height)
+			s.logger.Info("done sync work", "height", si.Height, "hash", si.Block.Hash, "node", si.Node)
+
+			select {
+			case s.committed <- si.Height:
+			case <-ctx.Done():
+			}
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		s.logger.Error("validate failure", "err", err, "height", si.Height, "node", si.Node)
+		metrics.Sync.AddValidateError(err)
+
+		local := isLocalError(err)
+		if local {
+			if retries++; retries > s.commitRetries {
+				s.logger.Error("stop pipeline; too many local errors", "err", err, "height", si.Height)
+				return false
+			}
+		} else {
+			rf.ReportInvalid(si.Node, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(s.retryInterval):
+		}
+
+		if local {
+			continue
+		}
+
+		infos := s.fetchBatch(ctx, rf, si.Height, si.Height)
+		if infos == nil {
+			return false
+		}
+		si = infos[0]
+	}
+}
+
+// isLocalError checks whether the error is caused by the local node, not by
+// the fetched block.
+func isLocalError(err error) bool {
+	e, ok := errors.Cause(err).(*errors.Error)
+	if !ok {
+		return false
+	}
+
+	switch e.Code {
+	case errors.StorageCoreError.Code, errors.NotCommittable.Code, errors.AlreadyCommittable.Code:
+		return true
+	}
+
+	return false
+}
@@ -2,10 +2,12 @@ package sync
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
 	"boscoin.io/sebak/lib/block"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -46,13 +48,24 @@ func TestSyncerSetSyncTarget(t *testing.T) {
 
 		syncer.SetSyncTargetBlock(ctx, height, nodeAddrs)
 
+		// if the work pool was not ready, the rest heights will be tried in
+		// next check interval.
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
 		var heights []uint64
-		for si := range infoc {
-			heights = append(heights, si.Height)
-			if len(heights) >= 9 {
-				close(infoc)
+		for len(heights) < 9 {
+			select {
+			case si := <-infoc:
+				heights = append(heights, si.Height)
+			case <-ticker.C:
+				select {
+				case tctx.tickC <- time.Now():
+				default:
+				}
 			}
 		}
+		close(infoc)
 		require.Equal(t, len(heights), 9)
 
 		progress, err := syncer.SyncProgress(ctx)
@@ -105,3 +118,154 @@ func SyncerTest(t *testing.T, fn func(*SyncerTestContext)) {
 
 	fn(ctx)
 }
+
+func TestSyncerPipeline(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	var (
+		height   uint64 = 20
+		invalids        = make(chan string, 10)
+		infoc           = make(chan *SyncInfo)
+	)
+
+	fetcher := mockRangeFetcher{
+		fetchRangeFunc: func(ctx context.Context, from, to uint64) ([]*SyncInfo, error) {
+			// later batches are downloaded faster than earlier ones
+			time.Sleep(time.Duration(height-from) * time.Millisecond)
+
+			if to > from+1 { // node returns less blocks than requested
+				to = from + 1
+			}
+			var infos []*SyncInfo
+			for h := from; h <= to; h++ {
+				bk := block.TestMakeNewBlock([]string{})
+				bk.Height = h
+				infos = append(infos, &SyncInfo{Height: h, Block: &bk, Node: "node" + strconv.FormatUint(h, 10)})
+			}
+			return infos, nil
+		},
+		reportInvalidFunc: func(node string, err error) {
+			invalids <- node
+		},
+	}
+
+	var failed bool
+	validator := &mockValidator{
+		validateFunc: func(ctx context.Context, si *SyncInfo) error {
+			if si.Height == 7 && !failed {
+				failed = true
+				return errors.InvalidTransaction
+			}
+			infoc <- si
+			return nil
+		},
+	}
+
+	syncer := NewSyncer(fetcher, validator, st, func(s *Syncer) {
+		s.batchSize = 3
+		s.fetchAhead = 2
+		s.retryInterval = time.Millisecond
+	})
+	defer syncer.Stop()
+
+	go syncer.Start()
+
+	ctx := context.Background()
+	syncer.SetSyncTargetBlock(ctx, height, []string{"a", "b"})
+
+	// validated strictly in order
+	for h := uint64(2); h <= height; h++ {
+		select {
+		case si := <-infoc:
+			require.Equal(t, h, si.Height)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout", "height %d", h)
+		}
+	}
+
+	require.Equal(t, "node7", <-invalids)
+
+	progress := waitSyncProgress(t, syncer, height)
+	require.Equal(t, uint64(2), progress.StartingBlock)
+	require.Equal(t, height, progress.HighestBlock)
+}
+
+// waitSyncProgress waits until `CurrentBlock` reaches the height.
+func waitSyncProgress(t *testing.T, syncer *Syncer, height uint64) *SyncProgress {
+	timeout := time.After(5 * time.Second)
+	for {
+		progress, err := syncer.SyncProgress(context.Background())
+		require.NoError(t, err)
+		if progress.CurrentBlock == height {
+			return progress
+		}
+
+		select {
+		case <-timeout:
+			require.Fail(t, "timeout", "current block %d", progress.CurrentBlock)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// The block, which failed by the local error, is validated again without
+// fetching and reporting it; after `commitRetries`, the pipeline stops and
+// starts again from the last committed block.
+func TestSyncerPipelineLocalError(t *testing.T) {
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	var (
+		height    uint64 = 8
+		invalids         = make(chan string, 10)
+		committed        = make(map[uint64]int)
+		attempts  int
+	)
+
+	fetcher := mockRangeFetcher{
+		fetchRangeFunc: func(ctx context.Context, from, to uint64) ([]*SyncInfo, error) {
+			var infos []*SyncInfo
+			for h := from; h <= to; h++ {
+				bk := block.TestMakeNewBlock([]string{})
+				bk.Height = h
+				infos = append(infos, &SyncInfo{Height: h, Block: &bk, Node: "node" + strconv.FormatUint(h, 10)})
+			}
+			return infos, nil
+		},
+		reportInvalidFunc: func(node string, err error) {
+			invalids <- node
+		},
+	}
+
+	validator := &mockValidator{
+		validateFunc: func(ctx context.Context, si *SyncInfo) error {
+			if si.Height == 5 {
+				if attempts++; attempts <= 3 {
+					return errors.Newf(errors.StorageCoreError, "storage error")
+				}
+			}
+			committed[si.Height]++
+			return nil
+		},
+	}
+
+	syncer := NewSyncer(fetcher, validator, st, func(s *Syncer) {
+		s.batchSize = 3
+		s.fetchAhead = 2
+		s.retryInterval = time.Millisecond
+		s.commitRetries = 2
+	})
+	defer syncer.Stop()
+
+	go syncer.Start()
+
+	syncer.SetSyncTargetBlock(context.Background(), height, []string{"a", "b"})
+	waitSyncProgress(t, syncer, height)
+
+	require.Equal(t, 4, attempts)
+	require.Empty(t, invalids)
+	for h := uint64(2); h <= height; h++ {
+		require.Equal(t, 1, committed[h], "height %d", h)
+	}
+}
@@ -28,6 +28,9 @@ type SyncInfo struct {
 	// Fetching target node addresses, NodeList is  the validators which
 	// participated and confirmed the consensus of latest ballot.
 	NodeList *NodeList
+
+	// Node is the address of node, which the block is fetched from.
+	Node string
 }
 
 func (s *SyncInfo) NodeAddrs() []string {
@@ -44,6 +47,19 @@ type Fetcher interface {
 	Fetch(ctx context.Context, syncInfo *SyncInfo) (*SyncInfo, error)
 }
 
+// RangeFetcher fetches the blocks of height range at once.
+type RangeFetcher interface {
+	Fetcher
+	FetchRange(ctx context.Context, from, to uint64, nodeList *NodeList) ([]*SyncInfo, error)
+	ReportInvalid(node string, err error)
+}
+
 type Validator interface {
 	Validate(context.Context, *SyncInfo) error
 }
+
+// CheckpointLinker links the blocks to the trusted checkpoint before they are
+// validated.
+type CheckpointLinker interface {
+	LinkCheckpoint(ctx context.Context, from, batchSize uint64, fetch func(context.Context, uint64, uint64) ([]*SyncInfo, error)) error
+}
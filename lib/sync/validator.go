@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"strconv"
+	"sync"
 	"time"
 
 	"boscoin.io/sebak/lib/block"
@@ -26,6 +27,10 @@ type BlockValidator struct {
 
 	prevBlockWaitTimeout time.Duration // Waiting prev block if is doesn't exist
 	logger               log15.Logger
+	finishBlockFunc      func(block.Block) // called after the block is committed
+
+	linkedLock sync.RWMutex
+	linked     map[uint64]string // block hashes, which are linked to the trusted checkpoint
 }
 
 type BlockValidatorOption func(*BlockValidator)
@@ -36,6 +41,7 @@ func NewBlockValidator(ldb *storage.LevelDBBackend, tp *transaction.Pool, cfg co
 		txpool:               tp,
 		prevBlockWaitTimeout: CheckPrevBlockInterval,
 		commonCfg:            cfg,
+		linked:               map[uint64]string{},
 
 		logger: common.NopLogger(),
 	}
@@ -81,7 +87,8 @@ func (v *BlockValidator) validate(ctx context.Context, syncInfo *SyncInfo) error
 		return err
 	}
 
-	if err := v.validateTxs(ctx, syncInfo); err != nil {
+	// the cheap checks go first
+	if err := v.validateCheckpoint(ctx, syncInfo); err != nil {
 		return err
 	}
 
@@ -89,6 +96,157 @@ func (v *BlockValidator) validate(ctx context.Context, syncInfo *SyncInfo) error
 		return err
 	}
 
+	if hash, trusted := v.linkedHash(syncInfo.Height); !trusted {
+		if err := v.validateTxs(ctx, syncInfo); err != nil {
+			return err
+		}
+	} else if hash != syncInfo.Block.Hash {
+		v.logger.Error("block is not linked to checkpoint", "height", syncInfo.Height, "hash", syncInfo.Block.Hash, "linked", hash)
+		return errors.Wrapf(errors.CheckpointMismatch, "height %d: expected %s, but %s", syncInfo.Height, hash, syncInfo.Block.Hash)
+	} else if err := v.validateTxHashes(ctx, syncInfo); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// trusted checks the block is under the last checkpoint, which is trusted by
+// `common.Config.TrustCheckpoints`.
+func (v *BlockValidator) trusted(height uint64) bool {
+	if !v.commonCfg.TrustCheckpoints {
+		return false
+	}
+
+	last, found := v.commonCfg.Checkpoints.Last()
+	return found && height <= last.Height
+}
+
+// linkedHash returns the hash of trusted block, which is linked to the
+// checkpoint by `LinkCheckpoint`. The trusted block, which is not linked yet,
+// is fully validated.
+func (v *BlockValidator) linkedHash(height uint64) (string, bool) {
+	if !v.trusted(height) {
+		return "", false
+	}
+
+	v.linkedLock.RLock()
+	defer v.linkedLock.RUnlock()
+
+	hash, found := v.linked[height]
+	return hash, found
+}
+
+// LinkCheckpoint fetches the blocks from the last checkpoint down to `from`
+// by `fetch` and keeps their hashes, which are linked to the checkpoint by
+// `PrevBlockHash`. Only the trusted blocks, which match with these hashes,
+// skip the validation of transactions, so the forged chain can not be stored
+// under the checkpoint.
+func (v *BlockValidator) LinkCheckpoint(ctx context.Context, from, batchSize uint64, fetch func(context.Context, uint64, uint64) ([]*SyncInfo, error)) error {
+	if !v.trusted(from) {
+		return nil
+	}
+	last, _ := v.commonCfg.Checkpoints.Last()
+
+	height, hash := last.Height, last.Hash
+	v.linkedLock.RLock()
+	for h := last.Height; h >= from; h-- {
+		linked, found := v.linked[h]
+		if !found {
+			break
+		}
+		height, hash = h, linked
+	}
+	v.linkedLock.RUnlock()
+
+	v.logger.Debug("start linking checkpoint", "from", from, "height", height, "hash", hash)
+
+	for height >= from {
+		start := from
+		if height-from >= batchSize {
+			start = height - batchSize + 1
+		}
+
+		infos, err := fetch(ctx, start, height)
+		if err != nil {
+			return err
+		}
+
+		linked := map[uint64]string{}
+		for i := len(infos) - 1; i >= 0 && height >= start; i-- {
+			blk := infos[i].Block
+			if blk.Height != height {
+				continue
+			}
+			if blk.Hash != hash || blk.Hash != makeBlockHash(*blk) {
+				return errors.Wrapf(errors.CheckpointMismatch, "height %d: expected %s, but %s", height, hash, blk.Hash)
+			}
+
+			linked[height] = hash
+			height, hash = height-1, blk.PrevBlockHash
+		}
+		if len(linked) < 1 {
+			return errors.Wrapf(errors.CheckpointMismatch, "height %d: block is not fetched", height)
+		}
+
+		v.linkedLock.Lock()
+		for h, hash := range linked {
+			v.linked[h] = hash
+		}
+		v.linkedLock.Unlock()
+	}
+
+	v.logger.Debug("end linking checkpoint", "from", from, "checkpoint", last.Height)
+	return nil
+}
+
+// makeBlockHash makes the hash of block from its header, so the hash of the
+// previous block is included.
+func makeBlockHash(blk block.Block) string {
+	r := voting.Basis{
+		Round:     blk.Round,
+		Height:    blk.Height,
+		BlockHash: blk.PrevBlockHash,
+		TotalTxs:  blk.TotalTxs,
+		TotalOps:  blk.TotalOps,
+	}
+
+	return block.NewBlock(blk.Proposer, r, blk.ProposerTransaction, blk.Transactions, blk.ProposedTime).Hash
+}
+
+// validateCheckpoint refuses the block, which contradicts the checkpoint.
+// The blocks before the checkpoint are linked to it by `PrevBlockHash`, so the
+// chain, which contradicts the checkpoint, can not pass over it.
+func (v *BlockValidator) validateCheckpoint(ctx context.Context, si *SyncInfo) error {
+	hash, found := v.commonCfg.Checkpoints.Get(si.Height)
+	if !found {
+		return nil
+	}
+
+	if hash != si.Block.Hash {
+		v.logger.Error("block does not match with checkpoint", "height", si.Height, "hash", si.Block.Hash, "checkpoint", hash)
+		return errors.Wrapf(errors.CheckpointMismatch, "height %d: expected %s, but %s", si.Height, hash, si.Block.Hash)
+	}
+
+	return nil
+}
+
+// validateTxHashes only checks the hashes of transactions, which are
+// included in the block hash; the signatures and the state are not
+// validated.
+func (v *BlockValidator) validateTxHashes(ctx context.Context, si *SyncInfo) error {
+	if si.Ptx != nil {
+		if si.Ptx.B.MakeHashString() != si.Ptx.H.Hash || si.Ptx.H.Hash != si.Block.ProposerTransaction {
+			return errors.HashDoesNotMatch
+		}
+	}
+
+	for _, bt := range si.Bts {
+		tx := bt.Transaction()
+		if tx.B.MakeHashString() != tx.H.Hash || tx.H.Hash != bt.Hash {
+			return errors.HashDoesNotMatch
+		}
+	}
+
 	return nil
 }
 
@@ -161,6 +319,11 @@ func (v *BlockValidator) finishBlock(ctx context.Context, syncInfo *SyncInfo) er
 			bs.Discard()
 			return err
 		}
+
+		if err := runner.FinishLedger(bs, blk, txs, *ptx); err != nil {
+			bs.Discard()
+			return err
+		}
 	}
 
 	v.logger.Debug("finish to sync block height", "height", syncInfo.Height, "hash", blk.Hash)
@@ -170,10 +333,18 @@ func (v *BlockValidator) finishBlock(ctx context.Context, syncInfo *SyncInfo) er
 		return err
 	}
 
+	v.linkedLock.Lock()
+	delete(v.linked, syncInfo.Height)
+	v.linkedLock.Unlock()
+
 	//clean up txs of this block in txpool.
 	v.txpool.Remove(blk.Transactions...)
 	v.txpool.Remove(blk.ProposerTransaction)
 
+	if v.finishBlockFunc != nil {
+		v.finishBlockFunc(blk)
+	}
+
 	select {
 	case <-ctx.Done():
 		return nil
@@ -1,12 +1,17 @@
 package sync
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"testing"
 
 	"boscoin.io/sebak/lib/block"
 	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/common/keypair"
+	"boscoin.io/sebak/lib/errors"
 	"boscoin.io/sebak/lib/transaction"
+	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,3 +38,147 @@ func TestValidator(t *testing.T) {
 		require.NoError(t, err)
 	}
 }
+
+func TestValidatorFinishBlockFunc(t *testing.T) {
+	conf := common.NewTestConfig()
+	st := block.InitTestBlockchain()
+	defer st.Close()
+
+	var finished []uint64
+	makeTestChain(t, st, conf, 3, func(v *BlockValidator) {
+		v.finishBlockFunc = func(blk block.Block) {
+			require.Equal(t, blk.Hash, block.GetLatestBlock(st).Hash)
+			finished = append(finished, blk.Height)
+		}
+	})
+	require.Equal(t, []uint64{2, 3, 4}, finished)
+}
+
+func TestValidatorCheckpoints(t *testing.T) {
+	conf := common.NewTestConfig()
+
+	src := block.InitTestBlockchain()
+	defer src.Close()
+	makeTestChain(t, src, conf, 3)
+
+	// the blocks of height 2 to 4
+	syncInfos := func() (infos []*SyncInfo) {
+		b := new(bytes.Buffer)
+		_, err := ExportArchive(src, b, conf.NetworkID, 2, 4)
+		require.NoError(t, err)
+		ar, err := NewArchiveReader(b)
+		require.NoError(t, err)
+		for {
+			a, err := ar.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			infos = append(infos, a.SyncInfo())
+		}
+		return
+	}
+
+	blk3, err := block.GetBlockByHeight(src, 3)
+	require.NoError(t, err)
+	blk4, err := block.GetBlockByHeight(src, 4)
+	require.NoError(t, err)
+
+	// fetchFrom serves the blocks of `infos` to `BlockValidator.LinkCheckpoint`
+	fetchFrom := func(infos []*SyncInfo) func(context.Context, uint64, uint64) ([]*SyncInfo, error) {
+		return func(ctx context.Context, from, to uint64) (fetched []*SyncInfo, err error) {
+			for _, si := range infos {
+				if si.Height >= from && si.Height <= to {
+					fetched = append(fetched, si)
+				}
+			}
+			return
+		}
+	}
+
+	validate := func(conf common.Config, infos []*SyncInfo, linkFrom []*SyncInfo) (uint64, error) {
+		dst := block.InitTestBlockchain()
+		defer dst.Close()
+
+		v := NewBlockValidator(dst, transaction.NewPool(conf), conf)
+		if linkFrom != nil {
+			if err := v.LinkCheckpoint(context.Background(), 2, 2, fetchFrom(linkFrom)); err != nil {
+				return 0, err
+			}
+		}
+		for _, si := range infos {
+			if err := v.Validate(context.Background(), si); err != nil {
+				return si.Height, err
+			}
+		}
+		return 0, nil
+	}
+
+	{ // matched checkpoints
+		conf := conf
+		conf.Checkpoints = common.Checkpoints{{Height: 3, Hash: blk3.Hash}, {Height: 4, Hash: blk4.Hash}}
+		_, err := validate(conf, syncInfos(), nil)
+		require.NoError(t, err)
+	}
+
+	{ // the chain, which contradicts the checkpoint, is refused
+		conf := conf
+		conf.Checkpoints = common.Checkpoints{{Height: 3, Hash: blk4.Hash}}
+		height, err := validate(conf, syncInfos(), nil)
+		require.Equal(t, errors.CheckpointMismatch, errors.Cause(err))
+		require.Equal(t, uint64(3), height)
+	}
+
+	// the signature is not included in the block hash
+	infos := syncInfos()
+	tx := infos[0].Bts[0].Transaction()
+	signature, err := keypair.MakeSignature(keypair.Random(), conf.NetworkID, tx.H.Hash)
+	require.NoError(t, err)
+	tx.H.Signature = base58.Encode(signature)
+	bt := block.NewBlockTransactionFromTransaction(infos[0].Block.Hash, infos[0].Height, infos[0].Block.ProposedTime, tx)
+	infos[0].Bts[0] = &bt
+
+	{ // signatures are validated
+		conf := conf
+		conf.Checkpoints = common.Checkpoints{{Height: 4, Hash: blk4.Hash}}
+		height, err := validate(conf, infos, nil)
+		require.Error(t, err)
+		require.Equal(t, uint64(2), height)
+	}
+
+	{ // the trusted blocks, which are not linked to the checkpoint, are validated
+		conf := conf
+		conf.Checkpoints = common.Checkpoints{{Height: 4, Hash: blk4.Hash}}
+		conf.TrustCheckpoints = true
+		height, err := validate(conf, infos, nil)
+		require.Error(t, err)
+		require.Equal(t, uint64(2), height)
+	}
+
+	{ // the transactions until the trusted checkpoint are not validated
+		conf := conf
+		conf.Checkpoints = common.Checkpoints{{Height: 4, Hash: blk4.Hash}}
+		conf.TrustCheckpoints = true
+		_, err := validate(conf, infos, infos)
+		require.NoError(t, err)
+	}
+
+	{ // the forged chain is not linked to the trusted checkpoint
+		forged := syncInfos()
+		blk := block.TestMakeNewBlockWithPrevBlock(*forged[0].Block, nil)
+		blk.Height = 3
+		forged[1] = &SyncInfo{Height: 3, Block: &blk, Ptx: forged[1].Ptx}
+
+		conf := conf
+		conf.Checkpoints = common.Checkpoints{{Height: 4, Hash: blk4.Hash}}
+		conf.TrustCheckpoints = true
+		_, err := validate(conf, forged, forged)
+		require.Equal(t, errors.CheckpointMismatch, errors.Cause(err))
+
+		// without linking, the forged block is fully validated and the chain
+		// is refused at the checkpoint
+		height, err := validate(conf, forged, nil)
+		require.Error(t, err)
+		require.Equal(t, uint64(4), height)
+	}
+}
@@ -32,6 +32,7 @@ type Watcher struct {
 	client    Doer
 	after     AfterFunc
 	interval  time.Duration
+	highest   func(context.Context) (uint64, []string, error) // highest height and the nodes, which have it
 	stop      chan chan struct{}
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -54,6 +55,7 @@ func NewWatcher(
 		localNode: ln,
 		after:     time.After,
 		interval:  5 * time.Second,
+		stop:      make(chan chan struct{}),
 		ctx:       ctx,
 		cancel:    cancel,
 		logger:    common.NopLogger(),
@@ -61,6 +63,9 @@ func NewWatcher(
 	for _, o := range opts {
 		o(w)
 	}
+	if w.highest == nil {
+		w.highest = w.highestHeightAndNodes
+	}
 	return w
 }
 
@@ -100,7 +105,7 @@ L:
 	for {
 		select {
 		case <-checkc:
-			highestHeight, nodes, err = w.highestHeightAndNodes(ctx)
+			highestHeight, nodes, err = w.highest(ctx)
 			if err != nil {
 				if err == context.Canceled {
 					break L
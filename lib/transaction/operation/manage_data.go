@@ -0,0 +1,61 @@
+package operation
+
+import (
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// ManageData sets the data entry of the source account by `Key`; the entry is
+// deleted when `Value` is empty.
+type ManageData struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+func NewManageData(key string, value []byte) ManageData {
+	return ManageData{
+		Key:   key,
+		Value: value,
+	}
+}
+
+// IsValidDataKey checks the key of data entry; the key consists of the
+// alphanumerics and `-`, `_`, `.` and `:`, so it can be used in the URL path.
+func IsValidDataKey(key string) bool {
+	if len(key) < 1 || len(key) > common.MaxDataKeyLength {
+		return false
+	}
+
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Implement transaction/operation : IsWellFormed
+func (o ManageData) IsWellFormed(common.Config) (err error) {
+	if !IsValidDataKey(o.Key) {
+		return errors.DataEntryKeyInvalid
+	}
+
+	if len(o.Value) > common.MaxDataValueLength {
+		return errors.DataEntryValueTooLong
+	}
+
+	return
+}
+
+// IsDelete returns `true` when the operation deletes the data entry.
+func (o ManageData) IsDelete() bool {
+	return len(o.Value) < 1
+}
+
+func (o ManageData) HasFee() bool {
+	return true
+}
@@ -0,0 +1,53 @@
+package operation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestManageDataOperation(t *testing.T) {
+	conf := common.NewTestConfig()
+
+	{ // set
+		o := NewManageData("congress.member:1", []byte("findme"))
+		require.NoError(t, o.IsWellFormed(conf))
+		require.False(t, o.IsDelete())
+	}
+
+	{ // delete
+		o := NewManageData("home_domain", nil)
+		require.NoError(t, o.IsWellFormed(conf))
+		require.True(t, o.IsDelete())
+	}
+
+	{ // invalid key
+		for _, key := range []string{"", "home domain", "kyc/1", strings.Repeat("k", common.MaxDataKeyLength+1)} {
+			o := NewManageData(key, []byte("findme"))
+			require.Equal(t, errors.DataEntryKeyInvalid, o.IsWellFormed(conf), key)
+		}
+	}
+
+	{ // too long value
+		o := NewManageData("kyc", make([]byte, common.MaxDataValueLength+1))
+		require.Equal(t, errors.DataEntryValueTooLong, o.IsWellFormed(conf))
+	}
+}
+
+func TestSerializeOperationManageData(t *testing.T) {
+	op, err := NewOperation(NewManageData("kyc", []byte{0x00, 0x01}))
+	require.NoError(t, err)
+	require.Equal(t, TypeManageData, op.H.Type)
+	common.CheckRoundTripRLP(t, op)
+
+	b := common.MustMarshalJSON(op)
+
+	var o Operation
+	require.NoError(t, json.Unmarshal(b, &o))
+	require.Equal(t, op, o)
+}
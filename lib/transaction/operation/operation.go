@@ -20,6 +20,7 @@ const (
 	TypeInflation
 	TypeUnfreezingRequest
 	TypeInflationPF
+	TypeManageData
 )
 
 var (
@@ -32,6 +33,7 @@ var (
 		"inflation",
 		"unfreezing-request",
 		"inflation-pf",
+		"manage-data",
 	}
 )
 
@@ -60,7 +62,8 @@ func IsNormalOperation(t OperationType) bool {
 	switch t {
 	case TypeCreateAccount, TypePayment,
 		TypeCongressVoting, TypeCongressVotingResult,
-		TypeUnfreezingRequest, TypeInflationPF:
+		TypeUnfreezingRequest, TypeInflationPF,
+		TypeManageData:
 		return true
 	default:
 		return false
@@ -91,6 +94,8 @@ func NewOperation(opb Body) (op Operation, err error) {
 		t = TypeCongressVotingResult
 	case InflationPF:
 		t = TypeInflationPF
+	case ManageData:
+		t = TypeManageData
 	default:
 		err = errors.UnknownOperationType
 		return
@@ -263,6 +268,8 @@ func newBodyFromType(ty OperationType) (interface{}, error) {
 		return &UnfreezeRequest{}, nil
 	case TypeInflationPF:
 		return &InflationPF{}, nil
+	case TypeManageData:
+		return &ManageData{}, nil
 	default:
 		return nil, errors.InvalidOperation
 	}
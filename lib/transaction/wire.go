@@ -0,0 +1,58 @@
+package transaction
+
+import (
+	"github.com/btcsuite/btcutil/base58"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+// wireTransaction is the RLP wire form of `Transaction`. The signature is sent
+// as raw bytes instead of base58 string and `Header.Hash` is not sent, it is
+// made again from `Body` like `UnmarshalJSON`.
+type wireTransaction struct {
+	Version   string
+	Created   string
+	Signature []byte
+	B         Body
+}
+
+// SerializeRLP encodes the transaction in the compact wire format,
+// `common.ContentTypeRLP`.
+func (tx Transaction) SerializeRLP() (encoded []byte, err error) {
+	w := wireTransaction{
+		Version: tx.H.Version,
+		Created: tx.H.Created,
+		B:       tx.B,
+	}
+	if w.Signature, err = common.Base58ToBytes(tx.H.Signature); err != nil {
+		return
+	}
+
+	return common.EncodeToBytes(w)
+}
+
+func (w wireTransaction) transaction() Transaction {
+	return Transaction{
+		H: Header{
+			Version:   w.Version,
+			Created:   w.Created,
+			Hash:      w.B.MakeHashString(),
+			Signature: base58.Encode(w.Signature),
+		},
+		B: w.B,
+	}
+}
+
+// NewTransactionFromRLP decodes the transaction encoded by `SerializeRLP`.
+func NewTransactionFromRLP(data []byte) (tx Transaction, err error) {
+	var w wireTransaction
+	if err = common.DecodeBytes(data, &w); err != nil {
+		err = errors.InvalidWireEncoding.Clone().SetData("error", err.Error())
+		return
+	}
+
+	tx = w.transaction()
+
+	return
+}
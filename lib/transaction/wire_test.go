@@ -0,0 +1,30 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"boscoin.io/sebak/lib/common"
+	"boscoin.io/sebak/lib/errors"
+)
+
+func TestTransactionRLPWire(t *testing.T) {
+	conf := common.NewTestConfig()
+	_, tx := TestMakeTransaction(conf.NetworkID, 3)
+
+	encoded, err := tx.SerializeRLP()
+	require.NoError(t, err)
+
+	decoded, err := NewTransactionFromRLP(encoded)
+	require.NoError(t, err)
+	require.NoError(t, decoded.IsWellFormed(conf))
+	require.Equal(t, tx, decoded)
+
+	jsonEncoded, _ := tx.Serialize()
+	require.True(t, len(encoded) < len(jsonEncoded))
+
+	_, err = NewTransactionFromRLP(encoded[1:])
+	require.Error(t, err)
+	require.Equal(t, errors.InvalidWireEncoding.Code, err.(*errors.Error).Code)
+}